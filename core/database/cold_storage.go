@@ -0,0 +1,55 @@
+package database
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/database"
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/storage"
+)
+
+// creates the cold storage in which the milestones are archived before they get pruned.
+// the cold storage consists of a tangle and a UTXO database within the given path, which use the given engine.
+// the in-memory engine is not supported, since the archived milestones would be lost after a restart.
+func newColdStorage(engine database.Engine, path string) (*storage.ColdStorage, error) {
+
+	if engine == database.EngineMapDB {
+		return nil, errors.Errorf("database engine %s is not supported for the cold storage, supported engines: pebble/rocksdb", engine)
+	}
+
+	tangleDatabasePath := filepath.Join(path, TangleDatabaseDirectoryName)
+	utxoDatabasePath := filepath.Join(path, UTXODatabaseDirectoryName)
+
+	tangleTargetEngine, err := database.CheckDatabaseEngine(tangleDatabasePath, true, engine)
+	if err != nil {
+		return nil, err
+	}
+
+	utxoTargetEngine, err := database.CheckDatabaseEngine(utxoDatabasePath, true, engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if tangleTargetEngine != utxoTargetEngine {
+		return nil, errors.Errorf("cold storage tangle database engine does not match UTXO database engine (%s != %s)", tangleTargetEngine, utxoTargetEngine)
+	}
+
+	var tangleDatabase, utxoDatabase *database.Database
+
+	switch tangleTargetEngine {
+	case database.EnginePebble:
+		tangleDatabase = newPebble(tangleDatabasePath, &metrics.DatabaseMetrics{})
+		utxoDatabase = newPebble(utxoDatabasePath, &metrics.DatabaseMetrics{})
+
+	case database.EngineRocksDB:
+		tangleDatabase = newRocksDB(tangleDatabasePath, &metrics.DatabaseMetrics{})
+		utxoDatabase = newRocksDB(utxoDatabasePath, &metrics.DatabaseMetrics{})
+
+	default:
+		return nil, errors.Errorf("unknown database engine: %s, supported engines: pebble/rocksdb", tangleTargetEngine)
+	}
+
+	return storage.NewColdStorage(tangleDatabase.KVStore(), utxoDatabase.KVStore())
+}
@@ -62,6 +62,7 @@ type dependencies struct {
 	UTXODatabase   *database.Database `name:"utxoDatabase"`
 	Storage        *storage.Storage
 	StorageMetrics *metrics.StorageMetrics
+	NodeConfig     *configuration.Configuration `name:"nodeConfig"`
 }
 
 func initConfigPars(c *dig.Container) {
@@ -205,6 +206,7 @@ func provide(c *dig.Container) {
 		TangleDatabase *database.Database `name:"tangleDatabase"`
 		UTXODatabase   *database.Database `name:"utxoDatabase"`
 		Profile        *profile.Profile
+		NodeConfig     *configuration.Configuration `name:"nodeConfig"`
 	}
 
 	type storageOut struct {
@@ -220,6 +222,14 @@ func provide(c *dig.Container) {
 			CorePlugin.LogPanicf("can't initialize storage: %s", err)
 		}
 
+		if deps.NodeConfig.Bool(CfgDatabaseColdStorageEnabled) {
+			coldStorage, err := newColdStorage(deps.TangleDatabase.Engine(), deps.NodeConfig.String(CfgDatabaseColdStoragePath))
+			if err != nil {
+				CorePlugin.LogPanicf("can't initialize cold storage: %s", err)
+			}
+			store.SetColdStorage(coldStorage)
+		}
+
 		store.PrintSnapshotInfo()
 
 		return storageOut{
@@ -265,6 +275,18 @@ func configure() {
 		}
 	}
 
+	addressIndexEnabled := deps.NodeConfig.Bool(CfgDatabaseAddressIndex)
+	if addressIndexEnabled && !deps.Storage.UTXOManager().AddressIndexEnabled() {
+		CorePlugin.LogInfo("Building address index ...")
+	}
+	rebuilt, err := deps.Storage.UTXOManager().ConfigureAddressIndex(addressIndexEnabled)
+	if err != nil {
+		CorePlugin.LogPanicf("configuring address index failed: %s", err)
+	}
+	if rebuilt {
+		CorePlugin.LogInfo("Building address index ... done")
+	}
+
 	if err = CorePlugin.Daemon().BackgroundWorker("Close database", func(ctx context.Context) {
 		<-ctx.Done()
 
@@ -22,5 +22,6 @@ func newMapDB(metrics *metrics.DatabaseMetrics) *database.Database {
 		events,
 		false,
 		nil,
+		nil,
 	)
 }
@@ -16,6 +16,12 @@ const (
 	CfgDatabaseAutoRevalidation = "db.autoRevalidation"
 	// ignore the check for corrupted databases (should only be used for debug reasons).
 	CfgDatabaseDebug = "db.debug"
+	// whether to maintain an index of the unspent outputs by address in the UTXO ledger.
+	CfgDatabaseAddressIndex = "db.addressIndex"
+	// whether to archive pruned milestones to the cold storage instead of deleting them.
+	CfgDatabaseColdStorageEnabled = "db.coldStorage.enabled"
+	// the path to the cold storage folder.
+	CfgDatabaseColdStoragePath = "db.coldStorage.path"
 )
 
 var params = &node.PluginParams{
@@ -26,6 +32,9 @@ var params = &node.PluginParams{
 			fs.String(CfgDatabasePath, "mainnetdb", "the path to the database folder")
 			fs.Bool(CfgDatabaseAutoRevalidation, false, "whether to automatically start revalidation on startup if the database is corrupted")
 			fs.Bool(CfgDatabaseDebug, false, "ignore the check for corrupted databases (should only be used for debug reasons)")
+			fs.Bool(CfgDatabaseAddressIndex, false, "whether to maintain an index of the unspent outputs by address in the UTXO ledger")
+			fs.Bool(CfgDatabaseColdStorageEnabled, false, "whether to archive pruned milestones to the cold storage instead of deleting them")
+			fs.String(CfgDatabaseColdStoragePath, "coldstorage", "the path to the cold storage folder")
 			return fs
 		}(),
 	},
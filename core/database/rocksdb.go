@@ -41,6 +41,8 @@ func newRocksDB(path string, metrics *metrics.DatabaseMetrics) *database.Databas
 			}
 			return false
 		},
+		// the checkpoint facility of RocksDB is not exposed by the kvstore
+		nil,
 	)
 
 	return database
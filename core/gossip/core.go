@@ -36,7 +36,7 @@ const (
 	heartbeatReceiveTimeout = 100 * time.Second
 	checkHeartbeatsInterval = 5 * time.Second
 
-	iotaGossipProtocolIDTemplate = "/iota-gossip/%d/1.0.0"
+	iotaGossipProtocolIDTemplate = "/iota-gossip/%d/%d.0.0"
 )
 
 func init() {
@@ -60,6 +60,15 @@ var (
 	onGossipServiceProtocolStarted     *events.Closure
 	onGossipServiceProtocolTerminated  *events.Closure
 	onMessageProcessorBroadcastMessage *events.Closure
+	onPruningMilestoneIndexChanged     *events.Closure
+)
+
+var (
+	// the identifiers of the plugins which provide services to other nodes and are announced in extended heartbeats.
+	heartbeatServicePlugins = map[string]struct{}{
+		"autopeering":    {},
+		"snapshotserver": {},
+	}
 )
 
 type dependencies struct {
@@ -99,6 +108,7 @@ func provide(c *dig.Container) {
 		BelowMaxDepth             int     `name:"belowMaxDepth"`
 		MinPoWScore               float64 `name:"minPoWScore"`
 		Profile                   *profile.Profile
+		SnapshotManager           *snapshot.SnapshotManager
 	}
 
 	if err := c.Provide(func(deps msgProcDeps) *gossip.MessageProcessor {
@@ -115,6 +125,14 @@ func provide(c *dig.Container) {
 				NetworkID:         deps.NetworkID,
 				BelowMaxDepth:     milestone.Index(deps.BelowMaxDepth),
 				WorkUnitCacheOpts: deps.Profile.Caches.IncomingMessagesFilter,
+				ScoreChanges: gossip.ScoreChanges{
+					InvalidMessagePenalty:    deps.NodeConfig.Int64(CfgP2PGossipScoringInvalidMessagePenalty),
+					InsufficientPoWPenalty:   deps.NodeConfig.Int64(CfgP2PGossipScoringInsufficientPoWPenalty),
+					InvalidRequestPenalty:    deps.NodeConfig.Int64(CfgP2PGossipScoringInvalidRequestPenalty),
+					UnansweredRequestPenalty: deps.NodeConfig.Int64(CfgP2PGossipScoringUnansweredRequestPenalty),
+					NewMessageReward:         deps.NodeConfig.Int64(CfgP2PGossipScoringNewMessageReward),
+				},
+				ArchiveNode: isArchiveNode(deps.SnapshotManager, deps.Storage),
 			})
 		if err != nil {
 			CorePlugin.LogPanicf("MessageProcessor initialization failed: %s", err)
@@ -137,7 +155,7 @@ func provide(c *dig.Container) {
 
 	if err := c.Provide(func(deps serviceDeps) *gossip.Service {
 		return gossip.NewService(
-			protocol.ID(fmt.Sprintf(iotaGossipProtocolIDTemplate, deps.NetworkID)),
+			protocol.ID(fmt.Sprintf(iotaGossipProtocolIDTemplate, deps.NetworkID, gossip.MinimumVersion)),
 			deps.Host,
 			deps.PeeringManager,
 			deps.ServerMetrics,
@@ -145,6 +163,16 @@ func provide(c *dig.Container) {
 			gossip.WithUnknownPeersLimit(deps.NodeConfig.Int(CfgP2PGossipUnknownPeersLimit)),
 			gossip.WithStreamReadTimeout(deps.NodeConfig.Duration(CfgP2PGossipStreamReadTimeout)),
 			gossip.WithStreamWriteTimeout(deps.NodeConfig.Duration(CfgP2PGossipStreamWriteTimeout)),
+			gossip.WithBandwidthLimits(gossip.BandwidthLimits{
+				PeerInbound:    deps.NodeConfig.Int(CfgP2PGossipBandwidthPeerInboundLimit),
+				PeerOutbound:   deps.NodeConfig.Int(CfgP2PGossipBandwidthPeerOutboundLimit),
+				GlobalInbound:  deps.NodeConfig.Int(CfgP2PGossipBandwidthGlobalInboundLimit),
+				GlobalOutbound: deps.NodeConfig.Int(CfgP2PGossipBandwidthGlobalOutboundLimit),
+			}),
+			gossip.WithCapabilities(
+				protocol.ID(fmt.Sprintf(iotaGossipProtocolIDTemplate, deps.NetworkID, gossip.CapabilitiesVersion)),
+				gossip.NewLocalCapabilities(),
+			),
 		)
 	}); err != nil {
 		CorePlugin.LogPanic(err)
@@ -171,10 +199,11 @@ func provide(c *dig.Container) {
 
 	type broadcasterDeps struct {
 		dig.In
-		Storage        *storage.Storage
-		SyncManager    *syncmanager.SyncManager
-		PeeringManager *p2p.Manager
-		GossipService  *gossip.Service
+		Storage         *storage.Storage
+		SyncManager     *syncmanager.SyncManager
+		PeeringManager  *p2p.Manager
+		GossipService   *gossip.Service
+		SnapshotManager *snapshot.SnapshotManager
 	}
 
 	if err := c.Provide(func(deps broadcasterDeps) *gossip.Broadcaster {
@@ -183,12 +212,32 @@ func provide(c *dig.Container) {
 			deps.SyncManager,
 			deps.PeeringManager,
 			deps.GossipService,
+			isArchiveNode(deps.SnapshotManager, deps.Storage),
+			heartbeatServices(),
 			1000)
 	}); err != nil {
 		CorePlugin.LogPanic(err)
 	}
 }
 
+// tells whether the node keeps the whole history, either in its database or in the cold storage.
+func isArchiveNode(snapshotManager *snapshot.SnapshotManager, dbStorage *storage.Storage) bool {
+	return !snapshotManager.PruningEnabled() || dbStorage.ColdStorage() != nil
+}
+
+// returns the services announced in extended heartbeats, which are the identifiers of the enabled plugins
+// that provide services to other nodes.
+func heartbeatServices() []string {
+	var services []string
+	CorePlugin.Node.ForEachPlugin(func(plugin *node.Plugin) bool {
+		if _, exists := heartbeatServicePlugins[plugin.Identifier()]; exists {
+			services = append(services, plugin.Identifier())
+		}
+		return true
+	})
+	return services
+}
+
 func configure() {
 
 	// don't re-enqueue pending requests in case the node is running hot
@@ -342,6 +391,9 @@ func configureEvents() {
 		}
 
 		if err := CorePlugin.Daemon().BackgroundWorker(fmt.Sprintf("gossip-protocol-write-%s-%s", proto.PeerID, proto.Stream.ID()), func(ctx context.Context) {
+			// the capabilities are the first message on the stream
+			proto.SendCapabilities()
+
 			// send heartbeat and latest milestone request
 			if snapshotInfo := deps.Storage.SnapshotInfo(); snapshotInfo != nil {
 				latestMilestoneIndex := deps.SyncManager.LatestMilestoneIndex()
@@ -387,11 +439,17 @@ func configureEvents() {
 	})
 
 	onMessageProcessorBroadcastMessage = events.NewClosure(deps.Broadcaster.Broadcast)
+
+	onPruningMilestoneIndexChanged = events.NewClosure(func(_ milestone.Index) {
+		// the oldest milestone diff announced in extended heartbeats changes if the database was pruned
+		deps.Broadcaster.ResetOldestLedgerDiffIndex()
+	})
 }
 
 func attachEventsGossipService() {
 	deps.GossipService.Events.ProtocolStarted.Attach(onGossipServiceProtocolStarted)
 	deps.GossipService.Events.ProtocolTerminated.Attach(onGossipServiceProtocolTerminated)
+	deps.SnapshotManager.Events.PruningMilestoneIndexChanged.Attach(onPruningMilestoneIndexChanged)
 }
 
 func attachEventsBroadcastQueue() {
@@ -401,6 +459,7 @@ func attachEventsBroadcastQueue() {
 func detachEventsGossipService() {
 	deps.GossipService.Events.ProtocolStarted.Detach(onGossipServiceProtocolStarted)
 	deps.GossipService.Events.ProtocolTerminated.Detach(onGossipServiceProtocolTerminated)
+	deps.SnapshotManager.Events.PruningMilestoneIndexChanged.Detach(onPruningMilestoneIndexChanged)
 }
 
 func detachEventsBroadcastQueue() {
@@ -19,6 +19,24 @@ const (
 	CfgP2PGossipStreamReadTimeout = "p2p.gossip.streamReadTimeout"
 	// Defines the write timeout for writes to the stream.
 	CfgP2PGossipStreamWriteTimeout = "p2p.gossip.streamWriteTimeout"
+	// Defines the maximum inbound bandwidth per peer in bytes per second (0 = unlimited).
+	CfgP2PGossipBandwidthPeerInboundLimit = "p2p.gossip.bandwidth.peerInboundLimit"
+	// Defines the maximum outbound bandwidth per peer in bytes per second (0 = unlimited).
+	CfgP2PGossipBandwidthPeerOutboundLimit = "p2p.gossip.bandwidth.peerOutboundLimit"
+	// Defines the maximum inbound bandwidth of all peers together in bytes per second (0 = unlimited).
+	CfgP2PGossipBandwidthGlobalInboundLimit = "p2p.gossip.bandwidth.globalInboundLimit"
+	// Defines the maximum outbound bandwidth of all peers together in bytes per second (0 = unlimited).
+	CfgP2PGossipBandwidthGlobalOutboundLimit = "p2p.gossip.bandwidth.globalOutboundLimit"
+	// Defines the score penalty for peers sending invalid messages.
+	CfgP2PGossipScoringInvalidMessagePenalty = "p2p.gossip.scoring.invalidMessagePenalty"
+	// Defines the score penalty for peers sending messages with insufficient PoW.
+	CfgP2PGossipScoringInsufficientPoWPenalty = "p2p.gossip.scoring.insufficientPoWPenalty"
+	// Defines the score penalty for peers sending invalid requests.
+	CfgP2PGossipScoringInvalidRequestPenalty = "p2p.gossip.scoring.invalidRequestPenalty"
+	// Defines the score penalty for peers requesting milestones outside the range the node announced in its heartbeat.
+	CfgP2PGossipScoringUnansweredRequestPenalty = "p2p.gossip.scoring.unansweredRequestPenalty"
+	// Defines the score reward for peers sending new valid messages.
+	CfgP2PGossipScoringNewMessageReward = "p2p.gossip.scoring.newMessageReward"
 )
 
 var params = &node.PluginParams{
@@ -30,6 +48,15 @@ var params = &node.PluginParams{
 			fs.Int(CfgP2PGossipUnknownPeersLimit, 4, "maximum amount of unknown peers a gossip protocol connection is established to")
 			fs.Duration(CfgP2PGossipStreamReadTimeout, 60*time.Second, "the read timeout for reads from the gossip stream")
 			fs.Duration(CfgP2PGossipStreamWriteTimeout, 10*time.Second, "the write timeout for writes to the gossip stream")
+			fs.Int(CfgP2PGossipBandwidthPeerInboundLimit, 0, "the maximum inbound bandwidth per peer in bytes per second (0 = unlimited)")
+			fs.Int(CfgP2PGossipBandwidthPeerOutboundLimit, 0, "the maximum outbound bandwidth per peer in bytes per second (0 = unlimited)")
+			fs.Int(CfgP2PGossipBandwidthGlobalInboundLimit, 0, "the maximum inbound bandwidth of all peers together in bytes per second (0 = unlimited)")
+			fs.Int(CfgP2PGossipBandwidthGlobalOutboundLimit, 0, "the maximum outbound bandwidth of all peers together in bytes per second (0 = unlimited)")
+			fs.Int64(CfgP2PGossipScoringInvalidMessagePenalty, 50, "the score penalty for peers sending invalid messages")
+			fs.Int64(CfgP2PGossipScoringInsufficientPoWPenalty, 50, "the score penalty for peers sending messages with insufficient PoW")
+			fs.Int64(CfgP2PGossipScoringInvalidRequestPenalty, 50, "the score penalty for peers sending invalid requests")
+			fs.Int64(CfgP2PGossipScoringUnansweredRequestPenalty, 1, "the score penalty for peers requesting milestones outside the range the node announced in its heartbeat")
+			fs.Int64(CfgP2PGossipScoringNewMessageReward, 1, "the score reward for peers sending new valid messages")
 			return fs
 		}(),
 	},
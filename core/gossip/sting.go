@@ -1,15 +1,39 @@
 package gossip
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gohornet/hornet/pkg/protocol/gossip"
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/protocol/message"
 )
 
 // sets up the event handlers which propagate STING messages.
 func attachEventsProtocolMessages(proto *gossip.Protocol) {
 
+	proto.Parser.Events.Received[gossip.MessageTypeCapabilities].Attach(events.NewClosure(func(data []byte) {
+		if err := proto.HandleCapabilities(data); err != nil {
+			// closes the connection to the peer
+			proto.Events.Errors.Trigger(fmt.Errorf("capability negotiation failed: %w", err))
+		}
+	}))
+
+	proto.Events.Sent[gossip.MessageTypeCapabilities].Attach(events.NewClosure(func() {
+		proto.Metrics.SentPackets.Inc()
+	}))
+
+	proto.Events.CapabilitiesNegotiated.Attach(events.NewClosure(func(capabilities *gossip.NegotiatedCapabilities) {
+		CorePlugin.LogDebugf("negotiated gossip protocol version %d with peer %s, features: %v", capabilities.Version, proto.PeerID.ShortString(), capabilities.Features)
+
+		if proto.Supports(gossip.FeatureExtendedHeartbeat) {
+			// the heartbeat sent on stream start is a base heartbeat, since the features were not negotiated yet
+			deps.Broadcaster.BroadcastHeartbeat(func(p *gossip.Protocol) bool {
+				return p == proto
+			})
+		}
+	}))
+
 	proto.Parser.Events.Received[gossip.MessageTypeMessage].Attach(events.NewClosure(func(data []byte) {
 		proto.Metrics.ReceivedMessages.Inc()
 		deps.ServerMetrics.Messages.Inc()
@@ -46,33 +70,79 @@ func attachEventsProtocolMessages(proto *gossip.Protocol) {
 		deps.ServerMetrics.SentMilestoneRequests.Inc()
 	}))
 
-	proto.Parser.Events.Received[gossip.MessageTypeHeartbeat].Attach(events.NewClosure(func(data []byte) {
-		proto.Metrics.ReceivedHeartbeats.Inc()
-		deps.ServerMetrics.ReceivedHeartbeats.Inc()
-
-		proto.LatestHeartbeat = gossip.ParseHeartbeat(data)
-
-		/*
-			// TODO: reintroduce
-			if proto.Autopeering != nil && p.LatestHeartbeat.SolidMilestoneIndex < tangle.SnapshotInfo().PruningIndex {
-				// peer is connected via autopeering and its solid milestone index is below our pruning index.
-				// we can't help this neighbor to become sync, so it's better to drop the connection and free the slots for other peers.
-				log.Infof("dropping autopeered neighbor %s / %s because SMI (%d) is below our pruning index (%d)", p.Autopeering.Address(), p.Autopeering.ID(), p.LatestHeartbeat.SolidMilestoneIndex, tangle.SnapshotInfo().PruningIndex)
-				peering.Manager().Remove(p.ID)
-				return
-			}
-		*/
+	proto.Parser.Events.Received[gossip.MessageTypeMessageRequestBatch].Attach(events.NewClosure(func(data []byte) {
+		// count every requested item like a single request
+		requestsCount := uint32(gossip.RequestedItemsCount(gossip.MessageTypeMessageRequestBatch, data))
+		proto.Metrics.ReceivedMessageRequests.Add(requestsCount)
+		deps.ServerMetrics.ReceivedMessageRequests.Add(requestsCount)
+		deps.MessageProcessor.Process(proto, gossip.MessageTypeMessageRequestBatch, data)
+	}))
+
+	proto.Events.Sent[gossip.MessageTypeMessageRequestBatch].Attach(events.NewClosure(func() {
+		proto.Metrics.SentPackets.Inc()
+		proto.Metrics.SentMessageRequests.Inc()
+		deps.ServerMetrics.SentMessageRequests.Inc()
+	}))
 
-		proto.HeartbeatReceivedTime = time.Now()
-		proto.Events.HeartbeatUpdated.Trigger(proto.LatestHeartbeat)
+	proto.Parser.Events.Received[gossip.MessageTypeMilestoneRequestRange].Attach(events.NewClosure(func(data []byte) {
+		// count every requested item like a single request
+		requestsCount := uint32(gossip.RequestedItemsCount(gossip.MessageTypeMilestoneRequestRange, data))
+		proto.Metrics.ReceivedMilestoneRequests.Add(requestsCount)
+		deps.ServerMetrics.ReceivedMilestoneRequests.Add(requestsCount)
+		deps.MessageProcessor.Process(proto, gossip.MessageTypeMilestoneRequestRange, data)
 	}))
 
-	proto.Events.Sent[gossip.MessageTypeHeartbeat].Attach(events.NewClosure(func() {
+	proto.Events.Sent[gossip.MessageTypeMilestoneRequestRange].Attach(events.NewClosure(func() {
 		proto.Metrics.SentPackets.Inc()
-		proto.Metrics.SentHeartbeats.Inc()
-		deps.ServerMetrics.SentHeartbeats.Inc()
-		proto.HeartbeatSentTime = time.Now()
+		proto.Metrics.SentMilestoneRequests.Inc()
+		deps.ServerMetrics.SentMilestoneRequests.Inc()
+	}))
+
+	proto.Parser.Events.Received[gossip.MessageTypeHeartbeat].Attach(events.NewClosure(func(data []byte) {
+		handleHeartbeat(proto, gossip.ParseHeartbeat(data))
+	}))
+
+	proto.Parser.Events.Received[gossip.MessageTypeExtendedHeartbeat].Attach(events.NewClosure(func(data []byte) {
+		heartbeat, err := gossip.ParseExtendedHeartbeat(data)
+		if err != nil {
+			// closes the connection to the peer
+			proto.Events.Errors.Trigger(fmt.Errorf("invalid extended heartbeat: %w", err))
+			return
+		}
+
+		handleHeartbeat(proto, heartbeat)
 	}))
+
+	for _, msgType := range []message.Type{gossip.MessageTypeHeartbeat, gossip.MessageTypeExtendedHeartbeat} {
+		proto.Events.Sent[msgType].Attach(events.NewClosure(func() {
+			proto.Metrics.SentPackets.Inc()
+			proto.Metrics.SentHeartbeats.Inc()
+			deps.ServerMetrics.SentHeartbeats.Inc()
+			proto.HeartbeatSentTime = time.Now()
+		}))
+	}
+}
+
+// updates the latest heartbeat of the given protocol.
+func handleHeartbeat(proto *gossip.Protocol, heartbeat *gossip.Heartbeat) {
+	proto.Metrics.ReceivedHeartbeats.Inc()
+	deps.ServerMetrics.ReceivedHeartbeats.Inc()
+
+	proto.LatestHeartbeat = heartbeat
+
+	/*
+		// TODO: reintroduce
+		if proto.Autopeering != nil && p.LatestHeartbeat.SolidMilestoneIndex < tangle.SnapshotInfo().PruningIndex {
+			// peer is connected via autopeering and its solid milestone index is below our pruning index.
+			// we can't help this neighbor to become sync, so it's better to drop the connection and free the slots for other peers.
+			log.Infof("dropping autopeered neighbor %s / %s because SMI (%d) is below our pruning index (%d)", p.Autopeering.Address(), p.Autopeering.ID(), p.LatestHeartbeat.SolidMilestoneIndex, tangle.SnapshotInfo().PruningIndex)
+			peering.Manager().Remove(p.ID)
+			return
+		}
+	*/
+
+	proto.HeartbeatReceivedTime = time.Now()
+	proto.Events.HeartbeatUpdated.Trigger(proto.LatestHeartbeat)
 }
 
 // detachEventsProtocolMessages removes all the event handlers for sent and received messages.
@@ -90,6 +160,10 @@ func detachEventsProtocolMessages(proto *gossip.Protocol) {
 		}
 	}
 
+	if proto.Events.CapabilitiesNegotiated != nil {
+		proto.Events.CapabilitiesNegotiated.DetachAll()
+	}
+
 	if proto.Events.Sent != nil {
 		for _, event := range proto.Events.Sent {
 			if event == nil {
@@ -42,12 +42,13 @@ var (
 
 type dependencies struct {
 	dig.In
-	PeeringManager       *p2p.Manager
-	Host                 host.Host
-	NodeConfig           *configuration.Configuration `name:"nodeConfig"`
-	PeerStoreContainer   *p2p.PeerStoreContainer
-	PeeringConfig        *configuration.Configuration `name:"peeringConfig"`
-	PeeringConfigManager *p2p.ConfigManager
+	PeeringManager        *p2p.Manager
+	Host                  host.Host
+	NodeConfig            *configuration.Configuration `name:"nodeConfig"`
+	PeerStoreContainer    *p2p.PeerStoreContainer
+	PeeringConfig         *configuration.Configuration `name:"peeringConfig"`
+	PeeringConfigFilePath string                       `name:"peeringConfigFilePath"`
+	PeeringConfigManager  *p2p.ConfigManager
 }
 
 func initConfigPars(c *dig.Container) {
@@ -88,6 +89,7 @@ func provide(c *dig.Container) {
 		PeerStoreContainer *p2p.PeerStoreContainer
 		NodePrivateKey     crypto.PrivKey `name:"nodePrivateKey"`
 		Host               host.Host
+		ConnectionGater    *p2p.ConnectionGater
 	}
 
 	if err := c.Provide(func(deps hostDeps) p2presult {
@@ -141,11 +143,31 @@ Your node identity private key can now be found at "%s".
 			CorePlugin.LogPanicf("unable to initialize connection manager: %s", err)
 		}
 
+		allowedSubnets, err := p2p.ParseSubnets(deps.NodeConfig.Strings(CfgP2PConnectionPolicyAllowedSubnets))
+		if err != nil {
+			CorePlugin.LogPanicf("invalid connection policy: %s", err)
+		}
+
+		deniedSubnets, err := p2p.ParseSubnets(deps.NodeConfig.Strings(CfgP2PConnectionPolicyDeniedSubnets))
+		if err != nil {
+			CorePlugin.LogPanicf("invalid connection policy: %s", err)
+		}
+
+		connectionGater := p2p.NewConnectionGater(p2p.ConnectionPolicy{
+			AllowedSubnets:        allowedSubnets,
+			DeniedSubnets:         deniedSubnets,
+			MaxConnsPerIPv4Subnet: deps.NodeConfig.Int(CfgP2PConnectionPolicyMaxConnsPerIPv4Subnet),
+			MaxConnsPerIPv6Subnet: deps.NodeConfig.Int(CfgP2PConnectionPolicyMaxConnsPerIPv6Subnet),
+			KnownPeersOnly:        deps.NodeConfig.Bool(CfgP2PConnectionPolicyKnownPeersOnly),
+		})
+		res.ConnectionGater = connectionGater
+
 		createdHost, err := libp2p.New(libp2p.Identity(privKey),
 			libp2p.ListenAddrStrings(deps.P2PBindMultiAddresses...),
 			libp2p.Peerstore(peerStoreContainer.Peerstore()),
 			libp2p.DefaultTransports,
 			libp2p.ConnectionManager(connManager),
+			libp2p.ConnectionGater(connectionGater),
 			libp2p.NATPortMap(),
 		)
 		if err != nil {
@@ -161,6 +183,8 @@ Your node identity private key can now be found at "%s".
 	type mngDeps struct {
 		dig.In
 		Host                      host.Host
+		PeerStoreContainer        *p2p.PeerStoreContainer
+		ConnectionGater           *p2p.ConnectionGater
 		Config                    *configuration.Configuration `name:"nodeConfig"`
 		AutopeeringRunAsEntryNode bool                         `name:"autopeeringRunAsEntryNode"`
 	}
@@ -170,6 +194,13 @@ Your node identity private key can now be found at "%s".
 			return p2p.NewManager(deps.Host,
 				p2p.WithManagerLogger(logger.NewLogger("P2P-Manager")),
 				p2p.WithManagerReconnectInterval(deps.Config.Duration(CfgP2PReconnectInterval), 1*time.Second),
+				p2p.WithManagerPeerScoring(p2p.PeerScoringOptions{
+					MaxScore:     deps.Config.Int64(CfgP2PScoringMaxScore),
+					BanThreshold: deps.Config.Int64(CfgP2PScoringBanThreshold),
+					BanDuration:  deps.Config.Duration(CfgP2PScoringBanDuration),
+				}),
+				p2p.WithManagerBanStore(deps.PeerStoreContainer.BanStore()),
+				p2p.WithManagerConnectionGater(deps.ConnectionGater),
 			)
 		}
 		return nil
@@ -213,26 +244,18 @@ Your node identity private key can now be found at "%s".
 		// peers from CLI arguments
 		peerIDsStr := deps.PeeringConfig.Strings(CfgP2PPeers)
 		peerAliases := deps.PeeringConfig.Strings(CfgP2PPeerAliases)
-
-		applyAliases := true
 		if len(peerIDsStr) != len(peerAliases) {
 			CorePlugin.LogWarnf("won't apply peer aliases: you must define aliases for all defined static peers (got %d aliases, %d peers).", len(peerAliases), len(peerIDsStr))
-			applyAliases = false
 		}
 
-		for i, peerIDStr := range peerIDsStr {
-			multiAddr, err := multiaddr.NewMultiaddr(peerIDStr)
+		for i, p := range cliPeers(deps.PeeringConfig) {
+			multiAddr, err := multiaddr.NewMultiaddr(p.MultiAddress)
 			if err != nil {
 				CorePlugin.LogPanicf("invalid CLI peer address at pos %d: %s", i, err)
 			}
 
-			var alias string
-			if applyAliases {
-				alias = peerAliases[i]
-			}
-
-			if err = p2pConfigManager.AddPeer(multiAddr, alias); err != nil {
-				CorePlugin.LogWarnf("unable to add peer to config manager %s: %s", peerIDStr, err)
+			if err = p2pConfigManager.AddPeer(multiAddr, p.Alias); err != nil {
+				CorePlugin.LogWarnf("unable to add peer to config manager %s: %s", p.MultiAddress, err)
 			}
 		}
 
@@ -287,6 +310,12 @@ func run() {
 	}, shutdown.PriorityP2PManager); err != nil {
 		CorePlugin.LogPanicf("failed to start worker: %s", err)
 	}
+
+	if err := CorePlugin.Daemon().BackgroundWorker("PeeringConfigReloader", func(ctx context.Context) {
+		runPeeringConfigReloader(ctx)
+	}, shutdown.PriorityPeeringConfigReloader); err != nil {
+		CorePlugin.LogPanicf("failed to start worker: %s", err)
+	}
 }
 
 // connects to the peers defined in the config.
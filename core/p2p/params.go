@@ -21,6 +21,26 @@ const (
 	CfgP2PDatabasePath = "p2p.db.path"
 	// Defines the time to wait before trying to reconnect to a disconnected peer.
 	CfgP2PReconnectInterval = "p2p.reconnectInterval"
+	// Defines the subnets from which inbound connections are accepted (empty = all subnets).
+	CfgP2PConnectionPolicyAllowedSubnets = "p2p.connectionPolicy.allowedSubnets"
+	// Defines the subnets from which inbound connections are refused.
+	CfgP2PConnectionPolicyDeniedSubnets = "p2p.connectionPolicy.deniedSubnets"
+	// Defines the maximum amount of inbound connections of unknown peers from a /24 IPv4 subnet (0 = unlimited).
+	CfgP2PConnectionPolicyMaxConnsPerIPv4Subnet = "p2p.connectionPolicy.maxConnsPerIPv4Subnet"
+	// Defines the maximum amount of inbound connections of unknown peers from a /48 IPv6 subnet (0 = unlimited).
+	CfgP2PConnectionPolicyMaxConnsPerIPv6Subnet = "p2p.connectionPolicy.maxConnsPerIPv6Subnet"
+	// Defines whether only connections from and to known peers are accepted.
+	CfgP2PConnectionPolicyKnownPeersOnly = "p2p.connectionPolicy.knownPeersOnly"
+	// Defines whether the peering config file is watched for changes to reload it.
+	CfgP2PPeeringWatchFile = "p2p.peering.watchFile"
+	// Defines whether the peering config file is reloaded when the node receives SIGHUP.
+	CfgP2PPeeringReloadOnSIGHUP = "p2p.peering.reloadOnSIGHUP"
+	// Defines the maximum score a peer can reach.
+	CfgP2PScoringMaxScore = "p2p.scoring.maxScore"
+	// Defines the score at or below which a peer gets banned.
+	CfgP2PScoringBanThreshold = "p2p.scoring.banThreshold"
+	// Defines the duration for which misbehaving peers get banned (0 = never ban peers).
+	CfgP2PScoringBanDuration = "p2p.scoring.banDuration"
 	// Defines the static peers this node should retain a connection to (config file).
 	CfgP2PPeers = "p2p.peers"
 	// Defines the aliases of the static peers (must be the same length like CfgP2PPeers) (CLI).
@@ -39,6 +59,16 @@ var params = &node.PluginParams{
 			fs.String(CfgP2PIdentityPrivKey, "", "private key used to derive the node identity (optional)")
 			fs.String(CfgP2PDatabasePath, "p2pstore", "the path to the p2p database")
 			fs.Duration(CfgP2PReconnectInterval, 30*time.Second, "the time to wait before trying to reconnect to a disconnected peer")
+			fs.StringSlice(CfgP2PConnectionPolicyAllowedSubnets, []string{}, "the subnets from which inbound connections are accepted (empty = all subnets)")
+			fs.StringSlice(CfgP2PConnectionPolicyDeniedSubnets, []string{}, "the subnets from which inbound connections are refused")
+			fs.Int(CfgP2PConnectionPolicyMaxConnsPerIPv4Subnet, 0, "the maximum amount of inbound connections of unknown peers from a /24 IPv4 subnet (0 = unlimited)")
+			fs.Int(CfgP2PConnectionPolicyMaxConnsPerIPv6Subnet, 0, "the maximum amount of inbound connections of unknown peers from a /48 IPv6 subnet (0 = unlimited)")
+			fs.Bool(CfgP2PConnectionPolicyKnownPeersOnly, false, "whether only connections from and to known peers are accepted")
+			fs.Bool(CfgP2PPeeringWatchFile, true, "whether the peering config file is watched for changes to reload it")
+			fs.Bool(CfgP2PPeeringReloadOnSIGHUP, true, "whether the peering config file is reloaded when the node receives SIGHUP")
+			fs.Int64(CfgP2PScoringMaxScore, 100, "the maximum score a peer can reach")
+			fs.Int64(CfgP2PScoringBanThreshold, -100, "the score at or below which a peer gets banned")
+			fs.Duration(CfgP2PScoringBanDuration, 1*time.Hour, "the duration for which misbehaving peers get banned (0 = never ban peers)")
 			return fs
 		}(),
 		"peeringConfig": func() *flag.FlagSet {
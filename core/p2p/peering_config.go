@@ -0,0 +1,199 @@
+package p2p
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/p2p"
+	"github.com/iotaledger/hive.go/configuration"
+)
+
+const (
+	// the time to wait for further changes of the peering config file before it is reloaded,
+	// since writing a file often causes several events.
+	peeringConfigReloadDelay = 1 * time.Second
+)
+
+// returns the static peers defined via CLI arguments.
+// the aliases are only applied if they are defined for all peers.
+func cliPeers(peeringConfig *configuration.Configuration) []*p2p.PeerConfig {
+	peerIDsStr := peeringConfig.Strings(CfgP2PPeers)
+	peerAliases := peeringConfig.Strings(CfgP2PPeerAliases)
+
+	applyAliases := len(peerIDsStr) == len(peerAliases)
+
+	peers := make([]*p2p.PeerConfig, len(peerIDsStr))
+	for i, peerIDStr := range peerIDsStr {
+		var alias string
+		if applyAliases {
+			alias = peerAliases[i]
+		}
+
+		peers[i] = &p2p.PeerConfig{
+			MultiAddress: peerIDStr,
+			Alias:        alias,
+		}
+	}
+	return peers
+}
+
+// returns the address info of the given peer config.
+func peerConfigAddrInfo(p *p2p.PeerConfig) (*peer.AddrInfo, error) {
+	multiAddr, err := multiaddr.NewMultiaddr(p.MultiAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return peer.AddrInfoFromP2pAddr(multiAddr)
+}
+
+// watches the peering config file for changes and listens for SIGHUP (if enabled)
+// to reload the peering config. This function blocks until the given context is done.
+func runPeeringConfigReloader(ctx context.Context) {
+	peeringConfigFilePath, err := filepath.Abs(deps.PeeringConfigFilePath)
+	if err != nil {
+		CorePlugin.LogWarnf("unable to watch the peering config: %s", err)
+		return
+	}
+
+	var fileEvents chan fsnotify.Event
+	var fileErrors chan error
+	if deps.NodeConfig.Bool(CfgP2PPeeringWatchFile) {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			CorePlugin.LogWarnf("unable to watch the peering config: %s", err)
+		} else {
+			defer func() { _ = watcher.Close() }()
+
+			// the directory is watched, because tools and editors often replace the file instead of writing to it,
+			// which would end the watch on the file itself.
+			if err := watcher.Add(filepath.Dir(peeringConfigFilePath)); err != nil {
+				CorePlugin.LogWarnf("unable to watch the peering config: %s", err)
+			} else {
+				fileEvents = watcher.Events
+				fileErrors = watcher.Errors
+			}
+		}
+	}
+
+	var signals chan os.Signal
+	if deps.NodeConfig.Bool(CfgP2PPeeringReloadOnSIGHUP) {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGHUP)
+		defer signal.Stop(signals)
+	}
+
+	reloadTimer := time.NewTimer(peeringConfigReloadDelay)
+	reloadTimer.Stop()
+	defer reloadTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event := <-fileEvents:
+			if filepath.Clean(event.Name) != peeringConfigFilePath {
+				continue
+			}
+
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			reloadTimer.Reset(peeringConfigReloadDelay)
+
+		case err := <-fileErrors:
+			CorePlugin.LogWarnf("error while watching the peering config: %s", err)
+
+		case <-signals:
+			CorePlugin.LogInfo("received SIGHUP, reloading peering config ...")
+			reloadPeeringConfig()
+
+		case <-reloadTimer.C:
+			CorePlugin.LogInfo("peering config changed, reloading ...")
+			reloadPeeringConfig()
+		}
+	}
+}
+
+// reloads the peers from the peering config file and reconciles the known peers of the node with them.
+// newly listed peers are connected, removed peers are disconnected and the aliases and addresses of the others are updated.
+func reloadPeeringConfig() {
+	fileConfig := configuration.New()
+	if err := fileConfig.LoadFile(deps.PeeringConfigFilePath); err != nil {
+		CorePlugin.LogWarnf("unable to reload peering config: %s", err)
+		return
+	}
+
+	var filePeers []*p2p.PeerConfig
+	if err := fileConfig.Unmarshal(CfgPeers, &filePeers); err != nil {
+		CorePlugin.LogWarnf("unable to reload peering config, invalid peer config: %s", err)
+		return
+	}
+
+	// the peers from the CLI arguments are not part of the file, but they must not be removed
+	peers := make([]*p2p.PeerConfig, 0, len(filePeers))
+	peers = append(peers, filePeers...)
+	peers = append(peers, cliPeers(deps.PeeringConfig)...)
+
+	changes, err := deps.PeeringConfigManager.ReplacePeers(peers)
+	if err != nil {
+		CorePlugin.LogWarnf("unable to reload peering config: %s", err)
+		return
+	}
+
+	if !changes.HasChanges() {
+		CorePlugin.LogInfo("reloaded peering config, no changes")
+		return
+	}
+
+	if err := deps.PeeringConfig.Set(CfgPeers, filePeers); err != nil {
+		CorePlugin.LogWarnf("unable to update peering config: %s", err)
+	}
+
+	CorePlugin.LogInfof("reloaded peering config, added: %d, removed: %d, updated: %d", len(changes.Added), len(changes.Removed), len(changes.Updated))
+
+	for _, p := range changes.Removed {
+		addrInfo, err := peerConfigAddrInfo(p)
+		if err != nil {
+			continue
+		}
+
+		if err := deps.PeeringManager.DisconnectPeer(addrInfo.ID, errors.New("peer was removed from the peering config")); err != nil {
+			CorePlugin.LogInfof("can't disconnect peer (%s): %s", p.MultiAddress, err)
+		}
+	}
+
+	for _, p := range changes.Updated {
+		addrInfo, err := peerConfigAddrInfo(p)
+		if err != nil {
+			continue
+		}
+
+		deps.PeeringManager.Call(addrInfo.ID, func(peer *p2p.Peer) {
+			peer.Alias = p.Alias
+			peer.Addrs = addrInfo.Addrs
+		})
+	}
+
+	for _, p := range changes.Added {
+		addrInfo, err := peerConfigAddrInfo(p)
+		if err != nil {
+			continue
+		}
+
+		if err := deps.PeeringManager.ConnectPeer(addrInfo, p2p.PeerRelationKnown, p.Alias); err != nil {
+			CorePlugin.LogInfof("can't connect to peer (%s): %s", p.MultiAddress, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/p2p"
+	"github.com/iotaledger/hive.go/configuration"
+	"github.com/iotaledger/hive.go/logger"
+)
+
+func newTestHost(t *testing.T) host.Host {
+	// we use Ed25519 because otherwise it takes longer as the default is RSA
+	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	h, err := libp2p.New(
+		libp2p.Identity(sk),
+		libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = h.Close() })
+
+	return h
+}
+
+func writeTestPeeringConfig(t *testing.T, filePath string, peers []*p2p.PeerConfig) {
+	data, err := json.Marshal(map[string]interface{}{CfgPeers: peers})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filePath, data, 0600))
+}
+
+func TestPeeringConfigReloader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	require.NoError(t, cfg.Set("logger.disableStacktrace", true))
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = logger.InitGlobalLogger(cfg)
+
+	nodeConfig := configuration.New()
+	require.NoError(t, nodeConfig.Set(CfgP2PPeeringWatchFile, true))
+	require.NoError(t, nodeConfig.Set(CfgP2PPeeringReloadOnSIGHUP, false))
+
+	node := newTestHost(t)
+	remote := newTestHost(t)
+
+	peeringManager := p2p.NewManager(node)
+	go peeringManager.Start(ctx)
+
+	deps = dependencies{
+		PeeringManager:        peeringManager,
+		Host:                  node,
+		NodeConfig:            nodeConfig,
+		PeeringConfig:         configuration.New(),
+		PeeringConfigFilePath: filepath.Join(t.TempDir(), "peering.json"),
+		PeeringConfigManager:  p2p.NewConfigManager(func([]*p2p.PeerConfig) error { return nil }),
+	}
+
+	writeTestPeeringConfig(t, deps.PeeringConfigFilePath, []*p2p.PeerConfig{})
+
+	reloaderDone := make(chan struct{})
+	go func() {
+		defer close(reloaderDone)
+		runPeeringConfigReloader(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-reloaderDone
+	}()
+
+	// wait until the watcher was added
+	time.Sleep(200 * time.Millisecond)
+
+	remoteMultiAddress := remote.Addrs()[0].String() + "/p2p/" + remote.ID().String()
+
+	// several writes in a short time are only applied once after the reload delay
+	writeTestPeeringConfig(t, deps.PeeringConfigFilePath, []*p2p.PeerConfig{{MultiAddress: remoteMultiAddress, Alias: "first"}})
+	writeTestPeeringConfig(t, deps.PeeringConfigFilePath, []*p2p.PeerConfig{{MultiAddress: remoteMultiAddress, Alias: "remote"}})
+
+	time.Sleep(peeringConfigReloadDelay / 2)
+	require.Empty(t, deps.PeeringConfigManager.Peers())
+	require.Nil(t, peeringManager.PeerInfoSnapshot(remote.ID()))
+
+	require.Eventually(t, func() bool {
+		return len(deps.PeeringConfigManager.Peers()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, []*p2p.PeerConfig{{MultiAddress: remoteMultiAddress, Alias: "remote"}}, deps.PeeringConfigManager.Peers())
+
+	info := peeringManager.PeerInfoSnapshot(remote.ID())
+	require.NotNil(t, info)
+	require.Equal(t, "remote", info.Alias)
+	require.Equal(t, string(p2p.PeerRelationKnown), info.Relation)
+
+	// removed peers are disconnected and removed from the manager
+	writeTestPeeringConfig(t, deps.PeeringConfigFilePath, []*p2p.PeerConfig{})
+
+	require.Eventually(t, func() bool {
+		return len(deps.PeeringConfigManager.Peers()) == 0 && peeringManager.PeerInfoSnapshot(remote.ID()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"context"
+	"crypto/ed25519"
 	"os"
 
 	"github.com/labstack/gommon/bytes"
@@ -18,6 +19,7 @@ import (
 	"github.com/gohornet/hornet/pkg/shutdown"
 	"github.com/gohornet/hornet/pkg/snapshot"
 	"github.com/gohornet/hornet/pkg/tangle"
+	"github.com/gohornet/hornet/pkg/utils"
 	"github.com/iotaledger/hive.go/configuration"
 	"github.com/iotaledger/hive.go/events"
 	iotago "github.com/iotaledger/iota.go/v3"
@@ -144,6 +146,20 @@ func provide(c *dig.Container) {
 			CorePlugin.LogPanic(err)
 		}
 
+		var manifestPublicKeys []ed25519.PublicKey
+		for _, key := range deps.NodeConfig.Strings(CfgSnapshotsManifestPublicKeys) {
+			publicKey, err := utils.ParseEd25519PublicKeyFromString(key)
+			if err != nil {
+				CorePlugin.LogPanicf("parameter %s invalid: %s", CfgSnapshotsManifestPublicKeys, err)
+			}
+			manifestPublicKeys = append(manifestPublicKeys, publicKey)
+		}
+
+		compression, err := snapshot.CompressionFromString(deps.NodeConfig.String(CfgSnapshotsCompression))
+		if err != nil {
+			CorePlugin.LogPanicf("parameter %s invalid: %s", CfgSnapshotsCompression, err)
+		}
+
 		solidEntryPointCheckThresholdPast := milestone.Index(deps.BelowMaxDepth + SolidEntryPointCheckAdditionalThresholdPast)
 		solidEntryPointCheckThresholdFuture := milestone.Index(deps.BelowMaxDepth + SolidEntryPointCheckAdditionalThresholdFuture)
 		pruningThreshold := milestone.Index(deps.BelowMaxDepth + AdditionalPruningThreshold)
@@ -176,6 +192,25 @@ func provide(c *dig.Container) {
 			CorePlugin.LogPanicf("%s has to be specified if %s is enabled", CfgPruningSizeTargetSize, CfgPruningSizeEnabled)
 		}
 
+		pruningAgeEnabled := deps.NodeConfig.Bool(CfgPruningAgeEnabled)
+		pruningAgeMaxAge, err := utils.ParseDuration(deps.NodeConfig.String(CfgPruningAgeMaxAge))
+		if err != nil {
+			CorePlugin.LogPanicf("parameter %s invalid: %s", CfgPruningAgeMaxAge, err)
+		}
+
+		if pruningAgeEnabled && pruningAgeMaxAge == 0 {
+			CorePlugin.LogPanicf("%s has to be specified if %s is enabled", CfgPruningAgeMaxAge, CfgPruningAgeEnabled)
+		}
+
+		var pinnedTagPrefixes [][]byte
+		for _, tagPrefixHex := range deps.NodeConfig.Strings(CfgPruningPinnedTagPrefixes) {
+			tagPrefix, err := iotago.DecodeHex(tagPrefixHex)
+			if err != nil || len(tagPrefix) == 0 || len(tagPrefix) > iotago.MaxTagLength {
+				CorePlugin.LogPanicf("parameter %s invalid: %s", CfgPruningPinnedTagPrefixes, tagPrefixHex)
+			}
+			pinnedTagPrefixes = append(pinnedTagPrefixes, tagPrefix)
+		}
+
 		return snapshot.NewSnapshotManager(
 			CorePlugin.Logger(),
 			deps.TangleDatabase,
@@ -189,7 +224,10 @@ func provide(c *dig.Container) {
 			deps.SnapshotsFullPath,
 			deps.SnapshotsDeltaPath,
 			deps.NodeConfig.Float64(CfgSnapshotsDeltaSizeThresholdPercentage),
+			deps.NodeConfig.Bool(CfgSnapshotsDeltaChainEnabled),
+			compression,
 			downloadTargets,
+			manifestPublicKeys,
 			solidEntryPointCheckThresholdPast,
 			solidEntryPointCheckThresholdFuture,
 			pruningThreshold,
@@ -201,6 +239,9 @@ func provide(c *dig.Container) {
 			pruningTargetDatabaseSizeBytes,
 			deps.NodeConfig.Float64(CfgPruningSizeThresholdPercentage),
 			deps.NodeConfig.Duration(CfgPruningSizeCooldownTime),
+			pruningAgeEnabled,
+			pruningAgeMaxAge,
+			pinnedTagPrefixes,
 			deps.PruningPruneReceipts,
 		)
 	}); err != nil {
@@ -219,6 +260,10 @@ func configure() {
 		if err := os.Remove(deps.SnapshotsDeltaPath); err != nil && !os.IsNotExist(err) {
 			CorePlugin.LogPanicf("deleting delta snapshot file failed: %s", err)
 		}
+
+		if err := snapshot.RemoveDeltaChainFiles(deps.SnapshotsDeltaPath); err != nil {
+			CorePlugin.LogPanic(err)
+		}
 	}
 
 	snapshotInfo := deps.Storage.SnapshotInfo()
@@ -6,6 +6,7 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/gohornet/hornet/pkg/node"
+	"github.com/gohornet/hornet/pkg/snapshot"
 )
 
 const (
@@ -20,8 +21,14 @@ const (
 	// create a full snapshot if the size of a delta snapshot reaches a certain percentage of the full snapshot
 	// (0.0 = always create delta snapshot to keep ms diff history)
 	CfgSnapshotsDeltaSizeThresholdPercentage = "snapshots.deltaSizeThresholdPercentage"
+	// whether to write a chain of immutable delta snapshot files instead of rewriting a single delta snapshot file
+	CfgSnapshotsDeltaChainEnabled = "snapshots.deltaChainEnabled"
+	// the compression of the data of newly created snapshot files ("none", "gzip" or "zstd")
+	CfgSnapshotsCompression = "snapshots.compression"
 	// URLs to load the snapshot files from.
 	CfgSnapshotsDownloadURLs = "snapshots.downloadURLs"
+	// the ed25519 public keys of which one must have signed the manifest of downloaded snapshot files (optional)
+	CfgSnapshotsManifestPublicKeys = "snapshots.manifestPublicKeys"
 	// whether to delete old message data from the database based on maximum milestones to keep
 	CfgPruningMilestonesEnabled = "pruning.milestones.enabled"
 	// maximum amount of milestone cones to keep in the database
@@ -34,6 +41,12 @@ const (
 	CfgPruningSizeThresholdPercentage = "pruning.size.thresholdPercentage"
 	// cooldown time between two pruning by database size events
 	CfgPruningSizeCooldownTime = "pruning.size.cooldownTime"
+	// whether to delete old message data from the database based on the maximum age of the milestones to keep
+	CfgPruningAgeEnabled = "pruning.age.enabled"
+	// maximum age of the milestone cones to keep in the database (e.g. "30d")
+	CfgPruningAgeMaxAge = "pruning.age.maxAge"
+	// the hex encoded tag prefixes of messages that are pinned and therefore kept by the pruning
+	CfgPruningPinnedTagPrefixes = "pruning.pinnedTagPrefixes"
 	// whether to delete old receipts data from the database
 	CfgPruningPruneReceipts = "pruning.pruneReceipts"
 )
@@ -47,12 +60,18 @@ var params = &node.PluginParams{
 			fs.String(CfgSnapshotsFullPath, "snapshots/mainnet/full_snapshot.bin", "path to the full snapshot file")
 			fs.String(CfgSnapshotsDeltaPath, "snapshots/mainnet/delta_snapshot.bin", "path to the delta snapshot file")
 			fs.Float64(CfgSnapshotsDeltaSizeThresholdPercentage, 50.0, "create a full snapshot if the size of a delta snapshot reaches a certain percentage of the full snapshot (0.0 = always create delta snapshot to keep ms diff history)")
+			fs.Bool(CfgSnapshotsDeltaChainEnabled, false, "whether to write a chain of immutable delta snapshot files instead of rewriting a single delta snapshot file")
+			fs.String(CfgSnapshotsCompression, snapshot.DefaultCompression.String(), "the compression of the data of newly created snapshot files (\"none\", \"gzip\" or \"zstd\")")
+			fs.StringSlice(CfgSnapshotsManifestPublicKeys, []string{}, "the ed25519 public keys of which one must have signed the manifest of downloaded snapshot files (optional)")
 			fs.Bool(CfgPruningMilestonesEnabled, false, "whether to delete old message data from the database based on maximum milestones to keep")
 			fs.Int(CfgPruningMilestonesMaxMilestonesToKeep, 60480, "maximum amount of milestone cones to keep in the database")
 			fs.Bool(CfgPruningSizeEnabled, true, "whether to delete old message data from the database based on maximum database size")
 			fs.String(CfgPruningSizeTargetSize, "30GB", "target size of the database")
 			fs.Float64(CfgPruningSizeThresholdPercentage, 10.0, "the percentage the database size gets reduced if the target size is reached")
 			fs.Duration(CfgPruningSizeCooldownTime, 5*time.Minute, "cooldown time between two pruning by database size events")
+			fs.Bool(CfgPruningAgeEnabled, false, "whether to delete old message data from the database based on the maximum age of the milestones to keep")
+			fs.String(CfgPruningAgeMaxAge, "30d", "maximum age of the milestone cones to keep in the database (e.g. \"30d\")")
+			fs.StringSlice(CfgPruningPinnedTagPrefixes, []string{}, "the hex encoded tag prefixes of messages that are pinned and therefore kept by the pruning")
 			fs.Bool(CfgPruningPruneReceipts, false, "whether to delete old receipts data from the database")
 			return fs
 		}(),
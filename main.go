@@ -23,6 +23,7 @@ import (
 	"github.com/gohornet/hornet/plugins/receipt"
 	"github.com/gohornet/hornet/plugins/restapi"
 	restapiv2 "github.com/gohornet/hornet/plugins/restapi/v2"
+	"github.com/gohornet/hornet/plugins/snapshotserver"
 	"github.com/gohornet/hornet/plugins/spammer"
 	"github.com/gohornet/hornet/plugins/urts"
 	"github.com/gohornet/hornet/plugins/versioncheck"
@@ -59,6 +60,7 @@ func main() {
 			debug.Plugin,
 			faucet.Plugin,
 			participation.Plugin,
+			snapshotserver.Plugin,
 		}...),
 	)
 }
@@ -7,6 +7,7 @@ const (
 	StorePrefixChildren             byte = 4
 	StorePrefixSnapshot             byte = 5
 	StorePrefixUnreferencedMessages byte = 6
+	StorePrefixPinnedMessages       byte = 8
 	StorePrefixHealth               byte = 255
 )
 
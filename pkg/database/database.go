@@ -2,6 +2,8 @@ package database
 
 import (
 	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,6 +27,8 @@ const (
 var (
 	// ErrNothingToCleanUp is returned when nothing is there to clean up in the database.
 	ErrNothingToCleanUp = errors.New("Nothing to clean up in the databases")
+	// ErrCheckpointNotSupported is returned when the database engine does not support checkpoints.
+	ErrCheckpointNotSupported = errors.New("database checkpoints are not supported by the database engine")
 )
 
 type DatabaseCleanup struct {
@@ -71,10 +75,11 @@ type Database struct {
 	events                *Events
 	compactionSupported   bool
 	compactionRunningFunc func() bool
+	checkpointFunc        func(targetDirectory string) error
 }
 
 // New creates a new Database instance.
-func New(databaseDirectory string, kvStore kvstore.KVStore, engine Engine, metrics *metrics.DatabaseMetrics, events *Events, compactionSupported bool, compactionRunningFunc func() bool) *Database {
+func New(databaseDirectory string, kvStore kvstore.KVStore, engine Engine, metrics *metrics.DatabaseMetrics, events *Events, compactionSupported bool, compactionRunningFunc func() bool, checkpointFunc func(targetDirectory string) error) *Database {
 	return &Database{
 		databaseDir:           databaseDirectory,
 		store:                 kvStore,
@@ -83,6 +88,7 @@ func New(databaseDirectory string, kvStore kvstore.KVStore, engine Engine, metri
 		events:                events,
 		compactionSupported:   compactionSupported,
 		compactionRunningFunc: compactionRunningFunc,
+		checkpointFunc:        checkpointFunc,
 	}
 }
 
@@ -127,3 +133,23 @@ func (db *Database) Size() (int64, error) {
 	}
 	return utils.FolderSize(db.databaseDir)
 }
+
+// CheckpointSupported returns whether the database engine supports checkpoints.
+func (db *Database) CheckpointSupported() bool {
+	return db.checkpointFunc != nil
+}
+
+// Checkpoint creates a consistent copy of the database in the target directory,
+// which can be opened like any other database folder.
+// The target directory must not exist.
+func (db *Database) Checkpoint(targetDirectory string) error {
+	if db.checkpointFunc == nil {
+		return ErrCheckpointNotSupported
+	}
+
+	if err := db.checkpointFunc(targetDirectory); err != nil {
+		return fmt.Errorf("creating database checkpoint failed: %w", err)
+	}
+
+	return storeDatabaseInfoToFile(filepath.Join(targetDirectory, "dbinfo"), db.engine)
+}
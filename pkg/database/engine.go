@@ -185,6 +185,18 @@ func storeDatabaseInfoToFile(filePath string, engine Engine) error {
 // It also checks if the database engine is correct.
 func StoreWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEngine ...Engine) (kvstore.KVStore, error) {
 
+	db, err := DatabaseWithDefaultSettings(path, createDatabaseIfNotExists, dbEngine...)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.KVStore(), nil
+}
+
+// DatabaseWithDefaultSettings returns a database with default settings.
+// It also checks if the database engine is correct.
+func DatabaseWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEngine ...Engine) (*Database, error) {
+
 	targetEngine, err := CheckDatabaseEngine(path, createDatabaseIfNotExists, dbEngine...)
 	if err != nil {
 		return nil, err
@@ -196,17 +208,17 @@ func StoreWithDefaultSettings(path string, createDatabaseIfNotExists bool, dbEng
 		if err != nil {
 			return nil, err
 		}
-		return pebble.New(db), nil
+		return New(path, pebble.New(db), EnginePebble, nil, nil, true, nil, PebbleCheckpointFunc(db)), nil
 
 	case EngineRocksDB:
 		db, err := NewRocksDB(path)
 		if err != nil {
 			return nil, err
 		}
-		return rocksdb.New(db), nil
+		return New(path, rocksdb.New(db), EngineRocksDB, nil, nil, true, nil, nil), nil
 
 	case EngineMapDB:
-		return mapdb.NewMapDB(), nil
+		return New("", mapdb.NewMapDB(), EngineMapDB, nil, nil, false, nil, nil), nil
 
 	default:
 		return nil, fmt.Errorf("unknown database engine: %s, supported engines: pebble/rocksdb/mapdb", dbEngine)
@@ -240,3 +240,16 @@ func NewPebbleDB(directory string, reportCompactionRunning func(running bool), e
 
 	return pebble.CreateDB(directory, opts)
 }
+
+// PebbleCheckpointFunc returns a function that creates a checkpoint of the given pebble DB.
+func PebbleCheckpointFunc(db *pebbleDB.DB) func(targetDirectory string) error {
+	return func(targetDirectory string) error {
+		// the write-ahead log is disabled, so the memtables
+		// have to be flushed to be part of the checkpoint.
+		if err := db.Flush(); err != nil {
+			return err
+		}
+
+		return db.Checkpoint(targetDirectory)
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// INXMetrics defines INX metrics over the entire runtime of the node.
+type INXMetrics struct {
+	// The total number of requests that were rejected because of a missing or invalid extension token.
+	UnauthenticatedRequestCounter atomic.Uint32
+	// The total number of requests that were rejected because the extension is missing the required scope.
+	PermissionDeniedRequestCounter atomic.Uint32
+
+	extensionRequestsLock sync.RWMutex
+	// the total number of authorized requests by extension name.
+	extensionRequests map[string]uint64
+}
+
+// IncExtensionRequests increases the number of authorized requests of the given extension.
+func (m *INXMetrics) IncExtensionRequests(extensionName string) {
+	m.extensionRequestsLock.Lock()
+	defer m.extensionRequestsLock.Unlock()
+
+	if m.extensionRequests == nil {
+		m.extensionRequests = make(map[string]uint64)
+	}
+	m.extensionRequests[extensionName]++
+}
+
+// ExtensionRequests returns a copy of the number of authorized requests by extension name.
+func (m *INXMetrics) ExtensionRequests() map[string]uint64 {
+	m.extensionRequestsLock.RLock()
+	defer m.extensionRequestsLock.RUnlock()
+
+	extensionRequests := make(map[string]uint64, len(m.extensionRequests))
+	for extensionName, count := range m.extensionRequests {
+		extensionRequests[extensionName] = count
+	}
+	return extensionRequests
+}
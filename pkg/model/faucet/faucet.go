@@ -340,6 +340,19 @@ func (f *Faucet) collectUnspentBasicOutputsWithoutConstraints(address iotago.Add
 		}
 		return true
 	}
+
+	if f.utxoManager.AddressIndexEnabled() {
+		// only basic outputs which are owned by the address are of interest
+		role := utxo.AddressRoleAddress
+		outputType := iotago.OutputBasic
+		if err := f.utxoManager.ForEachUnspentOutputOnAddress(address, &utxo.AddressIndexFilter{Role: &role, OutputType: &outputType}, func(_ []byte, output *utxo.Output) bool {
+			return consumerFunc(output)
+		}, utxo.ReadLockLedger(false)); err != nil {
+			return nil, 0, err
+		}
+		return outputs, balance, nil
+	}
+
 	if err := f.utxoManager.ForEachUnspentOutput(consumerFunc, utxo.ReadLockLedger(false)); err != nil {
 		return nil, 0, err
 	}
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"bytes"
 	"time"
 
 	"github.com/gohornet/hornet/pkg/common"
@@ -72,6 +73,7 @@ func (s *Storage) configureChildrenStorage(store kvstore.KVStore, opts *profile.
 		return err
 	}
 
+	s.childrenStore = childrenStore
 	s.childrenStorage = objectstorage.New(
 		childrenStore,
 		childrenFactory,
@@ -104,6 +106,28 @@ func (s *Storage) ChildrenMessageIDs(messageID hornet.MessageID, iteratorOptions
 	return childrenMessageIDs, nil
 }
 
+// ChildMessageIDConsumer consumes the given child message ID during looping through the children of a message.
+type ChildMessageIDConsumer func(childMessageID hornet.MessageID) bool
+
+// forEachChildMessageIDFromCursor loops over the children of the given message in the lexical order of their message IDs.
+// All children positioned before the given cursor are skipped.
+func forEachChildMessageIDFromCursor(store kvstore.KVStore, messageID hornet.MessageID, cursor hornet.MessageID, consumer ChildMessageIDConsumer) error {
+	return store.IterateKeys(messageID, func(key kvstore.Key) bool {
+		childMessageID := hornet.MessageIDFromSlice(key[iotago.MessageIDLength : iotago.MessageIDLength+iotago.MessageIDLength])
+		if cursor != nil && bytes.Compare(childMessageID, cursor) < 0 {
+			return true
+		}
+		return consumer(childMessageID)
+	})
+}
+
+// ForEachChildMessageIDFromCursor loops over the children of the given message in the lexical order of their message IDs,
+// starting at the given cursor (child message ID). The children are read from the database directly to get a stable order,
+// so children that were added recently might be missing until they are persisted.
+func (s *Storage) ForEachChildMessageIDFromCursor(messageID hornet.MessageID, cursor hornet.MessageID, consumer ChildMessageIDConsumer) error {
+	return forEachChildMessageIDFromCursor(s.childrenStore, messageID, cursor, consumer)
+}
+
 // ContainsChild returns if the given child exists in the cache/persistence layer.
 func (s *Storage) ContainsChild(messageID hornet.MessageID, childMessageID hornet.MessageID, readOptions ...ReadOption) bool {
 	return s.childrenStorage.Contains(append(messageID, childMessageID...), readOptions...)
@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+var (
+	// ErrColdStorageDisabled is returned if data should be archived without a configured cold storage.
+	ErrColdStorageDisabled = errors.New("cold storage disabled")
+)
+
+// ColdStorage is an append-only storage for the milestone cones and ledger diffs of pruned milestones.
+// It is used by archive nodes to keep the full history, while the node databases only contain the recent history.
+// The tangle data is stored in the same format as in the tangle database,
+// the ledger diffs are stored together with the outputs they reference.
+type ColdStorage struct {
+	// the stores of the cold storage.
+	tangleStore kvstore.KVStore
+	utxoStore   kvstore.KVStore
+
+	// the realms of the tangle store.
+	messagesStore   kvstore.KVStore
+	metadataStore   kvstore.KVStore
+	milestonesStore kvstore.KVStore
+	childrenStore   kvstore.KVStore
+
+	// used to load the archived ledger diffs.
+	utxoManager *utxo.Manager
+}
+
+// NewColdStorage creates a new ColdStorage on top of the given stores.
+func NewColdStorage(tangleStore kvstore.KVStore, utxoStore kvstore.KVStore) (*ColdStorage, error) {
+
+	realms := make(map[byte]kvstore.KVStore)
+	for _, prefix := range []byte{common.StorePrefixMessages, common.StorePrefixMessageMetadata, common.StorePrefixMilestones, common.StorePrefixChildren} {
+		store, err := tangleStore.WithRealm([]byte{prefix})
+		if err != nil {
+			return nil, err
+		}
+		realms[prefix] = store
+	}
+
+	return &ColdStorage{
+		tangleStore:     tangleStore,
+		utxoStore:       utxoStore,
+		messagesStore:   realms[common.StorePrefixMessages],
+		metadataStore:   realms[common.StorePrefixMessageMetadata],
+		milestonesStore: realms[common.StorePrefixMilestones],
+		childrenStore:   realms[common.StorePrefixChildren],
+		utxoManager:     utxo.New(utxoStore),
+	}, nil
+}
+
+// MessageOrNil returns the archived message with the given ID or nil if it wasn't archived.
+func (cs *ColdStorage) MessageOrNil(messageID hornet.MessageID) *Message {
+	data, err := cs.messagesStore.Get(messageID)
+	if err != nil {
+		return nil
+	}
+
+	msg, err := MessageFactory(messageID, data)
+	if err != nil {
+		return nil
+	}
+	return msg.(*Message)
+}
+
+// MessageMetadataOrNil returns the archived metadata of the message with the given ID or nil if it wasn't archived.
+func (cs *ColdStorage) MessageMetadataOrNil(messageID hornet.MessageID) *MessageMetadata {
+	data, err := cs.metadataStore.Get(messageID)
+	if err != nil {
+		return nil
+	}
+
+	metadata, err := MetadataFactory(messageID, data)
+	if err != nil {
+		return nil
+	}
+	return metadata.(*MessageMetadata)
+}
+
+// MilestoneOrNil returns the archived milestone with the given index or nil if it wasn't archived.
+func (cs *ColdStorage) MilestoneOrNil(milestoneIndex milestone.Index) *Milestone {
+	key := databaseKeyForMilestoneIndex(milestoneIndex)
+
+	data, err := cs.milestonesStore.Get(key)
+	if err != nil {
+		return nil
+	}
+
+	ms, err := milestoneFactory(key, data)
+	if err != nil {
+		return nil
+	}
+	return ms.(*Milestone)
+}
+
+// ForEachChildMessageIDFromCursor loops over the archived children of the given message in the lexical order of their message IDs,
+// starting at the given cursor (child message ID).
+func (cs *ColdStorage) ForEachChildMessageIDFromCursor(messageID hornet.MessageID, cursor hornet.MessageID, consumer ChildMessageIDConsumer) error {
+	return forEachChildMessageIDFromCursor(cs.childrenStore, messageID, cursor, consumer)
+}
+
+// MilestoneDiff returns the archived ledger diff of the given milestone.
+func (cs *ColdStorage) MilestoneDiff(milestoneIndex milestone.Index) (*utxo.MilestoneDiff, error) {
+	return cs.utxoManager.MilestoneDiff(milestoneIndex)
+}
+
+// FlushAndClose flushes and closes the stores of the cold storage.
+func (cs *ColdStorage) FlushAndClose() error {
+
+	var flushAndCloseError error
+	if err := cs.tangleStore.Flush(); err != nil {
+		flushAndCloseError = err
+	}
+	if err := cs.utxoStore.Flush(); err != nil {
+		flushAndCloseError = err
+	}
+	if err := cs.tangleStore.Close(); err != nil {
+		flushAndCloseError = err
+	}
+	if err := cs.utxoStore.Close(); err != nil {
+		flushAndCloseError = err
+	}
+	return flushAndCloseError
+}
+
+// ColdStorage returns the cold storage of the node or nil if it is disabled.
+func (s *Storage) ColdStorage() *ColdStorage {
+	return s.coldStorage
+}
+
+// SetColdStorage sets the cold storage to which pruned milestones are archived.
+func (s *Storage) SetColdStorage(coldStorage *ColdStorage) {
+	s.coldStorage = coldStorage
+}
+
+// ArchiveMilestoneWithoutLocking copies the milestone with the given index, the messages of its cone
+// and its ledger diff to the cold storage. It has to be called before the milestone gets pruned.
+// The ledger diff is archived before the tangle data, so that an aborted archiving can safely be repeated.
+func (s *Storage) ArchiveMilestoneWithoutLocking(milestoneIndex milestone.Index, coneMessageIDs hornet.MessageIDs) error {
+	if s.coldStorage == nil {
+		return ErrColdStorageDisabled
+	}
+
+	utxoMutations, err := s.coldStorage.utxoStore.Batched()
+	if err != nil {
+		return err
+	}
+
+	if err := s.utxoManager.ArchiveMilestoneDiffWithoutLocking(milestoneIndex, utxoMutations); err != nil {
+		utxoMutations.Cancel()
+		return errors.Wrapf(err, "archiving ledger diff of milestone %d failed", milestoneIndex)
+	}
+
+	if err := utxoMutations.Commit(); err != nil {
+		return err
+	}
+
+	tangleMutations, err := s.coldStorage.tangleStore.Batched()
+	if err != nil {
+		return err
+	}
+
+	if err := s.archiveMilestoneCone(milestoneIndex, coneMessageIDs, tangleMutations); err != nil {
+		tangleMutations.Cancel()
+		return errors.Wrapf(err, "archiving cone of milestone %d failed", milestoneIndex)
+	}
+
+	return tangleMutations.Commit()
+}
+
+// writes the milestone and the messages of its cone including their metadata and children to the given mutations.
+func (s *Storage) archiveMilestoneCone(milestoneIndex milestone.Index, coneMessageIDs hornet.MessageIDs, mutations kvstore.BatchedMutations) error {
+
+	cachedMilestone := s.CachedMilestoneOrNil(milestoneIndex) // milestone +1
+	if cachedMilestone == nil {
+		return errors.Errorf("milestone %d not found", milestoneIndex)
+	}
+	ms := cachedMilestone.Milestone()
+	cachedMilestone.Release(true) // milestone -1
+
+	if err := mutations.Set(append([]byte{common.StorePrefixMilestones}, ms.ObjectStorageKey()...), ms.ObjectStorageValue()); err != nil {
+		return err
+	}
+
+	for _, messageID := range coneMessageIDs {
+		cachedMsg := s.CachedMessageOrNil(messageID) // message +1
+		if cachedMsg == nil {
+			// message was already deleted
+			continue
+		}
+
+		msg := cachedMsg.Message()
+		metadata := cachedMsg.Metadata()
+		metadataValue := metadata.ObjectStorageValue()
+		cachedMsg.Release(true) // message -1
+
+		if err := mutations.Set(append([]byte{common.StorePrefixMessages}, msg.ObjectStorageKey()...), msg.ObjectStorageValue()); err != nil {
+			return err
+		}
+
+		if err := mutations.Set(append([]byte{common.StorePrefixMessageMetadata}, metadata.ObjectStorageKey()...), metadataValue); err != nil {
+			return err
+		}
+
+		childrenMessageIDs, err := s.ChildrenMessageIDs(messageID)
+		if err != nil {
+			return err
+		}
+
+		for _, childMessageID := range childrenMessageIDs {
+			if err := mutations.Set(append([]byte{common.StorePrefixChildren}, NewChild(messageID, childMessageID).ObjectStorageKey()...), []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MessageWithFallbackOrNil returns the message with the given ID.
+// It falls back to the cold storage if the message is not found in the node database.
+func (s *Storage) MessageWithFallbackOrNil(messageID hornet.MessageID) *Message {
+	if cachedMsg := s.CachedMessageOrNil(messageID); cachedMsg != nil { // message +1
+		defer cachedMsg.Release(true) // message -1
+		return cachedMsg.Message()
+	}
+
+	if s.coldStorage == nil {
+		return nil
+	}
+	return s.coldStorage.MessageOrNil(messageID)
+}
+
+// MessageMetadataWithFallbackOrNil returns the metadata of the message with the given ID.
+// It falls back to the cold storage if the metadata is not found in the node database.
+func (s *Storage) MessageMetadataWithFallbackOrNil(messageID hornet.MessageID) *MessageMetadata {
+	if cachedMsgMeta := s.CachedMessageMetadataOrNil(messageID); cachedMsgMeta != nil { // meta +1
+		defer cachedMsgMeta.Release(true) // meta -1
+		return cachedMsgMeta.Metadata()
+	}
+
+	if s.coldStorage == nil {
+		return nil
+	}
+	return s.coldStorage.MessageMetadataOrNil(messageID)
+}
+
+// MilestoneWithFallbackOrNil returns the milestone with the given index.
+// It falls back to the cold storage if the milestone is not found in the node database.
+func (s *Storage) MilestoneWithFallbackOrNil(milestoneIndex milestone.Index) *Milestone {
+	if cachedMilestone := s.CachedMilestoneOrNil(milestoneIndex); cachedMilestone != nil { // milestone +1
+		defer cachedMilestone.Release(true) // milestone -1
+		return cachedMilestone.Milestone()
+	}
+
+	if s.coldStorage == nil {
+		return nil
+	}
+	return s.coldStorage.MilestoneOrNil(milestoneIndex)
+}
+
+// ForEachChildMessageIDFromCursorWithFallback loops over the children of the given message, starting at the given cursor.
+// It falls back to the cold storage if the message is not found in the node database.
+func (s *Storage) ForEachChildMessageIDFromCursorWithFallback(messageID hornet.MessageID, cursor hornet.MessageID, consumer ChildMessageIDConsumer) error {
+	if s.coldStorage == nil || s.ContainsMessage(messageID) {
+		return s.ForEachChildMessageIDFromCursor(messageID, cursor, consumer)
+	}
+	return s.coldStorage.ForEachChildMessageIDFromCursor(messageID, cursor, consumer)
+}
+
+// MilestoneDiffWithFallback returns the ledger diff of the given milestone.
+// It falls back to the cold storage if the diff is not found in the UTXO ledger.
+func (s *Storage) MilestoneDiffWithFallback(milestoneIndex milestone.Index) (*utxo.MilestoneDiff, error) {
+	diff, err := s.utxoManager.MilestoneDiff(milestoneIndex)
+	if err == nil || s.coldStorage == nil || !errors.Is(err, kvstore.ErrKeyNotFound) {
+		return diff, err
+	}
+	return s.coldStorage.MilestoneDiff(milestoneIndex)
+}
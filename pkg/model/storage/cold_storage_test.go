@@ -0,0 +1,100 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	BelowMaxDepth = 5
+	MinPoWScore   = 10.0
+)
+
+func TestColdStorageArchiveMilestone(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	coneMessageIDs := make(map[milestone.Index]hornet.MessageIDs)
+	_, _ = te.BuildTangle(10, BelowMaxDepth, 5, 5, 10,
+		nil,
+		func(messages hornet.MessageIDs, messagesPerMilestones []hornet.MessageIDs) hornet.MessageIDs {
+			return hornet.MessageIDs{messages[len(messages)-1]}
+		},
+		func(msIndex milestone.Index, _ hornet.MessageIDs, conf *whiteflag.Confirmation, _ *whiteflag.ConfirmedMilestoneStats) {
+			coneMessageIDs[msIndex] = conf.Mutations.MessagesReferenced
+		},
+	)
+	require.NotEmpty(t, coneMessageIDs)
+
+	// archiving is only possible with a cold storage
+	require.ErrorIs(t, te.Storage().ArchiveMilestoneWithoutLocking(2, coneMessageIDs[2]), storage.ErrColdStorageDisabled)
+
+	coldStorage, err := storage.NewColdStorage(mapdb.NewMapDB(), mapdb.NewMapDB())
+	require.NoError(t, err)
+	te.Storage().SetColdStorage(coldStorage)
+
+	for msIndex, messageIDs := range coneMessageIDs {
+		require.NoError(t, te.Storage().ArchiveMilestoneWithoutLocking(msIndex, messageIDs))
+	}
+
+	for msIndex, messageIDs := range coneMessageIDs {
+		cachedMilestone := te.Storage().CachedMilestoneOrNil(msIndex) // milestone +1
+		require.NotNil(t, cachedMilestone)
+		milestoneMessageID := cachedMilestone.Milestone().MessageID
+		cachedMilestone.Release(true) // milestone -1
+
+		childrenMessageIDs, err := te.Storage().ChildrenMessageIDs(messageIDs[0])
+		require.NoError(t, err)
+
+		// prune the milestone from the node databases
+		require.NoError(t, te.UTXOManager().PruneMilestoneIndexWithoutLocking(msIndex, false))
+		te.Storage().DeleteMilestone(msIndex)
+		for _, messageID := range messageIDs {
+			te.Storage().DeleteMessage(messageID)
+		}
+
+		// the storage falls back to the cold storage
+		ms := te.Storage().MilestoneWithFallbackOrNil(msIndex)
+		require.NotNil(t, ms)
+		require.Equal(t, milestoneMessageID, ms.MessageID)
+
+		for _, messageID := range messageIDs {
+			require.Nil(t, te.Storage().CachedMessageOrNil(messageID))
+
+			msg := te.Storage().MessageWithFallbackOrNil(messageID)
+			require.NotNil(t, msg)
+			require.Equal(t, messageID, msg.MessageID())
+
+			metadata := te.Storage().MessageMetadataWithFallbackOrNil(messageID)
+			require.NotNil(t, metadata)
+			referenced, referencedIndex := metadata.ReferencedWithIndex()
+			require.True(t, referenced)
+			require.Equal(t, msIndex, referencedIndex)
+		}
+
+		var archivedChildrenMessageIDs hornet.MessageIDs
+		require.NoError(t, te.Storage().ForEachChildMessageIDFromCursorWithFallback(messageIDs[0], nil, func(childMessageID hornet.MessageID) bool {
+			archivedChildrenMessageIDs = append(archivedChildrenMessageIDs, childMessageID)
+			return true
+		}))
+		require.ElementsMatch(t, childrenMessageIDs, archivedChildrenMessageIDs)
+
+		diff, err := te.Storage().MilestoneDiffWithFallback(msIndex)
+		require.NoError(t, err)
+		require.Equal(t, msIndex, diff.Index)
+	}
+
+	// messages which were never archived are not found
+	require.Nil(t, te.Storage().MessageWithFallbackOrNil(hornet.NullMessageID()))
+	require.Nil(t, te.Storage().MilestoneWithFallbackOrNil(1000))
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+// PinnedMessageIDConsumer consumes the given pinned message ID during looping through all pinned messages.
+type PinnedMessageIDConsumer func(messageID hornet.MessageID) bool
+
+func (s *Storage) configurePinnedMessagesStore(store kvstore.KVStore) error {
+	pinnedMessagesStore, err := store.WithRealm([]byte{common.StorePrefixPinnedMessages})
+	if err != nil {
+		return err
+	}
+
+	s.pinnedMessagesStore = pinnedMessagesStore
+	return nil
+}
+
+// PinMessage adds the message to the pinned messages, which are kept by the pruning.
+func (s *Storage) PinMessage(messageID hornet.MessageID) error {
+	if err := s.pinnedMessagesStore.Set(messageID, []byte{}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store pinned message")
+	}
+
+	return nil
+}
+
+// UnpinMessage removes the message from the pinned messages.
+func (s *Storage) UnpinMessage(messageID hornet.MessageID) error {
+	if err := s.pinnedMessagesStore.Delete(messageID); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete pinned message")
+	}
+
+	return nil
+}
+
+// IsMessagePinned returns whether the message is pinned.
+func (s *Storage) IsMessagePinned(messageID hornet.MessageID) bool {
+	pinned, err := s.pinnedMessagesStore.Has(messageID)
+	if err != nil {
+		return false
+	}
+
+	return pinned
+}
+
+// HasPinnedMessages returns whether there are any pinned messages.
+func (s *Storage) HasPinnedMessages() bool {
+	var hasPinnedMessages bool
+	if err := s.pinnedMessagesStore.IterateKeys(kvstore.EmptyPrefix, func(_ kvstore.Key) bool {
+		hasPinnedMessages = true
+		return false
+	}); err != nil {
+		return false
+	}
+
+	return hasPinnedMessages
+}
+
+// ForEachPinnedMessageID loops over all pinned message IDs.
+func (s *Storage) ForEachPinnedMessageID(consumer PinnedMessageIDConsumer) error {
+	if err := s.pinnedMessagesStore.IterateKeys(kvstore.EmptyPrefix, func(key kvstore.Key) bool {
+		return consumer(hornet.MessageIDFromSlice(key))
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate pinned messages")
+	}
+
+	return nil
+}
+
+// PinnedMessageIDs returns all pinned message IDs.
+func (s *Storage) PinnedMessageIDs() (hornet.MessageIDs, error) {
+	var messageIDs hornet.MessageIDs
+	if err := s.ForEachPinnedMessageID(func(messageID hornet.MessageID) bool {
+		messageIDs = append(messageIDs, messageID)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	return messageIDs, nil
+}
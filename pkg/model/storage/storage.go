@@ -83,7 +83,9 @@ type Storage struct {
 	healthTrackers []*StoreHealthTracker
 
 	// kv storages
-	snapshotStore kvstore.KVStore
+	snapshotStore       kvstore.KVStore
+	pinnedMessagesStore kvstore.KVStore
+	childrenStore       kvstore.KVStore
 
 	// object storages
 	childrenStorage             *objectstorage.ObjectStorage
@@ -103,6 +105,9 @@ type Storage struct {
 	// utxo
 	utxoManager *utxo.Manager
 
+	// the cold storage to which pruned milestones are archived (optional)
+	coldStorage *ColdStorage
+
 	// events
 	Events *packageEvents
 }
@@ -374,6 +379,10 @@ func (s *Storage) configureStorages(tangleStore kvstore.KVStore, cachesProfile .
 		return err
 	}
 
+	if err := s.configurePinnedMessagesStore(tangleStore); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -392,6 +401,11 @@ func (s *Storage) FlushAndCloseStores() error {
 	if err := s.utxoStore.Close(); err != nil {
 		flushAndCloseError = err
 	}
+	if s.coldStorage != nil {
+		if err := s.coldStorage.FlushAndClose(); err != nil {
+			flushAndCloseError = err
+		}
+	}
 	return flushAndCloseError
 }
 
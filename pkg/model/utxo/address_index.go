@@ -0,0 +1,393 @@
+package utxo
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/marshalutil"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// the amount of index entries that are written in a single batch while rebuilding the address index.
+	addressIndexRebuildBatchSize = 10000
+)
+
+var (
+	// ErrAddressIndexDisabled is returned if the address index is queried but not enabled.
+	ErrAddressIndexDisabled = errors.New("address index is disabled")
+
+	// ErrUnknownAddressRole is returned if an unknown address role is given.
+	ErrUnknownAddressRole = errors.New("unknown address role")
+)
+
+// AddressRole defines the role in which an address is referenced by the unlock conditions of an output.
+type AddressRole byte
+
+const (
+	// AddressRoleAddress denotes the address of an AddressUnlockCondition.
+	AddressRoleAddress AddressRole = iota
+	// AddressRoleStorageDepositReturn denotes the return address of a StorageDepositReturnUnlockCondition.
+	AddressRoleStorageDepositReturn
+	// AddressRoleExpirationReturn denotes the return address of an ExpirationUnlockCondition.
+	AddressRoleExpirationReturn
+	// AddressRoleStateController denotes the address of a StateControllerAddressUnlockCondition.
+	AddressRoleStateController
+	// AddressRoleGovernor denotes the address of a GovernorAddressUnlockCondition.
+	AddressRoleGovernor
+	// AddressRoleImmutableAlias denotes the alias address of an ImmutableAliasUnlockCondition.
+	AddressRoleImmutableAlias
+)
+
+var addressRoleNames = [AddressRoleImmutableAlias + 1]string{
+	"address",
+	"storageDepositReturn",
+	"expirationReturn",
+	"stateController",
+	"governor",
+	"immutableAlias",
+}
+
+func (r AddressRole) String() string {
+	if int(r) >= len(addressRoleNames) {
+		return fmt.Sprintf("unknown address role: %d", r)
+	}
+	return addressRoleNames[r]
+}
+
+// AddressRoleFromString returns the AddressRole for the given name.
+func AddressRoleFromString(name string) (AddressRole, error) {
+	for i, roleName := range addressRoleNames {
+		if roleName == name {
+			return AddressRole(i), nil
+		}
+	}
+	return 0, errors.Wrapf(ErrUnknownAddressRole, "%s", name)
+}
+
+// AddressIndexFilter is used to filter the results of an address index query.
+type AddressIndexFilter struct {
+	// If set, only outputs which reference the address in the given role are returned.
+	Role *AddressRole
+	// If set, only outputs of the given type are returned.
+	OutputType *iotago.OutputType
+}
+
+// AddressIndexConsumer is a function that consumes unspent outputs found in the address index.
+// The cursor is the position of the entry in the index of the address and can be
+// passed to IterateFromCursor to continue an iteration at exactly that entry.
+type AddressIndexConsumer func(cursor []byte, output *Output) bool
+
+type addressWithRole struct {
+	address iotago.Address
+	role    AddressRole
+}
+
+// addressesWithRoles returns all addresses that are referenced by the unlock conditions of the given output.
+func addressesWithRoles(output iotago.Output) []*addressWithRole {
+	var result []*addressWithRole
+	for _, unlockCondition := range output.UnlockConditions() {
+		switch condition := unlockCondition.(type) {
+		case *iotago.AddressUnlockCondition:
+			result = append(result, &addressWithRole{address: condition.Address, role: AddressRoleAddress})
+		case *iotago.StorageDepositReturnUnlockCondition:
+			result = append(result, &addressWithRole{address: condition.ReturnAddress, role: AddressRoleStorageDepositReturn})
+		case *iotago.ExpirationUnlockCondition:
+			result = append(result, &addressWithRole{address: condition.ReturnAddress, role: AddressRoleExpirationReturn})
+		case *iotago.StateControllerAddressUnlockCondition:
+			result = append(result, &addressWithRole{address: condition.Address, role: AddressRoleStateController})
+		case *iotago.GovernorAddressUnlockCondition:
+			result = append(result, &addressWithRole{address: condition.Address, role: AddressRoleGovernor})
+		case *iotago.ImmutableAliasUnlockCondition:
+			result = append(result, &addressWithRole{address: condition.Address, role: AddressRoleImmutableAlias})
+		}
+	}
+	return result
+}
+
+// UnlockConditionAddresses returns all addresses that are referenced by the unlock conditions of the output.
+func (o *Output) UnlockConditionAddresses() []iotago.Address {
+	return UnlockConditionAddresses(o.output)
+}
+
+// UnlockConditionAddresses returns all addresses that are referenced by the unlock conditions of the given output.
+func UnlockConditionAddresses(output iotago.Output) []iotago.Address {
+	addrsWithRoles := addressesWithRoles(output)
+
+	addresses := make([]iotago.Address, len(addrsWithRoles))
+	for i, addrWithRole := range addrsWithRoles {
+		addresses[i] = addrWithRole.address
+	}
+	return addresses
+}
+
+func addressIndexKeyPrefixForAddress(address iotago.Address) ([]byte, error) {
+	addressBytes, err := address.Serialize(serializer.DeSeriModeNoValidation, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ms := marshalutil.New(1 + len(addressBytes))
+	ms.WriteByte(UTXOStoreKeyPrefixAddressIndex) // 1 byte
+	ms.WriteBytes(addressBytes)                  // 21-33 bytes
+	return ms.Bytes(), nil
+}
+
+func addressIndexKeysForOutput(output *Output) ([][]byte, error) {
+	var keys [][]byte
+	for _, addrWithRole := range addressesWithRoles(output.output) {
+		prefix, err := addressIndexKeyPrefixForAddress(addrWithRole.address)
+		if err != nil {
+			return nil, err
+		}
+
+		ms := marshalutil.New(len(prefix) + 2 + iotago.OutputIDLength)
+		ms.WriteBytes(prefix)                   // 22-34 bytes
+		ms.WriteByte(byte(addrWithRole.role))   // 1 byte
+		ms.WriteByte(byte(output.OutputType())) // 1 byte
+		ms.WriteBytes(output.outputID[:])       // 34 bytes
+		keys = append(keys, ms.Bytes())
+	}
+	return keys, nil
+}
+
+func addressIndexStateKey() []byte {
+	return []byte{UTXOStoreKeyPrefixAddressIndexState}
+}
+
+func storeAddressIndex(output *Output, mutations kvstore.BatchedMutations) error {
+	keys, err := addressIndexKeysForOutput(output)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := mutations.Set(key, []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteAddressIndex(output *Output, mutations kvstore.BatchedMutations) error {
+	keys, err := addressIndexKeysForOutput(output)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := mutations.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adds the address index entries of the given output to the mutations if the index is enabled.
+func (u *Manager) addToAddressIndex(output *Output, mutations kvstore.BatchedMutations) error {
+	if !u.addressIndexEnabled {
+		return nil
+	}
+	return storeAddressIndex(output, mutations)
+}
+
+// adds delete instructions for the address index entries of the given output to the mutations if the index is enabled.
+func (u *Manager) removeFromAddressIndex(output *Output, mutations kvstore.BatchedMutations) error {
+	if !u.addressIndexEnabled {
+		return nil
+	}
+	return deleteAddressIndex(output, mutations)
+}
+
+// AddressIndexEnabled returns whether the address index is maintained by the manager.
+func (u *Manager) AddressIndexEnabled() bool {
+	return u.addressIndexEnabled
+}
+
+// ConfigureAddressIndex enables or disables the address index.
+// If the index gets enabled but was not completely built before, it is rebuilt from the unspent outputs of the ledger.
+// If the index gets disabled, all existing index entries are removed.
+// It returns whether the index was rebuilt.
+func (u *Manager) ConfigureAddressIndex(enabled bool) (rebuilt bool, err error) {
+	u.WriteLockLedger()
+	defer u.WriteUnlockLedger()
+
+	defer func() {
+		if errFlush := u.utxoStorage.Flush(); err == nil && errFlush != nil {
+			err = errFlush
+		}
+	}()
+
+	if !enabled {
+		u.addressIndexEnabled = false
+		if err := u.utxoStorage.Delete(addressIndexStateKey()); err != nil {
+			return false, err
+		}
+		return false, u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixAddressIndex})
+	}
+
+	built, err := u.utxoStorage.Has(addressIndexStateKey())
+	if err != nil {
+		return false, err
+	}
+	u.addressIndexEnabled = true
+
+	if built {
+		return false, nil
+	}
+
+	if err := u.rebuildAddressIndexWithoutLocking(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// rebuildAddressIndexWithoutLocking drops all address index entries and recreates them from the unspent outputs.
+func (u *Manager) rebuildAddressIndexWithoutLocking() error {
+	if err := u.utxoStorage.Delete(addressIndexStateKey()); err != nil {
+		return err
+	}
+
+	if err := u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixAddressIndex}); err != nil {
+		return err
+	}
+
+	mutations, err := u.utxoStorage.Batched()
+	if err != nil {
+		return err
+	}
+
+	var batchSize int
+	var innerErr error
+	if err := u.ForEachUnspentOutput(func(output *Output) bool {
+		if err := storeAddressIndex(output, mutations); err != nil {
+			innerErr = err
+			return false
+		}
+
+		batchSize++
+		if batchSize < addressIndexRebuildBatchSize {
+			return true
+		}
+
+		if err := mutations.Commit(); err != nil {
+			innerErr = err
+			return false
+		}
+
+		batchSize = 0
+		if mutations, err = u.utxoStorage.Batched(); err != nil {
+			innerErr = err
+			return false
+		}
+
+		return true
+	}, ReadLockLedger(false)); err != nil {
+		mutations.Cancel()
+		return err
+	}
+
+	if innerErr != nil {
+		mutations.Cancel()
+		return innerErr
+	}
+
+	// the index is only marked as built after all entries were written
+	if err := mutations.Set(addressIndexStateKey(), []byte{}); err != nil {
+		mutations.Cancel()
+		return err
+	}
+
+	return mutations.Commit()
+}
+
+// ForEachUnspentOutputOnAddress iterates over all unspent outputs that reference the given address in their unlock conditions.
+// The results are ordered by address role, output type and output ID.
+func (u *Manager) ForEachUnspentOutputOnAddress(address iotago.Address, filter *AddressIndexFilter, consumer AddressIndexConsumer, options ...UTXOIterateOption) error {
+	if !u.addressIndexEnabled {
+		return ErrAddressIndexDisabled
+	}
+
+	opt := iterateOptions(options)
+
+	if opt.readLockLedger {
+		u.ReadLockLedger()
+		defer u.ReadUnlockLedger()
+	}
+
+	addressPrefix, err := addressIndexKeyPrefixForAddress(address)
+	if err != nil {
+		return err
+	}
+
+	iteratePrefix := append([]byte{}, addressPrefix...)
+	var filterOutputType *iotago.OutputType
+	if filter != nil {
+		filterOutputType = filter.OutputType
+		if filter.Role != nil {
+			iteratePrefix = append(iteratePrefix, byte(*filter.Role))
+			if filter.OutputType != nil {
+				// the output type is part of the prefix, no need to filter
+				iteratePrefix = append(iteratePrefix, byte(*filter.OutputType))
+				filterOutputType = nil
+			}
+		}
+	}
+
+	var innerErr error
+	var i int
+	if err := u.utxoStorage.IterateKeys(iteratePrefix, func(key kvstore.Key) bool {
+		cursor := key[len(addressPrefix):]
+
+		if len(cursor) != 2+iotago.OutputIDLength {
+			innerErr = fmt.Errorf("invalid address index key length: %d", len(key))
+			return false
+		}
+
+		if opt.skipCursor(cursor) {
+			return true
+		}
+
+		if filterOutputType != nil && iotago.OutputType(cursor[1]) != *filterOutputType {
+			return true
+		}
+
+		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
+			return false
+		}
+		i++
+
+		outputID := &iotago.OutputID{}
+		copy(outputID[:], cursor[2:])
+
+		output, err := u.ReadOutputByOutputIDWithoutLocking(outputID)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+
+		return consumer(cursor, output)
+	}); err != nil {
+		return err
+	}
+
+	return innerErr
+}
+
+// UnspentOutputsOnAddress returns all unspent outputs that reference the given address in their unlock conditions.
+func (u *Manager) UnspentOutputsOnAddress(address iotago.Address, filter *AddressIndexFilter, options ...UTXOIterateOption) (Outputs, error) {
+	var outputs Outputs
+	consumerFunc := func(_ []byte, output *Output) bool {
+		outputs = append(outputs, output)
+		return true
+	}
+
+	if err := u.ForEachUnspentOutputOnAddress(address, filter, consumerFunc, options...); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
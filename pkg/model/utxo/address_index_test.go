@@ -0,0 +1,175 @@
+package utxo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo/utils"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func addressIndexOutputIDs(t *testing.T, manager *Manager, address iotago.Address, filter *AddressIndexFilter, options ...UTXOIterateOption) map[string]struct{} {
+	outputs, err := manager.UnspentOutputsOnAddress(address, filter, options...)
+	require.NoError(t, err)
+
+	outputIDs := make(map[string]struct{})
+	for _, output := range outputs {
+		outputIDs[output.mapKey()] = struct{}{}
+	}
+	return outputIDs
+}
+
+func TestAddressIndexApplyAndRollback(t *testing.T) {
+
+	manager := New(mapdb.NewMapDB())
+
+	_, err := manager.UnspentOutputsOnAddress(utils.RandAddress(iotago.AddressEd25519), nil)
+	require.ErrorIs(t, err, ErrAddressIndexDisabled)
+
+	rebuilt, err := manager.ConfigureAddressIndex(true)
+	require.NoError(t, err)
+	require.True(t, rebuilt)
+
+	address := utils.RandAddress(iotago.AddressEd25519)
+	aliasAddress := utils.RandAddress(iotago.AddressAlias)
+
+	initialOutput := RandUTXOOutputOnAddress(iotago.OutputBasic, address)
+	require.NoError(t, manager.AddUnspentOutput(initialOutput))
+
+	outputs := Outputs{
+		RandUTXOOutputOnAddress(iotago.OutputBasic, address),
+		RandUTXOOutputOnAddress(iotago.OutputNFT, address),
+		RandUTXOOutputOnAddress(iotago.OutputAlias, address),
+		RandUTXOOutputOnAddress(iotago.OutputFoundry, aliasAddress),
+		RandUTXOOutputOnAddress(iotago.OutputBasic, utils.RandAddress(iotago.AddressEd25519)),
+	}
+
+	msIndex := milestone.Index(756)
+	msTimestamp := rand.Uint32()
+
+	spents := Spents{
+		RandUTXOSpent(initialOutput, msIndex, msTimestamp),
+	}
+
+	require.NoError(t, manager.ApplyConfirmationWithoutLocking(msIndex, outputs, spents, nil, nil))
+
+	// the alias output is referenced twice (state controller and governor)
+	require.Len(t, addressIndexOutputIDs(t, manager, address, nil), 3)
+
+	allOutputs, err := manager.UnspentOutputsOnAddress(address, nil)
+	require.NoError(t, err)
+	require.Len(t, allOutputs, 4)
+
+	roleAddress := AddressRoleAddress
+	require.Equal(t, map[string]struct{}{
+		outputs[0].mapKey(): {},
+		outputs[1].mapKey(): {},
+	}, addressIndexOutputIDs(t, manager, address, &AddressIndexFilter{Role: &roleAddress}))
+
+	outputTypeNFT := iotago.OutputNFT
+	require.Equal(t, map[string]struct{}{
+		outputs[1].mapKey(): {},
+	}, addressIndexOutputIDs(t, manager, address, &AddressIndexFilter{Role: &roleAddress, OutputType: &outputTypeNFT}))
+
+	outputTypeAlias := iotago.OutputAlias
+	require.Equal(t, map[string]struct{}{
+		outputs[2].mapKey(): {},
+	}, addressIndexOutputIDs(t, manager, address, &AddressIndexFilter{OutputType: &outputTypeAlias}))
+
+	roleImmutableAlias := AddressRoleImmutableAlias
+	require.Equal(t, map[string]struct{}{
+		outputs[3].mapKey(): {},
+	}, addressIndexOutputIDs(t, manager, aliasAddress, &AddressIndexFilter{Role: &roleImmutableAlias}))
+
+	require.NoError(t, manager.RollbackConfirmationWithoutLocking(msIndex, outputs, spents, nil, nil))
+
+	require.Equal(t, map[string]struct{}{
+		initialOutput.mapKey(): {},
+	}, addressIndexOutputIDs(t, manager, address, nil))
+
+	require.Empty(t, addressIndexOutputIDs(t, manager, aliasAddress, nil))
+}
+
+func TestAddressIndexCursor(t *testing.T) {
+
+	manager := New(mapdb.NewMapDB())
+
+	_, err := manager.ConfigureAddressIndex(true)
+	require.NoError(t, err)
+
+	address := utils.RandAddress(iotago.AddressEd25519)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, manager.AddUnspentOutput(RandUTXOOutputOnAddress(iotago.OutputBasic, address)))
+	}
+
+	pageSize := 3
+	seen := make(map[string]struct{})
+
+	var cursor []byte
+	for {
+		var nextCursor []byte
+		var count int
+		require.NoError(t, manager.ForEachUnspentOutputOnAddress(address, nil, func(c []byte, output *Output) bool {
+			if count == pageSize {
+				nextCursor = c
+				return false
+			}
+			count++
+
+			_, exists := seen[output.mapKey()]
+			require.False(t, exists)
+			seen[output.mapKey()] = struct{}{}
+			return true
+		}, IterateFromCursor(cursor)))
+
+		if nextCursor == nil {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	require.Len(t, seen, 10)
+}
+
+func TestAddressIndexRebuild(t *testing.T) {
+
+	store := mapdb.NewMapDB()
+	manager := New(store)
+
+	address := utils.RandAddress(iotago.AddressEd25519)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, manager.AddUnspentOutput(RandUTXOOutputOnAddress(iotago.OutputBasic, address)))
+	}
+
+	rebuilt, err := manager.ConfigureAddressIndex(true)
+	require.NoError(t, err)
+	require.True(t, rebuilt)
+	require.Len(t, addressIndexOutputIDs(t, manager, address, nil), 5)
+
+	// a new manager on the same store keeps maintaining the index
+	manager = New(store)
+	require.True(t, manager.AddressIndexEnabled())
+	require.NoError(t, manager.AddUnspentOutput(RandUTXOOutputOnAddress(iotago.OutputBasic, address)))
+
+	rebuilt, err = manager.ConfigureAddressIndex(true)
+	require.NoError(t, err)
+	require.False(t, rebuilt)
+	require.Len(t, addressIndexOutputIDs(t, manager, address, nil), 6)
+
+	rebuilt, err = manager.ConfigureAddressIndex(false)
+	require.NoError(t, err)
+	require.False(t, rebuilt)
+
+	var indexEntries int
+	require.NoError(t, store.IterateKeys([]byte{UTXOStoreKeyPrefixAddressIndex}, func(_ []byte) bool {
+		indexEntries++
+		return true
+	}))
+	require.Zero(t, indexEntries)
+}
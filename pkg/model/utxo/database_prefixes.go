@@ -16,6 +16,10 @@ const (
 	// Chrysalis Migration
 	UTXOStoreKeyPrefixTreasuryOutput byte = 6
 	UTXOStoreKeyPrefixReceipts       byte = 7
+
+	// Address index
+	UTXOStoreKeyPrefixAddressIndex      byte = 10
+	UTXOStoreKeyPrefixAddressIndexState byte = 11
 )
 
 // Deprecated keys, just used for migration purposes
@@ -88,6 +92,26 @@ const (
        Amount
        8 bytes
 
+   Address Index:
+   ==============
+   Key:
+       UTXOStoreKeyPrefixAddressIndex +     iotago.Address.Serialized()     + AddressRole + iotago.OutputType + iotago.OutputID
+                   1 byte             +     1 byte type + 20-32 bytes       +   1 byte    +       1 byte      + 32 bytes + 2 bytes
+
+   Value:
+       Empty
+
+
+   Address Index State:
+   ====================
+   Key:
+       UTXOStoreKeyPrefixAddressIndexState
+                     1 byte
+
+   Value:
+       Empty (only exists if the address index was completely built)
+
+
    Milestone diffs:
    ================
    Key:
@@ -0,0 +1,268 @@
+package utxo
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/iotaledger/hive.go/kvstore"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+var (
+	// ErrLedgerStateNotAvailable is returned if the ledger state at a milestone index can't be computed,
+	// because the index is newer than the ledger index or the needed milestone diffs were already pruned.
+	ErrLedgerStateNotAvailable = errors.New("ledger state not available")
+)
+
+// CheckLedgerStateAvailableWithoutLocking checks whether the ledger state at the given milestone index
+// can be computed by rolling back the stored milestone diffs from the current ledger state.
+// It returns the current ledger index.
+func (u *Manager) CheckLedgerStateAvailableWithoutLocking(msIndex milestone.Index) (milestone.Index, error) {
+
+	ledgerIndex, err := u.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return 0, err
+	}
+
+	if msIndex > ledgerIndex {
+		return 0, errors.WithMessagef(ErrLedgerStateNotAvailable, "milestone index %d is newer than the ledger index %d", msIndex, ledgerIndex)
+	}
+
+	if msIndex == ledgerIndex {
+		return ledgerIndex, nil
+	}
+
+	// all milestone diffs after the given index are needed.
+	// the oldest milestone diffs are pruned first, so it is sufficient to check the oldest needed one.
+	exists, err := u.utxoStorage.Has(milestoneDiffKeyForIndex(msIndex + 1))
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, errors.WithMessagef(ErrLedgerStateNotAvailable, "milestone diff for index %d was already pruned", msIndex+1)
+	}
+
+	return ledgerIndex, nil
+}
+
+// OldestMilestoneDiffIndex returns the oldest milestone index for which the milestone diff is stored.
+// It returns 0 if there are no milestone diffs.
+func (u *Manager) OldestMilestoneDiffIndex() (milestone.Index, error) {
+	u.ReadLockLedger()
+	defer u.ReadUnlockLedger()
+
+	ledgerIndex, err := u.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return 0, err
+	}
+
+	exists, err := u.utxoStorage.Has(milestoneDiffKeyForIndex(ledgerIndex))
+	if err != nil {
+		return 0, err
+	}
+	if ledgerIndex == 0 || !exists {
+		return 0, nil
+	}
+
+	// the oldest milestone diffs are pruned first, so the stored milestone diffs are contiguous up to the ledger index.
+	lower, upper := milestone.Index(1), ledgerIndex
+	for lower < upper {
+		index := lower + (upper-lower)/2
+
+		exists, err := u.utxoStorage.Has(milestoneDiffKeyForIndex(index))
+		if err != nil {
+			return 0, err
+		}
+
+		if exists {
+			upper = index
+		} else {
+			lower = index + 1
+		}
+	}
+
+	return lower, nil
+}
+
+// ReadOutputStateAtMilestoneWithoutLocking returns the output with the given ID as it was at the given milestone index.
+// The returned spent is nil if the output was unspent at that milestone index.
+// kvstore.ErrKeyNotFound is returned if the output was not booked yet.
+func (u *Manager) ReadOutputStateAtMilestoneWithoutLocking(outputID *iotago.OutputID, msIndex milestone.Index) (*Output, *Spent, error) {
+
+	if _, err := u.CheckLedgerStateAvailableWithoutLocking(msIndex); err != nil {
+		return nil, nil, err
+	}
+
+	output, err := u.ReadOutputByOutputIDWithoutLocking(outputID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if output.MilestoneIndex() > msIndex {
+		// the output was booked after the given milestone index
+		return nil, nil, kvstore.ErrKeyNotFound
+	}
+
+	unspent, err := u.IsOutputIDUnspentWithoutLocking(outputID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if unspent {
+		return output, nil, nil
+	}
+
+	spent, err := u.ReadSpentForOutputIDWithoutLocking(outputID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if spent.MilestoneIndex() > msIndex {
+		// the output was spent after the given milestone index
+		return output, nil, nil
+	}
+
+	return output, spent, nil
+}
+
+// forEachOutputSpentAfterMilestoneWithoutLocking iterates over all outputs that were booked until the given milestone index,
+// but were spent by a later milestone.
+func (u *Manager) forEachOutputSpentAfterMilestoneWithoutLocking(msIndex milestone.Index, ledgerIndex milestone.Index, consumer OutputConsumer) error {
+	for index := msIndex + 1; index <= ledgerIndex; index++ {
+		msDiff, err := u.MilestoneDiffWithoutLocking(index)
+		if err != nil {
+			if errors.Is(err, kvstore.ErrKeyNotFound) {
+				return errors.WithMessagef(ErrLedgerStateNotAvailable, "milestone diff for index %d was already pruned", index)
+			}
+			return err
+		}
+
+		for _, spent := range msDiff.Spents {
+			if spent.Output().MilestoneIndex() > msIndex {
+				// the output didn't exist at the given milestone index
+				continue
+			}
+
+			if !consumer(spent.Output()) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// ForEachUnspentOutputAtMilestone iterates over all outputs that were unspent at the given milestone index.
+// The ledger state is computed by rolling back the milestone diffs from the current ledger state.
+// The outputs are not ordered and only the ReadLockLedger option is applied.
+func (u *Manager) ForEachUnspentOutputAtMilestone(msIndex milestone.Index, consumer OutputConsumer, options ...UTXOIterateOption) error {
+	opt := iterateOptions(options)
+
+	if opt.readLockLedger {
+		u.ReadLockLedger()
+		defer u.ReadUnlockLedger()
+	}
+
+	ledgerIndex, err := u.CheckLedgerStateAvailableWithoutLocking(msIndex)
+	if err != nil {
+		return err
+	}
+
+	consumerFinished := false
+	if err := u.forEachOutputSpentAfterMilestoneWithoutLocking(msIndex, ledgerIndex, func(output *Output) bool {
+		consumerFinished = !consumer(output)
+		return !consumerFinished
+	}); err != nil {
+		return err
+	}
+
+	if consumerFinished {
+		return nil
+	}
+
+	return u.ForEachUnspentOutput(func(output *Output) bool {
+		if output.MilestoneIndex() > msIndex {
+			// the output was booked after the given milestone index
+			return true
+		}
+		return consumer(output)
+	}, ReadLockLedger(false))
+}
+
+// ForEachUnspentOutputOnAddressAtMilestone iterates over all outputs that were unspent at the given milestone index
+// and are owned by the given address, which means the address is referenced by the AddressUnlockCondition of the output.
+// Outputs that only reference the address as a return address, state controller or governor are not owned by the address.
+// The address index is used if it is enabled, otherwise all unspent outputs are checked.
+// The outputs are not ordered and only the ReadLockLedger option is applied.
+func (u *Manager) ForEachUnspentOutputOnAddressAtMilestone(address iotago.Address, msIndex milestone.Index, consumer OutputConsumer, options ...UTXOIterateOption) error {
+	opt := iterateOptions(options)
+
+	if opt.readLockLedger {
+		u.ReadLockLedger()
+		defer u.ReadUnlockLedger()
+	}
+
+	ledgerIndex, err := u.CheckLedgerStateAvailableWithoutLocking(msIndex)
+	if err != nil {
+		return err
+	}
+
+	addressKey := address.Key()
+	ownedByAddress := func(output *Output) bool {
+		for _, addrWithRole := range addressesWithRoles(output.Output()) {
+			if addrWithRole.role == AddressRoleAddress && addrWithRole.address.Key() == addressKey {
+				return true
+			}
+		}
+		return false
+	}
+
+	consumerFinished := false
+	if err := u.forEachOutputSpentAfterMilestoneWithoutLocking(msIndex, ledgerIndex, func(output *Output) bool {
+		if !ownedByAddress(output) {
+			return true
+		}
+		consumerFinished = !consumer(output)
+		return !consumerFinished
+	}); err != nil {
+		return err
+	}
+
+	if consumerFinished {
+		return nil
+	}
+
+	if !u.addressIndexEnabled {
+		return u.ForEachUnspentOutput(func(output *Output) bool {
+			if output.MilestoneIndex() > msIndex || !ownedByAddress(output) {
+				return true
+			}
+			return consumer(output)
+		}, ReadLockLedger(false))
+	}
+
+	// an output has at most one AddressUnlockCondition, so every output is only contained once
+	role := AddressRoleAddress
+	return u.ForEachUnspentOutputOnAddress(address, &AddressIndexFilter{Role: &role}, func(_ []byte, output *Output) bool {
+		if output.MilestoneIndex() > msIndex {
+			// the output was booked after the given milestone index
+			return true
+		}
+		return consumer(output)
+	}, ReadLockLedger(false))
+}
+
+// AddressBalanceAtMilestone returns the sum of the deposits and the count of the outputs that were unspent at the given milestone index
+// and are owned by the given address.
+func (u *Manager) AddressBalanceAtMilestone(address iotago.Address, msIndex milestone.Index, options ...UTXOIterateOption) (balance uint64, count int, err error) {
+
+	consumerFunc := func(output *Output) bool {
+		balance += output.Deposit()
+		count++
+		return true
+	}
+
+	if err := u.ForEachUnspentOutputOnAddressAtMilestone(address, msIndex, consumerFunc, options...); err != nil {
+		return 0, 0, err
+	}
+
+	return balance, count, nil
+}
@@ -0,0 +1,164 @@
+package utxo
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo/utils"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func randUTXOOutputOnAddressAtMilestone(address iotago.Address, amount uint64, msIndex milestone.Index) *Output {
+	return CreateOutput(utils.RandOutputID(), utils.RandMessageID(), msIndex, rand.Uint32(), utils.RandOutputOnAddressWithAmount(iotago.OutputBasic, address, amount))
+}
+
+func TestHistoricLedgerState(t *testing.T) {
+
+	for _, addressIndexEnabled := range []bool{false, true} {
+
+		manager := New(mapdb.NewMapDB())
+		_, err := manager.ConfigureAddressIndex(addressIndexEnabled)
+		require.NoError(t, err)
+
+		address := utils.RandAddress(iotago.AddressEd25519)
+
+		outputA := randUTXOOutputOnAddressAtMilestone(address, 100, 10)
+		outputB := randUTXOOutputOnAddressAtMilestone(address, 200, 10)
+		outputC := randUTXOOutputOnAddressAtMilestone(utils.RandAddress(iotago.AddressEd25519), 50, 10)
+		require.NoError(t, manager.ApplyConfirmationWithoutLocking(10, Outputs{outputA, outputB, outputC}, Spents{}, nil, nil))
+
+		outputD := randUTXOOutputOnAddressAtMilestone(address, 300, 11)
+		require.NoError(t, manager.ApplyConfirmationWithoutLocking(11, Outputs{outputD}, Spents{RandUTXOSpent(outputA, 11, rand.Uint32())}, nil, nil))
+
+		outputE := randUTXOOutputOnAddressAtMilestone(address, 400, 12)
+		require.NoError(t, manager.ApplyConfirmationWithoutLocking(12, Outputs{outputE}, Spents{RandUTXOSpent(outputB, 12, rand.Uint32()), RandUTXOSpent(outputD, 12, rand.Uint32())}, nil, nil))
+
+		// balances
+		for msIndex, expectedBalance := range map[milestone.Index]uint64{10: 300, 11: 500, 12: 400} {
+			balance, _, err := manager.AddressBalanceAtMilestone(address, msIndex)
+			require.NoError(t, err)
+			require.Equal(t, expectedBalance, balance, "milestone index %d", msIndex)
+		}
+
+		// output set
+		for msIndex, expectedCount := range map[milestone.Index]int{10: 3, 11: 3, 12: 2} {
+			var count int
+			require.NoError(t, manager.ForEachUnspentOutputAtMilestone(msIndex, func(_ *Output) bool {
+				count++
+				return true
+			}))
+			require.Equal(t, expectedCount, count, "milestone index %d", msIndex)
+		}
+
+		// output states
+		output, spent, err := manager.ReadOutputStateAtMilestoneWithoutLocking(outputA.OutputID(), 10)
+		require.NoError(t, err)
+		EqualOutput(t, outputA, output)
+		require.Nil(t, spent)
+
+		_, spent, err = manager.ReadOutputStateAtMilestoneWithoutLocking(outputA.OutputID(), 11)
+		require.NoError(t, err)
+		require.NotNil(t, spent)
+		require.Equal(t, milestone.Index(11), spent.MilestoneIndex())
+
+		_, _, err = manager.ReadOutputStateAtMilestoneWithoutLocking(outputD.OutputID(), 10)
+		require.True(t, errors.Is(err, kvstore.ErrKeyNotFound))
+
+		// milestone indexes newer than the ledger index are not available
+		_, _, err = manager.AddressBalanceAtMilestone(address, 13)
+		require.True(t, errors.Is(err, ErrLedgerStateNotAvailable))
+
+		oldestMilestoneDiffIndex, err := manager.OldestMilestoneDiffIndex()
+		require.NoError(t, err)
+		require.Equal(t, milestone.Index(10), oldestMilestoneDiffIndex)
+
+		// the ledger state before pruned milestone diffs is not available
+		require.NoError(t, manager.PruneMilestoneIndexWithoutLocking(10, false))
+
+		oldestMilestoneDiffIndex, err = manager.OldestMilestoneDiffIndex()
+		require.NoError(t, err)
+		require.Equal(t, milestone.Index(11), oldestMilestoneDiffIndex)
+
+		_, _, err = manager.AddressBalanceAtMilestone(address, 9)
+		require.True(t, errors.Is(err, ErrLedgerStateNotAvailable))
+
+		balance, _, err := manager.AddressBalanceAtMilestone(address, 10)
+		require.NoError(t, err)
+		require.Equal(t, uint64(300), balance)
+	}
+}
+
+func TestHistoricBalanceOnlyCountsOwnedOutputs(t *testing.T) {
+
+	for _, addressIndexEnabled := range []bool{false, true} {
+
+		manager := New(mapdb.NewMapDB())
+		_, err := manager.ConfigureAddressIndex(addressIndexEnabled)
+		require.NoError(t, err)
+
+		address := utils.RandAddress(iotago.AddressEd25519)
+		otherAddress := utils.RandAddress(iotago.AddressEd25519)
+
+		newOutput := func(output iotago.Output, msIndex milestone.Index) *Output {
+			return CreateOutput(utils.RandOutputID(), utils.RandMessageID(), msIndex, rand.Uint32(), output)
+		}
+
+		ownedOutput := randUTXOOutputOnAddressAtMilestone(address, 100, 10)
+
+		// the address is only the return address of the storage deposit
+		storageDepositReturnOutput := newOutput(&iotago.BasicOutput{
+			Amount: 200,
+			Conditions: iotago.UnlockConditions{
+				&iotago.AddressUnlockCondition{Address: otherAddress},
+				&iotago.StorageDepositReturnUnlockCondition{ReturnAddress: address, Amount: 50},
+			},
+		}, 10)
+
+		// the address is only the return address after the expiration
+		expirationOutput := newOutput(&iotago.BasicOutput{
+			Amount: 300,
+			Conditions: iotago.UnlockConditions{
+				&iotago.AddressUnlockCondition{Address: otherAddress},
+				&iotago.ExpirationUnlockCondition{ReturnAddress: address, MilestoneIndex: 100},
+			},
+		}, 10)
+
+		// the address only controls the alias
+		aliasOutput := newOutput(&iotago.AliasOutput{
+			Amount: 400,
+			Conditions: iotago.UnlockConditions{
+				&iotago.StateControllerAddressUnlockCondition{Address: address},
+				&iotago.GovernorAddressUnlockCondition{Address: address},
+			},
+		}, 10)
+
+		require.NoError(t, manager.ApplyConfirmationWithoutLocking(10, Outputs{ownedOutput, storageDepositReturnOutput, expirationOutput, aliasOutput}, Spents{}, nil, nil))
+
+		// spending the outputs must not change the historic balance of the address either
+		require.NoError(t, manager.ApplyConfirmationWithoutLocking(11, Outputs{}, Spents{
+			RandUTXOSpent(ownedOutput, 11, rand.Uint32()),
+			RandUTXOSpent(storageDepositReturnOutput, 11, rand.Uint32()),
+			RandUTXOSpent(expirationOutput, 11, rand.Uint32()),
+		}, nil, nil))
+
+		balance, count, err := manager.AddressBalanceAtMilestone(address, 10)
+		require.NoError(t, err)
+		require.Equal(t, uint64(100), balance)
+		require.Equal(t, 1, count)
+
+		balance, count, err = manager.AddressBalanceAtMilestone(address, 11)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), balance)
+		require.Equal(t, 0, count)
+
+		balance, _, err = manager.AddressBalanceAtMilestone(otherAddress, 10)
+		require.NoError(t, err)
+		require.Equal(t, uint64(500), balance)
+	}
+}
@@ -1,12 +1,15 @@
 package utxo
 
 import (
+	"bytes"
+
 	"github.com/iotaledger/hive.go/kvstore"
 )
 
 type UTXOIterateOptions struct {
 	readLockLedger bool
 	maxResultCount int
+	cursor         []byte
 }
 
 type UTXOIterateOption func(*UTXOIterateOptions)
@@ -17,10 +20,27 @@ func ReadLockLedger(lockLedger bool) UTXOIterateOption {
 	}
 }
 
+// MaxResultCount stops the iteration after the given amount of consumed elements.
+// 0 disables the limit.
+func MaxResultCount(maxResultCount int) UTXOIterateOption {
+	return func(args *UTXOIterateOptions) {
+		args.maxResultCount = maxResultCount
+	}
+}
+
+// IterateFromCursor skips all elements that are positioned before the given cursor.
+// The cursor of an element is its database key without the prefix byte of the table.
+func IterateFromCursor(cursor []byte) UTXOIterateOption {
+	return func(args *UTXOIterateOptions) {
+		args.cursor = cursor
+	}
+}
+
 func iterateOptions(optionalOptions []UTXOIterateOption) *UTXOIterateOptions {
 	result := &UTXOIterateOptions{
 		readLockLedger: true,
 		maxResultCount: 0,
+		cursor:         nil,
 	}
 
 	for _, optionalOption := range optionalOptions {
@@ -30,6 +50,11 @@ func iterateOptions(optionalOptions []UTXOIterateOption) *UTXOIterateOptions {
 	return result
 }
 
+// skipCursor returns whether the element with the given cursor is positioned before the cursor of the options.
+func (o *UTXOIterateOptions) skipCursor(cursor []byte) bool {
+	return o.cursor != nil && bytes.Compare(cursor, o.cursor) < 0
+}
+
 func (u *Manager) ForEachOutput(consumer OutputConsumer, options ...UTXOIterateOption) error {
 	opt := iterateOptions(options)
 
@@ -41,6 +66,10 @@ func (u *Manager) ForEachOutput(consumer OutputConsumer, options ...UTXOIterateO
 	var innerErr error
 	var i int
 	if err := u.utxoStorage.Iterate([]byte{UTXOStoreKeyPrefixOutput}, func(key kvstore.Key, value kvstore.Value) bool {
+		if opt.skipCursor(key[1:]) {
+			return true
+		}
+
 		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
 			return false
 		}
@@ -73,6 +102,10 @@ func (u *Manager) ForEachSpentOutput(consumer SpentConsumer, options ...UTXOIter
 	var innerErr error
 	var i int
 	if err := u.utxoStorage.Iterate(key, func(key kvstore.Key, value kvstore.Value) bool {
+		if opt.skipCursor(key[1:]) {
+			return true
+		}
+
 		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
 			return false
 		}
@@ -121,6 +154,10 @@ func (u *Manager) ForEachUnspentOutput(consumer OutputConsumer, options ...UTXOI
 	var innerErr error
 	var i int
 	if err := u.utxoStorage.IterateKeys([]byte{UTXOStoreKeyPrefixOutputUnspent}, func(key kvstore.Key) bool {
+		if opt.skipCursor(key[1:]) {
+			return true
+		}
+
 		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
 			return false
 		}
@@ -124,3 +124,40 @@ func TestUTXOIteration(t *testing.T) {
 
 	require.Empty(t, spentByID)
 }
+
+func TestUTXOIterationFromCursor(t *testing.T) {
+
+	utxo := New(mapdb.NewMapDB())
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, utxo.AddUnspentOutput(RandUTXOOutputOnAddress(iotago.OutputBasic, utils.RandAddress(iotago.AddressEd25519))))
+	}
+
+	pageSize := 4
+	seen := make(map[string]struct{})
+
+	var cursor []byte
+	for {
+		var nextCursor []byte
+		var count int
+		require.NoError(t, utxo.ForEachUnspentOutput(func(output *Output) bool {
+			if count == pageSize {
+				nextCursor = output.outputID[:]
+				return false
+			}
+			count++
+
+			_, exists := seen[output.mapKey()]
+			require.False(t, exists)
+			seen[output.mapKey()] = struct{}{}
+			return true
+		}, IterateFromCursor(cursor)))
+
+		if nextCursor == nil {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	require.Len(t, seen, 10)
+}
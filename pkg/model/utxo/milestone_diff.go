@@ -218,3 +218,69 @@ func (u *Manager) MilestoneDiff(msIndex milestone.Index) (*MilestoneDiff, error)
 
 	return u.MilestoneDiffWithoutLocking(msIndex)
 }
+
+// ArchiveMilestoneDiffWithoutLocking writes the milestone diff of the given milestone index to the given mutations
+// of another store, together with the outputs, spents and treasury outputs it references.
+// This way the diff can be loaded from a Manager of that store, even after it was pruned from this ledger.
+func (u *Manager) ArchiveMilestoneDiffWithoutLocking(msIndex milestone.Index, mutations kvstore.BatchedMutations) error {
+
+	diff, err := u.MilestoneDiffWithoutLocking(msIndex)
+	if err != nil {
+		return err
+	}
+
+	for _, output := range diff.Outputs {
+		if err := storeOutput(output, mutations); err != nil {
+			return err
+		}
+	}
+
+	for _, spent := range diff.Spents {
+		if err := storeOutput(spent.output, mutations); err != nil {
+			return err
+		}
+
+		if err := storeSpent(spent, mutations); err != nil {
+			return err
+		}
+	}
+
+	if diff.TreasuryOutput != nil {
+		if err := storeTreasuryOutput(diff.TreasuryOutput, mutations); err != nil {
+			return err
+		}
+
+		if err := storeTreasuryOutput(diff.SpentTreasuryOutput, mutations); err != nil {
+			return err
+		}
+	}
+
+	return storeDiff(diff, mutations)
+}
+
+// MilestoneDiffPruningSize returns the amount of bytes in the ledger which are removed if the given milestone is pruned.
+// This includes the milestone diff itself and the spent outputs it references.
+func (u *Manager) MilestoneDiffPruningSize(msIndex milestone.Index) (int64, error) {
+	u.ReadLockLedger()
+	defer u.ReadUnlockLedger()
+
+	key := milestoneDiffKeyForIndex(msIndex)
+
+	value, err := u.utxoStorage.Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	diff := &MilestoneDiff{}
+	if err := diff.kvStorableLoad(u, key, value); err != nil {
+		return 0, err
+	}
+
+	size := int64(len(key) + len(value))
+	for _, spent := range diff.Spents {
+		size += int64(len(spent.output.kvStorableKey()) + len(spent.output.kvStorableValue()))
+		size += int64(len(spent.kvStorableKey()) + len(spent.kvStorableValue()))
+	}
+
+	return size, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/utxo/utils"
 	"github.com/iotaledger/hive.go/byteutils"
+	"github.com/iotaledger/hive.go/kvstore"
 	"github.com/iotaledger/hive.go/kvstore/mapdb"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
@@ -185,3 +186,86 @@ func TestMilestoneDiffSerialization(t *testing.T) {
 	require.Equal(t, treasuryOutput, readDiff.TreasuryOutput)
 	require.Equal(t, spentTreasuryOutput, readDiff.SpentTreasuryOutput)
 }
+
+func TestArchiveMilestoneDiff(t *testing.T) {
+	manager := New(mapdb.NewMapDB())
+
+	address := utils.RandAddress(iotago.AddressEd25519)
+
+	outputA := randUTXOOutputOnAddressAtMilestone(address, 100, 10)
+	outputB := randUTXOOutputOnAddressAtMilestone(address, 200, 10)
+	require.NoError(t, manager.ApplyConfirmationWithoutLocking(10, Outputs{outputA, outputB}, Spents{}, nil, nil))
+
+	outputC := randUTXOOutputOnAddressAtMilestone(address, 300, 11)
+	spentA := RandUTXOSpent(outputA, 11, rand.Uint32())
+	require.NoError(t, manager.ApplyConfirmationWithoutLocking(11, Outputs{outputC}, Spents{spentA}, nil, nil))
+
+	coldStore := mapdb.NewMapDB()
+	for _, msIndex := range []milestone.Index{10, 11} {
+		mutations, err := coldStore.Batched()
+		require.NoError(t, err)
+		require.NoError(t, manager.ArchiveMilestoneDiffWithoutLocking(msIndex, mutations))
+		require.NoError(t, mutations.Commit())
+	}
+
+	// the archived diffs can still be loaded after they were pruned from the ledger
+	require.NoError(t, manager.PruneMilestoneIndexWithoutLocking(10, false))
+	require.NoError(t, manager.PruneMilestoneIndexWithoutLocking(11, false))
+
+	coldManager := New(coldStore)
+
+	diff, err := coldManager.MilestoneDiffWithoutLocking(10)
+	require.NoError(t, err)
+	require.Equal(t, milestone.Index(10), diff.Index)
+	EqualOutputs(t, Outputs{outputA, outputB}, diff.Outputs)
+	require.Empty(t, diff.Spents)
+
+	diff, err = coldManager.MilestoneDiffWithoutLocking(11)
+	require.NoError(t, err)
+	EqualOutputs(t, Outputs{outputC}, diff.Outputs)
+	EqualSpents(t, Spents{spentA}, diff.Spents)
+
+	// diffs which don't exist can't be archived
+	mutations, err := coldStore.Batched()
+	require.NoError(t, err)
+	require.Error(t, manager.ArchiveMilestoneDiffWithoutLocking(12, mutations))
+	mutations.Cancel()
+}
+
+func TestMilestoneDiffPruningSize(t *testing.T) {
+	store := mapdb.NewMapDB()
+	manager := New(store)
+
+	storeSize := func() int64 {
+		var size int64
+		require.NoError(t, store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+			size += int64(len(key) + len(value))
+			return true
+		}))
+		return size
+	}
+
+	address := utils.RandAddress(iotago.AddressEd25519)
+
+	outputA := randUTXOOutputOnAddressAtMilestone(address, 100, 10)
+	outputB := randUTXOOutputOnAddressAtMilestone(address, 200, 10)
+	require.NoError(t, manager.ApplyConfirmationWithoutLocking(10, Outputs{outputA, outputB}, Spents{}, nil, nil))
+
+	outputC := randUTXOOutputOnAddressAtMilestone(address, 300, 11)
+	spentA := RandUTXOSpent(outputA, 11, rand.Uint32())
+	spentB := RandUTXOSpent(outputB, 11, rand.Uint32())
+	require.NoError(t, manager.ApplyConfirmationWithoutLocking(11, Outputs{outputC}, Spents{spentA, spentB}, nil, nil))
+
+	// the estimated size matches the amount of bytes removed by pruning
+	for _, msIndex := range []milestone.Index{10, 11} {
+		pruningSize, err := manager.MilestoneDiffPruningSize(msIndex)
+		require.NoError(t, err)
+
+		sizeBefore := storeSize()
+		require.NoError(t, manager.PruneMilestoneIndexWithoutLocking(msIndex, false))
+		require.Equal(t, sizeBefore-storeSize(), pruningSize)
+	}
+
+	_, err := manager.MilestoneDiffPruningSize(12)
+	require.ErrorIs(t, err, kvstore.ErrKeyNotFound)
+}
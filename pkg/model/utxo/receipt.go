@@ -28,6 +28,12 @@ func (rt *ReceiptTuple) kvStorableKey() (key []byte) {
 		Bytes()
 }
 
+// Cursor returns the position of the receipt tuple in the ledger, which can be used
+// to continue an iteration over the receipt tuples at exactly this receipt tuple.
+func (rt *ReceiptTuple) Cursor() []byte {
+	return rt.kvStorableKey()[1:]
+}
+
 func (rt *ReceiptTuple) kvStorableValue() (value []byte) {
 	receiptBytes, err := rt.Receipt.Serialize(serializer.DeSeriModeNoValidation, iotago.ZeroRentParas)
 	if err != nil {
@@ -105,6 +111,10 @@ func (u *Manager) ForEachReceiptTuple(consumer ReceiptTupleConsumer, options ...
 	var i int
 	if err := u.utxoStorage.Iterate([]byte{UTXOStoreKeyPrefixReceipts}, func(key kvstore.Key, value kvstore.Value) bool {
 
+		if opt.skipCursor(key[1:]) {
+			return true
+		}
+
 		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
 			return false
 		}
@@ -142,6 +152,10 @@ func (u *Manager) ForEachReceiptTupleMigratedAt(migratedAtIndex milestone.Index,
 	var i int
 	if err := u.utxoStorage.Iterate(prefix, func(key kvstore.Key, value kvstore.Value) bool {
 
+		if opt.skipCursor(key[1:]) {
+			return true
+		}
+
 		if (opt.maxResultCount > 0) && (i >= opt.maxResultCount) {
 			return false
 		}
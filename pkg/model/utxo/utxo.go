@@ -25,11 +25,20 @@ var (
 type Manager struct {
 	utxoStorage kvstore.KVStore
 	utxoLock    sync.RWMutex
+
+	// whether the address index is maintained.
+	addressIndexEnabled bool
 }
 
 func New(store kvstore.KVStore) *Manager {
+	// if the address index was built before, it needs to be maintained
+	// even if the index is not used, otherwise it would get out of sync.
+	// the index can be dropped by calling ConfigureAddressIndex.
+	addressIndexEnabled, _ := store.Has(addressIndexStateKey())
+
 	return &Manager{
-		utxoStorage: store,
+		utxoStorage:         store,
+		addressIndexEnabled: addressIndexEnabled,
 	}
 }
 
@@ -46,7 +55,16 @@ func (u *Manager) ClearLedger(pruneReceipts bool) (err error) {
 
 	if pruneReceipts {
 		// if we also prune the receipts, we can just clear everything
-		return u.utxoStorage.Clear()
+		if err = u.utxoStorage.Clear(); err != nil {
+			return err
+		}
+
+		if u.addressIndexEnabled {
+			// the index of an empty ledger is complete
+			return u.utxoStorage.Set(addressIndexStateKey(), []byte{})
+		}
+
+		return nil
 	}
 
 	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixLedgerMilestoneIndex}); err != nil {
@@ -61,6 +79,9 @@ func (u *Manager) ClearLedger(pruneReceipts bool) (err error) {
 	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixOutputUnspent}); err != nil {
 		return err
 	}
+	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixAddressIndex}); err != nil {
+		return err
+	}
 
 	if err = u.utxoStorage.DeletePrefix([]byte{UTXOStoreKeyPrefixMilestoneDiffs}); err != nil {
 		return err
@@ -198,6 +219,10 @@ func (u *Manager) ApplyConfirmationWithoutLocking(msIndex milestone.Index, newOu
 			mutations.Cancel()
 			return err
 		}
+		if err := u.addToAddressIndex(output, mutations); err != nil {
+			mutations.Cancel()
+			return err
+		}
 	}
 
 	for _, spent := range newSpents {
@@ -205,6 +230,10 @@ func (u *Manager) ApplyConfirmationWithoutLocking(msIndex milestone.Index, newOu
 			mutations.Cancel()
 			return err
 		}
+		if err := u.removeFromAddressIndex(spent.output, mutations); err != nil {
+			mutations.Cancel()
+			return err
+		}
 	}
 
 	msDiff := &MilestoneDiff{
@@ -274,6 +303,11 @@ func (u *Manager) RollbackConfirmationWithoutLocking(msIndex milestone.Index, ne
 			mutations.Cancel()
 			return err
 		}
+
+		if err := u.addToAddressIndex(spent.output, mutations); err != nil {
+			mutations.Cancel()
+			return err
+		}
 	}
 
 	// we have to delete the newOutputs of this milestone
@@ -286,6 +320,10 @@ func (u *Manager) RollbackConfirmationWithoutLocking(msIndex milestone.Index, ne
 			mutations.Cancel()
 			return err
 		}
+		if err := u.removeFromAddressIndex(output, mutations); err != nil {
+			mutations.Cancel()
+			return err
+		}
 	}
 
 	if rt != nil {
@@ -367,6 +405,11 @@ func (u *Manager) AddUnspentOutput(unspentOutput *Output) error {
 		return err
 	}
 
+	if err := u.addToAddressIndex(unspentOutput, mutations); err != nil {
+		mutations.Cancel()
+		return err
+	}
+
 	return mutations.Commit()
 }
 
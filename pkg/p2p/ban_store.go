@@ -0,0 +1,116 @@
+package p2p
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+const (
+	// the realm of the bans in the p2p store.
+	// it must not start with a '/', to not collide with the keys of the libp2p peer store.
+	storePrefixBans byte = 0xFF
+)
+
+var (
+	// ErrInvalidBanEntry gets returned if a stored ban entry can't be parsed.
+	ErrInvalidBanEntry = errors.New("invalid ban entry")
+)
+
+// BanStore persists temporary bans of peers.
+type BanStore struct {
+	store kvstore.KVStore
+}
+
+// NewBanStore creates a new BanStore which persists the bans in the given store.
+func NewBanStore(store kvstore.KVStore) (*BanStore, error) {
+	banStore, err := store.WithRealm([]byte{storePrefixBans})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BanStore{store: banStore}, nil
+}
+
+// Ban bans the given peer until the given time.
+func (bs *BanStore) Ban(peerID peer.ID, until time.Time) error {
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, uint64(until.UnixNano()))
+
+	if err := bs.store.Set([]byte(peerID), value); err != nil {
+		return fmt.Errorf("unable to store ban of peer %s: %w", peerID.ShortString(), err)
+	}
+	return nil
+}
+
+// Unban removes the ban of the given peer.
+func (bs *BanStore) Unban(peerID peer.ID) error {
+	if err := bs.store.Delete([]byte(peerID)); err != nil {
+		return fmt.Errorf("unable to remove ban of peer %s: %w", peerID.ShortString(), err)
+	}
+	return nil
+}
+
+// BannedUntil returns the time until the given peer is banned.
+// Expired bans are removed from the store.
+func (bs *BanStore) BannedUntil(peerID peer.ID) (time.Time, bool, error) {
+	value, err := bs.store.Get([]byte(peerID))
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("unable to load ban of peer %s: %w", peerID.ShortString(), err)
+	}
+
+	until, err := parseBanEntry(value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if !time.Now().Before(until) {
+		return time.Time{}, false, bs.Unban(peerID)
+	}
+
+	return until, true, nil
+}
+
+// Bans returns all active bans by peer.
+func (bs *BanStore) Bans() (map[peer.ID]time.Time, error) {
+	now := time.Now()
+
+	bans := make(map[peer.ID]time.Time)
+	var innerErr error
+	if err := bs.store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		until, err := parseBanEntry(value)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+
+		if now.Before(until) {
+			bans[peer.ID(key)] = until
+		}
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("unable to iterate bans: %w", err)
+	}
+
+	if innerErr != nil {
+		return nil, innerErr
+	}
+
+	return bans, nil
+}
+
+// parses the time until a peer is banned from the given stored value.
+func parseBanEntry(value []byte) (time.Time, error) {
+	if len(value) != 8 {
+		return time.Time{}, ErrInvalidBanEntry
+	}
+	return time.Unix(0, int64(binary.LittleEndian.Uint64(value))), nil
+}
@@ -0,0 +1,56 @@
+package p2p_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/p2p"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+)
+
+func TestBanStore(t *testing.T) {
+	store := mapdb.NewMapDB()
+
+	banStore, err := p2p.NewBanStore(store)
+	require.NoError(t, err)
+
+	peer1 := peer.ID("peer1")
+	peer2 := peer.ID("peer2")
+	peer3 := peer.ID("peer3")
+
+	until := time.Now().Add(time.Hour)
+	require.NoError(t, banStore.Ban(peer1, until))
+	require.NoError(t, banStore.Ban(peer2, until))
+	require.NoError(t, banStore.Ban(peer3, time.Now().Add(-time.Second)))
+
+	bannedUntil, banned, err := banStore.BannedUntil(peer1)
+	require.NoError(t, err)
+	require.True(t, banned)
+	require.Equal(t, until.UnixNano(), bannedUntil.UnixNano())
+
+	// expired bans are ignored
+	bans, err := banStore.Bans()
+	require.NoError(t, err)
+	require.Len(t, bans, 2)
+	require.Contains(t, bans, peer1)
+	require.Contains(t, bans, peer2)
+
+	_, banned, err = banStore.BannedUntil(peer3)
+	require.NoError(t, err)
+	require.False(t, banned)
+
+	require.NoError(t, banStore.Unban(peer2))
+	_, banned, err = banStore.BannedUntil(peer2)
+	require.NoError(t, err)
+	require.False(t, banned)
+
+	// bans are persisted in the underlying store
+	banStore, err = p2p.NewBanStore(store)
+	require.NoError(t, err)
+	_, banned, err = banStore.BannedUntil(peer1)
+	require.NoError(t, err)
+	require.True(t, banned)
+}
@@ -103,6 +103,96 @@ func (pm *ConfigManager) RemovePeer(peerID peer.ID) error {
 	return errors.New("peer not found")
 }
 
+// PeerConfigChanges holds the changes between two lists of peers.
+type PeerConfigChanges struct {
+	// The peers which were added.
+	Added []*PeerConfig
+	// The peers which were removed.
+	Removed []*PeerConfig
+	// The peers whose alias or address changed.
+	Updated []*PeerConfig
+}
+
+// HasChanges tells whether the lists of peers differ.
+func (c *PeerConfigChanges) HasChanges() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0 || len(c.Updated) > 0
+}
+
+// ReplacePeers replaces all peers of the config manager with the given peers and returns the changes.
+// The given peers are not stored, since they are meant to be loaded from the peering config.
+// No peer is replaced if any of the given peers is invalid or listed twice.
+func (pm *ConfigManager) ReplacePeers(peers []*PeerConfig) (*PeerConfigChanges, error) {
+	newPeers := make(map[peer.ID]*PeerConfig, len(peers))
+	for _, p := range peers {
+		peerID, err := peerConfigID(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid peer address %s", p.MultiAddress)
+		}
+
+		if _, exists := newPeers[peerID]; exists {
+			return nil, errors.Errorf("peer %s is listed twice", peerID.ShortString())
+		}
+		newPeers[peerID] = p
+	}
+
+	pm.peersLock.Lock()
+	defer pm.peersLock.Unlock()
+
+	changes := &PeerConfigChanges{}
+
+	oldPeers := make(map[peer.ID]*PeerConfig, len(pm.peers))
+	for _, p := range pm.peers {
+		peerID, err := peerConfigID(p)
+		if err != nil {
+			// ignore wrong values in the config file
+			continue
+		}
+		oldPeers[peerID] = p
+
+		newPeer, exists := newPeers[peerID]
+		if !exists {
+			changes.Removed = append(changes.Removed, p)
+			continue
+		}
+
+		if newPeer.MultiAddress != p.MultiAddress || newPeer.Alias != p.Alias {
+			changes.Updated = append(changes.Updated, newPeer)
+		}
+	}
+
+	replacedPeers := make([]*PeerConfig, 0, len(peers))
+	for _, p := range peers {
+		// the ID was already checked above
+		peerID, _ := peerConfigID(p)
+		if _, exists := oldPeers[peerID]; !exists {
+			changes.Added = append(changes.Added, p)
+		}
+
+		replacedPeers = append(replacedPeers, &PeerConfig{
+			MultiAddress: p.MultiAddress,
+			Alias:        p.Alias,
+		})
+	}
+	pm.peers = replacedPeers
+
+	return changes, nil
+}
+
+// returns the ID of the peer in the given peer config.
+func peerConfigID(p *PeerConfig) (peer.ID, error) {
+	multiAddr, err := multiaddr.NewMultiaddr(p.MultiAddress)
+	if err != nil {
+		return "", err
+	}
+
+	addrInfo, err := peer.AddrInfoFromP2pAddr(multiAddr)
+	if err != nil {
+		return "", err
+	}
+
+	return addrInfo.ID, nil
+}
+
 // StoreOnChange sets whether storing changes to the config is active or not.
 func (pm *ConfigManager) StoreOnChange(store bool) {
 	pm.storeOnChange = store
@@ -0,0 +1,72 @@
+package p2p_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/p2p"
+)
+
+// creates a peer config with a random peer ID.
+func randPeerConfig(t *testing.T, alias string) *p2p.PeerConfig {
+	_, pubKey, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	require.NoError(t, err)
+
+	peerID, err := peer.IDFromPublicKey(pubKey)
+	require.NoError(t, err)
+
+	return &p2p.PeerConfig{
+		MultiAddress: fmt.Sprintf("/ip4/127.0.0.1/tcp/15600/p2p/%s", peerID.String()),
+		Alias:        alias,
+	}
+}
+
+func TestConfigManagerReplacePeers(t *testing.T) {
+	var storedPeers []*p2p.PeerConfig
+	configManager := p2p.NewConfigManager(func(peers []*p2p.PeerConfig) error {
+		storedPeers = peers
+		return nil
+	})
+	configManager.StoreOnChange(true)
+
+	peer1 := randPeerConfig(t, "peer1")
+	peer2 := randPeerConfig(t, "peer2")
+	peer3 := randPeerConfig(t, "peer3")
+
+	for _, p := range []*p2p.PeerConfig{peer1, peer2} {
+		multiAddr, err := multiaddr.NewMultiaddr(p.MultiAddress)
+		require.NoError(t, err)
+		require.NoError(t, configManager.AddPeer(multiAddr, p.Alias))
+	}
+	storedPeers = nil
+
+	// peer 1 is renamed, peer 2 is removed and peer 3 is added
+	renamedPeer1 := &p2p.PeerConfig{MultiAddress: peer1.MultiAddress, Alias: "renamed"}
+	changes, err := configManager.ReplacePeers([]*p2p.PeerConfig{renamedPeer1, peer3})
+	require.NoError(t, err)
+	require.True(t, changes.HasChanges())
+	require.Equal(t, []*p2p.PeerConfig{peer3}, changes.Added)
+	require.Equal(t, []*p2p.PeerConfig{peer2}, changes.Removed)
+	require.Equal(t, []*p2p.PeerConfig{renamedPeer1}, changes.Updated)
+	require.Equal(t, []*p2p.PeerConfig{renamedPeer1, peer3}, configManager.Peers())
+
+	// the replaced peers are not stored, since they stem from the peering config
+	require.Nil(t, storedPeers)
+
+	// replacing the peers with the same peers causes no changes
+	changes, err = configManager.ReplacePeers([]*p2p.PeerConfig{peer3, renamedPeer1})
+	require.NoError(t, err)
+	require.False(t, changes.HasChanges())
+
+	// invalid or duplicate peers are rejected without replacing any peer
+	_, err = configManager.ReplacePeers([]*p2p.PeerConfig{peer1, {MultiAddress: "invalid"}})
+	require.Error(t, err)
+	_, err = configManager.ReplacePeers([]*p2p.PeerConfig{peer1, peer1})
+	require.Error(t, err)
+	require.Equal(t, []*p2p.PeerConfig{peer3, renamedPeer1}, configManager.Peers())
+}
@@ -0,0 +1,270 @@
+package p2p
+
+import (
+	"net"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/control"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/events"
+)
+
+const (
+	// the prefix length of the IPv4 subnets to which the connection limit applies.
+	ipv4SubnetPrefixLength = 24
+	// the prefix length of the IPv6 subnets to which the connection limit applies.
+	ipv6SubnetPrefixLength = 48
+)
+
+var (
+	// ErrConnectionFromDeniedSubnet gets returned if a connection from a denied subnet is refused.
+	ErrConnectionFromDeniedSubnet = errors.New("connection from denied subnet")
+	// ErrConnectionNotFromAllowedSubnet gets returned if a connection from outside the allowed subnets is refused.
+	ErrConnectionNotFromAllowedSubnet = errors.New("connection not from allowed subnet")
+	// ErrSubnetConnectionLimitReached gets returned if a connection is refused because its subnet has reached the connection limit.
+	ErrSubnetConnectionLimitReached = errors.New("connection limit of subnet reached")
+	// ErrOnlyKnownPeersAllowed gets returned if a connection to a peer which is not known is refused.
+	ErrOnlyKnownPeersAllowed = errors.New("only connections to known peers are allowed")
+)
+
+// ConnectionPolicy defines which connections are accepted by a ConnectionGater.
+// Known peers are exempt from the policy.
+type ConnectionPolicy struct {
+	// If not empty, inbound connections are only accepted from these subnets.
+	AllowedSubnets []*net.IPNet
+	// Inbound connections from these subnets are refused.
+	DeniedSubnets []*net.IPNet
+	// The maximum amount of inbound connections of unknown peers from a /24 IPv4 subnet (0 = unlimited).
+	MaxConnsPerIPv4Subnet int
+	// The maximum amount of inbound connections of unknown peers from a /48 IPv6 subnet (0 = unlimited).
+	MaxConnsPerIPv6Subnet int
+	// Whether only connections from and to known peers are accepted.
+	KnownPeersOnly bool
+}
+
+// ParseSubnets parses the given subnets in CIDR notation.
+func ParseSubnets(cidrs []string) ([]*net.IPNet, error) {
+	subnets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid subnet '%s'", cidr)
+		}
+		subnets[i] = subnet
+	}
+	return subnets, nil
+}
+
+// ConnectionRefusal holds the details of a connection refused by the ConnectionGater.
+type ConnectionRefusal struct {
+	// The ID of the remote peer.
+	PeerID peer.ID
+	// The address of the remote peer.
+	RemoteAddr multiaddr.Multiaddr
+	// The direction of the connection.
+	Direction network.Direction
+	// The reason why the connection was refused.
+	Reason error
+}
+
+// ConnectionRefusalCaller gets called with a ConnectionRefusal.
+func ConnectionRefusalCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*ConnectionRefusal))(params[0].(*ConnectionRefusal))
+}
+
+// ConnectionGater is a connmgr.ConnectionGater which enforces a ConnectionPolicy.
+// It must be passed to the libp2p host and to the Manager, which keeps the set of known peers up to date
+// and reports refused connections in its events.
+type ConnectionGater struct {
+	policy ConnectionPolicy
+
+	// protects the fields below, since the gater is called concurrently by libp2p.
+	lock sync.RWMutex
+	// the network of which the connections are counted per subnet.
+	network network.Network
+	// the event triggered for refused connections.
+	refusedEvent *events.Event
+	// holds the set of known peers.
+	knownPeers map[peer.ID]struct{}
+}
+
+// NewConnectionGater creates a new ConnectionGater which enforces the given policy.
+func NewConnectionGater(policy ConnectionPolicy) *ConnectionGater {
+	return &ConnectionGater{
+		policy:     policy,
+		knownPeers: map[peer.ID]struct{}{},
+	}
+}
+
+// ensure ConnectionGater implements connmgr.ConnectionGater.
+var _ connmgr.ConnectionGater = (*ConnectionGater)(nil)
+
+// InterceptPeerDial refuses dials to peers which are not known, if only known peers are allowed.
+func (g *ConnectionGater) InterceptPeerDial(peerID peer.ID) bool {
+	if !g.policy.KnownPeersOnly || g.isKnown(peerID) {
+		return true
+	}
+
+	g.refused(&ConnectionRefusal{PeerID: peerID, Direction: network.DirOutbound, Reason: ErrOnlyKnownPeersAllowed})
+	return false
+}
+
+// InterceptAddrDial allows all addresses of a peer, since InterceptPeerDial already checked the peer.
+func (g *ConnectionGater) InterceptAddrDial(peer.ID, multiaddr.Multiaddr) bool {
+	return true
+}
+
+// InterceptAccept allows all connections, since the policy is applied
+// as soon as the peer behind the connection is known in InterceptSecured.
+func (g *ConnectionGater) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured applies the policy to inbound connections.
+func (g *ConnectionGater) InterceptSecured(dir network.Direction, peerID peer.ID, addrs network.ConnMultiaddrs) bool {
+	if dir != network.DirInbound || g.isKnown(peerID) {
+		return true
+	}
+
+	if err := g.checkInbound(addrs.RemoteMultiaddr()); err != nil {
+		g.refused(&ConnectionRefusal{PeerID: peerID, RemoteAddr: addrs.RemoteMultiaddr(), Direction: dir, Reason: err})
+		return false
+	}
+	return true
+}
+
+// InterceptUpgraded allows all connections, since they were already checked in InterceptSecured.
+func (g *ConnectionGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
+
+// checks whether an inbound connection from the given address is allowed by the policy.
+func (g *ConnectionGater) checkInbound(remoteAddr multiaddr.Multiaddr) error {
+	if g.policy.KnownPeersOnly {
+		return ErrOnlyKnownPeersAllowed
+	}
+
+	ip, err := manet.ToIP(remoteAddr)
+	if err != nil {
+		// the policy can't be applied to connections without an IP address
+		if len(g.policy.AllowedSubnets) > 0 {
+			return ErrConnectionNotFromAllowedSubnet
+		}
+		return nil
+	}
+
+	if subnetsContain(g.policy.DeniedSubnets, ip) {
+		return ErrConnectionFromDeniedSubnet
+	}
+
+	if len(g.policy.AllowedSubnets) > 0 && !subnetsContain(g.policy.AllowedSubnets, ip) {
+		return ErrConnectionNotFromAllowedSubnet
+	}
+
+	subnet, maxConns := g.subnetLimit(ip)
+	if maxConns > 0 && g.subnetConnsCount(subnet) >= maxConns {
+		return ErrSubnetConnectionLimitReached
+	}
+
+	return nil
+}
+
+// returns the subnet of the given IP to which the connection limit applies and the limit itself.
+func (g *ConnectionGater) subnetLimit(ip net.IP) (*net.IPNet, int) {
+	if ip4 := ip.To4(); ip4 != nil {
+		mask := net.CIDRMask(ipv4SubnetPrefixLength, 8*net.IPv4len)
+		return &net.IPNet{IP: ip4.Mask(mask), Mask: mask}, g.policy.MaxConnsPerIPv4Subnet
+	}
+
+	mask := net.CIDRMask(ipv6SubnetPrefixLength, 8*net.IPv6len)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, g.policy.MaxConnsPerIPv6Subnet
+}
+
+// returns the amount of inbound connections of unknown peers from the given subnet.
+// outbound connections and connections of known peers are not counted,
+// since they were established on purpose and must not use up the limit for unknown peers.
+func (g *ConnectionGater) subnetConnsCount(subnet *net.IPNet) int {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	if g.network == nil {
+		return 0
+	}
+
+	var count int
+	for _, conn := range g.network.Conns() {
+		if conn.Stat().Direction != network.DirInbound {
+			continue
+		}
+
+		if _, known := g.knownPeers[conn.RemotePeer()]; known {
+			continue
+		}
+
+		ip, err := manet.ToIP(conn.RemoteMultiaddr())
+		if err != nil {
+			continue
+		}
+		if subnet.Contains(ip) {
+			count++
+		}
+	}
+	return count
+}
+
+// sets the network of which the connections are counted and the event triggered for refused connections.
+func (g *ConnectionGater) attach(network network.Network, refusedEvent *events.Event) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.network = network
+	g.refusedEvent = refusedEvent
+}
+
+// triggers the event for the given refused connection.
+func (g *ConnectionGater) refused(refusal *ConnectionRefusal) {
+	g.lock.RLock()
+	refusedEvent := g.refusedEvent
+	g.lock.RUnlock()
+
+	if refusedEvent != nil {
+		refusedEvent.Trigger(refusal)
+	}
+}
+
+// sets whether the given peer is known.
+func (g *ConnectionGater) setKnown(peerID peer.ID, known bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if known {
+		g.knownPeers[peerID] = struct{}{}
+		return
+	}
+	delete(g.knownPeers, peerID)
+}
+
+// checks whether the given peer is known.
+func (g *ConnectionGater) isKnown(peerID peer.ID) bool {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	_, has := g.knownPeers[peerID]
+	return has
+}
+
+// checks whether one of the given subnets contains the given IP.
+func subnetsContain(subnets []*net.IPNet, ip net.IP) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -41,6 +41,7 @@ var (
 type PeerStoreContainer struct {
 	store     kvstore.KVStore
 	peerStore peerstore.Peerstore
+	banStore  *BanStore
 }
 
 // Peerstore returns the libp2p peer store from the container.
@@ -48,6 +49,11 @@ func (psc *PeerStoreContainer) Peerstore() peerstore.Peerstore {
 	return psc.peerStore
 }
 
+// BanStore returns the store of the peer bans from the container.
+func (psc *PeerStoreContainer) BanStore() *BanStore {
+	return psc.banStore
+}
+
 // Flush persists all outstanding write operations to disc.
 func (psc *PeerStoreContainer) Flush() error {
 	return psc.store.Flush()
@@ -85,9 +91,15 @@ func NewPeerStoreContainer(peerStorePath string, dbEngine database.Engine, creat
 		return nil, fmt.Errorf("unable to initialize peer store: %w", err)
 	}
 
+	banStore, err := NewBanStore(store)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize ban store: %w", err)
+	}
+
 	return &PeerStoreContainer{
 		store:     store,
 		peerStore: peerStore,
+		banStore:  banStore,
 	}, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/libp2p/go-libp2p-core/host"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/gohornet/hornet/pkg/utils"
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/typeutils"
 )
@@ -40,6 +42,10 @@ var (
 	ErrPeerInManagerAlreadyAllowed = errors.New("peer is already allowed in manager")
 	// ErrManagerShutdown gets returned if the manager is shutting down.
 	ErrManagerShutdown = errors.New("manager is shutting down")
+	// ErrPeerBanned gets returned if a connection to a banned peer is tried to be established or allowed.
+	ErrPeerBanned = errors.New("peer is banned")
+	// ErrCantBanKnownPeer gets returned if a known peer is tried to be banned.
+	ErrCantBanKnownPeer = errors.New("known peers can't be banned")
 )
 
 // PeerRelation defines the type of relation to a remote peer.
@@ -93,6 +99,11 @@ type ManagerEvents struct {
 	Reconnected *events.Event
 	// Fired when the relation to a peer has been updated.
 	RelationUpdated *events.Event
+	// Fired when a peer got banned.
+	Banned *events.Event
+	// Fired when a connection got refused by the ConnectionGater.
+	// The event is not fired from within the event loop of the Manager.
+	ConnectionRefused *events.Event
 	// Fired when the Manager's state changes.
 	StateChange *events.Event
 	// Fired when internal error happens.
@@ -120,6 +131,21 @@ func PeerOptErrorCaller(handler interface{}, params ...interface{}) {
 	handler.(func(*PeerOptError))(params[0].(*PeerOptError))
 }
 
+// PeerBan holds the ID of a banned peer and the details of the ban.
+type PeerBan struct {
+	// The ID of the banned peer.
+	PeerID peer.ID
+	// The time until the peer is banned.
+	Until time.Time
+	// The reason of the ban.
+	Reason error
+}
+
+// PeerBanCaller gets called with a PeerBan.
+func PeerBanCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*PeerBan))(params[0].(*PeerBan))
+}
+
 // ManagerStateCaller gets called with a ManagerState.
 func ManagerStateCaller(handler interface{}, params ...interface{}) {
 	handler.(func(ManagerState))(params[0].(ManagerState))
@@ -140,9 +166,35 @@ func PeerRelationCaller(handler interface{}, params ...interface{}) {
 	handler.(func(p *Peer, old PeerRelation))(params[0].(*Peer), params[1].(PeerRelation))
 }
 
+// the duration after which the score of a peer expires if it wasn't adjusted in the meantime.
+const scoreRetention = 24 * time.Hour
+
+// the score of a peer.
+type peerScore struct {
+	// the current score.
+	value int64
+	// the time of the last adjustment of the score.
+	updated time.Time
+}
+
 // the default options applied to the Manager.
 var defaultManagerOptions = []ManagerOption{
 	WithManagerReconnectInterval(30*time.Second, 1*time.Second),
+	WithManagerPeerScoring(PeerScoringOptions{
+		MaxScore:     100,
+		BanThreshold: -100,
+		BanDuration:  0,
+	}),
+}
+
+// PeerScoringOptions define how the scores of peers are limited and when peers get banned.
+type PeerScoringOptions struct {
+	// The maximum score a peer can reach.
+	MaxScore int64
+	// The score at or below which a peer gets banned.
+	BanThreshold int64
+	// The duration of a ban. Peers are never banned automatically if it is zero.
+	BanDuration time.Duration
 }
 
 // ManagerOptions define options for a Manager.
@@ -153,6 +205,12 @@ type ManagerOptions struct {
 	reconnectInterval time.Duration
 	// The randomized jitter applied to the reconnect interval.
 	reconnectIntervalJitter time.Duration
+	// The scoring options of the peers.
+	scoring PeerScoringOptions
+	// The store used to persist the bans of peers.
+	banStore *BanStore
+	// The connection gater which enforces the connection policy.
+	connectionGater *ConnectionGater
 }
 
 // ManagerOption is a function setting a ManagerOptions option.
@@ -174,6 +232,29 @@ func WithManagerReconnectInterval(interval time.Duration, jitter time.Duration)
 	}
 }
 
+// WithManagerPeerScoring defines how the scores of peers are limited and when peers get banned.
+func WithManagerPeerScoring(scoring PeerScoringOptions) ManagerOption {
+	return func(opts *ManagerOptions) {
+		opts.scoring = scoring
+	}
+}
+
+// WithManagerBanStore defines the store used to persist the bans of peers.
+// If no store is given, the bans are only kept in memory.
+func WithManagerBanStore(banStore *BanStore) ManagerOption {
+	return func(opts *ManagerOptions) {
+		opts.banStore = banStore
+	}
+}
+
+// WithManagerConnectionGater defines the connection gater used by the libp2p host,
+// which is kept up to date about the known peers by the Manager.
+func WithManagerConnectionGater(connectionGater *ConnectionGater) ManagerOption {
+	return func(opts *ManagerOptions) {
+		opts.connectionGater = connectionGater
+	}
+}
+
 // applies the given ManagerOption.
 func (mo *ManagerOptions) apply(opts ...ManagerOption) {
 	for _, opt := range opts {
@@ -195,6 +276,16 @@ func NewManager(host host.Host, opts ...ManagerOption) *Manager {
 	mngOpts.apply(defaultManagerOptions...)
 	mngOpts.apply(opts...)
 
+	if mngOpts.banStore == nil {
+		// the in-memory store never returns an error
+		mngOpts.banStore, _ = NewBanStore(mapdb.NewMapDB())
+	}
+
+	if mngOpts.connectionGater == nil {
+		// the gater is not used by the host, but it is still kept up to date
+		mngOpts.connectionGater = NewConnectionGater(ConnectionPolicy{})
+	}
+
 	peeringManager := &Manager{
 		Events: &ManagerEvents{
 			Connect:            events.NewEvent(PeerCaller),
@@ -207,12 +298,15 @@ func NewManager(host host.Host, opts ...ManagerOption) *Manager {
 			Reconnecting:       events.NewEvent(PeerCaller),
 			Reconnected:        events.NewEvent(PeerCaller),
 			RelationUpdated:    events.NewEvent(PeerRelationCaller),
+			Banned:             events.NewEvent(PeerBanCaller),
+			ConnectionRefused:  events.NewEvent(ConnectionRefusalCaller),
 			StateChange:        events.NewEvent(ManagerStateCaller),
 			Error:              events.NewEvent(events.ErrorCaller),
 		},
 		host:               host,
 		peers:              map[peer.ID]*Peer{},
 		allowedPeers:       map[peer.ID]struct{}{},
+		scores:             map[peer.ID]*peerScore{},
 		opts:               mngOpts,
 		stopped:            typeutils.NewAtomicBool(),
 		connectPeerChan:    make(chan *connectpeermsg, 10),
@@ -221,6 +315,7 @@ func NewManager(host host.Host, opts ...ManagerOption) *Manager {
 		allowPeerChan:      make(chan *allowpeermsg, 10),
 		disallowPeerChan:   make(chan *disallowpeermsg, 10),
 		isAllowedReqChan:   make(chan *isallowedrequestmsg, 10),
+		banPeerChan:        make(chan *banpeermsg, 10),
 		connectedChan:      make(chan *connectionmsg, 10),
 		disconnectedChan:   make(chan *disconnectmsg, 10),
 		reconnectChan:      make(chan *reconnectmsg, 100),
@@ -228,6 +323,7 @@ func NewManager(host host.Host, opts ...ManagerOption) *Manager {
 		callChan:           make(chan *callmsg, 10),
 	}
 	peeringManager.WrappedLogger = utils.NewWrappedLogger(peeringManager.opts.logger)
+	mngOpts.connectionGater.attach(host.Network(), peeringManager.Events.ConnectionRefused)
 	peeringManager.configureEvents()
 	return peeringManager
 }
@@ -246,6 +342,11 @@ type Manager struct {
 	peers map[peer.ID]*Peer
 	// holds the set of allowed peers (autopeering).
 	allowedPeers map[peer.ID]struct{}
+	// holds the scores of the peers.
+	// negative scores are kept after disconnects, so that misbehaving peers can't reset them by reconnecting.
+	scores map[peer.ID]*peerScore
+	// protects the scores, since they are updated outside of the event loop.
+	scoresLock sync.Mutex
 	// holds the manager options.
 	opts *ManagerOptions
 	// tells whether the manager was shut down.
@@ -257,6 +358,7 @@ type Manager struct {
 	allowPeerChan      chan *allowpeermsg
 	disallowPeerChan   chan *disallowpeermsg
 	isAllowedReqChan   chan *isallowedrequestmsg
+	banPeerChan        chan *banpeermsg
 	connectedChan      chan *connectionmsg
 	disconnectedChan   chan *disconnectmsg
 	reconnectChan      chan *reconnectmsg
@@ -271,6 +373,8 @@ type Manager struct {
 	onP2PManagerScheduledReconnect *events.Closure
 	onP2PManagerReconnecting       *events.Closure
 	onP2PManagerRelationUpdated    *events.Closure
+	onP2PManagerBanned             *events.Closure
+	onP2PManagerConnectionRefused  *events.Closure
 	onP2PManagerStateChange        *events.Closure
 	onP2PManagerError              *events.Closure
 }
@@ -331,6 +435,9 @@ drainLoop:
 		case isAllowedReqMsg := <-m.isAllowedReqChan:
 			isAllowedReqMsg.back <- false
 
+		case banPeerMsg := <-m.banPeerChan:
+			banPeerMsg.back <- ErrManagerShutdown
+
 		case <-m.connectedChan:
 
 		case <-m.disconnectedChan:
@@ -426,6 +533,90 @@ func (m *Manager) IsAllowed(peerID peer.ID) bool {
 	return <-back
 }
 
+// BanPeer bans the given peer for the given duration.
+// The peer gets disallowed and disconnected, and connections from and to it are refused until the ban expires.
+// Known peers can't be banned.
+func (m *Manager) BanPeer(peerID peer.ID, duration time.Duration, reason error) error {
+	if m.stopped.IsSet() {
+		return ErrManagerShutdown
+	}
+
+	back := make(chan error)
+	m.banPeerChan <- &banpeermsg{peerID: peerID, duration: duration, reason: reason, back: back}
+	return <-back
+}
+
+// IsBanned tells whether the given peer is currently banned.
+func (m *Manager) IsBanned(peerID peer.ID) bool {
+	return m.isBanned(peerID)
+}
+
+// Score returns the current score of the given peer.
+func (m *Manager) Score(peerID peer.ID) int64 {
+	m.scoresLock.Lock()
+	defer m.scoresLock.Unlock()
+
+	score, has := m.scores[peerID]
+	if !has {
+		return 0
+	}
+	return score.value
+}
+
+// AdjustScore adds the given delta to the score of the given peer and returns the new score.
+// The score is capped at the configured maximum. If a penalty lets the score drop to the ban threshold,
+// the peer gets banned with the given reason.
+// This function must not be called from within the event handlers of the Manager.
+func (m *Manager) AdjustScore(peerID peer.ID, delta int64, reason error) int64 {
+	m.scoresLock.Lock()
+	current, has := m.scores[peerID]
+	if !has {
+		// the expired scores are only removed if a new peer is scored, so that the scores don't grow without bound
+		m.removeExpiredScoresWithoutLocking()
+		current = &peerScore{}
+		m.scores[peerID] = current
+	}
+	score := current.value + delta
+	if score > m.opts.scoring.MaxScore {
+		score = m.opts.scoring.MaxScore
+	}
+	current.value = score
+	current.updated = time.Now()
+	m.scoresLock.Unlock()
+
+	if delta >= 0 || m.opts.scoring.BanDuration <= 0 || score > m.opts.scoring.BanThreshold {
+		return score
+	}
+
+	if err := m.BanPeer(peerID, m.opts.scoring.BanDuration, fmt.Errorf("score dropped to %d: %w", score, reason)); err != nil && !errors.Is(err, ErrCantBanKnownPeer) {
+		m.Events.Error.Trigger(fmt.Errorf("error banning %s: %w", peerID.ShortString(), err))
+	}
+	return score
+}
+
+// removes the scores which weren't adjusted within the retention duration.
+// these are mostly the negative scores of peers which are not managed anymore,
+// since the non-negative scores are already removed when the peers are removed from the Manager.
+func (m *Manager) removeExpiredScoresWithoutLocking() {
+	expiredBefore := time.Now().Add(-scoreRetention)
+	for peerID, score := range m.scores {
+		if score.updated.Before(expiredBefore) {
+			delete(m.scores, peerID)
+		}
+	}
+}
+
+// removes the score of the given peer which is not managed anymore.
+// only negative scores need to be remembered, so that misbehaving peers can't reset them by reconnecting.
+func (m *Manager) removeNonNegativeScore(peerID peer.ID) {
+	m.scoresLock.Lock()
+	defer m.scoresLock.Unlock()
+
+	if score, has := m.scores[peerID]; has && score.value >= 0 {
+		delete(m.scores, peerID)
+	}
+}
+
 // PeerForEachFunc is used in Manager.ForEach.
 // Returning false indicates to stop looping.
 // This function must not call any methods on Manager.
@@ -463,6 +654,7 @@ func (m *Manager) PeerInfoSnapshot(id peer.ID) *PeerInfoSnapshot {
 	m.Call(id, func(p *Peer) {
 		info = p.InfoSnapshot()
 		info.Connected = m.host.Network().Connectedness(p.ID) == network.Connected
+		info.Score = m.Score(p.ID)
 	})
 	return info
 }
@@ -473,6 +665,7 @@ func (m *Manager) PeerInfoSnapshots() []*PeerInfoSnapshot {
 	m.ForEach(func(p *Peer) bool {
 		info := p.InfoSnapshot()
 		info.Connected = m.host.Network().Connectedness(p.ID) == network.Connected
+		info.Score = m.Score(p.ID)
 		infos = append(infos, info)
 		return true
 	})
@@ -538,6 +731,13 @@ type isallowedrequestmsg struct {
 	back   chan bool
 }
 
+type banpeermsg struct {
+	peerID   peer.ID
+	duration time.Duration
+	reason   error
+	back     chan error
+}
+
 type reconnectmsg struct {
 	peerID peer.ID
 }
@@ -602,6 +802,17 @@ func (m *Manager) eventLoop(ctx context.Context) {
 			allowed := m.isAllowed(isAllowedReqMsg.peerID)
 			isAllowedReqMsg.back <- allowed
 
+		case banPeerMsg := <-m.banPeerChan:
+			p := m.peers[banPeerMsg.peerID]
+			disconnected, err := m.banPeer(banPeerMsg.peerID, banPeerMsg.duration, banPeerMsg.reason)
+			if err != nil && !errors.Is(err, ErrCantBanKnownPeer) {
+				m.Events.Error.Trigger(fmt.Errorf("error ban %s: %w", banPeerMsg.peerID.ShortString(), err))
+			}
+			if disconnected {
+				m.Events.Disconnected.Trigger(&PeerOptError{Peer: p, Error: banPeerMsg.reason})
+			}
+			banPeerMsg.back <- err
+
 		case reconnectMsg := <-m.reconnectChan:
 			reconnect, err := m.reconnectPeer(reconnectMsg.peerID)
 			if err != nil {
@@ -618,6 +829,12 @@ func (m *Manager) eventLoop(ctx context.Context) {
 			isConnectedReqMsg.back <- connected
 
 		case connectedMsg := <-m.connectedChan:
+			if m.isBanned(connectedMsg.conn.RemotePeer()) {
+				// refuse connections of banned peers
+				_ = connectedMsg.conn.Close()
+				continue
+			}
+
 			p := m.peers[connectedMsg.conn.RemotePeer()]
 			m.addPeerAsUnknownIfAbsent(connectedMsg.conn)
 			if p != nil {
@@ -665,10 +882,22 @@ func (m *Manager) connectPeer(addrInfo *peer.AddrInfo, relation PeerRelation, al
 		return ErrCantConnectToItself
 	}
 
+	if m.isBanned(addrInfo.ID) {
+		if relation != PeerRelationKnown {
+			return ErrPeerBanned
+		}
+
+		// the operator explicitly wants to connect to the peer, so the ban is lifted
+		if err := m.opts.banStore.Unban(addrInfo.ID); err != nil {
+			return err
+		}
+	}
+
 	p := NewPeer(addrInfo.ID, relation, addrInfo.Addrs, alias)
 	if p.Relation == PeerRelationKnown || p.Relation == PeerRelationAutopeered {
 		m.host.ConnManager().Protect(addrInfo.ID, PeerConnectivityProtectionTag)
 	}
+	m.opts.connectionGater.setKnown(addrInfo.ID, p.Relation == PeerRelationKnown)
 
 	m.peers[addrInfo.ID] = p
 	m.Events.Connect.Trigger(p)
@@ -684,7 +913,9 @@ func (m *Manager) disconnectPeer(peerID peer.ID) (bool, error) {
 		return false, nil
 	}
 	m.host.ConnManager().Unprotect(peerID, PeerConnectivityProtectionTag)
+	m.opts.connectionGater.setKnown(peerID, false)
 	delete(m.peers, peerID)
+	m.removeNonNegativeScore(peerID)
 	m.Events.Disconnect.Trigger(p)
 	return true, m.host.Network().ClosePeer(peerID)
 }
@@ -699,6 +930,10 @@ func (m *Manager) allowPeer(peerID peer.ID) error {
 		return ErrCantAllowItself
 	}
 
+	if m.isBanned(peerID) {
+		return ErrPeerBanned
+	}
+
 	m.allowedPeers[peerID] = struct{}{}
 	m.Events.Allowed.Trigger(peerID)
 
@@ -715,6 +950,43 @@ func (m *Manager) disallowPeer(peerID peer.ID) {
 	m.Events.Disallowed.Trigger(peerID)
 }
 
+// bans the given peer for the given duration and disallows and disconnects it.
+// the score of the peer is reset, so that it starts from scratch after the ban expired.
+func (m *Manager) banPeer(peerID peer.ID, duration time.Duration, reason error) (bool, error) {
+	if p, has := m.peers[peerID]; has && p.Relation == PeerRelationKnown {
+		return false, ErrCantBanKnownPeer
+	}
+
+	until := time.Now().Add(duration)
+	if err := m.opts.banStore.Ban(peerID, until); err != nil {
+		return false, err
+	}
+
+	m.scoresLock.Lock()
+	delete(m.scores, peerID)
+	m.scoresLock.Unlock()
+
+	m.Events.Banned.Trigger(&PeerBan{PeerID: peerID, Until: until, Reason: reason})
+
+	m.disallowPeer(peerID)
+	disconnected, err := m.disconnectPeer(peerID)
+	if !disconnected {
+		// the peer could still be connected without being managed
+		return false, m.host.Network().ClosePeer(peerID)
+	}
+	return disconnected, err
+}
+
+// checks whether the given peer is currently banned.
+func (m *Manager) isBanned(peerID peer.ID) bool {
+	_, banned, err := m.opts.banStore.BannedUntil(peerID)
+	if err != nil {
+		m.Events.Error.Trigger(err)
+		return false
+	}
+	return banned
+}
+
 // checks whether the given peer is allowed to connect (autopeering).
 func (m *Manager) isAllowed(peerID peer.ID) bool {
 	_, has := m.allowedPeers[peerID]
@@ -730,6 +1002,7 @@ func (m *Manager) updateRelation(peerID peer.ID, newRelation PeerRelation) {
 	}
 	oldRelation := p.Relation
 	p.Relation = newRelation
+	m.opts.connectionGater.setKnown(peerID, newRelation == PeerRelationKnown)
 	switch newRelation {
 	case PeerRelationUnknown:
 		p.reconnectTimer.Stop()
@@ -855,6 +1128,7 @@ func (m *Manager) cleanupPeerIfNotKnown(peerID peer.ID) {
 	if has && p.Relation != PeerRelationKnown && len(m.host.Network().ConnsToPeer(peerID)) == 0 {
 		m.host.ConnManager().Unprotect(peerID, PeerConnectivityProtectionTag)
 		delete(m.peers, peerID)
+		m.removeNonNegativeScore(peerID)
 	}
 }
 
@@ -922,6 +1196,19 @@ func (m *Manager) configureEvents() {
 		m.LogInfof("updated relation of %s from '%s' to '%s'", p.ID.ShortString(), oldRel, p.Relation)
 	})
 
+	m.onP2PManagerBanned = events.NewClosure(func(ban *PeerBan) {
+		m.LogWarnf("banned %s until %s: %s", ban.PeerID.ShortString(), ban.Until.Format(time.RFC3339), ban.Reason)
+	})
+
+	// refused connections are logged at debug level, since unknown peers can cause them at a high rate.
+	m.onP2PManagerConnectionRefused = events.NewClosure(func(refusal *ConnectionRefusal) {
+		if refusal.RemoteAddr == nil {
+			m.LogDebugf("refused %s connection with %s: %s", refusal.Direction.String(), refusal.PeerID.ShortString(), refusal.Reason)
+			return
+		}
+		m.LogDebugf("refused %s connection with %s (%s): %s", refusal.Direction.String(), refusal.PeerID.ShortString(), refusal.RemoteAddr, refusal.Reason)
+	})
+
 	m.onP2PManagerStateChange = events.NewClosure(func(mngState ManagerState) {
 		m.LogInfo(mngState)
 	})
@@ -939,6 +1226,8 @@ func (m *Manager) attachEvents() {
 	m.Events.ScheduledReconnect.Attach(m.onP2PManagerScheduledReconnect)
 	m.Events.Reconnecting.Attach(m.onP2PManagerReconnecting)
 	m.Events.RelationUpdated.Attach(m.onP2PManagerRelationUpdated)
+	m.Events.Banned.Attach(m.onP2PManagerBanned)
+	m.Events.ConnectionRefused.Attach(m.onP2PManagerConnectionRefused)
 	m.Events.StateChange.Attach(m.onP2PManagerStateChange)
 	m.Events.Error.Attach(m.onP2PManagerError)
 }
@@ -951,6 +1240,8 @@ func (m *Manager) detachEvents() {
 	m.Events.ScheduledReconnect.Detach(m.onP2PManagerScheduledReconnect)
 	m.Events.Reconnecting.Detach(m.onP2PManagerReconnecting)
 	m.Events.RelationUpdated.Detach(m.onP2PManagerRelationUpdated)
+	m.Events.Banned.Detach(m.onP2PManagerBanned)
+	m.Events.ConnectionRefused.Detach(m.onP2PManagerConnectionRefused)
 	m.Events.StateChange.Detach(m.onP2PManagerStateChange)
 	m.Events.Error.Detach(m.onP2PManagerError)
 }
@@ -21,7 +21,7 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 )
 
-func newNode(t require.TestingT) host.Host {
+func newNode(t require.TestingT, opts ...libp2p.Option) host.Host {
 	// we use Ed25519 because otherwise it takes longer as the default is RSA
 	sk, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
 	require.NoError(t, err)
@@ -33,10 +33,10 @@ func newNode(t require.TestingT) host.Host {
 	)
 	require.NoError(t, err)
 
-	h, err := libp2p.New(
+	h, err := libp2p.New(append([]libp2p.Option{
 		libp2p.Identity(sk),
 		libp2p.ConnectionManager(connManager),
-	)
+	}, opts...)...)
 	require.NoError(t, err)
 	return h
 }
@@ -312,6 +312,101 @@ func TestManagerEvents(t *testing.T) {
 	require.True(t, reconnectedCalled)
 }
 
+func TestManagerBanning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = logger.InitGlobalLogger(cfg)
+
+	scoringOpt := p2p.WithManagerPeerScoring(p2p.PeerScoringOptions{
+		MaxScore:     10,
+		BanThreshold: -10,
+		BanDuration:  time.Hour,
+	})
+
+	node1 := newNode(t)
+	node1Logger := logger.NewLogger(fmt.Sprintf("node1/%s", node1.ID().ShortString()))
+	node1Manager := p2p.NewManager(node1, p2p.WithManagerLogger(node1Logger), scoringOpt)
+	go node1Manager.Start(ctx)
+
+	node2 := newNode(t)
+	node2Manager := p2p.NewManager(node2)
+	go node2Manager.Start(ctx)
+	node2AddrInfo := &peer.AddrInfo{ID: node2.ID(), Addrs: node2.Addrs()}
+
+	node3 := newNode(t)
+	node3Manager := p2p.NewManager(node3)
+	go node3Manager.Start(ctx)
+	node3AddrInfo := &peer.AddrInfo{ID: node3.ID(), Addrs: node3.Addrs()}
+
+	go func() {
+		_ = node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationAutopeered)
+	}()
+	go func() {
+		_ = node1Manager.ConnectPeer(node3AddrInfo, p2p.PeerRelationKnown)
+	}()
+	connectivity(t, node1Manager, node2.ID(), false)
+	connectivity(t, node1Manager, node3.ID(), false)
+
+	// rewards are capped at the maximum score
+	require.EqualValues(t, 10, node1Manager.AdjustScore(node2.ID(), 15, nil))
+	require.EqualValues(t, 10, node1Manager.PeerInfoSnapshot(node2.ID()).Score)
+
+	var bannedPeerID peer.ID
+	node1Manager.Events.Banned.Attach(events.NewClosure(func(ban *p2p.PeerBan) {
+		bannedPeerID = ban.PeerID
+	}))
+
+	// the autopeered peer gets banned and disconnected once its score drops to the ban threshold
+	require.EqualValues(t, -5, node1Manager.AdjustScore(node2.ID(), -15, errors.New("invalid message")))
+	require.False(t, node1Manager.IsBanned(node2.ID()))
+	require.EqualValues(t, -10, node1Manager.AdjustScore(node2.ID(), -5, errors.New("invalid message")))
+	require.True(t, node1Manager.IsBanned(node2.ID()))
+	require.Equal(t, node2.ID(), bannedPeerID)
+	connectivity(t, node1Manager, node2.ID(), true)
+	require.Nil(t, node1Manager.PeerInfoSnapshot(node2.ID()))
+
+	// the score is reset by the ban
+	require.EqualValues(t, 0, node1Manager.Score(node2.ID()))
+
+	// connections from and to the banned peer are refused
+	require.ErrorIs(t, node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationAutopeered), p2p.ErrPeerBanned)
+	require.ErrorIs(t, node1Manager.AllowPeer(node2.ID()), p2p.ErrPeerBanned)
+	node1AddrInfo := &peer.AddrInfo{ID: node1.ID(), Addrs: node1.Addrs()}
+	go func() {
+		_ = node2Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown)
+	}()
+	require.Never(t, func() bool {
+		return node1Manager.IsConnected(node2.ID())
+	}, 2*time.Second, 100*time.Millisecond)
+
+	// known peers are never banned
+	require.EqualValues(t, -20, node1Manager.AdjustScore(node3.ID(), -20, errors.New("invalid message")))
+	require.False(t, node1Manager.IsBanned(node3.ID()))
+	require.ErrorIs(t, node1Manager.BanPeer(node3.ID(), time.Hour, errors.New("test")), p2p.ErrCantBanKnownPeer)
+	connectivity(t, node1Manager, node3.ID(), false)
+
+	// adding a banned peer as known peer lifts the ban
+	require.NoError(t, node2Manager.DisconnectPeer(node1.ID()))
+	go func() {
+		_ = node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationKnown)
+	}()
+	connectivity(t, node1Manager, node2.ID(), false, 10*time.Second)
+	require.False(t, node1Manager.IsBanned(node2.ID()))
+
+	// only negative scores are kept for peers which are removed from the manager
+	require.EqualValues(t, 5, node1Manager.AdjustScore(node2.ID(), 5, nil))
+	require.NoError(t, node1Manager.DisconnectPeer(node2.ID()))
+	require.EqualValues(t, 0, node1Manager.Score(node2.ID()))
+	require.NoError(t, node1Manager.DisconnectPeer(node3.ID()))
+	require.EqualValues(t, -20, node1Manager.Score(node3.ID()))
+}
+
 func BenchmarkManager_ForEach(b *testing.B) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -326,3 +421,136 @@ func BenchmarkManager_ForEach(b *testing.B) {
 		})
 	}
 }
+
+func TestManagerConnectionPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = logger.InitGlobalLogger(cfg)
+
+	// all nodes only listen on the loopback interface, so that all connections stem from the same subnet
+	listenOpt := libp2p.ListenAddrStrings("/ip4/127.0.0.1/tcp/0")
+
+	newGatedManager := func(policy p2p.ConnectionPolicy) (host.Host, *p2p.Manager, chan error) {
+		gater := p2p.NewConnectionGater(policy)
+		node := newNode(t, listenOpt, libp2p.ConnectionGater(gater))
+		manager := p2p.NewManager(node, p2p.WithManagerConnectionGater(gater))
+		go manager.Start(ctx)
+
+		refusals := make(chan error, 10)
+		manager.Events.ConnectionRefused.Attach(events.NewClosure(func(refusal *p2p.ConnectionRefusal) {
+			refusals <- refusal.Reason
+		}))
+		return node, manager, refusals
+	}
+
+	newManager := func() (*p2p.Manager, *peer.AddrInfo) {
+		node := newNode(t, listenOpt)
+		manager := p2p.NewManager(node)
+		go manager.Start(ctx)
+		return manager, &peer.AddrInfo{ID: node.ID(), Addrs: node.Addrs()}
+	}
+
+	requireRefusal := func(refusals chan error, expected error) {
+		select {
+		case reason := <-refusals:
+			require.ErrorIs(t, reason, expected)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "connection was not refused")
+		}
+	}
+
+	loopbackSubnets, err := p2p.ParseSubnets([]string{"127.0.0.0/8"})
+	require.NoError(t, err)
+	_, err = p2p.ParseSubnets([]string{"127.0.0.1"})
+	require.Error(t, err)
+
+	t.Run("denied subnets", func(t *testing.T) {
+		node1, node1Manager, refusals := newGatedManager(p2p.ConnectionPolicy{DeniedSubnets: loopbackSubnets})
+		node1AddrInfo := &peer.AddrInfo{ID: node1.ID(), Addrs: node1.Addrs()}
+
+		node2Manager, node2AddrInfo := newManager()
+		go func() {
+			_ = node2Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown)
+		}()
+		requireRefusal(refusals, p2p.ErrConnectionFromDeniedSubnet)
+		require.False(t, node1Manager.IsConnected(node2AddrInfo.ID))
+
+		// known peers are exempt from the policy
+		require.NoError(t, node2Manager.DisconnectPeer(node1.ID()))
+		go func() {
+			_ = node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationKnown)
+		}()
+		connectivity(t, node1Manager, node2AddrInfo.ID, false)
+	})
+
+	t.Run("allowed subnets", func(t *testing.T) {
+		otherSubnets, err := p2p.ParseSubnets([]string{"10.0.0.0/8"})
+		require.NoError(t, err)
+
+		node1, node1Manager, refusals := newGatedManager(p2p.ConnectionPolicy{AllowedSubnets: otherSubnets})
+		node1AddrInfo := &peer.AddrInfo{ID: node1.ID(), Addrs: node1.Addrs()}
+
+		node2Manager, node2AddrInfo := newManager()
+		go func() {
+			_ = node2Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown)
+		}()
+		requireRefusal(refusals, p2p.ErrConnectionNotFromAllowedSubnet)
+		require.False(t, node1Manager.IsConnected(node2AddrInfo.ID))
+	})
+
+	t.Run("subnet connection limit", func(t *testing.T) {
+		node1, node1Manager, refusals := newGatedManager(p2p.ConnectionPolicy{MaxConnsPerIPv4Subnet: 1})
+		node1AddrInfo := &peer.AddrInfo{ID: node1.ID(), Addrs: node1.Addrs()}
+
+		// connections to known peers don't count towards the limit
+		_, node4AddrInfo := newManager()
+		go func() {
+			_ = node1Manager.ConnectPeer(node4AddrInfo, p2p.PeerRelationKnown)
+		}()
+		connectivity(t, node1Manager, node4AddrInfo.ID, false)
+
+		node2Manager, node2AddrInfo := newManager()
+		go func() {
+			_ = node2Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown)
+		}()
+		connectivity(t, node1Manager, node2AddrInfo.ID, false)
+
+		// the second connection from the same subnet exceeds the limit
+		node3Manager, node3AddrInfo := newManager()
+		go func() {
+			_ = node3Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown)
+		}()
+		requireRefusal(refusals, p2p.ErrSubnetConnectionLimitReached)
+		require.False(t, node1Manager.IsConnected(node3AddrInfo.ID))
+	})
+
+	t.Run("known peers only", func(t *testing.T) {
+		node1, node1Manager, refusals := newGatedManager(p2p.ConnectionPolicy{KnownPeersOnly: true})
+		node1AddrInfo := &peer.AddrInfo{ID: node1.ID(), Addrs: node1.Addrs()}
+
+		// dials to peers which are not known are refused
+		_, node2AddrInfo := newManager()
+		require.Error(t, node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationUnknown))
+		requireRefusal(refusals, p2p.ErrOnlyKnownPeersAllowed)
+
+		// inbound connections of peers which are not known are refused
+		node3Manager, node3AddrInfo := newManager()
+		go func() {
+			_ = node3Manager.ConnectPeer(node1AddrInfo, p2p.PeerRelationKnown)
+		}()
+		requireRefusal(refusals, p2p.ErrOnlyKnownPeersAllowed)
+		require.False(t, node1Manager.IsConnected(node3AddrInfo.ID))
+
+		// known peers are accepted
+		go func() {
+			_ = node1Manager.ConnectPeer(node2AddrInfo, p2p.PeerRelationKnown)
+		}()
+		connectivity(t, node1Manager, node2AddrInfo.ID, false)
+	})
+}
@@ -68,4 +68,6 @@ type PeerInfoSnapshot struct {
 	Connected bool `json:"connected"`
 	// The relation to the peer.
 	Relation string `json:"relation"`
+	// The score of the peer.
+	Score int64 `json:"score"`
 }
@@ -0,0 +1,171 @@
+package gossip
+
+import (
+	"context"
+
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	"github.com/iotaledger/hive.go/protocol/message"
+	"github.com/iotaledger/hive.go/protocol/tlv"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// the amount of message types which are tracked by the byte counters.
+	// needs to be increased if a message type with a higher ID is added.
+	messageTypesCount = int(MessageTypeExtendedHeartbeat) + 1
+)
+
+var (
+	// the minimum burst of a bandwidth limiter, so that every gossip message can pass it at once.
+	minBandwidthLimiterBurst = int(tlv.HeaderMessageDefinition.MaxBytesLength) + iotago.MessageBinSerializedMaxSize
+)
+
+// the sizes of the single request packets that correspond to the items of the batched request packets.
+// batched requests are charged to the inbound bandwidth limiters like the equivalent single requests,
+// so that they can't be used to request more data than single requests within the same limits.
+var singleRequestPacketSizes = map[message.Type]int{
+	MessageTypeMessageRequestBatch:   int(tlv.HeaderMessageDefinition.MaxBytesLength) + RequestedMessageIDMsgBytesLength,
+	MessageTypeMilestoneRequestRange: int(tlv.HeaderMessageDefinition.MaxBytesLength) + RequestedMilestoneIndexMsgBytesLength,
+}
+
+// the names of the message types used in the byte counter snapshots.
+var messageTypeNames = map[message.Type]string{
+	MessageTypeMilestoneRequest:      "milestoneRequest",
+	MessageTypeMessage:               "message",
+	MessageTypeMessageRequest:        "messageRequest",
+	MessageTypeHeartbeat:             "heartbeat",
+	MessageTypeCapabilities:          "capabilities",
+	MessageTypeMessageRequestBatch:   "messageRequestBatch",
+	MessageTypeMilestoneRequestRange: "milestoneRequestRange",
+	MessageTypeExtendedHeartbeat:     "extendedHeartbeat",
+}
+
+// MessageTypeName returns the name of the given message type.
+func MessageTypeName(msgType message.Type) string {
+	if name, exists := messageTypeNames[msgType]; exists {
+		return name
+	}
+	return "unknown"
+}
+
+// ByteCounters counts the bytes of gossip messages per message type.
+type ByteCounters struct {
+	counters [messageTypesCount]atomic.Uint64
+}
+
+// Add adds the given amount of bytes to the counter of the given message type.
+func (bc *ByteCounters) Add(msgType message.Type, bytes int) {
+	if int(msgType) >= messageTypesCount {
+		return
+	}
+	bc.counters[msgType].Add(uint64(bytes))
+}
+
+// adds the size of the given gossip message to the counter of its message type.
+func (bc *ByteCounters) addMessage(msg []byte) {
+	if len(msg) == 0 {
+		return
+	}
+	bc.Add(message.Type(msg[0]), len(msg))
+}
+
+// Load returns the amount of bytes counted for the given message type.
+func (bc *ByteCounters) Load(msgType message.Type) uint64 {
+	if int(msgType) >= messageTypesCount {
+		return 0
+	}
+	return bc.counters[msgType].Load()
+}
+
+// Total returns the amount of bytes counted for all message types.
+func (bc *ByteCounters) Total() uint64 {
+	var total uint64
+	for i := range bc.counters {
+		total += bc.counters[i].Load()
+	}
+	return total
+}
+
+// Snapshot returns the counted bytes by the names of the message types.
+func (bc *ByteCounters) Snapshot() map[string]uint64 {
+	snapshot := make(map[string]uint64, len(messageTypeNames))
+	for msgType, name := range messageTypeNames {
+		snapshot[name] = bc.Load(msgType)
+	}
+	return snapshot
+}
+
+// BandwidthLimits defines the bandwidth limits of gossip protocol streams in bytes per second.
+// A limit of zero disables the limit.
+type BandwidthLimits struct {
+	// The inbound bandwidth limit per peer.
+	PeerInbound int
+	// The outbound bandwidth limit per peer.
+	PeerOutbound int
+	// The inbound bandwidth limit of all peers together.
+	GlobalInbound int
+	// The outbound bandwidth limit of all peers together.
+	GlobalOutbound int
+}
+
+// creates a token bucket rate limiter for the given bandwidth limit in bytes per second.
+// returns nil if the limit is disabled.
+func newBandwidthLimiter(bytesPerSecond int) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	burst := bytesPerSecond
+	if burst < minBandwidthLimiterBurst {
+		burst = minBandwidthLimiterBurst
+	}
+
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
+
+// returns the given limiters without the disabled ones.
+func activeBandwidthLimiters(limiters ...*rate.Limiter) []*rate.Limiter {
+	active := make([]*rate.Limiter, 0, len(limiters))
+	for _, limiter := range limiters {
+		if limiter != nil {
+			active = append(active, limiter)
+		}
+	}
+	return active
+}
+
+// returns the amount of bytes the given batched request packet needs to be charged to the inbound bandwidth limiters
+// in addition to its own size, so that it is charged like the equivalent single requests.
+func batchedRequestExtraBytes(msgType message.Type, data []byte) int {
+	singleRequestPacketSize, isBatchedRequest := singleRequestPacketSizes[msgType]
+	if !isBatchedRequest {
+		return 0
+	}
+
+	extraBytes := RequestedItemsCount(msgType, data)*singleRequestPacketSize - (int(tlv.HeaderMessageDefinition.MaxBytesLength) + len(data))
+	if extraBytes < 0 {
+		return 0
+	}
+	return extraBytes
+}
+
+// waits until the given amount of bytes is allowed by all given limiters.
+func waitBandwidth(ctx context.Context, limiters []*rate.Limiter, bytes int) error {
+	for _, limiter := range limiters {
+		// the limiter doesn't allow to wait for more than its burst at once
+		for remaining := bytes; remaining > 0; {
+			n := remaining
+			if n > limiter.Burst() {
+				n = limiter.Burst()
+			}
+
+			if err := limiter.WaitN(ctx, n); err != nil {
+				return err
+			}
+			remaining -= n
+		}
+	}
+	return nil
+}
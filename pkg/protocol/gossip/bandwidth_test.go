@@ -0,0 +1,40 @@
+package gossip_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/protocol/gossip"
+	"github.com/iotaledger/hive.go/protocol/message"
+)
+
+func TestMessageTypeName(t *testing.T) {
+	require.Equal(t, "message", gossip.MessageTypeName(gossip.MessageTypeMessage))
+	require.Equal(t, "milestoneRequestRange", gossip.MessageTypeName(gossip.MessageTypeMilestoneRequestRange))
+	require.Equal(t, "extendedHeartbeat", gossip.MessageTypeName(gossip.MessageTypeExtendedHeartbeat))
+	require.Equal(t, "unknown", gossip.MessageTypeName(message.Type(200)))
+}
+
+func TestByteCounters(t *testing.T) {
+	var counters gossip.ByteCounters
+
+	counters.Add(gossip.MessageTypeMessage, 100)
+	counters.Add(gossip.MessageTypeMessage, 50)
+	counters.Add(gossip.MessageTypeHeartbeat, 10)
+
+	// unknown message types are ignored
+	counters.Add(message.Type(200), 1000)
+
+	require.EqualValues(t, 150, counters.Load(gossip.MessageTypeMessage))
+	require.EqualValues(t, 10, counters.Load(gossip.MessageTypeHeartbeat))
+	require.EqualValues(t, 0, counters.Load(gossip.MessageTypeMessageRequest))
+	require.EqualValues(t, 0, counters.Load(message.Type(200)))
+	require.EqualValues(t, 160, counters.Total())
+
+	snapshot := counters.Snapshot()
+	require.EqualValues(t, 150, snapshot["message"])
+	require.EqualValues(t, 10, snapshot["heartbeat"])
+	require.EqualValues(t, 0, snapshot["milestoneRequest"])
+	require.NotContains(t, snapshot, "unknown")
+}
@@ -3,6 +3,9 @@ package gossip
 import (
 	"context"
 
+	"go.uber.org/atomic"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/storage"
 	"github.com/gohornet/hornet/pkg/model/syncmanager"
 	"github.com/gohornet/hornet/pkg/p2p"
@@ -18,6 +21,13 @@ type Broadcaster struct {
 	peeringManager *p2p.Manager
 	// used to access gossip service.
 	service *Service
+	// whether the node is an archive node, announced in extended heartbeats.
+	archiveNode bool
+	// the cached oldest milestone index with a stored milestone diff, announced in extended heartbeats.
+	// 0 means it needs to be determined again.
+	oldestLedgerDiffIndex *atomic.Uint32
+	// the services of the node, announced in extended heartbeats.
+	services []string
 	// the queue for pending broadcasts.
 	queue chan *Broadcast
 }
@@ -28,17 +38,45 @@ func NewBroadcaster(
 	syncManager *syncmanager.SyncManager,
 	peeringManager *p2p.Manager,
 	service *Service,
+	archiveNode bool,
+	services []string,
 	broadcastQueueSize int) *Broadcaster {
 
 	return &Broadcaster{
-		storage:        dbStorage,
-		syncManager:    syncManager,
-		peeringManager: peeringManager,
-		service:        service,
-		queue:          make(chan *Broadcast, broadcastQueueSize),
+		storage:               dbStorage,
+		syncManager:           syncManager,
+		peeringManager:        peeringManager,
+		service:               service,
+		archiveNode:           archiveNode,
+		oldestLedgerDiffIndex: atomic.NewUint32(0),
+		services:              services,
+		queue:                 make(chan *Broadcast, broadcastQueueSize),
 	}
 }
 
+// ResetOldestLedgerDiffIndex resets the cached oldest milestone index with a stored milestone diff.
+// It needs to be called after milestone diffs were pruned.
+func (b *Broadcaster) ResetOldestLedgerDiffIndex() {
+	b.oldestLedgerDiffIndex.Store(0)
+}
+
+// returns the cached oldest milestone index with a stored milestone diff and determines it if necessary.
+func (b *Broadcaster) cachedOldestLedgerDiffIndex() (milestone.Index, error) {
+	if index := b.oldestLedgerDiffIndex.Load(); index != 0 {
+		return milestone.Index(index), nil
+	}
+
+	// the oldest milestone diff only changes if milestone diffs are pruned,
+	// so it is only determined again after pruning or as long as there are no milestone diffs.
+	index, err := b.storage.UTXOManager().OldestMilestoneDiffIndex()
+	if err != nil {
+		return 0, err
+	}
+	b.oldestLedgerDiffIndex.Store(uint32(index))
+
+	return index, nil
+}
+
 // RunBroadcastQueueDrainer runs the broadcast queue drainer.
 func (b *Broadcaster) RunBroadcastQueueDrainer(ctx context.Context) {
 exit:
@@ -65,6 +103,7 @@ func (b *Broadcaster) Broadcast(broadcast *Broadcast) {
 }
 
 // BroadcastHeartbeat broadcasts a heartbeat message to every peer.
+// Peers which support FeatureExtendedHeartbeat receive an extended heartbeat message.
 func (b *Broadcaster) BroadcastHeartbeat(filter func(proto *Protocol) bool) {
 	snapshotInfo := b.storage.SnapshotInfo()
 	if snapshotInfo == nil {
@@ -74,18 +113,64 @@ func (b *Broadcaster) BroadcastHeartbeat(filter func(proto *Protocol) bool) {
 	confirmedMilestoneIndex := b.syncManager.ConfirmedMilestoneIndex() // bee differentiates between solid and confirmed milestone, for hornet it is the same.
 	connectedCount := b.peeringManager.ConnectedCount()
 	syncedCount := b.service.SynchronizedCount(confirmedMilestoneIndex)
-	// TODO: overflow not handled for synced/connected
 
-	heartbeatMsg, err := NewHeartbeatMsg(confirmedMilestoneIndex, snapshotInfo.PruningIndex, b.syncManager.LatestMilestoneIndex(), byte(connectedCount), byte(syncedCount))
+	heartbeatMsg, err := NewHeartbeatMsg(confirmedMilestoneIndex, snapshotInfo.PruningIndex, b.syncManager.LatestMilestoneIndex(), capNeighbors(connectedCount), capNeighbors(syncedCount))
 	if err != nil {
 		return
 	}
 
+	// the extended heartbeat message is only created if there is a peer which supports it
+	var extendedHeartbeatMsg []byte
+	extendedHeartbeatMsgCreated := false
+
 	b.service.ForEach(func(proto *Protocol) bool {
 		if filter != nil && !filter(proto) {
 			return true
 		}
-		proto.Enqueue(heartbeatMsg)
+
+		if !proto.Supports(FeatureExtendedHeartbeat) {
+			proto.Enqueue(heartbeatMsg)
+			return true
+		}
+
+		if !extendedHeartbeatMsgCreated {
+			extendedHeartbeatMsgCreated = true
+			extendedHeartbeatMsg = b.extendedHeartbeatMsg(confirmedMilestoneIndex, snapshotInfo, connectedCount, syncedCount)
+		}
+
+		if extendedHeartbeatMsg == nil {
+			// fall back to the base heartbeat if the extended heartbeat can't be created
+			proto.Enqueue(heartbeatMsg)
+			return true
+		}
+
+		proto.Enqueue(extendedHeartbeatMsg)
 		return true
 	})
 }
+
+// creates an extended heartbeat message. Returns nil if it can't be created.
+func (b *Broadcaster) extendedHeartbeatMsg(confirmedMilestoneIndex milestone.Index, snapshotInfo *storage.SnapshotInfo, connectedCount int, syncedCount int) []byte {
+	oldestLedgerDiffIndex, err := b.cachedOldestLedgerDiffIndex()
+	if err != nil {
+		return nil
+	}
+
+	extendedHeartbeatMsg, err := NewExtendedHeartbeatMsg(&Heartbeat{
+		SolidMilestoneIndex:            confirmedMilestoneIndex,
+		PrunedMilestoneIndex:           snapshotInfo.PruningIndex,
+		LatestMilestoneIndex:           b.syncManager.LatestMilestoneIndex(),
+		ConnectedNeighbors:             connectedCount,
+		SyncedNeighbors:                syncedCount,
+		Extended:                       true,
+		OldestLedgerDiffMilestoneIndex: oldestLedgerDiffIndex,
+		SnapshotMilestoneIndex:         snapshotInfo.SnapshotIndex,
+		ArchiveNode:                    b.archiveNode,
+		Services:                       b.services,
+	})
+	if err != nil {
+		return nil
+	}
+
+	return extendedHeartbeatMsg
+}
@@ -0,0 +1,224 @@
+package gossip
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/protocol/message"
+	"github.com/iotaledger/hive.go/protocol/tlv"
+)
+
+var (
+	// ErrInvalidCapabilities is returned when the capabilities of a peer are malformed.
+	ErrInvalidCapabilities = errors.New("invalid capabilities")
+	// ErrNoCommonProtocolVersion is returned when the supported protocol versions of two peers don't overlap.
+	ErrNoCommonProtocolVersion = errors.New("no common protocol version")
+	// ErrCapabilitiesAlreadyNegotiated is returned when a peer sends its capabilities more than once.
+	ErrCapabilitiesAlreadyNegotiated = errors.New("capabilities already negotiated")
+	// ErrCapabilitiesNotSupported is returned when a peer sends its capabilities on a stream without capability negotiation.
+	ErrCapabilitiesNotSupported = errors.New("capabilities not supported on this stream")
+)
+
+// CapabilitiesVersion is the first protocol version in which peers exchange their capabilities.
+// Streams of older versions only support the base feature set of MinimumVersion.
+const CapabilitiesVersion = 2
+
+// CurrentVersion is the highest protocol version supported by this node.
+const CurrentVersion = CapabilitiesVersion
+
+const (
+	MessageTypeCapabilities message.Type = 5
+)
+
+const (
+	// The maximum amount of features within a capabilities packet.
+	MaxCapabilitiesFeatures = 16
+
+	// The maximum length of the name of a feature.
+	MaxFeatureNameLength = 32
+
+	// The amount of bytes used for the versions and the feature count within a capabilities packet.
+	capabilitiesHeaderBytesLength = 3
+)
+
+var (
+	// The capabilities packet containing the supported protocol versions and optional features.
+	// It is the first message sent on a stream with capability negotiation.
+	CapabilitiesMessageDefinition = &message.Definition{
+		ID:             MessageTypeCapabilities,
+		MaxBytesLength: capabilitiesHeaderBytesLength + MaxCapabilitiesFeatures*(1+MaxFeatureNameLength),
+		VariableLength: true,
+	}
+)
+
+// Feature is the name of an optional gossip protocol feature.
+// A feature is only used on a stream if both peers announced it.
+type Feature string
+
+// SupportedFeatures returns the optional features supported by this node.
+func SupportedFeatures() []Feature {
+	return []Feature{
+		FeatureBatchedRequests,
+		FeatureExtendedHeartbeat,
+	}
+}
+
+// Capabilities are the supported protocol versions and optional features of a peer.
+type Capabilities struct {
+	// The minimum supported protocol version.
+	MinVersion uint8
+	// The maximum supported protocol version.
+	MaxVersion uint8
+	// The supported optional features.
+	Features []Feature
+}
+
+// NewLocalCapabilities returns the capabilities of this node.
+func NewLocalCapabilities() *Capabilities {
+	return &Capabilities{
+		MinVersion: MinimumVersion,
+		MaxVersion: CurrentVersion,
+		Features:   SupportedFeatures(),
+	}
+}
+
+// NegotiatedCapabilities are the protocol version and features used on a stream.
+type NegotiatedCapabilities struct {
+	// The protocol version used on the stream.
+	Version uint8 `json:"version"`
+	// The optional features supported by both peers.
+	Features []Feature `json:"features"`
+}
+
+// Supports tells whether the given feature was negotiated.
+func (nc *NegotiatedCapabilities) Supports(feature Feature) bool {
+	for _, f := range nc.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// NegotiateCapabilities returns the highest protocol version and the features supported by both peers.
+func NegotiateCapabilities(local *Capabilities, remote *Capabilities) (*NegotiatedCapabilities, error) {
+
+	version := local.MaxVersion
+	if remote.MaxVersion < version {
+		version = remote.MaxVersion
+	}
+
+	if version < local.MinVersion || version < remote.MinVersion {
+		return nil, errors.Wrapf(ErrNoCommonProtocolVersion, "local: %d-%d, remote: %d-%d", local.MinVersion, local.MaxVersion, remote.MinVersion, remote.MaxVersion)
+	}
+
+	remoteFeatures := make(map[Feature]struct{}, len(remote.Features))
+	for _, feature := range remote.Features {
+		remoteFeatures[feature] = struct{}{}
+	}
+
+	features := make([]Feature, 0)
+	for _, feature := range local.Features {
+		if _, has := remoteFeatures[feature]; has {
+			features = append(features, feature)
+		}
+	}
+
+	return &NegotiatedCapabilities{
+		Version:  version,
+		Features: features,
+	}, nil
+}
+
+// NewCapabilitiesMsg creates a new capabilities message.
+func NewCapabilitiesMsg(capabilities *Capabilities) ([]byte, error) {
+	if len(capabilities.Features) > MaxCapabilitiesFeatures {
+		return nil, errors.Wrapf(ErrInvalidCapabilities, "too many features: %d, max: %d", len(capabilities.Features), MaxCapabilitiesFeatures)
+	}
+
+	payload := bytes.NewBuffer(make([]byte, 0, CapabilitiesMessageDefinition.MaxBytesLength))
+	if err := binary.Write(payload, binary.LittleEndian, []byte{capabilities.MinVersion, capabilities.MaxVersion, byte(len(capabilities.Features))}); err != nil {
+		return nil, err
+	}
+
+	for _, feature := range capabilities.Features {
+		if len(feature) == 0 || len(feature) > MaxFeatureNameLength {
+			return nil, errors.Wrapf(ErrInvalidCapabilities, "invalid feature name length: %d, max: %d", len(feature), MaxFeatureNameLength)
+		}
+
+		if err := payload.WriteByte(byte(len(feature))); err != nil {
+			return nil, err
+		}
+
+		if _, err := payload.WriteString(string(feature)); err != nil {
+			return nil, err
+		}
+	}
+
+	msgBytesLength := uint16(payload.Len())
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeCapabilities, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	if _, err := buf.Write(payload.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseCapabilities parses the given message into capabilities.
+func ParseCapabilities(data []byte) (*Capabilities, error) {
+	if len(data) < capabilitiesHeaderBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	capabilities := &Capabilities{
+		MinVersion: data[0],
+		MaxVersion: data[1],
+	}
+
+	if capabilities.MinVersion < MinimumVersion || capabilities.MinVersion > capabilities.MaxVersion {
+		return nil, errors.Wrapf(ErrInvalidCapabilities, "invalid protocol versions: %d-%d", capabilities.MinVersion, capabilities.MaxVersion)
+	}
+
+	featuresCount := int(data[2])
+	if featuresCount > MaxCapabilitiesFeatures {
+		return nil, errors.Wrapf(ErrInvalidCapabilities, "too many features: %d, max: %d", featuresCount, MaxCapabilitiesFeatures)
+	}
+
+	offset := capabilitiesHeaderBytesLength
+	capabilities.Features = make([]Feature, 0, featuresCount)
+	for i := 0; i < featuresCount; i++ {
+		if offset >= len(data) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		featureLength := int(data[offset])
+		offset++
+
+		if featureLength == 0 || featureLength > MaxFeatureNameLength {
+			return nil, errors.Wrapf(ErrInvalidCapabilities, "invalid feature name length: %d, max: %d", featureLength, MaxFeatureNameLength)
+		}
+
+		if offset+featureLength > len(data) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		capabilities.Features = append(capabilities.Features, Feature(data[offset:offset+featureLength]))
+		offset += featureLength
+	}
+
+	if offset != len(data) {
+		return nil, errors.Wrapf(ErrInvalidCapabilities, "%d trailing bytes", len(data)-offset)
+	}
+
+	return capabilities, nil
+}
+
+func NegotiatedCapabilitiesCaller(handler interface{}, params ...interface{}) {
+	handler.(func(capabilities *NegotiatedCapabilities))(params[0].(*NegotiatedCapabilities))
+}
@@ -0,0 +1,192 @@
+package gossip_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/p2p"
+	"github.com/gohornet/hornet/pkg/protocol/gossip"
+	"github.com/iotaledger/hive.go/configuration"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/protocol/tlv"
+)
+
+const capabilitiesProtocolID = "/iota/abcdf/2.0.0"
+
+// returns the payload of the given gossip message without the TLV header.
+func messagePayload(t *testing.T, msg []byte) []byte {
+	require.GreaterOrEqual(t, len(msg), int(tlv.HeaderMessageDefinition.MaxBytesLength))
+	return msg[tlv.HeaderMessageDefinition.MaxBytesLength:]
+}
+
+func TestCapabilitiesMsg(t *testing.T) {
+	capabilities := &gossip.Capabilities{
+		MinVersion: 1,
+		MaxVersion: 3,
+		Features:   []gossip.Feature{"batchedRequests", "compressedMessages"},
+	}
+
+	msg, err := gossip.NewCapabilitiesMsg(capabilities)
+	require.NoError(t, err)
+	require.Equal(t, byte(gossip.MessageTypeCapabilities), msg[0])
+
+	parsed, err := gossip.ParseCapabilities(messagePayload(t, msg))
+	require.NoError(t, err)
+	require.EqualValues(t, capabilities, parsed)
+
+	// invalid feature names can't be sent
+	_, err = gossip.NewCapabilitiesMsg(&gossip.Capabilities{MinVersion: 1, MaxVersion: 2, Features: []gossip.Feature{""}})
+	require.ErrorIs(t, err, gossip.ErrInvalidCapabilities)
+	_, err = gossip.NewCapabilitiesMsg(&gossip.Capabilities{MinVersion: 1, MaxVersion: 2, Features: []gossip.Feature{gossip.Feature(strings.Repeat("a", gossip.MaxFeatureNameLength+1))}})
+	require.ErrorIs(t, err, gossip.ErrInvalidCapabilities)
+
+	// malformed messages
+	_, err = gossip.ParseCapabilities([]byte{1, 2})
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, err = gossip.ParseCapabilities([]byte{2, 1, 0})
+	require.ErrorIs(t, err, gossip.ErrInvalidCapabilities)
+	_, err = gossip.ParseCapabilities([]byte{0, 1, 0})
+	require.ErrorIs(t, err, gossip.ErrInvalidCapabilities)
+	_, err = gossip.ParseCapabilities([]byte{1, 2, 1, 5, 'a'})
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, err = gossip.ParseCapabilities([]byte{1, 2, 1, 1, 'a', 'b'})
+	require.ErrorIs(t, err, gossip.ErrInvalidCapabilities)
+	_, err = gossip.ParseCapabilities([]byte{1, 2, gossip.MaxCapabilitiesFeatures + 1})
+	require.ErrorIs(t, err, gossip.ErrInvalidCapabilities)
+}
+
+func TestNegotiateCapabilities(t *testing.T) {
+	local := &gossip.Capabilities{
+		MinVersion: 1,
+		MaxVersion: 3,
+		Features:   []gossip.Feature{"a", "b", "c"},
+	}
+
+	// the highest common version and the common features are used
+	negotiated, err := gossip.NegotiateCapabilities(local, &gossip.Capabilities{
+		MinVersion: 2,
+		MaxVersion: 5,
+		Features:   []gossip.Feature{"c", "a", "d"},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, negotiated.Version)
+	require.Equal(t, []gossip.Feature{"a", "c"}, negotiated.Features)
+	require.True(t, negotiated.Supports("a"))
+	require.False(t, negotiated.Supports("b"))
+	require.False(t, negotiated.Supports("d"))
+
+	negotiated, err = gossip.NegotiateCapabilities(local, &gossip.Capabilities{
+		MinVersion: 1,
+		MaxVersion: 2,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, negotiated.Version)
+	require.Empty(t, negotiated.Features)
+
+	// no overlapping versions
+	_, err = gossip.NegotiateCapabilities(local, &gossip.Capabilities{
+		MinVersion: 4,
+		MaxVersion: 5,
+	})
+	require.ErrorIs(t, err, gossip.ErrNoCommonProtocolVersion)
+}
+
+func TestProtocolHandleCapabilities(t *testing.T) {
+	local := &gossip.Capabilities{
+		MinVersion: 1,
+		MaxVersion: 2,
+		Features:   []gossip.Feature{"a", "b"},
+	}
+
+	remoteMsg, err := gossip.NewCapabilitiesMsg(&gossip.Capabilities{
+		MinVersion: 1,
+		MaxVersion: 2,
+		Features:   []gossip.Feature{"b"},
+	})
+	require.NoError(t, err)
+
+	proto := gossip.NewProtocol("peer", nil, local, 10, time.Second, time.Second, &metrics.ServerMetrics{})
+
+	// before the negotiation only the base feature set is used
+	require.Nil(t, proto.Capabilities())
+	require.EqualValues(t, gossip.MinimumVersion, proto.Version())
+	require.False(t, proto.Supports("b"))
+
+	// the own capabilities are sent
+	proto.SendCapabilities()
+	require.Len(t, proto.SendQueue, 1)
+	sentCapabilities, err := gossip.ParseCapabilities(messagePayload(t, <-proto.SendQueue))
+	require.NoError(t, err)
+	require.EqualValues(t, local, sentCapabilities)
+
+	var negotiatedCalled bool
+	proto.Events.CapabilitiesNegotiated.Attach(events.NewClosure(func(_ *gossip.NegotiatedCapabilities) {
+		negotiatedCalled = true
+	}))
+
+	require.NoError(t, proto.HandleCapabilities(messagePayload(t, remoteMsg)))
+	require.True(t, negotiatedCalled)
+	require.EqualValues(t, 2, proto.Version())
+	require.True(t, proto.Supports("b"))
+	require.False(t, proto.Supports("a"))
+	require.Equal(t, proto.Capabilities(), proto.Info().Capabilities)
+
+	// the capabilities can only be negotiated once per stream
+	require.ErrorIs(t, proto.HandleCapabilities(messagePayload(t, remoteMsg)), gossip.ErrCapabilitiesAlreadyNegotiated)
+
+	// streams without capability negotiation don't accept capabilities
+	legacyProto := gossip.NewProtocol("peer", nil, nil, 10, time.Second, time.Second, &metrics.ServerMetrics{})
+	legacyProto.SendCapabilities()
+	require.Len(t, legacyProto.SendQueue, 0)
+	require.ErrorIs(t, legacyProto.HandleCapabilities(messagePayload(t, remoteMsg)), gossip.ErrCapabilitiesNotSupported)
+}
+
+func TestServiceCapabilitiesFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = logger.InitGlobalLogger(cfg)
+
+	mngOpts := []p2p.ManagerOption{
+		p2p.WithManagerReconnectInterval(1*time.Second, 500*time.Millisecond),
+	}
+	// node 1 is unknown to node 2 and 3
+	legacySrvOpts := []gossip.ServiceOption{
+		gossip.WithUnknownPeersLimit(1),
+	}
+	srvOpts := append(legacySrvOpts, gossip.WithCapabilities(capabilitiesProtocolID, gossip.NewLocalCapabilities()))
+
+	_, node1Manager, node1Service, _ := newNode("node1", ctx, t, mngOpts, srvOpts)
+	node2, _, _, node2AddrInfo := newNode("node2", ctx, t, mngOpts, srvOpts)
+	node3, _, _, node3AddrInfo := newNode("node3", ctx, t, mngOpts, legacySrvOpts)
+
+	go func() {
+		_ = node1Manager.ConnectPeer(&node2AddrInfo, p2p.PeerRelationKnown)
+	}()
+	go func() {
+		_ = node1Manager.ConnectPeer(&node3AddrInfo, p2p.PeerRelationKnown)
+	}()
+
+	// node 2 supports the capability negotiation
+	require.Eventually(t, func() bool {
+		return node1Service.Protocol(node2.ID()) != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	require.EqualValues(t, capabilitiesProtocolID, node1Service.Protocol(node2.ID()).Stream.Protocol())
+
+	// node 3 only supports the base protocol
+	require.Eventually(t, func() bool {
+		return node1Service.Protocol(node3.ID()) != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	require.EqualValues(t, protocolID, node1Service.Protocol(node3.ID()).Stream.Protocol())
+}
@@ -17,6 +17,10 @@ func init() {
 		MessageMessageDefinition,
 		MessageRequestMessageDefinition,
 		HeartbeatMessageDefinition,
+		CapabilitiesMessageDefinition,
+		MessageRequestBatchMessageDefinition,
+		MilestoneRequestRangeMessageDefinition,
+		ExtendedHeartbeatMessageDefinition,
 	}
 	gossipMessageRegistry = hiveproto.NewRegistry(definitions)
 }
@@ -35,6 +35,7 @@ var (
 	ErrInvalidTimestamp     = errors.New("invalid timestamp")
 	ErrMessageNotSolid      = errors.New("msg is not solid")
 	ErrMessageBelowMaxDepth = errors.New("msg is below max depth")
+	ErrUnansweredRequest    = errors.New("peer requested a milestone outside the announced range")
 )
 
 func MessageProcessedCaller(handler interface{}, params ...interface{}) {
@@ -61,6 +62,22 @@ type MessageProcessorEvents struct {
 	BroadcastMessage *events.Event
 }
 
+// ScoreChanges defines how the scores of peers change depending on the outcome of processing their gossip.
+// Penalties are subtracted from and rewards are added to the score of a peer.
+type ScoreChanges struct {
+	// The penalty for sending an invalid message.
+	InvalidMessagePenalty int64
+	// The penalty for sending a message with insufficient PoW.
+	InsufficientPoWPenalty int64
+	// The penalty for sending an invalid request.
+	InvalidRequestPenalty int64
+	// The penalty for requesting milestones outside the range the node announced in its heartbeat.
+	// Requests for messages are never penalized, since peers can't know which messages the node has.
+	UnansweredRequestPenalty int64
+	// The reward for sending a new valid message.
+	NewMessageReward int64
+}
+
 // The Options for the MessageProcessor.
 type Options struct {
 	MinPoWScore       float64
@@ -68,6 +85,9 @@ type Options struct {
 	ProtocolVersion   byte
 	BelowMaxDepth     milestone.Index
 	WorkUnitCacheOpts *profile.CacheOpts
+	ScoreChanges      ScoreChanges
+	// Whether the node is an archive node which keeps the whole history, either in its database or in the cold storage.
+	ArchiveNode bool
 }
 
 // MessageProcessor processes submitted messages in parallel and fires appropriate completion events.
@@ -165,6 +185,10 @@ func NewMessageProcessor(
 			proc.processMessageRequest(p, data)
 		case MessageTypeMilestoneRequest:
 			proc.processMilestoneRequest(p, data)
+		case MessageTypeMessageRequestBatch:
+			proc.processMessageRequestBatch(p, data)
+		case MessageTypeMilestoneRequestRange:
+			proc.processMilestoneRequestRange(p, data)
 		}
 
 		task.Return(nil)
@@ -292,6 +316,7 @@ func (proc *MessageProcessor) processMilestoneRequest(p *Protocol, data []byte)
 	msIndex, err := ExtractRequestedMilestoneIndex(data)
 	if err != nil {
 		proc.serverMetrics.InvalidRequests.Inc()
+		proc.adjustScore(p, -proc.opts.ScoreChanges.InvalidRequestPenalty, err)
 
 		// drop the connection to the peer
 		_ = proc.peeringManager.DisconnectPeer(p.PeerID, errors.WithMessage(err, "processMilestoneRequest failed"))
@@ -301,44 +326,104 @@ func (proc *MessageProcessor) processMilestoneRequest(p *Protocol, data []byte)
 	// peers can request the latest milestone we know
 	if msIndex == LatestMilestoneRequestIndex {
 		msIndex = proc.syncManager.LatestMilestoneIndex()
+		if msIndex == 0 {
+			// we don't know any milestone yet
+			return
+		}
 	}
 
-	cachedMsgMilestone := proc.storage.MilestoneCachedMessageOrNil(msIndex) // message +1
-	if cachedMsgMilestone == nil {
-		// can't reply if we don't have the wanted milestone
-		return
-	}
-	defer cachedMsgMilestone.Release(true) // message -1
+	proc.replyMilestone(p, msIndex)
+}
 
-	requestedData, err := cachedMsgMilestone.Message().Message().Serialize(serializer.DeSeriModeNoValidation, iotago.ZeroRentParas)
+// processes the given milestone range request by parsing it and then replying to the peer with the milestones.
+func (proc *MessageProcessor) processMilestoneRequestRange(p *Protocol, data []byte) {
+	startIndex, endIndex, err := ExtractRequestedMilestoneRange(data)
 	if err != nil {
-		// can't reply if serialization fails
+		proc.serverMetrics.InvalidRequests.Inc()
+		proc.adjustScore(p, -proc.opts.ScoreChanges.InvalidRequestPenalty, err)
+
+		// drop the connection to the peer
+		_ = proc.peeringManager.DisconnectPeer(p.PeerID, errors.WithMessage(err, "processMilestoneRequestRange failed"))
 		return
 	}
 
-	msg, err := NewMessageMsg(requestedData)
-	if err != nil {
-		// can't reply if serialization fails
+	for msIndex := startIndex; msIndex <= endIndex; msIndex++ {
+		if proc.peeringManager.IsBanned(p.PeerID) {
+			// stop replying if the peer was banned because of its requests
+			return
+		}
+		proc.replyMilestone(p, msIndex)
+	}
+}
+
+// replies to the peer with the milestone message of the given index.
+// requests for milestones outside the range announced in our heartbeat are penalized.
+func (proc *MessageProcessor) replyMilestone(p *Protocol, msIndex milestone.Index) {
+	var requestedMsg *storage.Message
+	if ms := proc.storage.MilestoneWithFallbackOrNil(msIndex); ms != nil {
+		requestedMsg = proc.storage.MessageWithFallbackOrNil(ms.MessageID)
+	}
+
+	if requestedMsg == nil {
+		// can't reply if we don't have the wanted milestone.
+		// milestones within the announced range may still be missing, e.g. while the node is syncing,
+		// so only peers that ignore our heartbeat are penalized.
+		if !proc.announcedMilestone(msIndex) {
+			proc.adjustScore(p, -proc.opts.ScoreChanges.UnansweredRequestPenalty, errors.WithMessagef(ErrUnansweredRequest, "milestone %d", msIndex))
+		}
 		return
 	}
 
-	p.Enqueue(msg)
+	proc.replyWithMessage(p, requestedMsg)
 }
 
 // processes the given message request by parsing it and then replying to the peer with it.
 func (proc *MessageProcessor) processMessageRequest(p *Protocol, data []byte) {
 	if len(data) != iotago.MessageIDLength {
+		proc.adjustScore(p, -proc.opts.ScoreChanges.InvalidRequestPenalty, ErrInvalidSourceLength)
 		return
 	}
 
-	cachedMsg := proc.storage.CachedMessageOrNil(hornet.MessageIDFromSlice(data)) // message +1
-	if cachedMsg == nil {
-		// can't reply if we don't have the requested message
+	proc.replyMessage(p, hornet.MessageIDFromSlice(data))
+}
+
+// processes the given batched message request by parsing it and then replying to the peer with the messages.
+func (proc *MessageProcessor) processMessageRequestBatch(p *Protocol, data []byte) {
+	messageIDs, err := ExtractRequestedMessageIDs(data)
+	if err != nil {
+		proc.serverMetrics.InvalidRequests.Inc()
+		proc.adjustScore(p, -proc.opts.ScoreChanges.InvalidRequestPenalty, err)
+
+		// drop the connection to the peer
+		_ = proc.peeringManager.DisconnectPeer(p.PeerID, errors.WithMessage(err, "processMessageRequestBatch failed"))
 		return
 	}
-	defer cachedMsg.Release(true) // message -1
 
-	requestedData, err := cachedMsg.Message().Message().Serialize(serializer.DeSeriModeNoValidation, iotago.ZeroRentParas)
+	for _, messageID := range messageIDs {
+		if proc.peeringManager.IsBanned(p.PeerID) {
+			// stop replying if the peer was banned because of its requests
+			return
+		}
+		proc.replyMessage(p, messageID)
+	}
+}
+
+// replies to the peer with the message of the given ID.
+// archive nodes serve the messages below the pruning index from the cold storage.
+func (proc *MessageProcessor) replyMessage(p *Protocol, messageID hornet.MessageID) {
+	requestedMsg := proc.storage.MessageWithFallbackOrNil(messageID)
+	if requestedMsg == nil {
+		// can't reply if we don't have the requested message.
+		// this is not penalized, since the peers can't know which messages we have.
+		return
+	}
+
+	proc.replyWithMessage(p, requestedMsg)
+}
+
+// sends the given message to the peer.
+func (proc *MessageProcessor) replyWithMessage(p *Protocol, requestedMsg *storage.Message) {
+	requestedData, err := requestedMsg.Message().Serialize(serializer.DeSeriModeNoValidation, iotago.ZeroRentParas)
 	if err != nil {
 		// can't reply if serialization fails
 		return
@@ -353,6 +438,22 @@ func (proc *MessageProcessor) processMessageRequest(p *Protocol, data []byte) {
 	p.Enqueue(msg)
 }
 
+// tells whether the given milestone index is within the range announced in our heartbeat,
+// which contains the milestones above the pruning index up to the latest milestone index.
+// archive nodes announce the milestones below the pruning index as well.
+func (proc *MessageProcessor) announcedMilestone(msIndex milestone.Index) bool {
+	snapshotInfo := proc.storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		return true
+	}
+
+	if msIndex > proc.syncManager.LatestMilestoneIndex() {
+		return false
+	}
+
+	return proc.opts.ArchiveNode || msIndex > snapshotInfo.PruningIndex
+}
+
 // gets or creates a new WorkUnit for the given message and then processes the WorkUnit.
 func (proc *MessageProcessor) processMessage(p *Protocol, data []byte) {
 	cachedWorkUnit, newlyAdded := proc.workUnitFor(data) // workUnit +1
@@ -405,8 +506,11 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 
 		proc.serverMetrics.InvalidMessages.Inc()
 
+		reason := errors.New("peer sent an invalid message")
+		proc.adjustScore(p, -proc.opts.ScoreChanges.InvalidMessagePenalty, reason)
+
 		// drop the connection to the peer
-		_ = proc.peeringManager.DisconnectPeer(p.PeerID, errors.New("peer sent an invalid message"))
+		_ = proc.peeringManager.DisconnectPeer(p.PeerID, reason)
 		return
 
 	case wu.Is(Hashed):
@@ -434,21 +538,21 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 	msg, err := storage.MessageFromBytes(wu.receivedMsgBytes, serializer.DeSeriModePerformValidation, proc.deSeriParas)
 	if err != nil {
 		wu.UpdateState(Invalid)
-		wu.punish(errors.WithMessagef(err, "peer sent an invalid message"))
+		wu.punish(proc.opts.ScoreChanges.InvalidMessagePenalty, errors.WithMessagef(err, "peer sent an invalid message"))
 		return
 	}
 
 	// check the network ID of the message
 	if msg.ProtocolVersion() != proc.opts.ProtocolVersion {
 		wu.UpdateState(Invalid)
-		wu.punish(errors.New("peer sent a message with an invalid protocol version"))
+		wu.punish(proc.opts.ScoreChanges.InvalidMessagePenalty, errors.New("peer sent a message with an invalid protocol version"))
 		return
 	}
 
 	essence := msg.TransactionEssence()
 	if essence != nil && essence.NetworkID != proc.opts.NetworkID {
 		wu.UpdateState(Invalid)
-		wu.punish(errors.New("peer sent a message containing a transaction with an invalid network ID"))
+		wu.punish(proc.opts.ScoreChanges.InvalidMessagePenalty, errors.New("peer sent a message containing a transaction with an invalid network ID"))
 		return
 	}
 
@@ -460,7 +564,7 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 	// validate PoW score
 	if !wu.requested && pow.Score(wu.receivedMsgBytes) < proc.opts.MinPoWScore {
 		wu.UpdateState(Invalid)
-		wu.punish(errors.New("peer sent a message with insufficient PoW score"))
+		wu.punish(proc.opts.ScoreChanges.InsufficientPoWPenalty, errors.New("peer sent a message with insufficient PoW score"))
 		return
 	}
 
@@ -471,6 +575,9 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 	// increase the known message count for all other peers
 	wu.increaseKnownTxCount(p)
 
+	// reward the peer which sent us the message first
+	proc.adjustScore(p, proc.opts.ScoreChanges.NewMessageReward, nil)
+
 	// do not process gossip if we are not in sync.
 	// we ignore all received messages if we didn't request them and it's not a milestone.
 	// otherwise these messages would get evicted from the cache, and it's heavier to load them
@@ -482,6 +589,14 @@ func (proc *MessageProcessor) processWorkUnit(wu *WorkUnit, p *Protocol) {
 	proc.Events.MessageProcessed.Trigger(msg, requests, p)
 }
 
+// adjusts the score of the given peer by the given delta.
+func (proc *MessageProcessor) adjustScore(p *Protocol, delta int64, reason error) {
+	if delta == 0 {
+		return
+	}
+	proc.peeringManager.AdjustScore(p.PeerID, delta, reason)
+}
+
 func (proc *MessageProcessor) Broadcast(cachedMsgMeta *storage.CachedMetadata) {
 	proc.shutdownMutex.RLock()
 	defer proc.shutdownMutex.RUnlock()
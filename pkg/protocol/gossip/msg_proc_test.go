@@ -2,17 +2,22 @@ package gossip_test
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/libp2p/go-libp2p"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p/p2p/net/connmgr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo/utils"
 	"github.com/gohornet/hornet/pkg/p2p"
 	"github.com/gohornet/hornet/pkg/protocol/gossip"
 	"github.com/gohornet/hornet/pkg/testsuite"
@@ -151,3 +156,63 @@ func TestMsgProcessorEmit(t *testing.T) {
 	err = processor.Emit(message)
 	assert.Error(t, err)
 }
+
+func TestMsgProcessorUnansweredRequestPenalty(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	// we use Ed25519 because otherwise it takes longer as the default is RSA
+	sk, _, _ := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	n, err := libp2p.New(libp2p.Identity(sk))
+	require.NoError(t, err)
+
+	_, requesterPubKey, _ := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	requesterID, err := peer.IDFromPublicKey(requesterPubKey)
+	require.NoError(t, err)
+
+	serverMetrics := &metrics.ServerMetrics{}
+
+	manager := p2p.NewManager(n)
+	go manager.Start(ctx)
+
+	processor, err := gossip.NewMessageProcessor(te.Storage(), te.SyncManager(), gossip.NewRequestQueue(), manager, serverMetrics, testsuite.DeSerializationParameters, &gossip.Options{
+		MinPoWScore:       MinPoWScore,
+		ProtocolVersion:   ProtocolVersion,
+		NetworkID:         te.NetworkID(),
+		BelowMaxDepth:     BelowMaxDepth,
+		WorkUnitCacheOpts: testsuite.TestProfileCaches.IncomingMessagesFilter,
+		ScoreChanges: gossip.ScoreChanges{
+			UnansweredRequestPenalty: 1,
+		},
+	})
+	require.NoError(t, err)
+	go processor.Run(ctx)
+
+	proto := gossip.NewProtocol(requesterID, nil, nil, 10, time.Second, time.Second, serverMetrics)
+
+	milestoneRequest := func(msIndex milestone.Index) []byte {
+		data := make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, uint32(msIndex))
+		return data
+	}
+
+	// the node only knows the first milestone, but it announces a higher latest milestone index while syncing
+	te.SyncManager().SetLatestMilestoneIndex(5)
+
+	// unknown messages and missing milestones within the announced range are not penalized
+	processor.Process(proto, gossip.MessageTypeMessageRequest, utils.RandMessageID())
+	processor.Process(proto, gossip.MessageTypeMilestoneRequest, milestoneRequest(3))
+
+	// known milestones are answered
+	processor.Process(proto, gossip.MessageTypeMilestoneRequest, milestoneRequest(1))
+
+	// milestones outside the announced range are penalized
+	processor.Process(proto, gossip.MessageTypeMilestoneRequest, milestoneRequest(6))
+
+	require.Eventually(t, func() bool {
+		return len(proto.SendQueue) == 1 && manager.Score(requesterID) == -1
+	}, 5*time.Second, 10*time.Millisecond)
+}
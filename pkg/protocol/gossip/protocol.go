@@ -1,19 +1,24 @@
 package gossip
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 
 	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/hive.go/protocol"
+	"github.com/iotaledger/hive.go/protocol/message"
+	"github.com/iotaledger/hive.go/protocol/tlv"
 )
 
 const (
@@ -26,6 +31,8 @@ const (
 type ProtocolEvents struct {
 	// Fired when the heartbeat message state on the peer has been updated.
 	HeartbeatUpdated *events.Event
+	// Fired when the protocol version and features of the stream were negotiated with the peer.
+	CapabilitiesNegotiated *events.Event
 	// Fired when a message of the given type is sent.
 	// This exists solely because protocol.Protocol in hive.go doesn't
 	// emit events anymore for sent messages, as it is solely a parser.
@@ -35,7 +42,9 @@ type ProtocolEvents struct {
 }
 
 // NewProtocol creates a new gossip protocol instance associated to the given peer.
-func NewProtocol(peerID peer.ID, stream network.Stream, sendQueueSize int, readTimeout, writeTimeout time.Duration, serverMetrics *metrics.ServerMetrics) *Protocol {
+// If capabilities are given, they are negotiated with the peer at the start of the stream,
+// otherwise only the base feature set of MinimumVersion is used.
+func NewProtocol(peerID peer.ID, stream network.Stream, capabilities *Capabilities, sendQueueSize int, readTimeout, writeTimeout time.Duration, serverMetrics *metrics.ServerMetrics) *Protocol {
 	defs := gossipMessageRegistry.Definitions()
 	sentEvents := make([]*events.Event, len(defs))
 	for i, def := range defs {
@@ -45,23 +54,48 @@ func NewProtocol(peerID peer.ID, stream network.Stream, sendQueueSize int, readT
 		sentEvents[i] = events.NewEvent(events.VoidCaller)
 	}
 
-	return &Protocol{
+	ctx, cancel := context.WithCancel(context.Background())
+
+	proto := &Protocol{
 		Parser: protocol.New(gossipMessageRegistry),
 		PeerID: peerID,
 		Events: &ProtocolEvents{
-			HeartbeatUpdated: events.NewEvent(HeartbeatCaller),
+			HeartbeatUpdated:       events.NewEvent(HeartbeatCaller),
+			CapabilitiesNegotiated: events.NewEvent(NegotiatedCapabilitiesCaller),
 			// we need this because protocol.Protocol doesn't emit
 			// events for sent messages anymore.
 			Sent:   sentEvents,
 			Errors: events.NewEvent(events.ErrorCaller),
 		},
-		Stream:         stream,
-		terminatedChan: make(chan struct{}),
-		SendQueue:      make(chan []byte, sendQueueSize),
-		readTimeout:    readTimeout,
-		writeTimeout:   writeTimeout,
-		ServerMetrics:  serverMetrics,
+		Stream:            stream,
+		localCapabilities: capabilities,
+		terminatedChan:    make(chan struct{}),
+		ctx:               ctx,
+		cancel:            cancel,
+		SendQueue:         make(chan []byte, sendQueueSize),
+		readTimeout:       readTimeout,
+		writeTimeout:      writeTimeout,
+		ServerMetrics:     serverMetrics,
+	}
+
+	// count the received bytes per message type
+	for i, event := range proto.Parser.Events.Received {
+		if event == nil {
+			continue
+		}
+
+		msgType := message.Type(i)
+		event.Attach(events.NewClosure(func(data []byte) {
+			proto.Metrics.ReceivedBytes.Add(msgType, int(tlv.HeaderMessageDefinition.MaxBytesLength)+len(data))
+
+			// delays the next read if the inbound bandwidth limit is reached by the items of a batched request
+			if extraBytes := batchedRequestExtraBytes(msgType, data); extraBytes > 0 {
+				_ = waitBandwidth(proto.ctx, proto.inboundLimiters, extraBytes)
+			}
+		}))
 	}
+
+	return proto
 }
 
 // Protocol represents an instance of the gossip protocol.
@@ -72,8 +106,20 @@ type Protocol struct {
 	PeerID peer.ID
 	// The underlying stream for this Protocol.
 	Stream network.Stream
+	// The capabilities offered to the peer, nil if the stream doesn't support capability negotiation.
+	localCapabilities *Capabilities
+	// The capabilities negotiated with the peer.
+	negotiatedCapabilities *NegotiatedCapabilities
+	capabilitiesMu         sync.RWMutex
 	// terminatedChan is closed if the protocol was terminated.
 	terminatedChan chan struct{}
+	// ctx is canceled if the protocol was terminated.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// the rate limiters for the bandwidth of received data.
+	inboundLimiters []*rate.Limiter
+	// the rate limiters for the bandwidth of sent data.
+	outboundLimiters []*rate.Limiter
 	// The events surrounding a Protocol.
 	Events *ProtocolEvents
 	// The peer's latest heartbeat message.
@@ -98,6 +144,12 @@ func (p *Protocol) Terminated() <-chan struct{} {
 	return p.terminatedChan
 }
 
+// terminates the protocol.
+func (p *Protocol) terminate() {
+	close(p.terminatedChan)
+	p.cancel()
+}
+
 // Enqueue enqueues the given gossip protocol message to be sent to the peer.
 // If it can't because the send queue is over capacity, the message gets dropped.
 func (p *Protocol) Enqueue(data []byte) {
@@ -116,7 +168,17 @@ func (p *Protocol) Read(buf []byte) (int, error) {
 			return 0, fmt.Errorf("unable to set read deadline: %w", err)
 		}
 
-		return p.Stream.Read(buf)
+		r, err := p.Stream.Read(buf)
+		if err != nil {
+			return r, err
+		}
+
+		// delays the next read if the inbound bandwidth limit is reached
+		if err := waitBandwidth(p.ctx, p.inboundLimiters, r); err != nil {
+			return r, fmt.Errorf("inbound bandwidth limiter failed: %w", err)
+		}
+
+		return r, nil
 	}
 
 	r, err := readMessage(buf)
@@ -133,6 +195,11 @@ func (p *Protocol) Send(message []byte) error {
 	defer p.sendMu.Unlock()
 
 	sendMessage := func(message []byte) error {
+		// delays the message if the outbound bandwidth limit is reached
+		if err := waitBandwidth(p.ctx, p.outboundLimiters, len(message)); err != nil {
+			return fmt.Errorf("outbound bandwidth limiter failed: %w", err)
+		}
+
 		if err := p.Stream.SetWriteDeadline(time.Now().Add(p.writeTimeout)); err != nil {
 			return fmt.Errorf("unable to set write deadline: %w", err)
 		}
@@ -150,11 +217,82 @@ func (p *Protocol) Send(message []byte) error {
 		return err
 	}
 
+	p.Metrics.SentBytes.addMessage(message)
+
 	// fire event handler for sent message
 	p.Events.Sent[message[0]].Trigger()
 	return nil
 }
 
+// SendCapabilities sends the capabilities of this node to the given peer.
+// It does nothing if the stream doesn't support capability negotiation.
+func (p *Protocol) SendCapabilities() {
+	if p.localCapabilities == nil {
+		return
+	}
+
+	capabilitiesData, err := NewCapabilitiesMsg(p.localCapabilities)
+	if err != nil {
+		return
+	}
+	p.Enqueue(capabilitiesData)
+}
+
+// HandleCapabilities negotiates the protocol version and features of the stream with the given capabilities message of the peer.
+func (p *Protocol) HandleCapabilities(data []byte) error {
+	if p.localCapabilities == nil {
+		return ErrCapabilitiesNotSupported
+	}
+
+	remoteCapabilities, err := ParseCapabilities(data)
+	if err != nil {
+		return err
+	}
+
+	negotiatedCapabilities, err := NegotiateCapabilities(p.localCapabilities, remoteCapabilities)
+	if err != nil {
+		return err
+	}
+
+	p.capabilitiesMu.Lock()
+	if p.negotiatedCapabilities != nil {
+		p.capabilitiesMu.Unlock()
+		return ErrCapabilitiesAlreadyNegotiated
+	}
+	p.negotiatedCapabilities = negotiatedCapabilities
+	p.capabilitiesMu.Unlock()
+
+	p.Events.CapabilitiesNegotiated.Trigger(negotiatedCapabilities)
+	return nil
+}
+
+// Capabilities returns the capabilities negotiated with the peer.
+// Returns nil if the capabilities were not negotiated (yet).
+func (p *Protocol) Capabilities() *NegotiatedCapabilities {
+	p.capabilitiesMu.RLock()
+	defer p.capabilitiesMu.RUnlock()
+	return p.negotiatedCapabilities
+}
+
+// Version returns the protocol version used on the stream.
+// Returns MinimumVersion as long as the capabilities were not negotiated.
+func (p *Protocol) Version() uint8 {
+	if capabilities := p.Capabilities(); capabilities != nil {
+		return capabilities.Version
+	}
+	return MinimumVersion
+}
+
+// Supports tells whether the given optional feature can be used on the stream.
+// Returns false as long as the capabilities were not negotiated.
+func (p *Protocol) Supports(feature Feature) bool {
+	capabilities := p.Capabilities()
+	if capabilities == nil {
+		return false
+	}
+	return capabilities.Supports(feature)
+}
+
 // SendMessage sends a storage.Message to the given peer.
 func (p *Protocol) SendMessage(msgData []byte) {
 	messageMsg, err := NewMessageMsg(msgData)
@@ -191,18 +329,105 @@ func (p *Protocol) SendMilestoneRequest(index milestone.Index) {
 	p.Enqueue(milestoneRequestData)
 }
 
+// SendMessageRequests sends requests for the given storage.Messages to the given peer.
+// The requests are batched if the peer supports FeatureBatchedRequests.
+func (p *Protocol) SendMessageRequests(requestedMessageIDs hornet.MessageIDs) {
+	if !p.Supports(FeatureBatchedRequests) {
+		for _, requestedMessageID := range requestedMessageIDs {
+			p.SendMessageRequest(requestedMessageID)
+		}
+		return
+	}
+
+	for len(requestedMessageIDs) > 0 {
+		batchSize := MaxMessageRequestBatchSize
+		if len(requestedMessageIDs) < batchSize {
+			batchSize = len(requestedMessageIDs)
+		}
+
+		if batchSize == 1 {
+			// a single request doesn't need a batch
+			p.SendMessageRequest(requestedMessageIDs[0])
+		} else if msgReqBatchData, err := NewMessageRequestBatchMsg(requestedMessageIDs[:batchSize]); err == nil {
+			p.Enqueue(msgReqBatchData)
+		}
+
+		requestedMessageIDs = requestedMessageIDs[batchSize:]
+	}
+}
+
+// SendMilestoneRequests sends requests for the given storage.Milestones to the given peer.
+// Consecutive milestone indexes are requested as ranges if the peer supports FeatureBatchedRequests.
+func (p *Protocol) SendMilestoneRequests(indexes []milestone.Index) {
+	if !p.Supports(FeatureBatchedRequests) {
+		for _, index := range indexes {
+			p.SendMilestoneRequest(index)
+		}
+		return
+	}
+
+	sortedIndexes := make([]milestone.Index, len(indexes))
+	copy(sortedIndexes, indexes)
+	sort.Slice(sortedIndexes, func(i, j int) bool {
+		return sortedIndexes[i] < sortedIndexes[j]
+	})
+
+	sendRange := func(startIndex milestone.Index, endIndex milestone.Index) {
+		if startIndex == endIndex {
+			p.SendMilestoneRequest(startIndex)
+			return
+		}
+
+		milestoneRequestRangeData, err := NewMilestoneRequestRangeMsg(startIndex, endIndex)
+		if err != nil {
+			return
+		}
+		p.Enqueue(milestoneRequestRangeData)
+	}
+
+	var startIndex, endIndex milestone.Index
+	for _, index := range sortedIndexes {
+		switch {
+		case index == LatestMilestoneRequestIndex:
+			// the latest milestone can't be requested via a range
+			p.SendMilestoneRequest(index)
+			continue
+
+		case startIndex == 0:
+			startIndex, endIndex = index, index
+			continue
+
+		case index == endIndex:
+			// duplicate
+			continue
+
+		case index == endIndex+1 && index-startIndex < MaxMilestoneRequestRangeSize:
+			endIndex = index
+			continue
+		}
+
+		sendRange(startIndex, endIndex)
+		startIndex, endIndex = index, index
+	}
+
+	if startIndex != 0 {
+		sendRange(startIndex, endIndex)
+	}
+}
+
 // SendLatestMilestoneRequest sends a storage.Milestone request which requests the latest known milestone from the given peer.
 func (p *Protocol) SendLatestMilestoneRequest() {
 	p.SendMilestoneRequest(LatestMilestoneRequestIndex)
 }
 
 // HasDataForMilestone tells whether the underlying peer given the latest heartbeat message, has the cone data for the given milestone.
+// If the peer sent an extended heartbeat, it additionally needs to have the ledger diff of the milestone.
 // Returns false if no heartbeat message was received yet.
 func (p *Protocol) HasDataForMilestone(index milestone.Index) bool {
 	if p.LatestHeartbeat == nil {
 		return false
 	}
-	return p.LatestHeartbeat.PrunedMilestoneIndex < index && p.LatestHeartbeat.SolidMilestoneIndex >= index
+	return p.LatestHeartbeat.HasDataForMilestone(index)
 }
 
 // CouldHaveDataForMilestone tells whether the underlying peer given the latest heartbeat message, could have parts of the cone data for the given milestone.
@@ -211,7 +436,16 @@ func (p *Protocol) CouldHaveDataForMilestone(index milestone.Index) bool {
 	if p.LatestHeartbeat == nil {
 		return false
 	}
-	return p.LatestHeartbeat.PrunedMilestoneIndex < index && p.LatestHeartbeat.LatestMilestoneIndex >= index
+	return p.LatestHeartbeat.CouldHaveDataForMilestone(index)
+}
+
+// IsArchiveNode tells whether the underlying peer announced in its latest heartbeat message that it is an archive node.
+// Returns false if no extended heartbeat message was received yet.
+func (p *Protocol) IsArchiveNode() bool {
+	if p.LatestHeartbeat == nil {
+		return false
+	}
+	return p.LatestHeartbeat.ArchiveNode
 }
 
 // IsSynced tells whether the underlying peer is synced.
@@ -235,8 +469,9 @@ func (p *Protocol) IsSynced(cmi milestone.Index) bool {
 // Info returns
 func (p *Protocol) Info() *Info {
 	return &Info{
-		Heartbeat: p.LatestHeartbeat,
-		Metrics:   p.Metrics.Snapshot(),
+		Heartbeat:    p.LatestHeartbeat,
+		Capabilities: p.Capabilities(),
+		Metrics:      p.Metrics.Snapshot(),
 	}
 }
 
@@ -266,6 +501,10 @@ type Metrics struct {
 	SentHeartbeats atomic.Uint32
 	// The number of dropped packets.
 	DroppedPackets atomic.Uint32
+	// The number of received bytes per message type.
+	ReceivedBytes ByteCounters
+	// The number of sent bytes per message type.
+	SentBytes ByteCounters
 }
 
 // Snapshot returns MetricsSnapshot of the Metrics.
@@ -282,26 +521,31 @@ func (m *Metrics) Snapshot() MetricsSnapshot {
 		SentMilestoneReq:     m.SentMilestoneRequests.Load(),
 		SentHeartbeats:       m.SentHeartbeats.Load(),
 		DroppedPackets:       m.DroppedPackets.Load(),
+		ReceivedBytes:        m.ReceivedBytes.Snapshot(),
+		SentBytes:            m.SentBytes.Snapshot(),
 	}
 }
 
 // MetricsSnapshot represents a snapshot of the gossip protocol metrics.
 type MetricsSnapshot struct {
-	NewMessages          uint32 `json:"newMessages"`
-	KnownMessages        uint32 `json:"knownMessages"`
-	ReceivedMessages     uint32 `json:"receivedMessages"`
-	ReceivedMessageReq   uint32 `json:"receivedMessageRequests"`
-	ReceivedMilestoneReq uint32 `json:"receivedMilestoneRequests"`
-	ReceivedHeartbeats   uint32 `json:"receivedHeartbeats"`
-	SentMessages         uint32 `json:"sentMessages"`
-	SentMessageReq       uint32 `json:"sentMessageRequests"`
-	SentMilestoneReq     uint32 `json:"sentMilestoneRequests"`
-	SentHeartbeats       uint32 `json:"sentHeartbeats"`
-	DroppedPackets       uint32 `json:"droppedPackets"`
+	NewMessages          uint32            `json:"newMessages"`
+	KnownMessages        uint32            `json:"knownMessages"`
+	ReceivedMessages     uint32            `json:"receivedMessages"`
+	ReceivedMessageReq   uint32            `json:"receivedMessageRequests"`
+	ReceivedMilestoneReq uint32            `json:"receivedMilestoneRequests"`
+	ReceivedHeartbeats   uint32            `json:"receivedHeartbeats"`
+	SentMessages         uint32            `json:"sentMessages"`
+	SentMessageReq       uint32            `json:"sentMessageRequests"`
+	SentMilestoneReq     uint32            `json:"sentMilestoneRequests"`
+	SentHeartbeats       uint32            `json:"sentHeartbeats"`
+	DroppedPackets       uint32            `json:"droppedPackets"`
+	ReceivedBytes        map[string]uint64 `json:"receivedBytes"`
+	SentBytes            map[string]uint64 `json:"sentBytes"`
 }
 
 // Info represents information about an ongoing gossip protocol.
 type Info struct {
-	Heartbeat *Heartbeat      `json:"heartbeat"`
-	Metrics   MetricsSnapshot `json:"metrics"`
+	Heartbeat    *Heartbeat              `json:"heartbeat"`
+	Capabilities *NegotiatedCapabilities `json:"capabilities,omitempty"`
+	Metrics      MetricsSnapshot         `json:"metrics"`
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/libp2p/go-libp2p-core/peer"
+
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/storage"
@@ -91,34 +93,28 @@ func (r *Requester) RunRequestQueueDrainer(ctx context.Context) {
 			return
 		case <-r.drainSignal:
 
-			// drain request queue
+			// drain request queue into batches per peer
+			batches := make(requestBatches)
 			for request := r.rQueue.Next(); request != nil; request = r.rQueue.Next() {
 
-				sendRequest := func(request *Request, proto *Protocol) {
-					switch request.RequestType {
-					case RequestTypeMessageID:
-						proto.SendMessageRequest(request.MessageID)
-					case RequestTypeMilestoneIndex:
-						proto.SendMilestoneRequest(request.MilestoneIndex)
-					default:
-						panic(ErrUnknownRequestType)
-					}
-				}
-
-				requested := false
+				// we only send a request message if the peer actually has the data
+				// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex),
+				// archive nodes are preferred since they keep the data, even below their pruning index.
+				var target *Protocol
 				r.service.ForEach(func(proto *Protocol) bool {
-					// we only send a request message if the peer actually has the data
-					// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= SolidMilestoneIndex)
 					if !proto.HasDataForMilestone(request.MilestoneIndex) {
 						return true
 					}
 
-					sendRequest(request, proto)
-					requested = true
-					return false
+					if target == nil || proto.IsArchiveNode() {
+						target = proto
+					}
+					return !target.IsArchiveNode()
 				})
 
-				if !requested {
+				if target != nil {
+					batches.add(request, target)
+				} else {
 					// we have no neighbor that has the data for sure,
 					// so we ask all neighbors that could have the data
 					// (r.MilestoneIndex > PrunedMilestoneIndex && r.MilestoneIndex <= LatestMilestoneIndex)
@@ -128,12 +124,64 @@ func (r *Requester) RunRequestQueueDrainer(ctx context.Context) {
 							return true
 						}
 
-						sendRequest(request, proto)
+						batches.add(request, proto)
 						return true
 					})
 				}
 			}
+			batches.send()
+		}
+	}
+}
+
+// requestBatch holds the requests to send to a peer.
+type requestBatch struct {
+	proto            *Protocol
+	messageIDs       hornet.MessageIDs
+	milestoneIndexes []milestone.Index
+}
+
+// requestBatches collects requests per peer, so that they can be sent in batches
+// to peers which support FeatureBatchedRequests.
+type requestBatches map[peer.ID]*requestBatch
+
+// adds the request to the batch of the given peer.
+// full batches are sent immediately to not delay the requests.
+func (b requestBatches) add(request *Request, proto *Protocol) {
+	batch, exists := b[proto.PeerID]
+	if !exists {
+		batch = &requestBatch{proto: proto}
+		b[proto.PeerID] = batch
+	}
+
+	switch request.RequestType {
+	case RequestTypeMessageID:
+		batch.messageIDs = append(batch.messageIDs, request.MessageID)
+		if len(batch.messageIDs) >= MaxMessageRequestBatchSize {
+			batch.proto.SendMessageRequests(batch.messageIDs)
+			batch.messageIDs = nil
+		}
+	case RequestTypeMilestoneIndex:
+		batch.milestoneIndexes = append(batch.milestoneIndexes, request.MilestoneIndex)
+		if len(batch.milestoneIndexes) >= MaxMilestoneRequestRangeSize {
+			batch.proto.SendMilestoneRequests(batch.milestoneIndexes)
+			batch.milestoneIndexes = nil
+		}
+	default:
+		panic(ErrUnknownRequestType)
+	}
+}
+
+// sends the remaining requests of all batches.
+func (b requestBatches) send() {
+	for peerID, batch := range b {
+		if len(batch.messageIDs) > 0 {
+			batch.proto.SendMessageRequests(batch.messageIDs)
+		}
+		if len(batch.milestoneIndexes) > 0 {
+			batch.proto.SendMilestoneRequests(batch.milestoneIndexes)
 		}
+		delete(b, peerID)
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/multiformats/go-multiaddr"
+	"golang.org/x/time/rate"
 
 	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/milestone"
@@ -99,6 +100,12 @@ type ServiceOptions struct {
 	streamWriteTimeout time.Duration
 	// The amount of unknown peers to allow to have a gossip stream with.
 	unknownPeersLimit int
+	// The protocol ID of streams which start with a capability negotiation.
+	capabilitiesProtocol protocol.ID
+	// The capabilities offered to peers on streams with capability negotiation.
+	capabilities *Capabilities
+	// The bandwidth limits of the streams.
+	bandwidthLimits BandwidthLimits
 }
 
 // applies the given ServiceOption.
@@ -151,6 +158,22 @@ func WithUnknownPeersLimit(limit int) ServiceOption {
 	}
 }
 
+// WithCapabilities enables the capability negotiation on streams with the given protocol ID.
+// Streams to peers which don't support the given protocol ID fall back to the base protocol ID of the Service.
+func WithCapabilities(protocolID protocol.ID, capabilities *Capabilities) ServiceOption {
+	return func(opts *ServiceOptions) {
+		opts.capabilitiesProtocol = protocolID
+		opts.capabilities = capabilities
+	}
+}
+
+// WithBandwidthLimits defines the inbound and outbound bandwidth limits per peer and for all peers together.
+func WithBandwidthLimits(limits BandwidthLimits) ServiceOption {
+	return func(opts *ServiceOptions) {
+		opts.bandwidthLimits = limits
+	}
+}
+
 // ServiceOption is a function setting a ServiceOptions option.
 type ServiceOption func(opts *ServiceOptions)
 
@@ -176,6 +199,10 @@ type Service struct {
 	stopped *typeutils.AtomicBool
 	// the amount of unknown peers with which a gossip stream is ongoing.
 	unknownPeers map[peer.ID]struct{}
+	// the rate limiter for the inbound bandwidth of all streams, nil if disabled.
+	inboundLimiter *rate.Limiter
+	// the rate limiter for the outbound bandwidth of all streams, nil if disabled.
+	outboundLimiter *rate.Limiter
 	// event loop channels
 	inboundStreamChan   chan network.Stream
 	connectedChan       chan *connectionmsg
@@ -225,6 +252,8 @@ func NewService(
 		opts:                srvOpts,
 		stopped:             typeutils.NewAtomicBool(),
 		unknownPeers:        map[peer.ID]struct{}{},
+		inboundLimiter:      newBandwidthLimiter(srvOpts.bandwidthLimits.GlobalInbound),
+		outboundLimiter:     newBandwidthLimiter(srvOpts.bandwidthLimits.GlobalOutbound),
 		inboundStreamChan:   make(chan network.Stream, 10),
 		connectedChan:       make(chan *connectionmsg, 10),
 		closeStreamChan:     make(chan *closestreammsg, 10),
@@ -296,12 +325,14 @@ func (s *Service) Start(ctx context.Context) {
 	s.attachEvents()
 
 	// libp2p stream handler
-	s.host.SetStreamHandler(s.protocol, func(stream network.Stream) {
-		if s.stopped.IsSet() {
-			return
-		}
-		s.inboundStreamChan <- stream
-	})
+	for _, protocolID := range s.protocols() {
+		s.host.SetStreamHandler(protocolID, func(stream network.Stream) {
+			if s.stopped.IsSet() {
+				return
+			}
+			s.inboundStreamChan <- stream
+		})
+	}
 
 	// manage libp2p network events
 	s.host.Network().Notify((*netNotifiee)(s))
@@ -309,7 +340,9 @@ func (s *Service) Start(ctx context.Context) {
 	s.eventLoop(ctx)
 
 	// libp2p stream handler
-	s.host.RemoveStreamHandler(s.protocol)
+	for _, protocolID := range s.protocols() {
+		s.host.RemoveStreamHandler(protocolID)
+	}
 
 	// de-register libp2p network events
 	s.host.Network().StopNotify((*netNotifiee)(s))
@@ -317,6 +350,24 @@ func (s *Service) Start(ctx context.Context) {
 	s.detachEvents()
 }
 
+// returns the protocol IDs of the Service in the order of preference.
+func (s *Service) protocols() []protocol.ID {
+	if s.opts.capabilitiesProtocol == "" {
+		return []protocol.ID{s.protocol}
+	}
+	return []protocol.ID{s.opts.capabilitiesProtocol, s.protocol}
+}
+
+// tells whether the given protocol ID belongs to the Service.
+func (s *Service) isServiceProtocol(protocolID protocol.ID) bool {
+	for _, p := range s.protocols() {
+		if p == protocolID {
+			return true
+		}
+	}
+	return false
+}
+
 // shutdown sets the stopped flag and drains all outstanding requests of the event loop.
 func (s *Service) shutdown() {
 	s.stopped.Set()
@@ -527,7 +578,8 @@ func (s *Service) openStream(peerID peer.ID) (network.Stream, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), s.opts.streamConnectTimeout)
 	defer cancel()
 
-	stream, err := s.host.NewStream(ctx, peerID, s.protocol)
+	// the first protocol ID supported by the peer is used
+	stream, err := s.host.NewStream(ctx, peerID, s.protocols()...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create gossip stream to %s: %w", peerID, err)
 	}
@@ -544,7 +596,15 @@ func (s *Service) registerProtocol(peerID peer.ID, stream network.Stream) {
 		return
 	}
 
-	proto := NewProtocol(peerID, stream, s.opts.sendQueueSize, s.opts.streamReadTimeout, s.opts.streamWriteTimeout, s.serverMetrics)
+	// the capabilities are only negotiated if the peer supports it
+	var capabilities *Capabilities
+	if s.opts.capabilitiesProtocol != "" && stream.Protocol() == s.opts.capabilitiesProtocol {
+		capabilities = s.opts.capabilities
+	}
+
+	proto := NewProtocol(peerID, stream, capabilities, s.opts.sendQueueSize, s.opts.streamReadTimeout, s.opts.streamWriteTimeout, s.serverMetrics)
+	proto.inboundLimiters = activeBandwidthLimiters(newBandwidthLimiter(s.opts.bandwidthLimits.PeerInbound), s.inboundLimiter)
+	proto.outboundLimiters = activeBandwidthLimiters(newBandwidthLimiter(s.opts.bandwidthLimits.PeerOutbound), s.outboundLimiter)
 	s.streams[peerID] = proto
 	s.Events.ProtocolStarted.Trigger(proto)
 }
@@ -559,7 +619,7 @@ func (s *Service) deregisterProtocol(peerID peer.ID) error {
 	defer func() {
 		delete(s.streams, peerID)
 		delete(s.unknownPeers, peerID)
-		close(proto.terminatedChan)
+		proto.terminate()
 		s.Events.ProtocolTerminated.Trigger(proto)
 	}()
 
@@ -707,7 +767,7 @@ func (m *netNotifiee) Connected(net network.Network, conn network.Conn)
 func (m *netNotifiee) Disconnected(net network.Network, conn network.Conn)            {}
 func (m *netNotifiee) OpenedStream(net network.Network, stream network.Stream)        {}
 func (m *netNotifiee) ClosedStream(net network.Network, stream network.Stream) {
-	if stream.Protocol() != m.protocol {
+	if !(*Service)(m).isServiceProtocol(stream.Protocol()) {
 		return
 	}
 	if m.stopped.IsSet() {
@@ -3,6 +3,7 @@ package gossip
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
 
 	"github.com/pkg/errors"
 
@@ -17,6 +18,8 @@ import (
 var (
 	// ErrInvalidSourceLength is returned when an invalid source byte slice for extraction of certain data is passed.
 	ErrInvalidSourceLength = errors.New("invalid source byte slice")
+	// ErrInvalidMilestoneRange is returned when an invalid milestone index range is requested.
+	ErrInvalidMilestoneRange = errors.New("invalid milestone range")
 )
 
 // MinimumVersion denotes the minimum version for Chrysalis-Pt2 support.
@@ -26,10 +29,20 @@ const MinimumVersion = 1
 const FeatureSetName = "Chrysalis-Pt2"
 
 const (
-	MessageTypeMilestoneRequest message.Type = 1
-	MessageTypeMessage          message.Type = 2
-	MessageTypeMessageRequest   message.Type = 3
-	MessageTypeHeartbeat        message.Type = 4
+	MessageTypeMilestoneRequest      message.Type = 1
+	MessageTypeMessage               message.Type = 2
+	MessageTypeMessageRequest        message.Type = 3
+	MessageTypeHeartbeat             message.Type = 4
+	MessageTypeMessageRequestBatch   message.Type = 6
+	MessageTypeMilestoneRequestRange message.Type = 7
+	MessageTypeExtendedHeartbeat     message.Type = 8
+)
+
+const (
+	// FeatureBatchedRequests enables requests for multiple message IDs and milestone index ranges in a single packet.
+	FeatureBatchedRequests Feature = "batchedRequests"
+	// FeatureExtendedHeartbeat enables heartbeats which additionally contain the pruning state and the services of a node.
+	FeatureExtendedHeartbeat Feature = "extendedHeartbeat"
 )
 
 const (
@@ -44,6 +57,33 @@ const (
 
 	// The index to use to request the latest milestone via a milestone request message.
 	LatestMilestoneRequestIndex = 0
+
+	// The maximum amount of message IDs within a batched message request packet.
+	MaxMessageRequestBatchSize = 128
+
+	// The maximum amount of milestones within a milestone range request packet.
+	MaxMilestoneRequestRangeSize = 64
+
+	// The amount of bytes used for the requested milestone index range.
+	RequestedMilestoneRangeMsgBytesLength = RequestedMilestoneIndexMsgBytesLength * 2
+
+	// The maximum amount of services within an extended heartbeat packet.
+	MaxHeartbeatServices = 32
+
+	// The maximum length of the name of a service within an extended heartbeat packet.
+	MaxHeartbeatServiceNameLength = 32
+
+	// The amount of bytes used for the base heartbeat fields within a heartbeat packet.
+	heartbeatBytesLength = HeartbeatMilestoneIndexBytesLength*3 + 2
+
+	// The amount of bytes used for the fixed fields of an extended heartbeat packet
+	// (base heartbeat, oldest ledger diff index, snapshot index, flags and service count).
+	extendedHeartbeatHeaderBytesLength = heartbeatBytesLength + HeartbeatMilestoneIndexBytesLength*2 + 2
+)
+
+const (
+	// the flag within an extended heartbeat packet which tells that the node is an archive node.
+	heartbeatFlagArchiveNode byte = 1 << 0
 )
 
 var (
@@ -66,16 +106,41 @@ var (
 	// number of connected peers and number of synced peers.
 	HeartbeatMessageDefinition = &message.Definition{
 		ID:             MessageTypeHeartbeat,
-		MaxBytesLength: HeartbeatMilestoneIndexBytesLength*3 + 2,
+		MaxBytesLength: heartbeatBytesLength,
 		VariableLength: false,
 	}
 
+	// The extended heartbeat packet containing the fields of the heartbeat packet,
+	// the oldest milestone index with a ledger diff, the snapshot milestone index,
+	// whether the node is an archive node and the services of the node (FeatureExtendedHeartbeat).
+	ExtendedHeartbeatMessageDefinition = &message.Definition{
+		ID:             MessageTypeExtendedHeartbeat,
+		MaxBytesLength: extendedHeartbeatHeaderBytesLength + MaxHeartbeatServices*(1+MaxHeartbeatServiceNameLength),
+		VariableLength: true,
+	}
+
 	// The requested milestone index packet.
 	MilestoneRequestMessageDefinition = &message.Definition{
 		ID:             MessageTypeMilestoneRequest,
 		MaxBytesLength: RequestedMilestoneIndexMsgBytesLength,
 		VariableLength: false,
 	}
+
+	// The batched message request packet.
+	// Contains the IDs of multiple requested message payloads (FeatureBatchedRequests).
+	MessageRequestBatchMessageDefinition = &message.Definition{
+		ID:             MessageTypeMessageRequestBatch,
+		MaxBytesLength: RequestedMessageIDMsgBytesLength * MaxMessageRequestBatchSize,
+		VariableLength: true,
+	}
+
+	// The requested milestone index range packet.
+	// Contains the first and the last requested milestone index (FeatureBatchedRequests).
+	MilestoneRequestRangeMessageDefinition = &message.Definition{
+		ID:             MessageTypeMilestoneRequestRange,
+		MaxBytesLength: RequestedMilestoneRangeMsgBytesLength,
+		VariableLength: false,
+	}
 )
 
 // NewMessageMsg creates a new message message.
@@ -108,6 +173,27 @@ func NewMessageRequestMsg(requestedMessageID hornet.MessageID) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// NewMessageRequestBatchMsg creates a batched message request message.
+func NewMessageRequestBatchMsg(requestedMessageIDs hornet.MessageIDs) ([]byte, error) {
+	if len(requestedMessageIDs) == 0 || len(requestedMessageIDs) > MaxMessageRequestBatchSize {
+		return nil, ErrInvalidSourceLength
+	}
+
+	msgBytesLength := uint16(len(requestedMessageIDs) * RequestedMessageIDMsgBytesLength)
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeMessageRequestBatch, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	for _, requestedMessageID := range requestedMessageIDs {
+		if err := binary.Write(buf, binary.LittleEndian, requestedMessageID[:RequestedMessageIDMsgBytesLength]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
 // NewHeartbeatMsg creates a new heartbeat message.
 func NewHeartbeatMsg(solidMilestoneIndex milestone.Index, prunedMilestoneIndex milestone.Index, latestMilestoneIndex milestone.Index, connectedPeers uint8, syncedPeers uint8) ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+HeartbeatMessageDefinition.MaxBytesLength))
@@ -115,29 +201,98 @@ func NewHeartbeatMsg(solidMilestoneIndex milestone.Index, prunedMilestoneIndex m
 		return nil, err
 	}
 
-	if err := binary.Write(buf, binary.LittleEndian, solidMilestoneIndex); err != nil {
+	if err := writeHeartbeatFields(buf, solidMilestoneIndex, prunedMilestoneIndex, latestMilestoneIndex, connectedPeers, syncedPeers); err != nil {
 		return nil, err
 	}
 
-	if err := binary.Write(buf, binary.LittleEndian, prunedMilestoneIndex); err != nil {
+	return buf.Bytes(), nil
+}
+
+// NewExtendedHeartbeatMsg creates a new extended heartbeat message.
+// The connected and synced neighbors of the heartbeat are capped at 255.
+func NewExtendedHeartbeatMsg(heartbeat *Heartbeat) ([]byte, error) {
+	if len(heartbeat.Services) > MaxHeartbeatServices {
+		return nil, errors.Wrapf(ErrInvalidSourceLength, "too many services: %d, max: %d", len(heartbeat.Services), MaxHeartbeatServices)
+	}
+
+	payload := bytes.NewBuffer(make([]byte, 0, ExtendedHeartbeatMessageDefinition.MaxBytesLength))
+	if err := writeHeartbeatFields(payload, heartbeat.SolidMilestoneIndex, heartbeat.PrunedMilestoneIndex, heartbeat.LatestMilestoneIndex, capNeighbors(heartbeat.ConnectedNeighbors), capNeighbors(heartbeat.SyncedNeighbors)); err != nil {
 		return nil, err
 	}
 
-	if err := binary.Write(buf, binary.LittleEndian, latestMilestoneIndex); err != nil {
+	if err := binary.Write(payload, binary.LittleEndian, heartbeat.OldestLedgerDiffMilestoneIndex); err != nil {
 		return nil, err
 	}
 
-	if err := binary.Write(buf, binary.LittleEndian, connectedPeers); err != nil {
+	if err := binary.Write(payload, binary.LittleEndian, heartbeat.SnapshotMilestoneIndex); err != nil {
 		return nil, err
 	}
 
-	if err := binary.Write(buf, binary.LittleEndian, syncedPeers); err != nil {
+	var flags byte
+	if heartbeat.ArchiveNode {
+		flags |= heartbeatFlagArchiveNode
+	}
+
+	if err := binary.Write(payload, binary.LittleEndian, []byte{flags, byte(len(heartbeat.Services))}); err != nil {
+		return nil, err
+	}
+
+	for _, service := range heartbeat.Services {
+		if len(service) == 0 || len(service) > MaxHeartbeatServiceNameLength {
+			return nil, errors.Wrapf(ErrInvalidSourceLength, "invalid service name length: %d, max: %d", len(service), MaxHeartbeatServiceNameLength)
+		}
+
+		if err := payload.WriteByte(byte(len(service))); err != nil {
+			return nil, err
+		}
+
+		if _, err := payload.WriteString(service); err != nil {
+			return nil, err
+		}
+	}
+
+	msgBytesLength := uint16(payload.Len())
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+msgBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeExtendedHeartbeat, msgBytesLength); err != nil {
+		return nil, err
+	}
+
+	if _, err := buf.Write(payload.Bytes()); err != nil {
 		return nil, err
 	}
 
 	return buf.Bytes(), nil
 }
 
+// writes the fields of the base heartbeat to the given buffer.
+func writeHeartbeatFields(buf *bytes.Buffer, solidMilestoneIndex milestone.Index, prunedMilestoneIndex milestone.Index, latestMilestoneIndex milestone.Index, connectedPeers uint8, syncedPeers uint8) error {
+	if err := binary.Write(buf, binary.LittleEndian, solidMilestoneIndex); err != nil {
+		return err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, prunedMilestoneIndex); err != nil {
+		return err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, latestMilestoneIndex); err != nil {
+		return err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, connectedPeers); err != nil {
+		return err
+	}
+
+	return binary.Write(buf, binary.LittleEndian, syncedPeers)
+}
+
+// caps the given amount of neighbors to fit into a heartbeat packet.
+func capNeighbors(neighbors int) uint8 {
+	if neighbors > math.MaxUint8 {
+		return math.MaxUint8
+	}
+	return uint8(neighbors)
+}
+
 // NewMilestoneRequestMsg creates a new milestone request message.
 func NewMilestoneRequestMsg(requestedMilestoneIndex milestone.Index) ([]byte, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+MilestoneRequestMessageDefinition.MaxBytesLength))
@@ -152,6 +307,88 @@ func NewMilestoneRequestMsg(requestedMilestoneIndex milestone.Index) ([]byte, er
 	return buf.Bytes(), nil
 }
 
+// NewMilestoneRequestRangeMsg creates a new milestone index range request message.
+// The range includes the start and the end index.
+func NewMilestoneRequestRangeMsg(startIndex milestone.Index, endIndex milestone.Index) ([]byte, error) {
+	if err := validateMilestoneRange(startIndex, endIndex); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, tlv.HeaderMessageDefinition.MaxBytesLength+MilestoneRequestRangeMessageDefinition.MaxBytesLength))
+	if err := tlv.WriteHeader(buf, MessageTypeMilestoneRequestRange, MilestoneRequestRangeMessageDefinition.MaxBytesLength); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, startIndex); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, endIndex); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// checks that the given milestone range can be requested in a single packet.
+func validateMilestoneRange(startIndex milestone.Index, endIndex milestone.Index) error {
+	// the latest milestone can't be requested via a range
+	if startIndex == LatestMilestoneRequestIndex || endIndex < startIndex {
+		return errors.Wrapf(ErrInvalidMilestoneRange, "%d-%d", startIndex, endIndex)
+	}
+
+	if endIndex-startIndex+1 > MaxMilestoneRequestRangeSize {
+		return errors.Wrapf(ErrInvalidMilestoneRange, "%d-%d exceeds the maximum range size of %d", startIndex, endIndex, MaxMilestoneRequestRangeSize)
+	}
+
+	return nil
+}
+
+// ExtractRequestedMessageIDs extracts the requested message IDs from the given batched message request source.
+func ExtractRequestedMessageIDs(source []byte) (hornet.MessageIDs, error) {
+	if len(source) == 0 || len(source)%RequestedMessageIDMsgBytesLength != 0 || len(source) > RequestedMessageIDMsgBytesLength*MaxMessageRequestBatchSize {
+		return nil, ErrInvalidSourceLength
+	}
+
+	messageIDs := make(hornet.MessageIDs, 0, len(source)/RequestedMessageIDMsgBytesLength)
+	for offset := 0; offset < len(source); offset += RequestedMessageIDMsgBytesLength {
+		messageIDs = append(messageIDs, hornet.MessageIDFromSlice(source[offset:offset+RequestedMessageIDMsgBytesLength]))
+	}
+
+	return messageIDs, nil
+}
+
+// ExtractRequestedMilestoneRange extracts the requested milestone index range from the given source.
+func ExtractRequestedMilestoneRange(source []byte) (milestone.Index, milestone.Index, error) {
+	if len(source) != RequestedMilestoneRangeMsgBytesLength {
+		return 0, 0, ErrInvalidSourceLength
+	}
+
+	startIndex := milestone.Index(binary.LittleEndian.Uint32(source[:RequestedMilestoneIndexMsgBytesLength]))
+	endIndex := milestone.Index(binary.LittleEndian.Uint32(source[RequestedMilestoneIndexMsgBytesLength:]))
+	if err := validateMilestoneRange(startIndex, endIndex); err != nil {
+		return 0, 0, err
+	}
+
+	return startIndex, endIndex, nil
+}
+
+// RequestedItemsCount returns the amount of messages or milestones requested by the given request packet source.
+// Invalid batched requests are counted as a single request.
+func RequestedItemsCount(msgType message.Type, source []byte) int {
+	switch msgType {
+	case MessageTypeMessageRequestBatch:
+		if messageIDs, err := ExtractRequestedMessageIDs(source); err == nil {
+			return len(messageIDs)
+		}
+	case MessageTypeMilestoneRequestRange:
+		if startIndex, endIndex, err := ExtractRequestedMilestoneRange(source); err == nil {
+			return int(endIndex-startIndex) + 1
+		}
+	}
+	return 1
+}
+
 // ExtractRequestedMilestoneIndex extracts the requested milestone index from the given source.
 func ExtractRequestedMilestoneIndex(source []byte) (milestone.Index, error) {
 	if len(source) != serializer.UInt32ByteSize {
@@ -164,12 +401,49 @@ func ExtractRequestedMilestoneIndex(source []byte) (milestone.Index, error) {
 
 // Heartbeat contains information about a nodes current solid and pruned milestone index
 // and its connected and synced neighbors count.
+// Extended heartbeats additionally contain the pruning state and the services of the node.
 type Heartbeat struct {
 	SolidMilestoneIndex  milestone.Index `json:"solidMilestoneIndex"`
 	PrunedMilestoneIndex milestone.Index `json:"prunedMilestoneIndex"`
 	LatestMilestoneIndex milestone.Index `json:"latestMilestoneIndex"`
 	ConnectedNeighbors   int             `json:"connectedNeighbors"`
 	SyncedNeighbors      int             `json:"syncedNeighbors"`
+	// Whether the heartbeat is an extended heartbeat and the following fields are set.
+	Extended bool `json:"extended"`
+	// The oldest milestone index for which the node has the ledger diff (0 = none).
+	OldestLedgerDiffMilestoneIndex milestone.Index `json:"oldestLedgerDiffMilestoneIndex,omitempty"`
+	// The milestone index of the latest snapshot of the node.
+	SnapshotMilestoneIndex milestone.Index `json:"snapshotMilestoneIndex,omitempty"`
+	// Whether the node is an archive node which keeps the whole history, either in its database or in the cold storage.
+	ArchiveNode bool `json:"archiveNode,omitempty"`
+	// The services the node provides to other nodes, e.g. the identifiers of the corresponding plugins.
+	Services []string `json:"services,omitempty"`
+}
+
+// HasService tells whether the heartbeat announces the given service.
+func (hb *Heartbeat) HasService(service string) bool {
+	for _, s := range hb.Services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// HasDataForMilestone tells whether the node has the cone data for the given milestone.
+// Archive nodes additionally keep the cones below their pruning index.
+func (hb *Heartbeat) HasDataForMilestone(index milestone.Index) bool {
+	return hb.keepsDataForMilestone(index) && hb.SolidMilestoneIndex >= index
+}
+
+// CouldHaveDataForMilestone tells whether the node could have parts of the cone data for the given milestone.
+func (hb *Heartbeat) CouldHaveDataForMilestone(index milestone.Index) bool {
+	return hb.keepsDataForMilestone(index) && hb.LatestMilestoneIndex >= index
+}
+
+// tells whether the given milestone was not pruned by the node yet or the node is an archive node.
+func (hb *Heartbeat) keepsDataForMilestone(index milestone.Index) bool {
+	return hb.ArchiveNode || hb.PrunedMilestoneIndex < index
 }
 
 // ParseHeartbeat parses the given message into a heartbeat.
@@ -183,6 +457,59 @@ func ParseHeartbeat(data []byte) *Heartbeat {
 	}
 }
 
+// ParseExtendedHeartbeat parses the given message into an extended heartbeat.
+// Unknown flags are ignored, so that they can be added in the future.
+func ParseExtendedHeartbeat(data []byte) (*Heartbeat, error) {
+	if len(data) < extendedHeartbeatHeaderBytesLength {
+		return nil, ErrInvalidSourceLength
+	}
+
+	heartbeat := ParseHeartbeat(data[:heartbeatBytesLength])
+	heartbeat.Extended = true
+
+	offset := heartbeatBytesLength
+	heartbeat.OldestLedgerDiffMilestoneIndex = milestone.Index(binary.LittleEndian.Uint32(data[offset : offset+HeartbeatMilestoneIndexBytesLength]))
+	offset += HeartbeatMilestoneIndexBytesLength
+	heartbeat.SnapshotMilestoneIndex = milestone.Index(binary.LittleEndian.Uint32(data[offset : offset+HeartbeatMilestoneIndexBytesLength]))
+	offset += HeartbeatMilestoneIndexBytesLength
+
+	flags := data[offset]
+	heartbeat.ArchiveNode = flags&heartbeatFlagArchiveNode != 0
+
+	servicesCount := int(data[offset+1])
+	if servicesCount > MaxHeartbeatServices {
+		return nil, errors.Wrapf(ErrInvalidSourceLength, "too many services: %d, max: %d", servicesCount, MaxHeartbeatServices)
+	}
+	offset += 2
+
+	heartbeat.Services = make([]string, 0, servicesCount)
+	for i := 0; i < servicesCount; i++ {
+		if offset >= len(data) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		serviceLength := int(data[offset])
+		offset++
+
+		if serviceLength == 0 || serviceLength > MaxHeartbeatServiceNameLength {
+			return nil, errors.Wrapf(ErrInvalidSourceLength, "invalid service name length: %d, max: %d", serviceLength, MaxHeartbeatServiceNameLength)
+		}
+
+		if offset+serviceLength > len(data) {
+			return nil, ErrInvalidSourceLength
+		}
+
+		heartbeat.Services = append(heartbeat.Services, string(data[offset:offset+serviceLength]))
+		offset += serviceLength
+	}
+
+	if offset != len(data) {
+		return nil, errors.Wrapf(ErrInvalidSourceLength, "%d trailing bytes", len(data)-offset)
+	}
+
+	return heartbeat, nil
+}
+
 func HeartbeatCaller(handler interface{}, params ...interface{}) {
 	handler.(func(heartbeat *Heartbeat))(params[0].(*Heartbeat))
 }
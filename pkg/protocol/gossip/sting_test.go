@@ -0,0 +1,267 @@
+package gossip_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo/utils"
+	"github.com/gohornet/hornet/pkg/protocol/gossip"
+	"github.com/iotaledger/hive.go/protocol/message"
+)
+
+// creates a protocol which negotiated the given features with its peer.
+func newNegotiatedProtocol(t *testing.T, features ...gossip.Feature) *gossip.Protocol {
+	capabilities := &gossip.Capabilities{
+		MinVersion: gossip.MinimumVersion,
+		MaxVersion: gossip.CurrentVersion,
+		Features:   features,
+	}
+
+	proto := gossip.NewProtocol("peer", nil, capabilities, 1000, time.Second, time.Second, &metrics.ServerMetrics{})
+
+	capabilitiesMsg, err := gossip.NewCapabilitiesMsg(capabilities)
+	require.NoError(t, err)
+	require.NoError(t, proto.HandleCapabilities(messagePayload(t, capabilitiesMsg)))
+
+	return proto
+}
+
+// returns the types and payloads of all enqueued messages.
+func drainSendQueue(t *testing.T, proto *gossip.Protocol) ([]message.Type, [][]byte) {
+	var msgTypes []message.Type
+	var payloads [][]byte
+	for len(proto.SendQueue) > 0 {
+		msg := <-proto.SendQueue
+		msgTypes = append(msgTypes, message.Type(msg[0]))
+		payloads = append(payloads, messagePayload(t, msg))
+	}
+	return msgTypes, payloads
+}
+
+func TestMessageRequestBatchMsg(t *testing.T) {
+	messageIDs := hornet.MessageIDs{utils.RandMessageID(), utils.RandMessageID(), utils.RandMessageID()}
+
+	msg, err := gossip.NewMessageRequestBatchMsg(messageIDs)
+	require.NoError(t, err)
+	require.Equal(t, byte(gossip.MessageTypeMessageRequestBatch), msg[0])
+
+	extracted, err := gossip.ExtractRequestedMessageIDs(messagePayload(t, msg))
+	require.NoError(t, err)
+	require.Equal(t, messageIDs, extracted)
+
+	_, err = gossip.NewMessageRequestBatchMsg(hornet.MessageIDs{})
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+
+	_, err = gossip.ExtractRequestedMessageIDs(make([]byte, gossip.RequestedMessageIDMsgBytesLength+1))
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, err = gossip.ExtractRequestedMessageIDs(make([]byte, gossip.RequestedMessageIDMsgBytesLength*(gossip.MaxMessageRequestBatchSize+1)))
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+}
+
+func TestMilestoneRequestRangeMsg(t *testing.T) {
+	msg, err := gossip.NewMilestoneRequestRangeMsg(100, 120)
+	require.NoError(t, err)
+	require.Equal(t, byte(gossip.MessageTypeMilestoneRequestRange), msg[0])
+
+	startIndex, endIndex, err := gossip.ExtractRequestedMilestoneRange(messagePayload(t, msg))
+	require.NoError(t, err)
+	require.Equal(t, milestone.Index(100), startIndex)
+	require.Equal(t, milestone.Index(120), endIndex)
+
+	// the latest milestone can't be requested via a range
+	_, err = gossip.NewMilestoneRequestRangeMsg(gossip.LatestMilestoneRequestIndex, 10)
+	require.ErrorIs(t, err, gossip.ErrInvalidMilestoneRange)
+	_, err = gossip.NewMilestoneRequestRangeMsg(10, 9)
+	require.ErrorIs(t, err, gossip.ErrInvalidMilestoneRange)
+	_, err = gossip.NewMilestoneRequestRangeMsg(1, gossip.MaxMilestoneRequestRangeSize+1)
+	require.ErrorIs(t, err, gossip.ErrInvalidMilestoneRange)
+
+	_, _, err = gossip.ExtractRequestedMilestoneRange([]byte{1, 0, 0, 0})
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, _, err = gossip.ExtractRequestedMilestoneRange([]byte{10, 0, 0, 0, 1, 0, 0, 0})
+	require.ErrorIs(t, err, gossip.ErrInvalidMilestoneRange)
+}
+
+func TestRequestedItemsCount(t *testing.T) {
+	msg, err := gossip.NewMessageRequestBatchMsg(hornet.MessageIDs{utils.RandMessageID(), utils.RandMessageID(), utils.RandMessageID()})
+	require.NoError(t, err)
+	require.Equal(t, 3, gossip.RequestedItemsCount(gossip.MessageTypeMessageRequestBatch, messagePayload(t, msg)))
+
+	msg, err = gossip.NewMilestoneRequestRangeMsg(100, 100+gossip.MaxMilestoneRequestRangeSize-1)
+	require.NoError(t, err)
+	require.Equal(t, gossip.MaxMilestoneRequestRangeSize, gossip.RequestedItemsCount(gossip.MessageTypeMilestoneRequestRange, messagePayload(t, msg)))
+
+	msg, err = gossip.NewMilestoneRequestMsg(100)
+	require.NoError(t, err)
+	require.Equal(t, 1, gossip.RequestedItemsCount(gossip.MessageTypeMilestoneRequest, messagePayload(t, msg)))
+
+	// invalid batched requests are counted as a single request
+	require.Equal(t, 1, gossip.RequestedItemsCount(gossip.MessageTypeMessageRequestBatch, make([]byte, gossip.RequestedMessageIDMsgBytesLength+1)))
+	require.Equal(t, 1, gossip.RequestedItemsCount(gossip.MessageTypeMilestoneRequestRange, []byte{10, 0, 0, 0, 1, 0, 0, 0}))
+}
+
+func TestExtendedHeartbeatMsg(t *testing.T) {
+	heartbeat := &gossip.Heartbeat{
+		SolidMilestoneIndex:            1000,
+		PrunedMilestoneIndex:           100,
+		LatestMilestoneIndex:           1005,
+		ConnectedNeighbors:             8,
+		SyncedNeighbors:                6,
+		Extended:                       true,
+		OldestLedgerDiffMilestoneIndex: 150,
+		SnapshotMilestoneIndex:         900,
+		ArchiveNode:                    true,
+		Services:                       []string{"indexer", "participation"},
+	}
+
+	msg, err := gossip.NewExtendedHeartbeatMsg(heartbeat)
+	require.NoError(t, err)
+	require.Equal(t, byte(gossip.MessageTypeExtendedHeartbeat), msg[0])
+
+	payload := messagePayload(t, msg)
+	parsed, err := gossip.ParseExtendedHeartbeat(payload)
+	require.NoError(t, err)
+	require.Equal(t, heartbeat, parsed)
+	require.True(t, parsed.HasService("indexer"))
+	require.False(t, parsed.HasService("faucet"))
+
+	// the fields of the base heartbeat are the prefix of the extended heartbeat
+	require.Equal(t, heartbeat.SolidMilestoneIndex, gossip.ParseHeartbeat(payload).SolidMilestoneIndex)
+
+	// neighbor counts are capped
+	msg, err = gossip.NewExtendedHeartbeatMsg(&gossip.Heartbeat{ConnectedNeighbors: 300})
+	require.NoError(t, err)
+	parsed, err = gossip.ParseExtendedHeartbeat(messagePayload(t, msg))
+	require.NoError(t, err)
+	require.Equal(t, 255, parsed.ConnectedNeighbors)
+	require.Empty(t, parsed.Services)
+
+	// unknown flags are ignored
+	flagged := append([]byte{}, payload...)
+	flagged[22] |= 0x80
+	parsed, err = gossip.ParseExtendedHeartbeat(flagged)
+	require.NoError(t, err)
+	require.True(t, parsed.ArchiveNode)
+
+	_, err = gossip.NewExtendedHeartbeatMsg(&gossip.Heartbeat{Services: make([]string, gossip.MaxHeartbeatServices+1)})
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, err = gossip.NewExtendedHeartbeatMsg(&gossip.Heartbeat{Services: []string{""}})
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+
+	_, err = gossip.ParseExtendedHeartbeat(payload[:20])
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, err = gossip.ParseExtendedHeartbeat(payload[:len(payload)-1])
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+	_, err = gossip.ParseExtendedHeartbeat(append(payload, 0))
+	require.ErrorIs(t, err, gossip.ErrInvalidSourceLength)
+}
+
+func TestHeartbeatHasDataForMilestone(t *testing.T) {
+	heartbeat := &gossip.Heartbeat{
+		SolidMilestoneIndex:  1000,
+		PrunedMilestoneIndex: 100,
+		LatestMilestoneIndex: 1005,
+	}
+
+	require.False(t, heartbeat.HasDataForMilestone(100))
+	require.True(t, heartbeat.HasDataForMilestone(101))
+	require.True(t, heartbeat.HasDataForMilestone(1000))
+	require.False(t, heartbeat.HasDataForMilestone(1001))
+	require.True(t, heartbeat.CouldHaveDataForMilestone(1005))
+	require.False(t, heartbeat.CouldHaveDataForMilestone(1006))
+
+	// the ledger diffs are not needed for cone requests
+	heartbeat.Extended = true
+	heartbeat.OldestLedgerDiffMilestoneIndex = 150
+	require.True(t, heartbeat.HasDataForMilestone(101))
+
+	// archive nodes keep the cones below their pruning index
+	heartbeat.ArchiveNode = true
+	require.True(t, heartbeat.HasDataForMilestone(1))
+	require.True(t, heartbeat.HasDataForMilestone(100))
+	require.False(t, heartbeat.HasDataForMilestone(1001))
+	require.True(t, heartbeat.CouldHaveDataForMilestone(1))
+	require.False(t, heartbeat.CouldHaveDataForMilestone(1006))
+}
+
+func TestProtocolSendMessageRequests(t *testing.T) {
+	messageIDs := make(hornet.MessageIDs, gossip.MaxMessageRequestBatchSize+1)
+	for i := range messageIDs {
+		messageIDs[i] = utils.RandMessageID()
+	}
+
+	// peers without batched requests get a request per message
+	legacyProto := newNegotiatedProtocol(t)
+	legacyProto.SendMessageRequests(messageIDs)
+	msgTypes, _ := drainSendQueue(t, legacyProto)
+	require.Len(t, msgTypes, len(messageIDs))
+	for _, msgType := range msgTypes {
+		require.Equal(t, gossip.MessageTypeMessageRequest, msgType)
+	}
+
+	// peers with batched requests get full batches, a single remaining request is sent on its own
+	proto := newNegotiatedProtocol(t, gossip.FeatureBatchedRequests)
+	proto.SendMessageRequests(messageIDs)
+	msgTypes, payloads := drainSendQueue(t, proto)
+	require.Equal(t, []message.Type{gossip.MessageTypeMessageRequestBatch, gossip.MessageTypeMessageRequest}, msgTypes)
+
+	batchMessageIDs, err := gossip.ExtractRequestedMessageIDs(payloads[0])
+	require.NoError(t, err)
+	require.Equal(t, messageIDs[:gossip.MaxMessageRequestBatchSize], batchMessageIDs)
+	require.Equal(t, messageIDs[gossip.MaxMessageRequestBatchSize], hornet.MessageIDFromSlice(payloads[1]))
+}
+
+func TestProtocolSendMilestoneRequests(t *testing.T) {
+	indexes := []milestone.Index{12, 10, 11, 11, 20}
+	for i := milestone.Index(0); i < gossip.MaxMilestoneRequestRangeSize+1; i++ {
+		indexes = append(indexes, 100+i)
+	}
+
+	// peers without batched requests get a request per milestone
+	legacyProto := newNegotiatedProtocol(t)
+	legacyProto.SendMilestoneRequests(indexes)
+	msgTypes, _ := drainSendQueue(t, legacyProto)
+	require.Len(t, msgTypes, len(indexes))
+
+	// peers with batched requests get ranges of consecutive milestones
+	proto := newNegotiatedProtocol(t, gossip.FeatureBatchedRequests)
+	proto.SendMilestoneRequests(indexes)
+	msgTypes, payloads := drainSendQueue(t, proto)
+	require.Equal(t, []message.Type{
+		gossip.MessageTypeMilestoneRequestRange,
+		gossip.MessageTypeMilestoneRequest,
+		gossip.MessageTypeMilestoneRequestRange,
+		gossip.MessageTypeMilestoneRequest,
+	}, msgTypes)
+
+	type msRange struct {
+		start milestone.Index
+		end   milestone.Index
+	}
+
+	var ranges []msRange
+	for i, payload := range payloads {
+		if msgTypes[i] == gossip.MessageTypeMilestoneRequest {
+			index, err := gossip.ExtractRequestedMilestoneIndex(payload)
+			require.NoError(t, err)
+			ranges = append(ranges, msRange{index, index})
+			continue
+		}
+
+		start, end, err := gossip.ExtractRequestedMilestoneRange(payload)
+		require.NoError(t, err)
+		ranges = append(ranges, msRange{start, end})
+	}
+
+	require.Equal(t, []msRange{
+		{10, 12},
+		{20, 20},
+		{100, 100 + gossip.MaxMilestoneRequestRangeSize - 1},
+		{100 + gossip.MaxMilestoneRequestRangeSize, 100 + gossip.MaxMilestoneRequestRangeSize},
+	}, ranges)
+}
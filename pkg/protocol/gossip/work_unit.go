@@ -96,14 +96,15 @@ func (wu *WorkUnit) addReceivedFrom(p *Protocol) {
 	wu.receivedFrom = append(wu.receivedFrom, p)
 }
 
-// punishes, respectively increases the invalid message metric of all peers
-// which sent the given underlying message of this WorkUnit.
+// punishes, respectively increases the invalid message metric and decreases the score by the given penalty
+// of all peers which sent the given underlying message of this WorkUnit.
 // it also closes the connection to these peers.
-func (wu *WorkUnit) punish(reason error) {
+func (wu *WorkUnit) punish(penalty int64, reason error) {
 	wu.receivedFromLock.Lock()
 	defer wu.receivedFromLock.Unlock()
 	for _, p := range wu.receivedFrom {
 		wu.messageProcessor.serverMetrics.InvalidMessages.Inc()
+		wu.messageProcessor.adjustScore(p, -penalty, reason)
 
 		// drop the connection to the peer
 		_ = wu.messageProcessor.peeringManager.DisconnectPeer(p.PeerID, errors.WithMessagef(reason, "peer was punished"))
@@ -0,0 +1,240 @@
+package eventstream
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// TopicMilestoneConfirmed is the topic of the events about newly confirmed milestones.
+	TopicMilestoneConfirmed = "milestones/confirmed"
+	// TopicMessageReferenced is the topic of the events about messages that were referenced by a milestone.
+	TopicMessageReferenced = "messages/referenced"
+	// TopicOutputs is the topic of the events about outputs that were created or consumed by a milestone.
+	TopicOutputs = "outputs"
+	// TopicTreasury is the topic of the events about treasury mutations.
+	TopicTreasury = "treasury"
+	// TopicReceipts is the topic of the events about new receipts.
+	TopicReceipts = "receipts"
+	// TopicDropped is the topic of the notifications about events that were dropped
+	// because the subscriber did not keep up with the event rate.
+	TopicDropped = "dropped"
+)
+
+var (
+	// ErrSubscriptionLimitReached is returned if the maximum amount of subscriptions of a broker is reached.
+	ErrSubscriptionLimitReached = errors.New("maximum amount of event stream subscriptions reached")
+)
+
+var (
+	// Topics contains all topics a subscriber can subscribe to.
+	Topics = []string{
+		TopicMilestoneConfirmed,
+		TopicMessageReferenced,
+		TopicOutputs,
+		TopicTreasury,
+		TopicReceipts,
+	}
+)
+
+// Attribute is a property of an event that can be used to filter the events of a subscription.
+type Attribute byte
+
+const (
+	// AttributeMessageID is the hex encoded message ID an event relates to.
+	AttributeMessageID Attribute = iota
+	// AttributeOutputID is the hex encoded output ID an event relates to.
+	AttributeOutputID
+	// AttributeTag is the hex encoded tag of the tagged data payload an event relates to.
+	AttributeTag
+	// AttributeAddress is the bech32 encoded address an event relates to.
+	AttributeAddress
+)
+
+var (
+	// the attributes that are carried by the events of a topic.
+	// filters on attributes that are not carried by a topic do not apply to its events.
+	topicAttributes = map[string]map[Attribute]struct{}{
+		TopicMilestoneConfirmed: {AttributeMessageID: {}},
+		TopicMessageReferenced:  {AttributeMessageID: {}, AttributeTag: {}},
+		TopicOutputs:            {AttributeMessageID: {}, AttributeOutputID: {}, AttributeAddress: {}},
+	}
+)
+
+// Event is an event that is distributed to the subscribers of a Broker.
+type Event struct {
+	// The topic of the event.
+	Topic string
+	// The values of the attributes of the event.
+	// Events that lack an attribute carried by their topic don't pass filters on that attribute.
+	Attributes map[Attribute][]string
+	// The payload of the event which is sent to the subscribers.
+	Data interface{}
+}
+
+// Filter decides which events are delivered to a subscription.
+type Filter struct {
+	topics     map[string]struct{}
+	attributes map[Attribute][]string
+}
+
+// NewFilter creates a new Filter which matches all events of the given topics.
+func NewFilter(topics ...string) *Filter {
+	f := &Filter{
+		topics:     make(map[string]struct{}),
+		attributes: make(map[Attribute][]string),
+	}
+	for _, topic := range topics {
+		f.topics[topic] = struct{}{}
+	}
+	return f
+}
+
+// AddAttributeValue restricts the events of the filter to the ones that contain the given attribute value.
+// If multiple values are added for the same attribute, an event needs to match one of them.
+// Tags are matched by prefix, all other attributes need to be equal.
+func (f *Filter) AddAttributeValue(attribute Attribute, value string) {
+	f.attributes[attribute] = append(f.attributes[attribute], strings.ToLower(value))
+}
+
+// Matches returns whether the given event passes the filter.
+func (f *Filter) Matches(event *Event) bool {
+	if _, ok := f.topics[event.Topic]; !ok {
+		return false
+	}
+
+	for attribute, filterValues := range f.attributes {
+		if _, ok := topicAttributes[event.Topic][attribute]; !ok {
+			// the events of this topic don't carry this attribute
+			continue
+		}
+
+		if !attributeMatches(attribute, filterValues, event.Attributes[attribute]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func attributeMatches(attribute Attribute, filterValues []string, eventValues []string) bool {
+	for _, eventValue := range eventValues {
+		eventValue = strings.ToLower(eventValue)
+		for _, filterValue := range filterValues {
+			if attribute == AttributeTag {
+				if strings.HasPrefix(eventValue, filterValue) {
+					return true
+				}
+				continue
+			}
+
+			if eventValue == filterValue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Subscription receives the events of a Broker that pass its filter.
+type Subscription struct {
+	filter  *Filter
+	events  chan *Event
+	dropped uint64
+}
+
+// Events returns the channel on which the events of the subscription are delivered.
+// The channel is closed if the subscription is removed from the broker.
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// TakeDropped returns the amount of events that were dropped since the last call,
+// because the buffer of the subscription was full.
+func (s *Subscription) TakeDropped() uint64 {
+	return atomic.SwapUint64(&s.dropped, 0)
+}
+
+// Broker distributes events to its subscriptions.
+// Events are never blocking the publisher, if the buffer of a subscription is full, the event is dropped for that subscription.
+type Broker struct {
+	subscriptionsLock sync.RWMutex
+	subscriptions     map[*Subscription]struct{}
+	maxSubscriptions  int
+}
+
+// NewBroker creates a new Broker which accepts up to maxSubscriptions subscriptions.
+// 0 disables the limit.
+func NewBroker(maxSubscriptions int) *Broker {
+	return &Broker{
+		subscriptions:    make(map[*Subscription]struct{}),
+		maxSubscriptions: maxSubscriptions,
+	}
+}
+
+// Subscribe creates a new subscription with the given filter and buffer size.
+// It returns ErrSubscriptionLimitReached if the broker doesn't accept more subscriptions.
+func (b *Broker) Subscribe(filter *Filter, bufferSize int) (*Subscription, error) {
+	b.subscriptionsLock.Lock()
+	defer b.subscriptionsLock.Unlock()
+
+	if b.maxSubscriptions > 0 && len(b.subscriptions) >= b.maxSubscriptions {
+		return nil, ErrSubscriptionLimitReached
+	}
+
+	subscription := &Subscription{
+		filter: filter,
+		events: make(chan *Event, bufferSize),
+	}
+
+	b.subscriptions[subscription] = struct{}{}
+	return subscription, nil
+}
+
+// Unsubscribe removes the given subscription from the broker and closes its events channel.
+func (b *Broker) Unsubscribe(subscription *Subscription) {
+	b.subscriptionsLock.Lock()
+	defer b.subscriptionsLock.Unlock()
+
+	if _, exists := b.subscriptions[subscription]; !exists {
+		return
+	}
+
+	delete(b.subscriptions, subscription)
+	close(subscription.events)
+}
+
+// HasSubscribers returns whether there is at least one subscription for the given topic.
+// This can be used to skip the creation of expensive events.
+func (b *Broker) HasSubscribers(topic string) bool {
+	b.subscriptionsLock.RLock()
+	defer b.subscriptionsLock.RUnlock()
+
+	for subscription := range b.subscriptions {
+		if _, ok := subscription.filter.topics[topic]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish delivers the given event to all subscriptions with a matching filter.
+func (b *Broker) Publish(event *Event) {
+	b.subscriptionsLock.RLock()
+	defer b.subscriptionsLock.RUnlock()
+
+	for subscription := range b.subscriptions {
+		if !subscription.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case subscription.events <- event:
+		default:
+			// the subscriber doesn't keep up, drop the event
+			atomic.AddUint64(&subscription.dropped, 1)
+		}
+	}
+}
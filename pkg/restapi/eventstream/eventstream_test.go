@@ -0,0 +1,108 @@
+package eventstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+
+	milestoneEvent := &Event{Topic: TopicMilestoneConfirmed}
+
+	messageEvent := &Event{
+		Topic: TopicMessageReferenced,
+		Attributes: map[Attribute][]string{
+			AttributeMessageID: {"0xaabb"},
+			AttributeTag:       {"0x68656c6c6f"},
+		},
+	}
+
+	untaggedMessageEvent := &Event{
+		Topic: TopicMessageReferenced,
+		Attributes: map[Attribute][]string{
+			AttributeMessageID: {"0xccdd"},
+			AttributeTag:       {},
+		},
+	}
+
+	filter := NewFilter(TopicMilestoneConfirmed, TopicMessageReferenced)
+	require.True(t, filter.Matches(milestoneEvent))
+	require.True(t, filter.Matches(messageEvent))
+	require.True(t, filter.Matches(untaggedMessageEvent))
+	require.False(t, filter.Matches(&Event{Topic: TopicOutputs}))
+
+	// tags are matched by prefix, topics without the attribute are not filtered
+	filter.AddAttributeValue(AttributeTag, "0x6865")
+	require.True(t, filter.Matches(milestoneEvent))
+	require.True(t, filter.Matches(messageEvent))
+	require.False(t, filter.Matches(untaggedMessageEvent))
+
+	// message IDs need to be equal
+	filter = NewFilter(TopicMessageReferenced)
+	filter.AddAttributeValue(AttributeMessageID, "0xAABB")
+	filter.AddAttributeValue(AttributeMessageID, "0x1122")
+	require.True(t, filter.Matches(messageEvent))
+	require.False(t, filter.Matches(untaggedMessageEvent))
+
+	filter = NewFilter(TopicMessageReferenced)
+	filter.AddAttributeValue(AttributeMessageID, "0xaa")
+	require.False(t, filter.Matches(messageEvent))
+
+	// events that lack an attribute of their topic don't pass the filter,
+	// topics that don't carry the attribute are not filtered
+	filter = NewFilter(TopicMessageReferenced, TopicTreasury)
+	filter.AddAttributeValue(AttributeTag, "0x6865")
+	require.False(t, filter.Matches(&Event{Topic: TopicMessageReferenced}))
+	require.True(t, filter.Matches(&Event{Topic: TopicTreasury}))
+}
+
+func TestBrokerBackpressure(t *testing.T) {
+
+	broker := NewBroker(0)
+	require.False(t, broker.HasSubscribers(TopicMilestoneConfirmed))
+
+	subscription, err := broker.Subscribe(NewFilter(TopicMilestoneConfirmed), 2)
+	require.NoError(t, err)
+	require.True(t, broker.HasSubscribers(TopicMilestoneConfirmed))
+	require.False(t, broker.HasSubscribers(TopicOutputs))
+
+	for i := 0; i < 5; i++ {
+		broker.Publish(&Event{Topic: TopicMilestoneConfirmed, Data: i})
+	}
+	broker.Publish(&Event{Topic: TopicOutputs})
+
+	require.Len(t, subscription.Events(), 2)
+	require.Equal(t, uint64(3), subscription.TakeDropped())
+	require.Zero(t, subscription.TakeDropped())
+
+	require.Equal(t, 0, (<-subscription.Events()).Data)
+	require.Equal(t, 1, (<-subscription.Events()).Data)
+
+	broker.Unsubscribe(subscription)
+	require.False(t, broker.HasSubscribers(TopicMilestoneConfirmed))
+
+	_, ok := <-subscription.Events()
+	require.False(t, ok)
+
+	// unsubscribing twice must not panic
+	broker.Unsubscribe(subscription)
+}
+
+func TestBrokerSubscriptionLimit(t *testing.T) {
+
+	broker := NewBroker(2)
+
+	first, err := broker.Subscribe(NewFilter(TopicMilestoneConfirmed), 1)
+	require.NoError(t, err)
+	_, err = broker.Subscribe(NewFilter(TopicOutputs), 1)
+	require.NoError(t, err)
+
+	_, err = broker.Subscribe(NewFilter(TopicReceipts), 1)
+	require.ErrorIs(t, err, ErrSubscriptionLimitReached)
+
+	// removed subscriptions free their slot
+	broker.Unsubscribe(first)
+	_, err = broker.Subscribe(NewFilter(TopicReceipts), 1)
+	require.NoError(t, err)
+}
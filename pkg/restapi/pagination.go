@@ -0,0 +1,135 @@
+package restapi
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// DefaultMaxPageSize is the maximum page size used if no valid limit is configured.
+	DefaultMaxPageSize = 1000
+)
+
+// PaginationResponse contains the pagination information of a list response.
+// It is embedded into the responses of all paginated list routes.
+type PaginationResponse struct {
+	// The maximum count of results that are returned by the node.
+	PageSize int `json:"pageSize"`
+	// The cursor to request the next page of results, omitted if there are no more results.
+	Cursor *string `json:"cursor,omitempty"`
+}
+
+// Paginator collects the elements of a single page of a list.
+// Every element of a list is positioned by a cursor, which is derived from the
+// database key of the element. Elements have to be added in lexical order of their cursors.
+type Paginator struct {
+	// The cursor of the first element of the requested page.
+	Cursor []byte
+	// The maximum amount of elements of the page.
+	PageSize int
+
+	count      int
+	nextCursor []byte
+}
+
+// NewPaginator creates a new Paginator.
+func NewPaginator(cursor []byte, pageSize int) *Paginator {
+	return &Paginator{
+		Cursor:   cursor,
+		PageSize: pageSize,
+	}
+}
+
+// ParsePaginationQueryParams parses the page size and cursor query parameters and returns a Paginator.
+// If no page size is given or it exceeds maxPageSize, maxPageSize is used.
+func ParsePaginationQueryParams(c echo.Context, maxPageSize int) (*Paginator, error) {
+	pageSize, err := ParsePageSizeQueryParam(c, maxPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := ParseCursorQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPaginator(cursor, pageSize), nil
+}
+
+// Skip returns whether the element with the given cursor is positioned before the requested page.
+// This is only needed if the underlying iteration does not support starting at a cursor.
+func (p *Paginator) Skip(cursor []byte) bool {
+	return p.Cursor != nil && bytes.Compare(cursor, p.Cursor) < 0
+}
+
+// Add adds the element with the given cursor to the page.
+// It returns false if the page is already full. In that case the cursor
+// is remembered as the start of the next page and the iteration should be stopped.
+func (p *Paginator) Add(cursor []byte) bool {
+	if p.count >= p.PageSize {
+		// the cursor may be reused by the underlying iteration, so we need to copy it
+		p.nextCursor = append([]byte{}, cursor...)
+		return false
+	}
+	p.count++
+	return true
+}
+
+// Response returns the pagination information of the collected page.
+func (p *Paginator) Response() PaginationResponse {
+	var cursor *string
+	if p.nextCursor != nil {
+		encodedCursor := iotago.EncodeHex(p.nextCursor)
+		cursor = &encodedCursor
+	}
+
+	return PaginationResponse{
+		PageSize: p.PageSize,
+		Cursor:   cursor,
+	}
+}
+
+// ParsePageSizeQueryParam parses the page size query parameter.
+// If the parameter is not given or exceeds maxPageSize, maxPageSize is returned.
+// If maxPageSize is not set, DefaultMaxPageSize is used instead.
+func ParsePageSizeQueryParam(c echo.Context, maxPageSize int) (int, error) {
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+
+	pageSizeParam := c.QueryParam(QueryParameterPageSize)
+	if len(pageSizeParam) == 0 {
+		return maxPageSize, nil
+	}
+
+	pageSize, err := strconv.ParseUint(pageSizeParam, 10, 32)
+	if err != nil || pageSize == 0 {
+		return 0, errors.WithMessagef(ErrInvalidParameter, "invalid page size: %s", pageSizeParam)
+	}
+
+	if int(pageSize) > maxPageSize {
+		return maxPageSize, nil
+	}
+	return int(pageSize), nil
+}
+
+// ParseCursorQueryParam parses the hex encoded cursor query parameter.
+// It returns nil if no cursor was given.
+func ParseCursorQueryParam(c echo.Context) ([]byte, error) {
+	cursorParam := strings.ToLower(c.QueryParam(QueryParameterCursor))
+	if len(cursorParam) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := iotago.DecodeHex(cursorParam)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidParameter, "invalid cursor: %s, error: %s", cursorParam, err)
+	}
+	return cursor, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -40,6 +41,42 @@ const (
 
 	// QueryParameterOutputType is used to filter for a certain output type.
 	QueryParameterOutputType = "type"
+
+	// QueryParameterAddressRole is used to filter for a certain role of an address in the unlock conditions of an output.
+	QueryParameterAddressRole = "role"
+
+	// QueryParameterPageSize is used to define the page size for the results.
+	QueryParameterPageSize = "pageSize"
+
+	// QueryParameterCursor is used to pass the offset we want to start the next results from.
+	QueryParameterCursor = "cursor"
+
+	// QueryParameterTopics is used to define the topics of an event stream subscription (comma separated).
+	QueryParameterTopics = "topics"
+
+	// QueryParameterMessageID is used to filter for a certain message ID.
+	QueryParameterMessageID = "messageId"
+
+	// QueryParameterOutputID is used to filter for a certain output ID.
+	QueryParameterOutputID = "outputId"
+
+	// QueryParameterTag is used to filter for a certain tag prefix.
+	QueryParameterTag = "tag"
+
+	// QueryParameterAddress is used to filter for a certain bech32 address.
+	QueryParameterAddress = "address"
+
+	// QueryParameterAtMilestone is used to query the ledger state at a past milestone index.
+	QueryParameterAtMilestone = "atMilestone"
+
+	// QueryParameterIndex is used to define a milestone index.
+	QueryParameterIndex = "index"
+
+	// QueryParameterDepth is used to define a depth in milestones.
+	QueryParameterDepth = "depth"
+
+	// QueryParameterTargetDatabaseSize is used to define the target size of the database.
+	QueryParameterTargetDatabaseSize = "targetDatabaseSize"
 )
 
 var (
@@ -224,3 +261,47 @@ func ParseOutputTypeQueryParam(c echo.Context) (*iotago.OutputType, error) {
 	}
 	return filteredType, nil
 }
+
+// ParseAtMilestoneQueryParam parses the optional milestone index of a historic ledger query.
+func ParseAtMilestoneQueryParam(c echo.Context) (*milestone.Index, error) {
+	atMilestoneParam := c.QueryParam(QueryParameterAtMilestone)
+	if atMilestoneParam == "" {
+		return nil, nil
+	}
+
+	msIndex, err := strconv.ParseUint(atMilestoneParam, 10, 32)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidParameter, "invalid milestone index: %s, error: %s", atMilestoneParam, err)
+	}
+
+	atMilestone := milestone.Index(msIndex)
+	return &atMilestone, nil
+}
+
+func ParseMilestoneIndexQueryParam(c echo.Context, paramName string) (*milestone.Index, error) {
+	milestoneIndexParam := c.QueryParam(paramName)
+	if milestoneIndexParam == "" {
+		return nil, nil
+	}
+
+	msIndex, err := strconv.ParseUint(milestoneIndexParam, 10, 32)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidParameter, "invalid %s: %s, error: %s", paramName, milestoneIndexParam, err)
+	}
+
+	index := milestone.Index(msIndex)
+	return &index, nil
+}
+
+func ParseAddressRoleQueryParam(c echo.Context) (*utxo.AddressRole, error) {
+	roleParam := c.QueryParam(QueryParameterAddressRole)
+	if len(roleParam) == 0 {
+		return nil, nil
+	}
+
+	role, err := utxo.AddressRoleFromString(roleParam)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrInvalidParameter, "invalid role: %s, error: %s", roleParam, err)
+	}
+	return &role, nil
+}
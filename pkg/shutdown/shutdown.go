@@ -19,6 +19,7 @@ const (
 	PriorityRequestsProcessor // depends on PriorityGossipService
 	PriorityBroadcastQueue    // depends on PriorityGossipService
 	PriorityP2PManager
+	PriorityPeeringConfigReloader // depends on PriorityP2PManager
 	PriorityAutopeering
 	PriorityHeartbeats // depends on PriorityGossipService
 	PriorityWarpSync
@@ -37,4 +38,5 @@ const (
 	PriorityCoordinator // depends on PriorityPoWHandler
 	PriorityUpdateCheck
 	PriorityPrometheus
+	PrioritySnapshotServer
 )
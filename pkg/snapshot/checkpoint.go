@@ -0,0 +1,172 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/database"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/gohornet/hornet/pkg/utils"
+	hiveutils "github.com/iotaledger/hive.go/kvstore/utils"
+)
+
+const (
+	// CheckpointTangleDirectoryName is the name of the tangle database folder inside a checkpoint.
+	CheckpointTangleDirectoryName = "tangle"
+	// CheckpointUTXODirectoryName is the name of the UTXO database folder inside a checkpoint.
+	CheckpointUTXODirectoryName = "utxo"
+	// CheckpointInfoFileName is the name of the file inside a checkpoint that contains the checkpoint info.
+	CheckpointInfoFileName = "checkpoint.json"
+)
+
+var (
+	// ErrCheckpointTargetExists is returned when the target directory of a checkpoint already exists.
+	ErrCheckpointTargetExists = errors.New("checkpoint target directory already exists")
+)
+
+// CheckpointInfo holds the state of the databases at the time the checkpoint was created.
+type CheckpointInfo struct {
+	// The network ID of the databases.
+	NetworkID uint64 `json:"networkId"`
+	// The ledger index of the UTXO database.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+	// The index of the last snapshot.
+	SnapshotIndex milestone.Index `json:"snapshotIndex"`
+	// The index of the solid entry points.
+	EntryPointIndex milestone.Index `json:"entryPointIndex"`
+	// The index the database was pruned to.
+	PruningIndex milestone.Index `json:"pruningIndex"`
+	// The timestamp of the last snapshot.
+	SnapshotTimestamp int64 `json:"snapshotTimestamp"`
+	// The time the checkpoint was created.
+	CreatedAt int64 `json:"createdAt"`
+}
+
+// CreateDatabaseCheckpoint creates a checkpoint of the tangle and UTXO databases in the target directory.
+// The ledger is only read locked while the checkpoint of the UTXO database is created,
+// so the UTXO database in the checkpoint is consistent with the recorded ledger index.
+// The databases in the checkpoint are marked as healthy, so that a node can be started from them.
+func CreateDatabaseCheckpoint(dbStorage *storage.Storage, utxoManager *utxo.Manager, tangleDatabase *database.Database, utxoDatabase *database.Database, targetDirectory string) (*CheckpointInfo, error) {
+
+	if !tangleDatabase.CheckpointSupported() || !utxoDatabase.CheckpointSupported() {
+		return nil, database.ErrCheckpointNotSupported
+	}
+
+	targetDirectoryExists, err := hiveutils.PathExists(targetDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if targetDirectoryExists {
+		return nil, errors.Wrapf(ErrCheckpointTargetExists, "path: %s", targetDirectory)
+	}
+
+	if err := os.MkdirAll(targetDirectory, 0700); err != nil {
+		return nil, fmt.Errorf("could not create checkpoint directory: %w", err)
+	}
+
+	checkpointInfo, err := func() (*CheckpointInfo, error) {
+		ledgerIndex, err := func() (milestone.Index, error) {
+			utxoManager.ReadLockLedger()
+			defer utxoManager.ReadUnlockLedger()
+
+			ledgerIndex, err := utxoManager.ReadLedgerIndexWithoutLocking()
+			if err != nil {
+				return 0, err
+			}
+
+			if err := utxoDatabase.Checkpoint(filepath.Join(targetDirectory, CheckpointUTXODirectoryName)); err != nil {
+				return 0, fmt.Errorf("creating utxo database checkpoint failed: %w", err)
+			}
+
+			return ledgerIndex, nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		snapshotInfo := dbStorage.SnapshotInfo()
+		if snapshotInfo == nil {
+			return nil, errors.Wrap(ErrCritical, "no snapshot info found")
+		}
+
+		// the cached objects have to be written to the tangle database to be part of the checkpoint.
+		// the tangle database may already contain data of newer milestones than the ledger index of the checkpoint.
+		dbStorage.FlushStorages()
+
+		if err := tangleDatabase.Checkpoint(filepath.Join(targetDirectory, CheckpointTangleDirectoryName)); err != nil {
+			return nil, fmt.Errorf("creating tangle database checkpoint failed: %w", err)
+		}
+
+		return &CheckpointInfo{
+			NetworkID:         snapshotInfo.NetworkID,
+			LedgerIndex:       ledgerIndex,
+			SnapshotIndex:     snapshotInfo.SnapshotIndex,
+			EntryPointIndex:   snapshotInfo.EntryPointIndex,
+			PruningIndex:      snapshotInfo.PruningIndex,
+			SnapshotTimestamp: snapshotInfo.Timestamp.Unix(),
+			CreatedAt:         time.Now().Unix(),
+		}, nil
+	}()
+	if err != nil {
+		// remove the incomplete checkpoint
+		_ = os.RemoveAll(targetDirectory)
+		return nil, err
+	}
+
+	// the databases of a running node are marked as corrupted, which is copied to the checkpoint.
+	for _, directoryName := range []string{CheckpointTangleDirectoryName, CheckpointUTXODirectoryName} {
+		if err := markCheckpointDatabaseHealthy(filepath.Join(targetDirectory, directoryName), tangleDatabase.Engine()); err != nil {
+			_ = os.RemoveAll(targetDirectory)
+			return nil, fmt.Errorf("marking checkpoint database as healthy failed: %w", err)
+		}
+	}
+
+	if err := utils.WriteJSONToFile(filepath.Join(targetDirectory, CheckpointInfoFileName), checkpointInfo, 0600); err != nil {
+		_ = os.RemoveAll(targetDirectory)
+		return nil, fmt.Errorf("writing checkpoint info failed: %w", err)
+	}
+
+	return checkpointInfo, nil
+}
+
+// markCheckpointDatabaseHealthy removes the corrupted flag from the database in the given checkpoint directory.
+func markCheckpointDatabaseHealthy(databaseDirectory string, engine database.Engine) error {
+
+	checkpointDatabase, err := database.DatabaseWithDefaultSettings(databaseDirectory, false, engine)
+	if err != nil {
+		return err
+	}
+
+	healthTracker, err := storage.NewStoreHealthTracker(checkpointDatabase.KVStore())
+	if err != nil {
+		_ = checkpointDatabase.KVStore().Close()
+		return err
+	}
+
+	if err := healthTracker.MarkHealthy(); err != nil {
+		_ = checkpointDatabase.KVStore().Close()
+		return err
+	}
+
+	if err := checkpointDatabase.KVStore().Flush(); err != nil {
+		_ = checkpointDatabase.KVStore().Close()
+		return err
+	}
+
+	return checkpointDatabase.KVStore().Close()
+}
+
+// CreateDatabaseCheckpoint creates a checkpoint of the tangle and UTXO databases in the target directory.
+// Pruning and snapshot creation are blocked while the checkpoint is created.
+func (s *SnapshotManager) CreateDatabaseCheckpoint(targetDirectory string) (*CheckpointInfo, error) {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+
+	return CreateDatabaseCheckpoint(s.storage, s.utxoManager, s.tangleDatabase, s.utxoDatabase, targetDirectory)
+}
@@ -0,0 +1,109 @@
+package snapshot_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/database"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+func openTestPebbleStorage(t *testing.T, path string, createIfNotExists bool) (*storage.Storage, *database.Database, *database.Database) {
+
+	tangleDatabase, err := database.DatabaseWithDefaultSettings(filepath.Join(path, snapshot.CheckpointTangleDirectoryName), createIfNotExists, database.EnginePebble)
+	require.NoError(t, err)
+
+	utxoDatabase, err := database.DatabaseWithDefaultSettings(filepath.Join(path, snapshot.CheckpointUTXODirectoryName), createIfNotExists, database.EnginePebble)
+	require.NoError(t, err)
+
+	dbStorage, err := storage.New(tangleDatabase.KVStore(), utxoDatabase.KVStore())
+	require.NoError(t, err)
+
+	return dbStorage, tangleDatabase, utxoDatabase
+}
+
+func TestCreateDatabaseCheckpoint(t *testing.T) {
+
+	tempDir := t.TempDir()
+	checkpointPath := filepath.Join(tempDir, "checkpoint")
+
+	dbStorage, tangleDatabase, utxoDatabase := openTestPebbleStorage(t, filepath.Join(tempDir, "database"), true)
+	defer func() {
+		dbStorage.ShutdownStorages()
+		dbStorage.FlushAndCloseStores()
+	}()
+
+	snapshotTimestamp := time.Unix(1650000000, 0)
+	require.NoError(t, dbStorage.SetSnapshotMilestone(1337, 10, 10, 5, snapshotTimestamp))
+	require.NoError(t, dbStorage.UTXOManager().StoreLedgerIndex(20))
+
+	// the databases of a running node are marked as corrupted at startup
+	require.NoError(t, dbStorage.MarkDatabasesCorrupted())
+
+	checkpointInfo, err := snapshot.CreateDatabaseCheckpoint(dbStorage, dbStorage.UTXOManager(), tangleDatabase, utxoDatabase, checkpointPath)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1337), checkpointInfo.NetworkID)
+	require.Equal(t, milestone.Index(20), checkpointInfo.LedgerIndex)
+	require.Equal(t, milestone.Index(10), checkpointInfo.SnapshotIndex)
+	require.Equal(t, milestone.Index(10), checkpointInfo.EntryPointIndex)
+	require.Equal(t, milestone.Index(5), checkpointInfo.PruningIndex)
+	require.Equal(t, snapshotTimestamp.Unix(), checkpointInfo.SnapshotTimestamp)
+
+	// an existing checkpoint is not overwritten
+	_, err = snapshot.CreateDatabaseCheckpoint(dbStorage, dbStorage.UTXOManager(), tangleDatabase, utxoDatabase, checkpointPath)
+	require.ErrorIs(t, err, snapshot.ErrCheckpointTargetExists)
+
+	// changes after the checkpoint was created are not part of the checkpoint
+	require.NoError(t, dbStorage.UTXOManager().StoreLedgerIndex(21))
+
+	readCheckpointInfo := &snapshot.CheckpointInfo{}
+	require.NoError(t, utils.ReadJSONFromFile(filepath.Join(checkpointPath, snapshot.CheckpointInfoFileName), readCheckpointInfo))
+	require.Equal(t, checkpointInfo, readCheckpointInfo)
+
+	checkpointStorage, _, _ := openTestPebbleStorage(t, checkpointPath, false)
+	defer func() {
+		checkpointStorage.ShutdownStorages()
+		checkpointStorage.FlushAndCloseStores()
+	}()
+
+	// a node can be started from the checkpoint without failing the corruption check
+	corrupted, err := checkpointStorage.AreDatabasesCorrupted()
+	require.NoError(t, err)
+	require.False(t, corrupted)
+
+	corrupted, err = dbStorage.AreDatabasesCorrupted()
+	require.NoError(t, err)
+	require.True(t, corrupted)
+
+	ledgerIndex, err := checkpointStorage.UTXOManager().ReadLedgerIndex()
+	require.NoError(t, err)
+	require.Equal(t, milestone.Index(20), ledgerIndex)
+
+	snapshotInfo := checkpointStorage.SnapshotInfo()
+	require.NotNil(t, snapshotInfo)
+	require.Equal(t, uint64(1337), snapshotInfo.NetworkID)
+	require.Equal(t, milestone.Index(5), snapshotInfo.PruningIndex)
+}
+
+func TestCreateDatabaseCheckpointNotSupported(t *testing.T) {
+
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint")
+
+	tangleDatabase, err := database.DatabaseWithDefaultSettings("", true, database.EngineMapDB)
+	require.NoError(t, err)
+	utxoDatabase, err := database.DatabaseWithDefaultSettings("", true, database.EngineMapDB)
+	require.NoError(t, err)
+
+	dbStorage, err := storage.New(tangleDatabase.KVStore(), utxoDatabase.KVStore())
+	require.NoError(t, err)
+
+	_, err = snapshot.CreateDatabaseCheckpoint(dbStorage, dbStorage.UTXOManager(), tangleDatabase, utxoDatabase, checkpointPath)
+	require.ErrorIs(t, err, database.ErrCheckpointNotSupported)
+	require.NoDirExists(t, checkpointPath)
+}
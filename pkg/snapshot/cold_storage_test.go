@@ -0,0 +1,78 @@
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func TestPruningWithColdStorage(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	coneMessageIDs := make(map[milestone.Index]hornet.MessageIDs)
+	_, _ = te.BuildTangle(10, BelowMaxDepth, 20, 5, 10,
+		nil,
+		func(messages hornet.MessageIDs, messagesPerMilestones []hornet.MessageIDs) hornet.MessageIDs {
+			return hornet.MessageIDs{messages[len(messages)-1]}
+		},
+		func(msIndex milestone.Index, _ hornet.MessageIDs, conf *whiteflag.Confirmation, _ *whiteflag.ConfirmedMilestoneStats) {
+			coneMessageIDs[msIndex] = conf.Mutations.MessagesReferenced
+		},
+	)
+
+	confirmedMilestoneIndex := te.SyncManager().ConfirmedMilestoneIndex()
+	require.NoError(t, te.Storage().SetSnapshotMilestone(uint64(te.NetworkID()), confirmedMilestoneIndex, 0, 0, time.Now()))
+
+	coldStorage, err := storage.NewColdStorage(mapdb.NewMapDB(), mapdb.NewMapDB())
+	require.NoError(t, err)
+	te.Storage().SetColdStorage(coldStorage)
+
+	snapshotManager := newTestSnapshotManager(te, nil)
+
+	// consecutive pruning runs only archive and delete the cones of the newly pruned milestones
+	for _, targetIndex := range []milestone.Index{4, 10} {
+		prunedIndex, err := snapshotManager.PruneDatabaseByTargetIndex(context.Background(), targetIndex)
+		require.NoError(t, err)
+		require.Equal(t, targetIndex, prunedIndex)
+
+		for msIndex := milestone.Index(1); msIndex <= targetIndex; msIndex++ {
+			// the node database only contains the recent history
+			require.False(t, te.Storage().ContainsMilestone(msIndex))
+			require.Nil(t, te.Storage().CachedMilestoneOrNil(msIndex))
+
+			ms := te.Storage().MilestoneWithFallbackOrNil(msIndex)
+			require.NotNil(t, ms)
+			require.Equal(t, msIndex, ms.Index)
+
+			for _, messageID := range coneMessageIDs[msIndex] {
+				require.False(t, te.Storage().ContainsMessage(messageID))
+				require.Nil(t, te.Storage().CachedMessageOrNil(messageID))
+
+				metadata := te.Storage().MessageMetadataWithFallbackOrNil(messageID)
+				require.NotNil(t, metadata)
+				referenced, at := metadata.ReferencedWithIndex()
+				require.True(t, referenced)
+				require.Equal(t, msIndex, at)
+			}
+
+			diff, err := te.Storage().MilestoneDiffWithFallback(msIndex)
+			require.NoError(t, err)
+			require.Equal(t, msIndex, diff.Index)
+		}
+
+		// the milestones after the target index are not pruned
+		require.True(t, te.Storage().ContainsMilestone(targetIndex+1))
+	}
+}
@@ -1,13 +1,18 @@
 package snapshot
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -20,7 +25,17 @@ import (
 
 const (
 	timeoutDownloadSnapshotHeader = 5 * time.Second
-	timeoutDownloadSnapshotFile   = 10 * time.Minute
+	// the timeout for downloading a single chunk, respectively the idle timeout if the mirror doesn't support range requests.
+	timeoutDownloadSnapshotChunk = 2 * time.Minute
+	// the size of the chunks the snapshot files are downloaded in.
+	downloadChunkSize = 16 * 1024 * 1024
+	// the maximum amount of chunks that are downloaded in parallel or kept in memory until the preceding data was written,
+	// which limits the memory used by a download independent of the amount of mirrors.
+	downloadMaxChunksInMemory = 8
+	// the amount of failed chunk downloads in a row after which a mirror is not used anymore.
+	downloadMirrorMaxFailures = 3
+	// the suffix of the file the snapshot data is downloaded to.
+	downloadPartialFileSuffix = ".partial"
 )
 
 // WriteCounter counts the number of bytes written to it. It implements to the io.Writer interface
@@ -80,9 +95,109 @@ type DownloadTarget struct {
 	Full string `json:"full"`
 	// URL of the delta snapshot file.
 	Delta string `json:"delta"`
+	// URLs of the chained delta snapshot files, in the order they have to be applied (optional).
+	DeltaChain []string `json:"deltaChain,omitempty"`
+	// URL of the manifest of the snapshot files (optional).
+	Manifest string `json:"manifest,omitempty"`
 }
 
-func (s *SnapshotManager) filterTargets(wantedNetworkID uint64, targets []*DownloadTarget) []*DownloadTarget {
+// downloadMirrors holds the download targets that serve the same snapshot files.
+type downloadMirrors struct {
+	targets           []*DownloadTarget
+	fullHeader        *ReadFileHeader
+	deltaHeader       *ReadFileHeader
+	deltaChainHeaders []*ReadFileHeader
+}
+
+// ledgerIndex returns the final ledger index if the snapshot files of the mirrors would be applied.
+func (m *downloadMirrors) ledgerIndex() milestone.Index {
+	if len(m.deltaChainHeaders) > 0 {
+		return m.deltaChainHeaders[len(m.deltaChainHeaders)-1].SEPMilestoneIndex
+	}
+	if m.deltaHeader != nil {
+		return m.deltaHeader.SEPMilestoneIndex
+	}
+	return m.fullHeader.SEPMilestoneIndex
+}
+
+// fullURLs returns the URLs of the full snapshot file on all mirrors.
+func (m *downloadMirrors) fullURLs() []string {
+	urls := make([]string, 0, len(m.targets))
+	for _, target := range m.targets {
+		urls = append(urls, target.Full)
+	}
+	return urls
+}
+
+// deltaURLs returns the URLs of the delta snapshot file on all mirrors.
+func (m *downloadMirrors) deltaURLs() []string {
+	urls := make([]string, 0, len(m.targets))
+	for _, target := range m.targets {
+		if len(target.Delta) > 0 {
+			urls = append(urls, target.Delta)
+		}
+	}
+	return urls
+}
+
+// deltaChainURLs returns the URLs of the chained delta snapshot file with the given position on all mirrors.
+func (m *downloadMirrors) deltaChainURLs(position int) []string {
+	urls := make([]string, 0, len(m.targets))
+	for _, target := range m.targets {
+		urls = append(urls, target.DeltaChain[position])
+	}
+	return urls
+}
+
+// downloadMirror is a source of a single snapshot file.
+type downloadMirror struct {
+	url string
+	// the size of the file, -1 if unknown.
+	size int64
+	// whether the mirror supports HTTP range requests.
+	rangeSupported bool
+	// the amount of consecutive failed chunk downloads.
+	failures int
+}
+
+// snapshotHeadersEqual checks whether the given headers belong to the same snapshot file.
+func snapshotHeadersEqual(a *ReadFileHeader, b *ReadFileHeader) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Version == b.Version &&
+		a.Type == b.Type &&
+		a.NetworkID == b.NetworkID &&
+		a.SEPMilestoneIndex == b.SEPMilestoneIndex &&
+		a.LedgerMilestoneIndex == b.LedgerMilestoneIndex &&
+		a.Compression == b.Compression &&
+		a.Timestamp == b.Timestamp &&
+		a.SEPCount == b.SEPCount &&
+		a.OutputCount == b.OutputCount &&
+		a.MilestoneDiffCount == b.MilestoneDiffCount &&
+		bytes.Equal(a.Checksum, b.Checksum) &&
+		bytes.Equal(a.PreviousChecksum, b.PreviousChecksum)
+}
+
+// snapshotHeadersListEqual checks whether the given headers belong to the same snapshot files.
+func snapshotHeadersListEqual(a []*ReadFileHeader, b []*ReadFileHeader) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !snapshotHeadersEqual(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterTargets returns the consistent download targets, grouped by the snapshot files they serve.
+// Targets serving the same files are used as mirrors of each other. The latest snapshot files come first.
+func (s *SnapshotManager) filterTargets(wantedNetworkID uint64, targets []*DownloadTarget) []*downloadMirrors {
 
 	// check if the remote snapshot files fit the network ID and if delta fits the full snapshot.
 	checkTargetConsistency := func(wantedNetworkID uint64, fullHeader *ReadFileHeader, deltaHeader *ReadFileHeader) error {
@@ -111,15 +226,38 @@ func (s *SnapshotManager) filterTargets(wantedNetworkID uint64, targets []*Downl
 			return fmt.Errorf("full snapshot SEP index does not match the delta snapshot ledger index (%d != %d): %w", fullHeader.SEPMilestoneIndex, deltaHeader.LedgerMilestoneIndex, ErrInvalidSnapshotAvailabilityState)
 		}
 
+		if err := VerifyDeltaSnapshotLink(fullHeader, deltaHeader); err != nil {
+			return fmt.Errorf("delta snapshot does not fit the full snapshot (%s): %w", err, ErrInvalidSnapshotAvailabilityState)
+		}
+
 		return nil
 	}
 
-	type downloadTargetWithIndex struct {
-		target *DownloadTarget
-		index  milestone.Index
+	// downloads the headers of the chained delta snapshot files of the target.
+	// the chain ends at the first file that is not available or that doesn't fit its predecessor.
+	downloadDeltaChainHeaders := func(target *DownloadTarget, previousHeader *ReadFileHeader) []*ReadFileHeader {
+		var deltaChainHeaders []*ReadFileHeader
+		for _, url := range target.DeltaChain {
+			s.LogDebugf("downloading chained delta snapshot header from %s", url)
+			deltaChainHeader, err := s.downloadHeader(url)
+			if err != nil {
+				s.LogDebugf("downloading chained delta snapshot header from %s failed: %s", url, err)
+				break
+			}
+
+			if err := VerifyDeltaSnapshotLink(previousHeader, deltaChainHeader); err != nil {
+				s.LogInfof("chained delta snapshot %s does not fit the preceding snapshot file: %s", url, err)
+				break
+			}
+
+			deltaChainHeaders = append(deltaChainHeaders, deltaChainHeader)
+			previousHeader = deltaChainHeader
+		}
+
+		return deltaChainHeaders
 	}
 
-	filteredTargets := []*downloadTargetWithIndex{}
+	filteredMirrors := []*downloadMirrors{}
 
 	// search the latest snapshot by scanning all target headers
 	for _, target := range targets {
@@ -148,50 +286,146 @@ func (s *SnapshotManager) filterTargets(wantedNetworkID uint64, targets []*Downl
 			continue
 		}
 
-		filteredTargets = append(filteredTargets, &downloadTargetWithIndex{
-			target: target,
-			index:  getSnapshotFilesLedgerIndex(fullHeader, deltaHeader),
-		})
+		var deltaChainHeaders []*ReadFileHeader
+		if len(target.DeltaChain) > 0 {
+			previousHeader := fullHeader
+			if deltaHeader != nil {
+				previousHeader = deltaHeader
+			}
+			deltaChainHeaders = downloadDeltaChainHeaders(target, previousHeader)
+		}
+
+		mirrorFound := false
+		for _, mirrors := range filteredMirrors {
+			if snapshotHeadersEqual(mirrors.fullHeader, fullHeader) && snapshotHeadersEqual(mirrors.deltaHeader, deltaHeader) && snapshotHeadersListEqual(mirrors.deltaChainHeaders, deltaChainHeaders) {
+				mirrors.targets = append(mirrors.targets, target)
+				mirrorFound = true
+				break
+			}
+		}
+
+		if !mirrorFound {
+			filteredMirrors = append(filteredMirrors, &downloadMirrors{
+				targets:           []*DownloadTarget{target},
+				fullHeader:        fullHeader,
+				deltaHeader:       deltaHeader,
+				deltaChainHeaders: deltaChainHeaders,
+			})
+		}
 	}
 
 	// sort by snapshot index, latest index first
-	sort.Slice(filteredTargets, func(i int, j int) bool {
-		return filteredTargets[i].index > filteredTargets[j].index
+	sort.SliceStable(filteredMirrors, func(i int, j int) bool {
+		return filteredMirrors[i].ledgerIndex() > filteredMirrors[j].ledgerIndex()
 	})
 
-	results := make([]*DownloadTarget, len(filteredTargets))
-	for i := 0; i < len(filteredTargets); i++ {
-		results[i] = filteredTargets[i].target
-	}
-
-	return results
+	return filteredMirrors
 }
 
 // DownloadSnapshotFiles tries to download snapshots files from the given targets.
+// The files are downloaded in chunks from all targets that serve the same files,
+// interrupted downloads are resumed and the files are verified against the published manifest.
+// Chained delta snapshot files are stored in the delta snapshot chain directory of the given delta path.
+// Files of the delta snapshot chain that already exist are not downloaded again.
 func (s *SnapshotManager) DownloadSnapshotFiles(ctx context.Context, wantedNetworkID uint64, fullPath string, deltaPath string, targets []*DownloadTarget) error {
 
-	for _, target := range s.filterTargets(wantedNetworkID, targets) {
+	for _, mirrors := range s.filterTargets(wantedNetworkID, targets) {
+
+		manifest, err := s.manifestForMirrors(mirrors)
+		if err != nil {
+			s.LogWarnf("skipping snapshot files from %s: %s", strings.Join(mirrors.fullURLs(), ", "), err)
+			continue
+		}
+
+		var expectedFull, expectedDelta *SnapshotManifestFile
+		var expectedDeltaChain []*SnapshotManifestFile
+		if manifest != nil {
+			expectedFull = manifest.Full
+			expectedDelta = manifest.Delta
+			expectedDeltaChain = manifest.DeltaChain
+		}
 
-		s.LogInfof("downloading full snapshot file from %s", target.Full)
-		if err := s.downloadFile(ctx, fullPath, target.Full); err != nil {
+		s.LogInfof("downloading full snapshot file from %s", strings.Join(mirrors.fullURLs(), ", "))
+		if err := s.downloadFile(ctx, fullPath, mirrors.fullURLs(), mirrors.fullHeader, expectedFull); err != nil {
+			if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+				return err
+			}
 			s.LogWarn(err)
 			// as the full snapshot URL failed to download, we commence further with our targets
 			continue
 		}
 
-		if len(target.Delta) > 0 {
-			s.LogInfof("downloading delta snapshot file from %s", target.Delta)
-			if err := s.downloadFile(ctx, deltaPath, target.Delta); err != nil {
+		if mirrors.deltaHeader != nil {
+			s.LogInfof("downloading delta snapshot file from %s", strings.Join(mirrors.deltaURLs(), ", "))
+			if err := s.downloadFile(ctx, deltaPath, mirrors.deltaURLs(), mirrors.deltaHeader, expectedDelta); err != nil {
+				if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+					return err
+				}
 				// it is valid that no delta snapshot file is available on the target.
 				s.LogWarn(err)
+				return nil
 			}
 		}
+
+		if err := s.downloadDeltaChain(ctx, deltaPath, mirrors, expectedDeltaChain); err != nil {
+			if errors.Is(err, ErrSnapshotDownloadWasAborted) {
+				return err
+			}
+			// the chain is only applied up to the last downloaded file.
+			s.LogWarn(err)
+		}
 		return nil
 	}
 
 	return ErrSnapshotDownloadNoValidSource
 }
 
+// downloads the chained delta snapshot files of the given mirrors into the delta snapshot chain directory.
+// existing files of another chain are removed, existing files of the same chain are kept.
+func (s *SnapshotManager) downloadDeltaChain(ctx context.Context, deltaPath string, mirrors *downloadMirrors, expectedDeltaChain []*SnapshotManifestFile) error {
+
+	existingDeltaChainPaths, err := DeltaChainFilePaths(deltaPath)
+	if err != nil {
+		return err
+	}
+
+	// the existing chain is kept if it is a part of the downloaded chain
+	keepExisting := len(existingDeltaChainPaths) <= len(mirrors.deltaChainHeaders)
+	for i := 0; keepExisting && i < len(existingDeltaChainPaths); i++ {
+		header, err := ReadSnapshotHeaderFromFile(existingDeltaChainPaths[i])
+		keepExisting = err == nil && header.Checksum != nil && snapshotHeadersEqual(header, mirrors.deltaChainHeaders[i])
+	}
+
+	if !keepExisting {
+		if err := RemoveDeltaChainFiles(deltaPath); err != nil {
+			return err
+		}
+		existingDeltaChainPaths = nil
+	}
+
+	if len(mirrors.deltaChainHeaders) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(DeltaChainDirectory(deltaPath), 0700); err != nil {
+		return fmt.Errorf("could not create delta snapshot chain dir '%s': %w", DeltaChainDirectory(deltaPath), err)
+	}
+
+	for i := len(existingDeltaChainPaths); i < len(mirrors.deltaChainHeaders); i++ {
+		var expected *SnapshotManifestFile
+		if i < len(expectedDeltaChain) {
+			expected = expectedDeltaChain[i]
+		}
+
+		s.LogInfof("downloading chained delta snapshot file %d/%d from %s", i+1, len(mirrors.deltaChainHeaders), strings.Join(mirrors.deltaChainURLs(i), ", "))
+		if err := s.downloadFile(ctx, DeltaChainFilePath(deltaPath, i+1), mirrors.deltaChainURLs(i), mirrors.deltaChainHeaders[i], expected); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // downloads a snapshot header from the given url.
 func (s *SnapshotManager) downloadHeader(url string) (*ReadFileHeader, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutDownloadSnapshotHeader)
@@ -215,55 +449,398 @@ func (s *SnapshotManager) downloadHeader(url string) (*ReadFileHeader, error) {
 	return ReadSnapshotHeader(resp.Body)
 }
 
-// downloads a snapshot file from the given url to the specified path.
-func (s *SnapshotManager) downloadFile(ctx context.Context, path string, url string) error {
-	downloadCtx, downloadCtxCancel := context.WithTimeout(context.Background(), timeoutDownloadSnapshotFile)
-	defer downloadCtxCancel()
+// probes the given mirrors for the size of the snapshot file and their support of range requests.
+// mirrors that are not reachable or serve a file of a different size are skipped.
+func (s *SnapshotManager) probeMirrors(ctx context.Context, urls []string, expected *SnapshotManifestFile) []*downloadMirror {
 
-	req, err := http.NewRequestWithContext(downloadCtx, http.MethodGet, url, nil)
+	var mirrors []*downloadMirror
+	// the size of the file on the other mirrors, -1 if unknown.
+	var size int64 = -1
+	for _, url := range urls {
+		mirror, err := probeMirror(ctx, url)
+		if err != nil {
+			s.LogDebugf("probing snapshot mirror %s failed: %s", url, err)
+			continue
+		}
+
+		if mirror.size < 0 {
+			// the size can't be compared, the downloaded file is still verified afterwards
+			mirrors = append(mirrors, mirror)
+			continue
+		}
+
+		if expected != nil && mirror.size != int64(expected.Size) {
+			s.LogInfof("snapshot file size on mirror %s does not match the manifest (%d != %d)", url, mirror.size, expected.Size)
+			continue
+		}
+
+		if size >= 0 && mirror.size != size {
+			s.LogInfof("snapshot file size on mirror %s does not match the other mirrors (%d != %d)", url, mirror.size, size)
+			continue
+		}
+
+		size = mirror.size
+		mirrors = append(mirrors, mirror)
+	}
+
+	// mirrors with a known size come first, so that their size is used for the download
+	sort.SliceStable(mirrors, func(i, j int) bool {
+		return mirrors[i].size >= 0 && mirrors[j].size < 0
+	})
+
+	return mirrors
+}
+
+// probes a mirror by requesting the first byte of the snapshot file.
+func probeMirror(ctx context.Context, url string) (*downloadMirror, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, timeoutDownloadSnapshotHeader)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
+		return nil, err
 	}
+	req.Header.Set("Range", "bytes=0-0")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed, server returned status code %d", resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// the content range has the format "bytes 0-0/<size>"
+		contentRange := resp.Header.Get("Content-Range")
+		sizeIndex := strings.LastIndex(contentRange, "/")
+		if sizeIndex == -1 {
+			return nil, fmt.Errorf("invalid content range: %s", contentRange)
+		}
+
+		if contentRange[sizeIndex+1:] == "*" {
+			// the size of the file is unknown, so the file can't be downloaded in chunks
+			return &downloadMirror{url: url, size: -1}, nil
+		}
+
+		size, err := strconv.ParseInt(contentRange[sizeIndex+1:], 10, 64)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid content range: %s", contentRange)
+		}
+
+		return &downloadMirror{url: url, size: size, rangeSupported: true}, nil
+
+	case http.StatusOK:
+		return &downloadMirror{url: url, size: resp.ContentLength}, nil
+
+	default:
+		return nil, fmt.Errorf("server returned status code %d", resp.StatusCode)
+	}
+}
+
+// returns the amount of bytes of a previous download of the same snapshot file that can be resumed.
+func (s *SnapshotManager) resumableOffset(partialFilePath string, header *ReadFileHeader, size int64) int64 {
+
+	info, err := os.Stat(partialFilePath)
+	if err != nil || info.Size() == 0 || info.Size() > size {
+		return 0
+	}
+
+	// the partial download must belong to the same snapshot file
+	partialHeader, err := ReadSnapshotHeaderFromFile(partialFilePath)
+	if err != nil || !snapshotHeadersEqual(partialHeader, header) {
+		s.LogInfof("discarding partial download %s", partialFilePath)
+		return 0
 	}
 
-	tempFileName := path + ".tmp"
-	out, err := os.Create(tempFileName)
+	return info.Size()
+}
+
+// downloads a snapshot file from the given mirrors to the specified path.
+// the data is written to a partial file first, which is kept to resume the download if it fails.
+// the file is only moved into place after it was verified.
+func (s *SnapshotManager) downloadFile(ctx context.Context, path string, urls []string, header *ReadFileHeader, expected *SnapshotManifestFile) error {
+
+	mirrors := s.probeMirrors(ctx, urls, expected)
+	if len(mirrors) == 0 {
+		return fmt.Errorf("download failed: %w", ErrSnapshotDownloadNoValidSource)
+	}
+
+	var rangeMirrors []*downloadMirror
+	for _, mirror := range mirrors {
+		if mirror.rangeSupported {
+			rangeMirrors = append(rangeMirrors, mirror)
+		}
+	}
+
+	partialFilePath := path + downloadPartialFileSuffix
+
+	var offset int64
+	if len(rangeMirrors) > 0 {
+		offset = s.resumableOffset(partialFilePath, header, rangeMirrors[0].size)
+	}
+
+	out, err := os.OpenFile(partialFilePath, os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = out.Close() }()
+
+	if err := out.Truncate(offset); err != nil {
+		return err
+	}
 
-	var ok bool
-	defer func() {
-		if !ok {
-			// we don't need to check the error, maybe the file doesn't exist
-			_ = os.Remove(tempFileName)
+	// hash the already downloaded data of the partial file
+	hash := sha256.New()
+	if offset > 0 {
+		s.LogInfof("resuming download of %s at %s", path, humanize.Bytes(uint64(offset)))
+		if _, err := io.Copy(hash, out); err != nil {
+			return fmt.Errorf("unable to read partial download: %w", err)
 		}
-	}()
+	}
+
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var expectedSize uint64
+	if mirrors[0].size > 0 {
+		expectedSize = uint64(mirrors[0].size)
+	}
 
 	// create our progress reporter and pass it to be used alongside our writer
-	counter := NewWriteCounter(ctx, uint64(resp.ContentLength))
-	if _, err = io.Copy(out, io.TeeReader(resp.Body, counter)); err != nil {
-		_ = out.Close()
-		return fmt.Errorf("download failed: %w", err)
+	counter := NewWriteCounter(ctx, expectedSize)
+	counter.total = uint64(offset)
+	counter.last = uint64(offset)
+
+	writer := io.MultiWriter(out, hash, counter)
+	if len(rangeMirrors) > 0 {
+		err = s.downloadChunks(ctx, writer, rangeMirrors, offset, rangeMirrors[0].size)
+	} else {
+		err = downloadStream(ctx, writer, mirrors[0].url)
 	}
 
 	// the progress indicator uses the same line so print a new line once it's finished downloading
 	fmt.Print("\n")
 
-	_ = out.Close()
-	if err = os.Rename(tempFileName, path); err != nil {
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	hashSum := hash.Sum(nil)
+	if err := verifyDownloadedFile(partialFilePath, header, hashSum, expected); err != nil {
+		// the downloaded data is corrupted, there is no use in resuming it
+		_ = os.Remove(partialFilePath)
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	if err = os.Rename(partialFilePath, path); err != nil {
 		return fmt.Errorf("unable to rename downloaded snapshot file: %w", err)
 	}
 
-	ok = true
+	s.LogInfof("downloaded snapshot file %s, SHA-256: %s", path, hex.EncodeToString(hashSum))
+
+	return nil
+}
+
+// downloads the snapshot file in chunks from the given mirrors, which all need to support range requests.
+// the chunks are written in order to the writer, starting at the given offset.
+func (s *SnapshotManager) downloadChunks(ctx context.Context, writer io.Writer, mirrors []*downloadMirror, offset int64, size int64) error {
+
+	type chunkRequest struct {
+		mirror *downloadMirror
+		start  int64
+		end    int64
+		data   []byte
+		err    error
+	}
+
+	// chunks that were downloaded but not written yet, because a previous chunk is still missing.
+	downloaded := make(map[int64][]byte)
+
+	for round := 0; offset < size; round++ {
+		if err := utils.ReturnErrIfCtxDone(ctx, ErrSnapshotDownloadWasAborted); err != nil {
+			return err
+		}
+
+		// assign the next missing chunks to the mirrors. the assignment is rotated every round,
+		// so that a chunk that failed to download is retried on another mirror.
+		// the amount of chunks kept in memory is limited by the window.
+		windowChunks := 2 * len(mirrors)
+		if windowChunks > downloadMaxChunksInMemory {
+			windowChunks = downloadMaxChunksInMemory
+		}
+		windowEnd := offset + int64(windowChunks)*downloadChunkSize
+		var requests []*chunkRequest
+		for start := offset; start < size && start < windowEnd && len(requests) < len(mirrors); start += downloadChunkSize {
+			if _, exists := downloaded[start]; exists {
+				continue
+			}
+
+			end := start + downloadChunkSize
+			if end > size {
+				end = size
+			}
+
+			requests = append(requests, &chunkRequest{
+				mirror: mirrors[(round+len(requests))%len(mirrors)],
+				start:  start,
+				end:    end,
+			})
+		}
+
+		var wg sync.WaitGroup
+		for _, request := range requests {
+			wg.Add(1)
+			go func(request *chunkRequest) {
+				defer wg.Done()
+				request.data, request.err = downloadChunk(ctx, request.mirror.url, request.start, request.end)
+			}(request)
+		}
+		wg.Wait()
+
+		for _, request := range requests {
+			if request.err != nil {
+				request.mirror.failures++
+				s.LogDebugf("downloading bytes %d-%d from %s failed: %s", request.start, request.end-1, request.mirror.url, request.err)
+				continue
+			}
+			request.mirror.failures = 0
+			downloaded[request.start] = request.data
+		}
+
+		// write all chunks that continue the written data
+		for {
+			data, exists := downloaded[offset]
+			if !exists {
+				break
+			}
+
+			if _, err := writer.Write(data); err != nil {
+				return err
+			}
+			delete(downloaded, offset)
+			offset += int64(len(data))
+		}
+
+		// stop using mirrors that failed repeatedly
+		activeMirrors := make([]*downloadMirror, 0, len(mirrors))
+		for _, mirror := range mirrors {
+			if mirror.failures >= downloadMirrorMaxFailures {
+				s.LogWarnf("snapshot mirror %s failed %d times in a row, not using it anymore", mirror.url, mirror.failures)
+				continue
+			}
+			activeMirrors = append(activeMirrors, mirror)
+		}
+		mirrors = activeMirrors
+
+		if len(mirrors) == 0 && offset < size {
+			return errors.New("all snapshot mirrors failed")
+		}
+	}
+
+	return nil
+}
+
+// downloads the given byte range [start, end) of the snapshot file from the given url.
+func downloadChunk(ctx context.Context, url string, start int64, end int64) ([]byte, error) {
+	chunkCtx, cancel := context.WithTimeout(ctx, timeoutDownloadSnapshotChunk)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(chunkCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("server returned status code %d", resp.StatusCode)
+	}
+
+	if contentRange := resp.Header.Get("Content-Range"); !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-%d/", start, end-1)) {
+		return nil, fmt.Errorf("server returned wrong content range: %s", contentRange)
+	}
+
+	data := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// idleTimeoutReader cancels the underlying request if no data was read within the timeout.
+type idleTimeoutReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.timer.Reset(r.timeout)
+	return n, err
+}
+
+// downloads the whole snapshot file from a mirror that doesn't support range requests.
+func downloadStream(ctx context.Context, writer io.Writer, url string) error {
+	downloadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(downloadCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	timer := time.AfterFunc(timeoutDownloadSnapshotChunk, cancel)
+	defer timer.Stop()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status code %d", resp.StatusCode)
+	}
+
+	_, err = io.Copy(writer, &idleTimeoutReader{reader: resp.Body, timer: timer, timeout: timeoutDownloadSnapshotChunk})
+	return err
+}
+
+// verifies the downloaded snapshot file against the manifest and the header served by the mirrors.
+func verifyDownloadedFile(filePath string, header *ReadFileHeader, hashSum []byte, expected *SnapshotManifestFile) error {
+
+	if expected != nil {
+		expectedHash, err := expected.hash()
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(hashSum, expectedHash) {
+			return fmt.Errorf("%w: SHA-256 of the downloaded file does not match the manifest (%s != %s)", ErrSnapshotChecksumMismatch, hex.EncodeToString(hashSum), expected.SHA256)
+		}
+	}
+
+	// reading the header also verifies the checksum of the header
+	fileHeader, err := ReadSnapshotHeaderFromFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	if !snapshotHeadersEqual(fileHeader, header) {
+		return errors.New("header of the downloaded file does not match the header served by the mirrors")
+	}
+
 	return nil
 }
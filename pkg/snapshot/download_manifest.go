@@ -0,0 +1,325 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+const (
+	// the maximum size of a snapshot manifest.
+	maxSnapshotManifestSize = 1024 * 1024
+)
+
+var (
+	// ErrSnapshotManifestInvalid is returned if a snapshot manifest is malformed or its signature is invalid.
+	ErrSnapshotManifestInvalid = errors.New("invalid snapshot manifest")
+	// ErrSnapshotManifestNotFound is returned if a signed snapshot manifest is required but none was found.
+	ErrSnapshotManifestNotFound = errors.New("no valid snapshot manifest found")
+)
+
+// SnapshotManifestFile describes a published snapshot file.
+type SnapshotManifestFile struct {
+	// the SHA-256 hash of the snapshot file (hex).
+	SHA256 string `json:"sha256"`
+	// the size of the snapshot file in bytes.
+	Size uint64 `json:"size"`
+	// the solid entry point milestone index of the snapshot file.
+	SEPMilestoneIndex milestone.Index `json:"sepMilestoneIndex"`
+	// the ledger milestone index of the snapshot file.
+	LedgerMilestoneIndex milestone.Index `json:"ledgerMilestoneIndex"`
+}
+
+// SnapshotManifest describes the snapshot files published by a download target.
+// It is used to verify the downloaded files before they are moved into place.
+type SnapshotManifest struct {
+	// the full snapshot file.
+	Full *SnapshotManifestFile `json:"full"`
+	// the delta snapshot file (optional).
+	Delta *SnapshotManifestFile `json:"delta,omitempty"`
+	// the chained delta snapshot files, in the order they have to be applied (optional).
+	DeltaChain []*SnapshotManifestFile `json:"deltaChain,omitempty"`
+	// the ed25519 public key of the signer (hex, optional).
+	PublicKey string `json:"publicKey,omitempty"`
+	// the ed25519 signature of the manifest (hex, optional).
+	Signature string `json:"signature,omitempty"`
+}
+
+// NewSnapshotManifestFile creates the manifest entry of the snapshot file at the given path.
+func NewSnapshotManifestFile(filePath string) (*SnapshotManifestFile, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open snapshot file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	return NewSnapshotManifestFileFromReader(file)
+}
+
+// NewSnapshotManifestFileFromReader creates the manifest entry of the snapshot file read from the given reader.
+// The reader is positioned at the start of the file afterwards.
+func NewSnapshotManifestFileFromReader(reader io.ReadSeeker) (*SnapshotManifestFile, error) {
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	header, err := ReadSnapshotHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to hash snapshot file: %w", err)
+	}
+
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return &SnapshotManifestFile{
+		SHA256:               hex.EncodeToString(hash.Sum(nil)),
+		Size:                 uint64(size),
+		SEPMilestoneIndex:    header.SEPMilestoneIndex,
+		LedgerMilestoneIndex: header.LedgerMilestoneIndex,
+	}, nil
+}
+
+// hash returns the decoded SHA-256 hash of the snapshot file.
+func (f *SnapshotManifestFile) hash() ([]byte, error) {
+	hash, err := hex.DecodeString(f.SHA256)
+	if err != nil {
+		return nil, errors.WithMessagef(ErrSnapshotManifestInvalid, "invalid SHA-256 hash: %s", err)
+	}
+	if len(hash) != sha256.Size {
+		return nil, errors.WithMessagef(ErrSnapshotManifestInvalid, "invalid SHA-256 hash length: %d", len(hash))
+	}
+	return hash, nil
+}
+
+// matchesHeader checks whether the manifest entry describes the snapshot file with the given header.
+func (f *SnapshotManifestFile) matchesHeader(header *ReadFileHeader) bool {
+	return f.SEPMilestoneIndex == header.SEPMilestoneIndex && f.LedgerMilestoneIndex == header.LedgerMilestoneIndex
+}
+
+// matchesDeltaChainHeaders checks whether the manifest describes the chained delta snapshot files with the given headers.
+func (m *SnapshotManifest) matchesDeltaChainHeaders(headers []*ReadFileHeader) bool {
+	if len(m.DeltaChain) < len(headers) {
+		return false
+	}
+
+	for i, header := range headers {
+		if !m.DeltaChain[i].matchesHeader(header) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SigningMessage returns the message that is signed by the publisher of the manifest.
+func (m *SnapshotManifest) SigningMessage() ([]byte, error) {
+
+	if m.Full == nil {
+		return nil, errors.WithMessage(ErrSnapshotManifestInvalid, "full snapshot file missing")
+	}
+
+	var buf bytes.Buffer
+	writeFile := func(file *SnapshotManifestFile) error {
+		hash, err := file.hash()
+		if err != nil {
+			return err
+		}
+
+		buf.Write(hash)
+		if err := binary.Write(&buf, binary.LittleEndian, file.Size); err != nil {
+			return err
+		}
+		if err := binary.Write(&buf, binary.LittleEndian, file.SEPMilestoneIndex); err != nil {
+			return err
+		}
+		return binary.Write(&buf, binary.LittleEndian, file.LedgerMilestoneIndex)
+	}
+
+	for _, file := range []*SnapshotManifestFile{m.Full, m.Delta} {
+		if file == nil {
+			continue
+		}
+
+		if err := writeFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(m.DeltaChain) > 0 {
+		// the amount of chained files separates the chain from the delta snapshot file
+		if err := binary.Write(&buf, binary.LittleEndian, uint32(len(m.DeltaChain))); err != nil {
+			return nil, err
+		}
+
+		for _, file := range m.DeltaChain {
+			if file == nil {
+				return nil, errors.WithMessage(ErrSnapshotManifestInvalid, "chained delta snapshot file missing")
+			}
+
+			if err := writeFile(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Sign signs the manifest with the given private key.
+func (m *SnapshotManifest) Sign(privateKey ed25519.PrivateKey) error {
+
+	msg, err := m.SigningMessage()
+	if err != nil {
+		return err
+	}
+
+	m.PublicKey = hex.EncodeToString(privateKey.Public().(ed25519.PublicKey))
+	m.Signature = hex.EncodeToString(ed25519.Sign(privateKey, msg))
+
+	return nil
+}
+
+// verify checks that the manifest is well-formed.
+// If trusted public keys are given, the manifest must be signed by one of them.
+func (m *SnapshotManifest) verify(trustedPublicKeys []ed25519.PublicKey) error {
+
+	msg, err := m.SigningMessage()
+	if err != nil {
+		return err
+	}
+
+	if len(trustedPublicKeys) == 0 {
+		return nil
+	}
+
+	if m.PublicKey == "" || m.Signature == "" {
+		return errors.WithMessage(ErrSnapshotManifestInvalid, "manifest is not signed")
+	}
+
+	publicKey, err := utils.ParseEd25519PublicKeyFromString(m.PublicKey)
+	if err != nil {
+		return errors.WithMessagef(ErrSnapshotManifestInvalid, "invalid public key: %s", err)
+	}
+
+	trusted := false
+	for _, trustedPublicKey := range trustedPublicKeys {
+		if trustedPublicKey.Equal(publicKey) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return errors.WithMessagef(ErrSnapshotManifestInvalid, "manifest is signed by an untrusted public key: %s", m.PublicKey)
+	}
+
+	signature, err := hex.DecodeString(m.Signature)
+	if err != nil {
+		return errors.WithMessagef(ErrSnapshotManifestInvalid, "invalid signature: %s", err)
+	}
+
+	if !ed25519.Verify(publicKey, msg, signature) {
+		return errors.WithMessage(ErrSnapshotManifestInvalid, "signature verification failed")
+	}
+
+	return nil
+}
+
+// downloads a snapshot manifest from the given url.
+func (s *SnapshotManager) downloadManifest(url string) (*SnapshotManifest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutDownloadSnapshotHeader)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed, server returned status code %d", resp.StatusCode)
+	}
+
+	manifest := &SnapshotManifest{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSnapshotManifestSize)).Decode(manifest); err != nil {
+		return nil, errors.WithMessagef(ErrSnapshotManifestInvalid, "unable to decode manifest: %s", err)
+	}
+
+	return manifest, nil
+}
+
+// searches the manifest of the targets of the given mirrors that matches the snapshot headers of the mirrors.
+// a nil manifest is returned if no manifest is published and no signed manifest is required.
+func (s *SnapshotManager) manifestForMirrors(mirrors *downloadMirrors) (*SnapshotManifest, error) {
+
+	for _, target := range mirrors.targets {
+		if len(target.Manifest) == 0 {
+			continue
+		}
+
+		s.LogDebugf("downloading snapshot manifest from %s", target.Manifest)
+		manifest, err := s.downloadManifest(target.Manifest)
+		if err != nil {
+			s.LogDebugf("downloading snapshot manifest from %s failed: %s", target.Manifest, err)
+			continue
+		}
+
+		if err := manifest.verify(s.manifestPublicKeys); err != nil {
+			s.LogWarnf("snapshot manifest from %s is invalid: %s", target.Manifest, err)
+			continue
+		}
+
+		if !manifest.Full.matchesHeader(mirrors.fullHeader) {
+			s.LogInfof("snapshot manifest from %s does not match the full snapshot file", target.Manifest)
+			continue
+		}
+
+		if mirrors.deltaHeader != nil && (manifest.Delta == nil || !manifest.Delta.matchesHeader(mirrors.deltaHeader)) {
+			s.LogInfof("snapshot manifest from %s does not match the delta snapshot file", target.Manifest)
+			continue
+		}
+
+		if !manifest.matchesDeltaChainHeaders(mirrors.deltaChainHeaders) {
+			s.LogInfof("snapshot manifest from %s does not match the chained delta snapshot files", target.Manifest)
+			continue
+		}
+
+		return manifest, nil
+	}
+
+	if len(s.manifestPublicKeys) > 0 {
+		return nil, ErrSnapshotManifestNotFound
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,268 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/utxo/utils"
+	hornetutils "github.com/gohornet/hornet/pkg/utils"
+	"github.com/iotaledger/hive.go/configuration"
+	"github.com/iotaledger/hive.go/logger"
+)
+
+func newTestSnapshotManager(t *testing.T, manifestPublicKeys ...ed25519.PublicKey) *SnapshotManager {
+	cfg := configuration.New()
+	err := cfg.Set("logger.disableStacktrace", true)
+	require.NoError(t, err)
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = logger.InitGlobalLogger(cfg)
+
+	return &SnapshotManager{
+		WrappedLogger:      hornetutils.NewWrappedLogger(logger.NewLogger("Snapshot")),
+		manifestPublicKeys: manifestPublicKeys,
+	}
+}
+
+// serves the given data and supports range requests.
+// the first failCount requests fail.
+func newMirrorServer(data []byte, failCount int32, requestedRanges *[]string) *httptest.Server {
+	var requests int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failCount {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if requestedRanges != nil {
+			*requestedRanges = append(*requestedRanges, r.Header.Get("Range"))
+		}
+		http.ServeContent(w, r, "snapshot.bin", time.Time{}, bytes.NewReader(data))
+	}))
+}
+
+// writes a delta snapshot file with random solid entry points to the given path.
+func writeTestSnapshotFile(t *testing.T, filePath string, sepsCount int) []byte {
+	header := &FileHeader{
+		Type:              Delta,
+		Version:           SupportedFormatVersion,
+		NetworkID:         1337133713371337,
+		SEPMilestoneIndex: 1000,
+		Compression:       CompressionNone,
+	}
+
+	sepProducer := func() (hornet.MessageID, error) {
+		if sepsCount == 0 {
+			return nil, nil
+		}
+		sepsCount--
+		return utils.RandMessageID(), nil
+	}
+
+	msDiffProducer := func() (*MilestoneDiff, error) {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	require.NoError(t, err)
+	_, err = StreamSnapshotDataTo(file, uint64(time.Now().Unix()), header, sepProducer, nil, msDiffProducer)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	data, err := ioutil.ReadFile(filePath)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestDownloadChunks(t *testing.T) {
+	s := newTestSnapshotManager(t)
+
+	data := make([]byte, 2*downloadChunkSize+12345)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	mirror1 := newMirrorServer(data, 0, nil)
+	defer mirror1.Close()
+
+	// the second mirror fails some requests, the chunks have to be retried on the other mirror
+	mirror2 := newMirrorServer(data, 2, nil)
+	defer mirror2.Close()
+
+	mirrors := s.probeMirrors(context.Background(), []string{mirror1.URL, mirror2.URL}, nil)
+	require.Len(t, mirrors, 1)
+
+	mirrors = append(mirrors, &downloadMirror{url: mirror2.URL, size: int64(len(data)), rangeSupported: true})
+
+	var buf bytes.Buffer
+	require.NoError(t, s.downloadChunks(context.Background(), &buf, mirrors, 0, int64(len(data))))
+	require.True(t, bytes.Equal(data, buf.Bytes()))
+
+	// resume at an offset
+	buf.Reset()
+	require.NoError(t, s.downloadChunks(context.Background(), &buf, mirrors[:1], 1000, int64(len(data))))
+	require.True(t, bytes.Equal(data[1000:], buf.Bytes()))
+}
+
+func TestDownloadFileUnknownSize(t *testing.T) {
+	s := newTestSnapshotManager(t)
+
+	dir := t.TempDir()
+	data := writeTestSnapshotFile(t, filepath.Join(dir, "source.bin"), 1000)
+
+	header, err := ReadSnapshotHeaderFromFile(filepath.Join(dir, "source.bin"))
+	require.NoError(t, err)
+
+	// the mirror supports range requests, but doesn't know the size of the file
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", "bytes 0-0/*")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(data[:1])
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer mirror.Close()
+
+	mirrors := s.probeMirrors(context.Background(), []string{mirror.URL}, nil)
+	require.Len(t, mirrors, 1)
+	require.Equal(t, int64(-1), mirrors[0].size)
+	require.False(t, mirrors[0].rangeSupported)
+
+	// the file is downloaded as a stream instead of in chunks
+	targetPath := filepath.Join(dir, "delta_snapshot.bin")
+	require.NoError(t, s.downloadFile(context.Background(), targetPath, []string{mirror.URL}, header, nil))
+
+	downloaded, err := ioutil.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, downloaded))
+}
+
+func TestDownloadFileResume(t *testing.T) {
+	s := newTestSnapshotManager(t)
+
+	dir := t.TempDir()
+	data := writeTestSnapshotFile(t, filepath.Join(dir, "source.bin"), 1000)
+
+	manifestFile, err := NewSnapshotManifestFile(filepath.Join(dir, "source.bin"))
+	require.NoError(t, err)
+
+	header, err := ReadSnapshotHeaderFromFile(filepath.Join(dir, "source.bin"))
+	require.NoError(t, err)
+
+	var requestedRanges []string
+	mirror := newMirrorServer(data, 0, &requestedRanges)
+	defer mirror.Close()
+
+	// a previous download was interrupted
+	targetPath := filepath.Join(dir, "delta_snapshot.bin")
+	require.NoError(t, ioutil.WriteFile(targetPath+downloadPartialFileSuffix, data[:len(data)/2], 0666))
+
+	require.NoError(t, s.downloadFile(context.Background(), targetPath, []string{mirror.URL}, header, manifestFile))
+
+	downloaded, err := ioutil.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, downloaded))
+
+	_, err = os.Stat(targetPath + downloadPartialFileSuffix)
+	require.True(t, os.IsNotExist(err))
+
+	// only the missing part was downloaded
+	require.Equal(t, []string{"bytes=0-0", fmt.Sprintf("bytes=%d-%d", len(data)/2, len(data)-1)}, requestedRanges)
+
+	// a partial download of another snapshot file is discarded
+	require.NoError(t, os.Remove(targetPath))
+	otherData := writeTestSnapshotFile(t, filepath.Join(dir, "other.bin"), 1000)
+	require.NoError(t, ioutil.WriteFile(targetPath+downloadPartialFileSuffix, otherData[:len(otherData)/2], 0666))
+
+	require.NoError(t, s.downloadFile(context.Background(), targetPath, []string{mirror.URL}, header, manifestFile))
+
+	downloaded, err = ioutil.ReadFile(targetPath)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, downloaded))
+}
+
+func TestDownloadFileChecksumMismatch(t *testing.T) {
+	s := newTestSnapshotManager(t)
+
+	dir := t.TempDir()
+	data := writeTestSnapshotFile(t, filepath.Join(dir, "source.bin"), 1000)
+
+	manifestFile, err := NewSnapshotManifestFile(filepath.Join(dir, "source.bin"))
+	require.NoError(t, err)
+
+	header, err := ReadSnapshotHeaderFromFile(filepath.Join(dir, "source.bin"))
+	require.NoError(t, err)
+
+	// the mirror serves corrupted data
+	corrupted := make([]byte, len(data))
+	copy(corrupted, data)
+	corrupted[len(corrupted)-1] ^= 0xFF
+
+	mirror := newMirrorServer(corrupted, 0, nil)
+	defer mirror.Close()
+
+	targetPath := filepath.Join(dir, "delta_snapshot.bin")
+	err = s.downloadFile(context.Background(), targetPath, []string{mirror.URL}, header, manifestFile)
+	require.ErrorIs(t, err, ErrSnapshotChecksumMismatch)
+
+	// neither the file nor the corrupted partial download are kept
+	_, err = os.Stat(targetPath)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(targetPath + downloadPartialFileSuffix)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestSnapshotManifestSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	otherPublicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	manifest := &SnapshotManifest{
+		Full: &SnapshotManifestFile{
+			SHA256:               strings.Repeat("ab", 32),
+			Size:                 1337,
+			SEPMilestoneIndex:    100,
+			LedgerMilestoneIndex: 100,
+		},
+		Delta: &SnapshotManifestFile{
+			SHA256:               strings.Repeat("cd", 32),
+			Size:                 42,
+			SEPMilestoneIndex:    200,
+			LedgerMilestoneIndex: 100,
+		},
+	}
+
+	// unsigned manifests are only accepted if no trusted keys are configured
+	require.NoError(t, manifest.verify(nil))
+	require.ErrorIs(t, manifest.verify([]ed25519.PublicKey{publicKey}), ErrSnapshotManifestInvalid)
+
+	require.NoError(t, manifest.Sign(privateKey))
+	require.NoError(t, manifest.verify([]ed25519.PublicKey{otherPublicKey, publicKey}))
+	require.ErrorIs(t, manifest.verify([]ed25519.PublicKey{otherPublicKey}), ErrSnapshotManifestInvalid)
+
+	// the signature covers the hashes of the files
+	manifest.Delta.SHA256 = strings.Repeat("ef", 32)
+	require.ErrorIs(t, manifest.verify([]ed25519.PublicKey{publicKey}), ErrSnapshotManifestInvalid)
+
+	// malformed hashes are rejected
+	manifest.Delta.SHA256 = "abcd"
+	require.ErrorIs(t, manifest.verify(nil), ErrSnapshotManifestInvalid)
+}
@@ -14,9 +14,15 @@ func PruningMetricsCaller(handler interface{}, params ...interface{}) {
 	handler.(func(metrics *PruningMetrics))(params[0].(*PruningMetrics))
 }
 
+// PruningTriggerCaller is used to signal a started pruning run.
+func PruningTriggerCaller(handler interface{}, params ...interface{}) {
+	handler.(func(trigger *PruningTrigger))(params[0].(*PruningTrigger))
+}
+
 type Events struct {
 	SnapshotMilestoneIndexChanged *events.Event
 	SnapshotMetricsUpdated        *events.Event
+	PruningTriggered              *events.Event
 	PruningMilestoneIndexChanged  *events.Event
 	PruningMetricsUpdated         *events.Event
 }
@@ -19,8 +19,10 @@ type SnapshotMetrics struct {
 
 // PruningMetrics holds metrics about a database pruning run.
 type PruningMetrics struct {
+	Policy                               PruningPolicy
 	DurationPruneUnreferencedMessages    time.Duration
 	DurationTraverseMilestoneCone        time.Duration
+	DurationArchiveMilestone             time.Duration
 	DurationPruneMilestone               time.Duration
 	DurationPruneMessages                time.Duration
 	DurationSetSnapshotInfo              time.Duration
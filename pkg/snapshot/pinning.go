@@ -0,0 +1,224 @@
+package snapshot
+
+import (
+	"bytes"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+)
+
+// PinMessage pins the message, so that the message, its metadata and
+// the milestone that referenced it are kept by the pruning.
+func (s *SnapshotManager) PinMessage(messageID hornet.MessageID) error {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+
+	if !s.storage.ContainsMessage(messageID) {
+		return common.ErrMessageNotFound
+	}
+
+	return s.storage.PinMessage(messageID)
+}
+
+// UnpinMessage unpins the message.
+// If the message was only kept because it was pinned, it is removed from the database.
+func (s *SnapshotManager) UnpinMessage(messageID hornet.MessageID) error {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+
+	if !s.storage.IsMessagePinned(messageID) {
+		return ErrMessageNotPinned
+	}
+
+	if err := s.storage.UnpinMessage(messageID); err != nil {
+		return err
+	}
+
+	return s.pruneUnpinnedMessage(messageID)
+}
+
+// PinnedMessageIDs returns the IDs of all pinned messages.
+func (s *SnapshotManager) PinnedMessageIDs() (hornet.MessageIDs, error) {
+	return s.storage.PinnedMessageIDs()
+}
+
+// matchesPinnedTagPrefix returns whether the tag of the message matches one of the pinned tag prefixes.
+func (s *SnapshotManager) matchesPinnedTagPrefix(msg *storage.Message) bool {
+	return matchesPinnedTagPrefix(msg, s.pinnedTagPrefixes)
+}
+
+// matchesPinnedTagPrefix returns whether the tag of the message matches one of the given pinned tag prefixes.
+func matchesPinnedTagPrefix(msg *storage.Message, pinnedTagPrefixes [][]byte) bool {
+
+	if len(pinnedTagPrefixes) == 0 {
+		return false
+	}
+
+	taggedData := msg.TaggedData()
+	if taggedData == nil {
+		taggedData = msg.TransactionEssenceTaggedData()
+	}
+	if taggedData == nil {
+		return false
+	}
+
+	for _, tagPrefix := range pinnedTagPrefixes {
+		if bytes.HasPrefix(taggedData.Tag, tagPrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMessagePinned returns whether the message is pinned or its tag matches one of the pinned tag prefixes.
+// Messages matching a pinned tag prefix are added to the pinned messages, so that they can be unpinned individually.
+func (s *SnapshotManager) isMessagePinned(msg *storage.Message) bool {
+
+	if s.storage.IsMessagePinned(msg.MessageID()) {
+		return true
+	}
+
+	if !s.matchesPinnedTagPrefix(msg) {
+		return false
+	}
+
+	if err := s.storage.PinMessage(msg.MessageID()); err != nil {
+		s.LogWarnf("Pinning message %s failed! %s", msg.MessageID().ToHex(), err)
+	}
+
+	return true
+}
+
+// pinnedMilestones returns the indexes of the milestones that referenced pinned messages.
+func (s *SnapshotManager) pinnedMilestones() (map[milestone.Index]struct{}, error) {
+	return pinnedMilestonesFromStorage(s.storage)
+}
+
+// pinnedMilestonesFromStorage returns the indexes of the milestones that referenced the pinned messages in the given storage.
+func pinnedMilestonesFromStorage(dbStorage *storage.Storage) (map[milestone.Index]struct{}, error) {
+
+	pinnedMilestones := make(map[milestone.Index]struct{})
+
+	if err := dbStorage.ForEachPinnedMessageID(func(messageID hornet.MessageID) bool {
+		cachedMsgMeta := dbStorage.CachedMessageMetadataOrNil(messageID) // meta +1
+		if cachedMsgMeta == nil {
+			// message was removed by a revalidation of the database
+			return true
+		}
+		defer cachedMsgMeta.Release(true) // meta -1
+
+		if referenced, at := cachedMsgMeta.Metadata().ReferencedWithIndex(); referenced {
+			pinnedMilestones[at] = struct{}{}
+		}
+
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	return pinnedMilestones, nil
+}
+
+// keepPinnedMessages removes the pinned messages and the messages of the milestones that referenced
+// pinned messages from the messages to delete. The milestones that referenced the pinned messages
+// are added to pinnedMilestones. It returns the amount of kept messages.
+func (s *SnapshotManager) keepPinnedMessages(messageIDsToDeleteMap map[string]struct{}, pinnedMilestones map[milestone.Index]struct{}) int {
+
+	if len(s.pinnedTagPrefixes) == 0 && !s.storage.HasPinnedMessages() {
+		// nothing to keep
+		return 0
+	}
+
+	var keptCount int
+
+	// the milestone messages are checked after all pinned messages are known,
+	// since the milestone message could be traversed before the pinned messages.
+	milestoneMessages := make(map[string]milestone.Index)
+
+	for messageIDMapKey := range messageIDsToDeleteMap {
+		cachedMsg := s.storage.CachedMessageOrNil(hornet.MessageIDFromMapKey(messageIDMapKey)) // message +1
+		if cachedMsg == nil {
+			continue
+		}
+
+		if s.isMessagePinned(cachedMsg.Message()) {
+			if referenced, at := cachedMsg.Metadata().ReferencedWithIndex(); referenced {
+				pinnedMilestones[at] = struct{}{}
+			}
+			delete(messageIDsToDeleteMap, messageIDMapKey)
+			keptCount++
+		} else if ms := cachedMsg.Message().Milestone(); ms != nil {
+			milestoneMessages[messageIDMapKey] = milestone.Index(ms.Index)
+		}
+
+		cachedMsg.Release(true) // message -1
+	}
+
+	for messageIDMapKey, msIndex := range milestoneMessages {
+		if _, pinned := pinnedMilestones[msIndex]; !pinned {
+			continue
+		}
+		delete(messageIDsToDeleteMap, messageIDMapKey)
+		keptCount++
+	}
+
+	return keptCount
+}
+
+// pruneUnpinnedMessage removes the unpinned message from the database if it was only kept because it was pinned.
+// The milestone that referenced the message is removed as well, if it doesn't reference other pinned messages.
+func (s *SnapshotManager) pruneUnpinnedMessage(messageID hornet.MessageID) error {
+
+	snapshotInfo := s.storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		s.LogPanic("No snapshotInfo found!")
+	}
+
+	cachedMsgMeta := s.storage.CachedMessageMetadataOrNil(messageID) // meta +1
+	if cachedMsgMeta == nil {
+		return nil
+	}
+	referenced, at := cachedMsgMeta.Metadata().ReferencedWithIndex()
+	cachedMsgMeta.Release(true) // meta -1
+
+	//lint:ignore SA5011 nil pointer is already checked before with a panic
+	pruningIndex := snapshotInfo.PruningIndex
+
+	if !referenced {
+		// the message is pruned with the unreferenced messages of the next pruning run
+		s.storage.StoreUnreferencedMessage(pruningIndex+1, messageID).Release(true) // unreferencedTx +-0
+		return nil
+	}
+
+	if at > pruningIndex {
+		// the message is pruned together with its milestone
+		return nil
+	}
+
+	s.pruneMessages(map[string]struct{}{messageID.ToMapKey(): {}})
+
+	pinnedMilestones, err := s.pinnedMilestones()
+	if err != nil {
+		return err
+	}
+
+	if _, pinned := pinnedMilestones[at]; pinned {
+		// the milestone is still needed by other pinned messages
+		return nil
+	}
+
+	if cachedMsgMilestone := s.storage.MilestoneCachedMessageOrNil(at); cachedMsgMilestone != nil { // message +1
+		milestoneMessageID := cachedMsgMilestone.Message().MessageID()
+		cachedMsgMilestone.Release(true) // message -1
+
+		if !s.storage.IsMessagePinned(milestoneMessageID) {
+			s.pruneMessages(map[string]struct{}{milestoneMessageID.ToMapKey(): {}})
+		}
+	}
+	s.storage.DeleteMilestone(at)
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func referencedIndex(t *testing.T, te *testsuite.TestEnvironment, messageID hornet.MessageID) (bool, milestone.Index) {
+	cachedMsgMeta := te.Storage().CachedMessageMetadataOrNil(messageID) // meta +1
+	require.NotNil(t, cachedMsgMeta)
+	defer cachedMsgMeta.Release(true) // meta -1
+
+	return cachedMsgMeta.Metadata().ReferencedWithIndex()
+}
+
+func messageTag(t *testing.T, te *testsuite.TestEnvironment, messageID hornet.MessageID) []byte {
+	cachedMsg := te.Storage().CachedMessageOrNil(messageID) // message +1
+	require.NotNil(t, cachedMsg)
+	defer cachedMsg.Release(true) // message -1
+
+	return cachedMsg.Message().TaggedData().Tag
+}
+
+func TestPruningKeepsPinnedMessages(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	messages, _ := te.BuildTangle(10, BelowMaxDepth, 20, 5, 10,
+		nil,
+		func(messages hornet.MessageIDs, messagesPerMilestones []hornet.MessageIDs) hornet.MessageIDs {
+			return hornet.MessageIDs{messages[len(messages)-1]}
+		},
+		nil,
+	)
+
+	confirmedMilestoneIndex := te.SyncManager().ConfirmedMilestoneIndex()
+	require.NoError(t, te.Storage().SetSnapshotMilestone(uint64(te.NetworkID()), confirmedMilestoneIndex, 0, 0, time.Now()))
+
+	// the messages of the test tangle are tagged with their number, message "1" is pinned by its tag
+	snapshotManager := newTestSnapshotManager(te, [][]byte{[]byte("1")})
+
+	targetIndex := milestone.Index(8)
+
+	require.ErrorIs(t, snapshotManager.PinMessage(hornet.NullMessageID()), common.ErrMessageNotFound)
+	require.ErrorIs(t, snapshotManager.UnpinMessage(messages[0]), snapshot.ErrMessageNotPinned)
+	require.False(t, te.Storage().HasPinnedMessages())
+
+	// the messages of the pruned range that are pinned by their tag or manually, and the ones that get pruned
+	var tagPinnedMessageIDs, prunedMessageIDs hornet.MessageIDs
+	for _, messageID := range messages {
+		if referenced, at := referencedIndex(t, te, messageID); !referenced || at > targetIndex {
+			continue
+		}
+
+		if messageTag(t, te, messageID)[0] == '1' {
+			tagPinnedMessageIDs = append(tagPinnedMessageIDs, messageID)
+			continue
+		}
+		prunedMessageIDs = append(prunedMessageIDs, messageID)
+	}
+	require.NotEmpty(t, tagPinnedMessageIDs)
+	require.Greater(t, len(prunedMessageIDs), 1)
+
+	pinnedMessageID := prunedMessageIDs[0]
+	prunedMessageIDs = prunedMessageIDs[1:]
+	require.NoError(t, snapshotManager.PinMessage(pinnedMessageID))
+	require.True(t, te.Storage().HasPinnedMessages())
+
+	prunedIndex, err := snapshotManager.PruneDatabaseByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+	require.Equal(t, targetIndex, prunedIndex)
+
+	for _, messageID := range prunedMessageIDs {
+		require.False(t, te.Storage().ContainsMessage(messageID))
+	}
+
+	// the pinned messages, their metadata and the referencing milestones are kept
+	for _, messageID := range append(tagPinnedMessageIDs, pinnedMessageID) {
+		require.True(t, te.Storage().ContainsMessage(messageID))
+
+		_, msIndex := referencedIndex(t, te, messageID)
+		require.True(t, te.Storage().ContainsMilestone(msIndex))
+
+		cachedMsgMilestone := te.Storage().MilestoneCachedMessageOrNil(msIndex) // message +1
+		require.NotNil(t, cachedMsgMilestone)
+		cachedMsgMilestone.Release(true) // message -1
+	}
+
+	// messages matching a pinned tag prefix are added to the pinned messages
+	pinnedMessageIDs, err := snapshotManager.PinnedMessageIDs()
+	require.NoError(t, err)
+	require.Subset(t, pinnedMessageIDs, tagPinnedMessageIDs)
+	require.Contains(t, pinnedMessageIDs, pinnedMessageID)
+
+	// unpinned messages are removed, the milestone is only kept if it referenced other pinned messages
+	_, pinnedMessageIndex := referencedIndex(t, te, pinnedMessageID)
+	require.NoError(t, snapshotManager.UnpinMessage(pinnedMessageID))
+	require.False(t, te.Storage().ContainsMessage(pinnedMessageID))
+
+	pinnedMessageIDs, err = snapshotManager.PinnedMessageIDs()
+	require.NoError(t, err)
+	require.NotContains(t, pinnedMessageIDs, pinnedMessageID)
+
+	milestoneStillPinned := false
+	for _, messageID := range pinnedMessageIDs {
+		if _, at := referencedIndex(t, te, messageID); at == pinnedMessageIndex {
+			milestoneStillPinned = true
+		}
+	}
+	require.Equal(t, milestoneStillPinned, te.Storage().ContainsMilestone(pinnedMessageIndex))
+}
@@ -15,6 +15,44 @@ import (
 	"github.com/gohornet/hornet/pkg/utils"
 )
 
+// PruningPolicy is the policy which determined the target index of a pruning run.
+type PruningPolicy byte
+
+const (
+	// PruningPolicyManual is used if the pruning was triggered manually.
+	PruningPolicyManual PruningPolicy = iota
+	// PruningPolicyMilestones is used if the pruning was triggered by the maximum amount of milestones to keep.
+	PruningPolicyMilestones
+	// PruningPolicySize is used if the pruning was triggered by the target size of the database.
+	PruningPolicySize
+	// PruningPolicyAge is used if the pruning was triggered by the maximum age of the milestones to keep.
+	PruningPolicyAge
+)
+
+// String returns the name of the pruning policy.
+func (p PruningPolicy) String() string {
+	switch p {
+	case PruningPolicyManual:
+		return "manual"
+	case PruningPolicyMilestones:
+		return "milestones"
+	case PruningPolicySize:
+		return "size"
+	case PruningPolicyAge:
+		return "age"
+	default:
+		return "unknown"
+	}
+}
+
+// PruningTrigger holds information about a started pruning run.
+type PruningTrigger struct {
+	// Policy is the policy which determined the target index.
+	Policy PruningPolicy
+	// TargetIndex is the index up to which the database gets pruned.
+	TargetIndex milestone.Index
+}
+
 func (s *SnapshotManager) setIsPruning(value bool) {
 	s.statusLock.Lock()
 	s.isPruning = value
@@ -54,6 +92,13 @@ func (s *SnapshotManager) calcTargetIndexBySize(targetSizeBytes ...int64) (miles
 		targetDatabaseSizeBytes = targetSizeBytes[0]
 	}
 
+	return TargetIndexBySize(currentDatabaseSizeBytes, targetDatabaseSizeBytes, s.pruningSizeThresholdPercentage, s.storage.SnapshotInfo().PruningIndex, s.syncManager.ConfirmedMilestoneIndex())
+}
+
+// TargetIndexBySize extrapolates the pruning target index which is needed to reduce the database to the target size.
+// It assumes that the milestones between the pruning index and the confirmed milestone index occupy the same space.
+func TargetIndexBySize(currentDatabaseSizeBytes int64, targetDatabaseSizeBytes int64, thresholdPercentage float64, pruningIndex milestone.Index, confirmedMilestoneIndex milestone.Index) (milestone.Index, error) {
+
 	if targetDatabaseSizeBytes <= 0 {
 		// pruning by size deactivated
 		return 0, ErrNoPruningNeeded
@@ -63,16 +108,70 @@ func (s *SnapshotManager) calcTargetIndexBySize(targetSizeBytes ...int64) (miles
 		return 0, ErrNoPruningNeeded
 	}
 
-	milestoneRange := s.syncManager.ConfirmedMilestoneIndex() - s.storage.SnapshotInfo().PruningIndex
-	prunedDatabaseSizeBytes := float64(targetDatabaseSizeBytes) * ((100.0 - s.pruningSizeThresholdPercentage) / 100.0)
+	milestoneRange := confirmedMilestoneIndex - pruningIndex
+	prunedDatabaseSizeBytes := float64(targetDatabaseSizeBytes) * ((100.0 - thresholdPercentage) / 100.0)
 	diffPercentage := prunedDatabaseSizeBytes / float64(currentDatabaseSizeBytes)
 	milestoneDiff := milestone.Index(math.Ceil(float64(milestoneRange) * diffPercentage))
 
-	return s.syncManager.ConfirmedMilestoneIndex() - milestoneDiff, nil
+	return confirmedMilestoneIndex - milestoneDiff, nil
+}
+
+// calcTargetIndexByAge returns the newest milestone that is older than the maximum age of the milestones to keep.
+func (s *SnapshotManager) calcTargetIndexByAge() (milestone.Index, error) {
+
+	if !s.pruningAgeEnabled || s.pruningAgeMaxAge <= 0 {
+		// pruning by age deactivated
+		return 0, ErrNoPruningNeeded
+	}
+
+	snapshotInfo := s.storage.SnapshotInfo()
+	if snapshotInfo == nil {
+		s.LogPanic("No snapshotInfo found!")
+	}
+
+	maxTimestamp := time.Now().Add(-s.pruningAgeMaxAge)
+
+	milestoneTimestamp := func(msIndex milestone.Index) (time.Time, error) {
+		cachedMilestone := s.storage.CachedMilestoneOrNil(msIndex) // milestone +1
+		if cachedMilestone == nil {
+			return time.Time{}, errors.Errorf("milestone (%d) not found", msIndex)
+		}
+		defer cachedMilestone.Release(true) // milestone -1
+
+		return cachedMilestone.Milestone().Timestamp, nil
+	}
+
+	// the milestone timestamps are monotonically increasing,
+	// so the target index can be found with a binary search.
+	var targetIndex milestone.Index
+	//lint:ignore SA5011 nil pointer is already checked before with a panic
+	lowIndex, highIndex := snapshotInfo.PruningIndex+1, s.syncManager.ConfirmedMilestoneIndex()
+	for lowIndex <= highIndex {
+		msIndex := lowIndex + (highIndex-lowIndex)/2
+
+		timestamp, err := milestoneTimestamp(msIndex)
+		if err != nil {
+			return 0, err
+		}
+
+		if timestamp.After(maxTimestamp) {
+			highIndex = msIndex - 1
+			continue
+		}
+
+		targetIndex = msIndex
+		lowIndex = msIndex + 1
+	}
+
+	if targetIndex == 0 {
+		return 0, ErrNoPruningNeeded
+	}
+
+	return targetIndex, nil
 }
 
 // pruneUnreferencedMessages prunes all unreferenced messages from the database for the given milestone
-func (s *SnapshotManager) pruneUnreferencedMessages(targetIndex milestone.Index) (msgCountDeleted int, msgCountChecked int) {
+func (s *SnapshotManager) pruneUnreferencedMessages(targetIndex milestone.Index, pinnedMilestones map[milestone.Index]struct{}) (msgCountDeleted int, msgCountChecked int) {
 
 	messageIDsToDeleteMap := make(map[string]struct{})
 
@@ -99,19 +198,27 @@ func (s *SnapshotManager) pruneUnreferencedMessages(targetIndex milestone.Index)
 		messageIDsToDeleteMap[messageIDMapKey] = struct{}{}
 	}
 
+	msgCountChecked = len(messageIDsToDeleteMap)
+	s.keepPinnedMessages(messageIDsToDeleteMap, pinnedMilestones)
+
 	msgCountDeleted = s.pruneMessages(messageIDsToDeleteMap)
 	s.storage.DeleteUnreferencedMessages(targetIndex)
 
-	return msgCountDeleted, len(messageIDsToDeleteMap)
+	return msgCountDeleted, msgCountChecked
 }
 
-// pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone
-func (s *SnapshotManager) pruneMilestone(milestoneIndex milestone.Index, receiptMigratedAtIndex ...uint32) error {
+// pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone.
+// The milestone metadata is kept if the milestone referenced pinned messages.
+func (s *SnapshotManager) pruneMilestone(milestoneIndex milestone.Index, keepMilestone bool, receiptMigratedAtIndex ...uint32) error {
 
 	if err := s.utxoManager.PruneMilestoneIndexWithoutLocking(milestoneIndex, s.pruneReceipts, receiptMigratedAtIndex...); err != nil {
 		return err
 	}
 
+	if keepMilestone {
+		return nil
+	}
+
 	s.storage.DeleteMilestone(milestoneIndex)
 
 	return nil
@@ -146,7 +253,34 @@ func (s *SnapshotManager) pruneMessages(messageIDsToDeleteMap map[string]struct{
 	return len(messageIDsToDeleteMap)
 }
 
-func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milestone.Index) (milestone.Index, error) {
+// adjustPruningTargetIndex checks whether the database can be pruned up to the given target index.
+// The target index is lowered if it is too close to the snapshot index.
+func adjustPruningTargetIndex(snapshotInfo *storage.SnapshotInfo, targetIndex milestone.Index, solidEntryPointCheckThresholdPast milestone.Index, additionalPruningThreshold milestone.Index) (milestone.Index, error) {
+
+	if snapshotInfo.SnapshotIndex < solidEntryPointCheckThresholdPast+additionalPruningThreshold+1 {
+		// Not enough history
+		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", solidEntryPointCheckThresholdPast+additionalPruningThreshold+1, targetIndex)
+	}
+
+	targetIndexMax := snapshotInfo.SnapshotIndex - solidEntryPointCheckThresholdPast - additionalPruningThreshold - 1
+	if targetIndex > targetIndexMax {
+		targetIndex = targetIndexMax
+	}
+
+	if snapshotInfo.PruningIndex >= targetIndex {
+		// no pruning needed
+		return 0, errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex, targetIndex)
+	}
+
+	if snapshotInfo.EntryPointIndex+additionalPruningThreshold+1 > targetIndex {
+		// we prune in "additionalPruningThreshold" steps to recalculate the solidEntryPoints
+		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex+additionalPruningThreshold+1, targetIndex)
+	}
+
+	return targetIndex, nil
+}
+
+func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milestone.Index, policy PruningPolicy) (milestone.Index, error) {
 
 	if err := utils.ReturnErrIfCtxDone(ctx, common.ErrOperationAborted); err != nil {
 		// do not prune the database if the node was shut down
@@ -163,32 +297,26 @@ func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milesto
 	}
 
 	//lint:ignore SA5011 nil pointer is already checked before with a panic
-	if snapshotInfo.SnapshotIndex < s.solidEntryPointCheckThresholdPast+s.additionalPruningThreshold+1 {
-		// Not enough history
-		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", s.solidEntryPointCheckThresholdPast+s.additionalPruningThreshold+1, targetIndex)
+	targetIndex, err := adjustPruningTargetIndex(snapshotInfo, targetIndex, s.solidEntryPointCheckThresholdPast, s.additionalPruningThreshold)
+	if err != nil {
+		return 0, err
 	}
 
-	targetIndexMax := snapshotInfo.SnapshotIndex - s.solidEntryPointCheckThresholdPast - s.additionalPruningThreshold - 1
-	if targetIndex > targetIndexMax {
-		targetIndex = targetIndexMax
-	}
+	s.setIsPruning(true)
+	defer s.setIsPruning(false)
 
-	if snapshotInfo.PruningIndex >= targetIndex {
-		// no pruning needed
-		return 0, errors.Wrapf(ErrNoPruningNeeded, "pruning index: %d, target index: %d", snapshotInfo.PruningIndex, targetIndex)
-	}
+	s.LogInfof("Pruning database up to milestone %d (policy: %s)...", targetIndex, policy)
+	s.Events.PruningTriggered.Trigger(&PruningTrigger{Policy: policy, TargetIndex: targetIndex})
 
-	if snapshotInfo.EntryPointIndex+s.additionalPruningThreshold+1 > targetIndex {
-		// we prune in "additionalPruningThreshold" steps to recalculate the solidEntryPoints
-		return 0, errors.Wrapf(ErrNotEnoughHistory, "minimum index: %d, target index: %d", snapshotInfo.EntryPointIndex+s.additionalPruningThreshold+1, targetIndex)
+	// the pinned messages are kept together with the milestones that referenced them
+	pinnedMilestones, err := s.pinnedMilestones()
+	if err != nil {
+		return 0, err
 	}
 
-	s.setIsPruning(true)
-	defer s.setIsPruning(false)
-
 	// calculate solid entry points for the new end of the tangle history
 	var solidEntryPoints []*storage.SolidEntryPoint
-	err := forEachSolidEntryPoint(
+	err = forEachSolidEntryPoint(
 		ctx,
 		s.storage,
 		targetIndex,
@@ -219,7 +347,7 @@ func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milesto
 	}
 
 	// unreferenced msgs have to be pruned for PruningIndex as well, since this could be CMI at startup of the node
-	s.pruneUnreferencedMessages(snapshotInfo.PruningIndex)
+	s.pruneUnreferencedMessages(snapshotInfo.PruningIndex, pinnedMilestones)
 
 	// Iterate through all milestones that have to be pruned
 	for milestoneIndex := snapshotInfo.PruningIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
@@ -232,7 +360,7 @@ func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milesto
 		s.LogInfof("Pruning milestone (%d)...", milestoneIndex)
 
 		timeStart := time.Now()
-		txCountDeleted, msgCountChecked := s.pruneUnreferencedMessages(milestoneIndex)
+		txCountDeleted, msgCountChecked := s.pruneUnreferencedMessages(milestoneIndex, pinnedMilestones)
 		timePruneUnreferencedMessages := time.Now()
 
 		cachedMilestone := s.storage.CachedMilestoneOrNil(milestoneIndex) // milestone +1
@@ -276,6 +404,23 @@ func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milesto
 			continue
 		}
 
+		if s.storage.ColdStorage() != nil {
+			// archive nodes move the milestone to the cold storage instead of deleting it.
+			// pruning is stopped if the milestone can't be archived, so that no history is lost.
+			coneMessageIDs := make(hornet.MessageIDs, 0, len(messageIDsToDeleteMap))
+			for messageIDMapKey := range messageIDsToDeleteMap {
+				coneMessageIDs = append(coneMessageIDs, hornet.MessageIDFromMapKey(messageIDMapKey))
+			}
+
+			if err := s.storage.ArchiveMilestoneWithoutLocking(milestoneIndex, coneMessageIDs); err != nil {
+				return 0, errors.Wrapf(ErrArchivingFailed, "milestone %d: %s", milestoneIndex, err)
+			}
+		}
+		timeArchiveMilestone := time.Now()
+
+		msgCountKept := s.keepPinnedMessages(messageIDsToDeleteMap, pinnedMilestones)
+		_, keepMilestone := pinnedMilestones[milestoneIndex]
+
 		// check whether milestone contained receipt and delete it accordingly
 		cachedMsgMilestone := s.storage.MilestoneCachedMessageOrNil(milestoneIndex) // message +1
 		if cachedMsgMilestone == nil {
@@ -289,14 +434,14 @@ func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milesto
 			migratedAtIndex = append(migratedAtIndex, r.MigratedAt)
 		}
 
-		if err := s.pruneMilestone(milestoneIndex, migratedAtIndex...); err != nil {
+		if err := s.pruneMilestone(milestoneIndex, keepMilestone, migratedAtIndex...); err != nil {
 			s.LogWarnf("Pruning milestone (%d) failed! %s", milestoneIndex, err)
 		}
 		timePruneMilestone := time.Now()
 
 		cachedMsgMilestone.Release(true) // message -1
 
-		msgCountChecked += len(messageIDsToDeleteMap)
+		msgCountChecked += len(messageIDsToDeleteMap) + msgCountKept
 		txCountDeleted += s.pruneMessages(messageIDsToDeleteMap)
 		timePruneMessages := time.Now()
 
@@ -312,9 +457,11 @@ func (s *SnapshotManager) pruneDatabase(ctx context.Context, targetIndex milesto
 		timePruningMilestoneIndexChanged := time.Now()
 
 		s.Events.PruningMetricsUpdated.Trigger(&PruningMetrics{
+			Policy:                               policy,
 			DurationPruneUnreferencedMessages:    timePruneUnreferencedMessages.Sub(timeStart),
 			DurationTraverseMilestoneCone:        timeTraverseMilestoneCone.Sub(timePruneUnreferencedMessages),
-			DurationPruneMilestone:               timePruneMilestone.Sub(timeTraverseMilestoneCone),
+			DurationArchiveMilestone:             timeArchiveMilestone.Sub(timeTraverseMilestoneCone),
+			DurationPruneMilestone:               timePruneMilestone.Sub(timeArchiveMilestone),
 			DurationPruneMessages:                timePruneMessages.Sub(timePruneMilestone),
 			DurationSetSnapshotInfo:              timeSetSnapshotInfo.Sub(timePruneMessages),
 			DurationPruningMilestoneIndexChanged: timePruningMilestoneIndexChanged.Sub(timeSetSnapshotInfo),
@@ -347,14 +494,14 @@ func (s *SnapshotManager) PruneDatabaseByDepth(ctx context.Context, depth milest
 		return 0, ErrNotEnoughHistory
 	}
 
-	return s.pruneDatabase(ctx, confirmedMilestoneIndex-depth)
+	return s.pruneDatabase(ctx, confirmedMilestoneIndex-depth, PruningPolicyManual)
 }
 
 func (s *SnapshotManager) PruneDatabaseByTargetIndex(ctx context.Context, targetIndex milestone.Index) (milestone.Index, error) {
 	s.snapshotLock.Lock()
 	defer s.snapshotLock.Unlock()
 
-	return s.pruneDatabase(ctx, targetIndex)
+	return s.pruneDatabase(ctx, targetIndex, PruningPolicyManual)
 }
 
 func (s *SnapshotManager) PruneDatabaseBySize(ctx context.Context, targetSizeBytes int64) (milestone.Index, error) {
@@ -366,5 +513,5 @@ func (s *SnapshotManager) PruneDatabaseBySize(ctx context.Context, targetSizeByt
 		return 0, err
 	}
 
-	return s.pruneDatabase(ctx, targetIndex)
+	return s.pruneDatabase(ctx, targetIndex, PruningPolicyManual)
 }
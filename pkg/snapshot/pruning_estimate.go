@@ -0,0 +1,303 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/dag"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+// PruningEstimate holds the estimated effects of pruning the database up to a target index.
+// The sizes are the raw sizes of the keys and values in the databases,
+// the space actually freed on disk depends on the compression and compaction of the database engine.
+type PruningEstimate struct {
+	// StartIndex is the first milestone that would be pruned.
+	StartIndex milestone.Index
+	// TargetIndex is the last milestone that would be pruned.
+	TargetIndex milestone.Index
+	// MilestonesCount is the amount of milestones that would be pruned.
+	MilestonesCount int
+	// MessagesCount is the amount of referenced messages that would be pruned.
+	MessagesCount int
+	// UnreferencedMessagesCount is the amount of unreferenced messages that would be pruned.
+	UnreferencedMessagesCount int
+	// ChildrenCount is the amount of parent/child references that would be pruned.
+	ChildrenCount int
+	// TangleBytes is the size of the tangle data that would be pruned.
+	TangleBytes int64
+	// LedgerDiffsBytes is the size of the ledger diffs (including the spent outputs) that would be pruned.
+	LedgerDiffsBytes int64
+	// EstimatedFreedBytes is the estimated amount of bytes freed by pruning.
+	EstimatedFreedBytes int64
+}
+
+// messageSize returns the size of the message, its metadata and the references to its parents.
+func messageSize(cachedMsg *storage.CachedMessage) (size int64, childrenCount int) {
+	msg := cachedMsg.Message()
+	metadata := cachedMsg.Metadata()
+
+	size = int64(len(msg.ObjectStorageKey()) + len(msg.ObjectStorageValue()))
+	size += int64(len(metadata.ObjectStorageKey()) + len(metadata.ObjectStorageValue()))
+
+	// every parent contains a reference to this message as a child
+	childrenCount = len(msg.Parents())
+	for _, parent := range msg.Parents() {
+		size += int64(len(storage.NewChild(parent, msg.MessageID()).ObjectStorageKey()))
+	}
+
+	return size, childrenCount
+}
+
+// pruningEstimator adds the data that would be pruned to an estimate.
+// Pinned messages and the milestones that referenced them are kept by the pruning, so they are skipped.
+type pruningEstimator struct {
+	dbStorage         *storage.Storage
+	pinnedTagPrefixes [][]byte
+	// the indexes of the milestones that referenced pinned messages.
+	pinnedMilestones map[milestone.Index]struct{}
+	estimate         *PruningEstimate
+}
+
+// isMessagePinned returns whether the message is pinned or its tag matches one of the pinned tag prefixes.
+func (e *pruningEstimator) isMessagePinned(msg *storage.Message) bool {
+	return e.dbStorage.IsMessagePinned(msg.MessageID()) || matchesPinnedTagPrefix(msg, e.pinnedTagPrefixes)
+}
+
+// estimateUnreferencedMessages adds the unreferenced messages of the given milestone to the estimate.
+func (e *pruningEstimator) estimateUnreferencedMessages(msIndex milestone.Index) {
+
+	for _, messageID := range e.dbStorage.UnreferencedMessageIDs(msIndex) {
+		e.estimate.TangleBytes += int64(len(storage.NewUnreferencedMessage(msIndex, messageID).ObjectStorageKey()))
+
+		cachedMsg := e.dbStorage.CachedMessageOrNil(messageID) // message +1
+		if cachedMsg == nil {
+			continue
+		}
+
+		if cachedMsg.Metadata().IsReferenced() {
+			// message was referenced in the meantime, it is pruned together with its milestone
+			cachedMsg.Release(true) // message -1
+			continue
+		}
+
+		if e.isMessagePinned(cachedMsg.Message()) {
+			// pinned messages are kept
+			cachedMsg.Release(true) // message -1
+			continue
+		}
+
+		size, childrenCount := messageSize(cachedMsg)
+		cachedMsg.Release(true) // message -1
+
+		e.estimate.UnreferencedMessagesCount++
+		e.estimate.ChildrenCount += childrenCount
+		e.estimate.TangleBytes += size
+	}
+}
+
+// estimateMilestoneCone adds the milestone and the messages referenced by it to the estimate.
+// The milestone and its milestone message are kept if the milestone referenced pinned messages.
+func (e *pruningEstimator) estimateMilestoneCone(ctx context.Context, msIndex milestone.Index) error {
+
+	cachedMilestone := e.dbStorage.CachedMilestoneOrNil(msIndex) // milestone +1
+	if cachedMilestone == nil {
+		// milestone not found, pruning would skip it
+		return nil
+	}
+	defer cachedMilestone.Release(true) // milestone -1
+
+	ms := cachedMilestone.Milestone()
+
+	// the milestone message is added after the traversal,
+	// since the milestone message could be traversed before the pinned messages.
+	var milestoneMessageSize int64
+	var milestoneMessageChildrenCount int
+
+	if err := dag.TraverseParentsOfMessage(
+		ctx,
+		e.dbStorage,
+		ms.MessageID,
+		// traversal stops if no more messages pass the given condition
+		// Caution: condition func is not in DFS order
+		func(cachedMsgMeta *storage.CachedMetadata) (bool, error) { // meta +1
+			defer cachedMsgMeta.Release(true) // meta -1
+			metadata := cachedMsgMeta.Metadata()
+			if bytes.Equal(metadata.MessageID(), ms.MessageID) {
+				// the milestone message is pruned together with its milestone
+				return true, nil
+			}
+
+			// messages of older milestones (including their milestone messages)
+			// are part of the estimate of their own milestone.
+			referenced, at := metadata.ReferencedWithIndex()
+			return referenced && at == msIndex && !metadata.IsMilestone(), nil
+		},
+		// consumer
+		func(cachedMsgMeta *storage.CachedMetadata) error { // meta +1
+			defer cachedMsgMeta.Release(true) // meta -1
+
+			cachedMsg := e.dbStorage.CachedMessageOrNil(cachedMsgMeta.Metadata().MessageID()) // message +1
+			if cachedMsg == nil {
+				return nil
+			}
+			defer cachedMsg.Release(true) // message -1
+
+			if e.isMessagePinned(cachedMsg.Message()) {
+				// pinned messages are kept together with the milestone that referenced them
+				e.pinnedMilestones[msIndex] = struct{}{}
+				return nil
+			}
+
+			size, childrenCount := messageSize(cachedMsg)
+			if bytes.Equal(cachedMsg.Message().MessageID(), ms.MessageID) {
+				milestoneMessageSize, milestoneMessageChildrenCount = size, childrenCount
+				return nil
+			}
+
+			e.estimate.MessagesCount++
+			e.estimate.ChildrenCount += childrenCount
+			e.estimate.TangleBytes += size
+			return nil
+		},
+		// called on missing parents
+		func(parentMessageID hornet.MessageID) error { return nil },
+		// called on solid entry points
+		// Ignore solid entry points (snapshot milestone included)
+		nil,
+		// the pruning target index is also a solid entry point => traverse it anyways
+		true); err != nil {
+		return err
+	}
+
+	if _, pinned := e.pinnedMilestones[msIndex]; pinned {
+		// the milestone and its milestone message are kept
+		return nil
+	}
+
+	e.estimate.MilestonesCount++
+	e.estimate.TangleBytes += int64(len(ms.ObjectStorageKey()) + len(ms.ObjectStorageValue()))
+
+	if milestoneMessageSize > 0 {
+		e.estimate.MessagesCount++
+		e.estimate.ChildrenCount += milestoneMessageChildrenCount
+		e.estimate.TangleBytes += milestoneMessageSize
+	}
+
+	return nil
+}
+
+// EstimatePruningFromStorage estimates the effects of pruning the database up to the given target index.
+// The target index is adjusted like for pruning, the database is not modified.
+// Messages that are pinned or match one of the pinned tag prefixes are kept by the pruning
+// together with the milestones that referenced them, so they are not part of the estimate.
+func EstimatePruningFromStorage(
+	ctx context.Context,
+	dbStorage *storage.Storage,
+	utxoManager *utxo.Manager,
+	targetIndex milestone.Index,
+	solidEntryPointCheckThresholdPast milestone.Index,
+	additionalPruningThreshold milestone.Index,
+	pinnedTagPrefixes [][]byte,
+) (*PruningEstimate, error) {
+
+	snapshotInfo := dbStorage.SnapshotInfo()
+	if snapshotInfo == nil {
+		return nil, errors.Wrap(ErrCritical, "no snapshot info found")
+	}
+
+	targetIndex, err := adjustPruningTargetIndex(snapshotInfo, targetIndex, solidEntryPointCheckThresholdPast, additionalPruningThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	pinnedMilestones, err := pinnedMilestonesFromStorage(dbStorage)
+	if err != nil {
+		return nil, err
+	}
+
+	estimator := &pruningEstimator{
+		dbStorage:         dbStorage,
+		pinnedTagPrefixes: pinnedTagPrefixes,
+		pinnedMilestones:  pinnedMilestones,
+		estimate: &PruningEstimate{
+			StartIndex:  snapshotInfo.PruningIndex + 1,
+			TargetIndex: targetIndex,
+		},
+	}
+	estimate := estimator.estimate
+
+	// unreferenced msgs are pruned for PruningIndex as well
+	estimator.estimateUnreferencedMessages(snapshotInfo.PruningIndex)
+
+	for msIndex := estimate.StartIndex; msIndex <= targetIndex; msIndex++ {
+
+		if err := utils.ReturnErrIfCtxDone(ctx, common.ErrOperationAborted); err != nil {
+			return nil, err
+		}
+
+		estimator.estimateUnreferencedMessages(msIndex)
+
+		if err := estimator.estimateMilestoneCone(ctx, msIndex); err != nil {
+			return nil, errors.Wrapf(err, "estimating cone of milestone %d failed", msIndex)
+		}
+
+		// the ledger diffs are pruned even if the milestone is kept
+		ledgerDiffBytes, err := utxoManager.MilestoneDiffPruningSize(msIndex)
+		if err != nil {
+			// ledger diff not found, pruning would skip it
+			continue
+		}
+		estimate.LedgerDiffsBytes += ledgerDiffBytes
+	}
+
+	estimate.EstimatedFreedBytes = estimate.TangleBytes + estimate.LedgerDiffsBytes
+
+	return estimate, nil
+}
+
+// EstimatePruningByTargetIndex estimates the effects of pruning the database up to the given target index.
+// The database is not modified, but snapshotting and pruning are blocked while the estimate is computed.
+func (s *SnapshotManager) EstimatePruningByTargetIndex(ctx context.Context, targetIndex milestone.Index) (*PruningEstimate, error) {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+
+	return EstimatePruningFromStorage(ctx, s.storage, s.utxoManager, targetIndex, s.solidEntryPointCheckThresholdPast, s.additionalPruningThreshold, s.pinnedTagPrefixes)
+}
+
+// EstimatePruningByDepth estimates the effects of pruning the database up to the given depth below the confirmed milestone.
+// The database is not modified, but snapshotting and pruning are blocked while the estimate is computed.
+func (s *SnapshotManager) EstimatePruningByDepth(ctx context.Context, depth milestone.Index) (*PruningEstimate, error) {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+
+	confirmedMilestoneIndex := s.syncManager.ConfirmedMilestoneIndex()
+
+	if confirmedMilestoneIndex <= depth {
+		// Not enough history
+		return nil, ErrNotEnoughHistory
+	}
+
+	return EstimatePruningFromStorage(ctx, s.storage, s.utxoManager, confirmedMilestoneIndex-depth, s.solidEntryPointCheckThresholdPast, s.additionalPruningThreshold, s.pinnedTagPrefixes)
+}
+
+// EstimatePruningBySize estimates the effects of pruning the database to the given target size.
+// The database is not modified, but snapshotting and pruning are blocked while the estimate is computed.
+func (s *SnapshotManager) EstimatePruningBySize(ctx context.Context, targetSizeBytes int64) (*PruningEstimate, error) {
+	s.snapshotLock.Lock()
+	defer s.snapshotLock.Unlock()
+
+	targetIndex, err := s.calcTargetIndexBySize(targetSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return EstimatePruningFromStorage(ctx, s.storage, s.utxoManager, targetIndex, s.solidEntryPointCheckThresholdPast, s.additionalPruningThreshold, s.pinnedTagPrefixes)
+}
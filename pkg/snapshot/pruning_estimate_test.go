@@ -0,0 +1,183 @@
+package snapshot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/database"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/logger"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	BelowMaxDepth = 5
+	MinPoWScore   = 10.0
+)
+
+func storeSize(t *testing.T, store kvstore.KVStore) int64 {
+	var size int64
+	require.NoError(t, store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		size += int64(len(key) + len(value))
+		return true
+	}))
+	return size
+}
+
+func newTestSnapshotManager(te *testsuite.TestEnvironment, pinnedTagPrefixes [][]byte) *snapshot.SnapshotManager {
+
+	newDatabase := func(store kvstore.KVStore) *database.Database {
+		return database.New("", store, database.EngineMapDB, nil, nil, false, func() bool { return false }, nil)
+	}
+
+	return snapshot.NewSnapshotManager(
+		logger.NewExampleLogger("snapshot"),
+		newDatabase(te.Storage().TangleStore()),
+		newDatabase(te.Storage().UTXOStore()),
+		te.Storage(),
+		te.SyncManager(),
+		te.UTXOManager(),
+		uint64(te.NetworkID()),
+		"",
+		testsuite.DeSerializationParameters,
+		"", "",
+		0, false, snapshot.DefaultCompression,
+		nil, nil,
+		BelowMaxDepth, BelowMaxDepth,
+		2,
+		BelowMaxDepth, 10,
+		false, 0,
+		false, 0, 0, 0,
+		false, 0,
+		pinnedTagPrefixes,
+		false,
+	)
+}
+
+func TestEstimatePruning(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	_, _ = te.BuildTangle(10, BelowMaxDepth, 20, 5, 10,
+		nil,
+		func(messages hornet.MessageIDs, messagesPerMilestones []hornet.MessageIDs) hornet.MessageIDs {
+			return hornet.MessageIDs{messages[len(messages)-1]}
+		},
+		nil,
+	)
+
+	confirmedMilestoneIndex := te.SyncManager().ConfirmedMilestoneIndex()
+	require.NoError(t, te.Storage().SetSnapshotMilestone(uint64(te.NetworkID()), confirmedMilestoneIndex, 0, 0, time.Now()))
+
+	snapshotManager := newTestSnapshotManager(te, nil)
+
+	targetIndex := milestone.Index(8)
+
+	tangleSize := storeSize(t, te.Storage().TangleStore())
+	utxoSize := storeSize(t, te.Storage().UTXOStore())
+
+	estimate, err := snapshotManager.EstimatePruningByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+
+	// the estimate doesn't modify the databases
+	require.Equal(t, tangleSize, storeSize(t, te.Storage().TangleStore()))
+	require.Equal(t, utxoSize, storeSize(t, te.Storage().UTXOStore()))
+
+	require.Equal(t, milestone.Index(1), estimate.StartIndex)
+	require.Equal(t, targetIndex, estimate.TargetIndex)
+	require.Equal(t, int(targetIndex), estimate.MilestonesCount)
+
+	require.Positive(t, estimate.ChildrenCount)
+	require.Positive(t, estimate.TangleBytes)
+	require.Positive(t, estimate.LedgerDiffsBytes)
+	require.Equal(t, estimate.TangleBytes+estimate.LedgerDiffsBytes, estimate.EstimatedFreedBytes)
+
+	// the estimate matches the actual pruning
+	var messageIDs hornet.MessageIDs
+	te.Storage().NonCachedStorage().ForEachMessageID(func(messageID hornet.MessageID) bool {
+		messageIDs = append(messageIDs, messageID)
+		return true
+	})
+
+	prunedIndex, err := snapshotManager.PruneDatabaseByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+	require.Equal(t, targetIndex, prunedIndex)
+
+	var prunedMessagesCount int
+	for _, messageID := range messageIDs {
+		if !te.Storage().ContainsMessage(messageID) {
+			prunedMessagesCount++
+		}
+	}
+	require.Equal(t, prunedMessagesCount, estimate.MessagesCount+estimate.UnreferencedMessagesCount)
+
+	// the target index is adjusted like for pruning
+	estimate, err = snapshotManager.EstimatePruningByTargetIndex(context.Background(), confirmedMilestoneIndex)
+	require.NoError(t, err)
+	require.Less(t, estimate.TargetIndex, confirmedMilestoneIndex)
+
+	_, err = snapshotManager.EstimatePruningByDepth(context.Background(), confirmedMilestoneIndex)
+	require.ErrorIs(t, err, snapshot.ErrNotEnoughHistory)
+}
+
+func TestEstimatePruningSkipsPinnedMessages(t *testing.T) {
+
+	te := testsuite.SetupTestEnvironment(t, &iotago.Ed25519Address{}, 0, BelowMaxDepth, MinPoWScore, false)
+	defer te.CleanupTestEnvironment(true)
+
+	messages, _ := te.BuildTangle(10, BelowMaxDepth, 20, 5, 10,
+		nil,
+		func(messages hornet.MessageIDs, messagesPerMilestones []hornet.MessageIDs) hornet.MessageIDs {
+			return hornet.MessageIDs{messages[len(messages)-1]}
+		},
+		nil,
+	)
+
+	confirmedMilestoneIndex := te.SyncManager().ConfirmedMilestoneIndex()
+	require.NoError(t, te.Storage().SetSnapshotMilestone(uint64(te.NetworkID()), confirmedMilestoneIndex, 0, 0, time.Now()))
+
+	// the messages of the test tangle are tagged with their number, message "1" is pinned by its tag
+	snapshotManager := newTestSnapshotManager(te, [][]byte{[]byte("1")})
+	require.NoError(t, snapshotManager.PinMessage(messages[len(messages)/2]))
+
+	targetIndex := milestone.Index(8)
+
+	estimate, err := snapshotManager.EstimatePruningByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+
+	var messageIDs hornet.MessageIDs
+	te.Storage().NonCachedStorage().ForEachMessageID(func(messageID hornet.MessageID) bool {
+		messageIDs = append(messageIDs, messageID)
+		return true
+	})
+
+	prunedIndex, err := snapshotManager.PruneDatabaseByTargetIndex(context.Background(), targetIndex)
+	require.NoError(t, err)
+	require.Equal(t, targetIndex, prunedIndex)
+
+	// the estimate matches the actual pruning, which keeps the pinned messages and the milestones that referenced them
+	var prunedMessagesCount int
+	for _, messageID := range messageIDs {
+		if !te.Storage().ContainsMessage(messageID) {
+			prunedMessagesCount++
+		}
+	}
+	require.Equal(t, prunedMessagesCount, estimate.MessagesCount+estimate.UnreferencedMessagesCount)
+
+	var prunedMilestonesCount int
+	for msIndex := milestone.Index(1); msIndex <= targetIndex; msIndex++ {
+		if !te.Storage().ContainsMilestone(msIndex) {
+			prunedMilestonesCount++
+		}
+	}
+	require.Less(t, prunedMilestonesCount, int(targetIndex))
+	require.Equal(t, prunedMilestonesCount, estimate.MilestonesCount)
+}
@@ -0,0 +1,66 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/syncmanager"
+	"github.com/gohornet/hornet/pkg/utils"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/hive.go/logger"
+)
+
+func TestCalcTargetIndexByAge(t *testing.T) {
+
+	dbStorage, err := storage.New(mapdb.NewMapDB(), mapdb.NewMapDB())
+	require.NoError(t, err)
+
+	syncManager, err := syncmanager.New(dbStorage.UTXOManager(), 15)
+	require.NoError(t, err)
+
+	// one milestone per hour, the newest one was issued now
+	now := time.Now()
+	confirmedMilestoneIndex := milestone.Index(100)
+	for msIndex := milestone.Index(1); msIndex <= confirmedMilestoneIndex; msIndex++ {
+		timestamp := now.Add(-time.Duration(confirmedMilestoneIndex-msIndex) * time.Hour)
+		cachedMilestone, _ := dbStorage.StoreMilestoneIfAbsent(msIndex, hornet.NullMessageID(), timestamp) // milestone +1
+		require.NotNil(t, cachedMilestone)
+		cachedMilestone.Release(true) // milestone -1
+	}
+	require.NoError(t, syncManager.SetConfirmedMilestoneIndex(confirmedMilestoneIndex, false))
+	require.NoError(t, dbStorage.SetSnapshotMilestone(0, 10, 10, 10, now))
+
+	s := &SnapshotManager{
+		WrappedLogger: utils.NewWrappedLogger(logger.NewExampleLogger("snapshot")),
+		storage:       dbStorage,
+		syncManager:   syncManager,
+	}
+
+	// pruning by age deactivated
+	_, err = s.calcTargetIndexByAge()
+	require.ErrorIs(t, err, ErrNoPruningNeeded)
+
+	s.pruningAgeEnabled = true
+
+	// milestone 70 was issued 30h and some milliseconds ago
+	s.pruningAgeMaxAge = 30 * time.Hour
+	targetIndex, err := s.calcTargetIndexByAge()
+	require.NoError(t, err)
+	require.Equal(t, milestone.Index(70), targetIndex)
+
+	// everything older than the newest milestone gets pruned
+	s.pruningAgeMaxAge = time.Minute
+	targetIndex, err = s.calcTargetIndexByAge()
+	require.NoError(t, err)
+	require.Equal(t, milestone.Index(99), targetIndex)
+
+	// all milestones which were not pruned yet are younger than the maximum age
+	s.pruningAgeMaxAge = 90 * time.Hour
+	_, err = s.calcTargetIndexByAge()
+	require.ErrorIs(t, err, ErrNoPruningNeeded)
+}
@@ -2,6 +2,7 @@ package snapshot
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -51,6 +52,8 @@ var (
 	ErrNotEnoughHistory                      = errors.New("not enough history")
 	ErrNoPruningNeeded                       = errors.New("no pruning needed")
 	ErrPruningAborted                        = errors.New("pruning was aborted")
+	ErrArchivingFailed                       = errors.New("archiving milestone to the cold storage failed")
+	ErrMessageNotPinned                      = errors.New("message is not pinned")
 	ErrDatabaseCompactionNotSupported        = errors.New("database compaction not supported")
 	ErrDatabaseCompactionRunning             = errors.New("database compaction is running")
 	ErrExistingDeltaSnapshotWrongLedgerIndex = errors.New("existing delta ledger snapshot has wrong ledger index")
@@ -80,7 +83,10 @@ type SnapshotManager struct {
 	snapshotFullPath                     string
 	snapshotDeltaPath                    string
 	deltaSnapshotSizeThresholdPercentage float64
+	deltaChainEnabled                    bool
+	compression                          Compression
 	downloadTargets                      []*DownloadTarget
+	manifestPublicKeys                   []ed25519.PublicKey
 	solidEntryPointCheckThresholdPast    milestone.Index
 	solidEntryPointCheckThresholdFuture  milestone.Index
 	additionalPruningThreshold           milestone.Index
@@ -92,6 +98,9 @@ type SnapshotManager struct {
 	pruningSizeTargetSizeBytes           int64
 	pruningSizeThresholdPercentage       float64
 	pruningSizeCooldownTime              time.Duration
+	pruningAgeEnabled                    bool
+	pruningAgeMaxAge                     time.Duration
+	pinnedTagPrefixes                    [][]byte
 	pruneReceipts                        bool
 
 	snapshotLock          syncutils.Mutex
@@ -117,7 +126,10 @@ func NewSnapshotManager(
 	snapshotFullPath string,
 	snapshotDeltaPath string,
 	deltaSnapshotSizeThresholdPercentage float64,
+	deltaChainEnabled bool,
+	compression Compression,
 	downloadTargets []*DownloadTarget,
+	manifestPublicKeys []ed25519.PublicKey,
 	solidEntryPointCheckThresholdPast milestone.Index,
 	solidEntryPointCheckThresholdFuture milestone.Index,
 	additionalPruningThreshold milestone.Index,
@@ -129,6 +141,9 @@ func NewSnapshotManager(
 	pruningSizeTargetSizeBytes int64,
 	pruningSizeThresholdPercentage float64,
 	pruningSizeCooldownTime time.Duration,
+	pruningAgeEnabled bool,
+	pruningAgeMaxAge time.Duration,
+	pinnedTagPrefixes [][]byte,
 	pruneReceipts bool) *SnapshotManager {
 
 	return &SnapshotManager{
@@ -144,7 +159,10 @@ func NewSnapshotManager(
 		snapshotFullPath:                     snapshotFullPath,
 		snapshotDeltaPath:                    snapshotDeltaPath,
 		deltaSnapshotSizeThresholdPercentage: deltaSnapshotSizeThresholdPercentage,
+		deltaChainEnabled:                    deltaChainEnabled,
+		compression:                          compression,
 		downloadTargets:                      downloadTargets,
+		manifestPublicKeys:                   manifestPublicKeys,
 		solidEntryPointCheckThresholdPast:    solidEntryPointCheckThresholdPast,
 		solidEntryPointCheckThresholdFuture:  solidEntryPointCheckThresholdFuture,
 		additionalPruningThreshold:           additionalPruningThreshold,
@@ -156,10 +174,14 @@ func NewSnapshotManager(
 		pruningSizeTargetSizeBytes:           pruningSizeTargetSizeBytes,
 		pruningSizeThresholdPercentage:       pruningSizeThresholdPercentage,
 		pruningSizeCooldownTime:              pruningSizeCooldownTime,
+		pruningAgeEnabled:                    pruningAgeEnabled,
+		pruningAgeMaxAge:                     pruningAgeMaxAge,
+		pinnedTagPrefixes:                    pinnedTagPrefixes,
 		pruneReceipts:                        pruneReceipts,
 		Events: &Events{
 			SnapshotMilestoneIndexChanged: events.NewEvent(milestone.IndexCaller),
 			SnapshotMetricsUpdated:        events.NewEvent(SnapshotMetricsCaller),
+			PruningTriggered:              events.NewEvent(PruningTriggerCaller),
 			PruningMilestoneIndexChanged:  events.NewEvent(milestone.IndexCaller),
 			PruningMetricsUpdated:         events.NewEvent(PruningMetricsCaller),
 		},
@@ -172,6 +194,12 @@ func (s *SnapshotManager) IsSnapshottingOrPruning() bool {
 	return s.isSnapshotting || s.isPruning
 }
 
+// PruningEnabled tells whether the database is pruned automatically, either by milestones, by size or by age.
+// Nodes without automatic pruning keep the whole history and act as archive nodes.
+func (s *SnapshotManager) PruningEnabled() bool {
+	return s.pruningMilestonesEnabled || s.pruningSizeEnabled || s.pruningAgeEnabled
+}
+
 func (s *SnapshotManager) shouldTakeSnapshot(confirmedMilestoneIndex milestone.Index) bool {
 
 	snapshotInfo := s.storage.SnapshotInfo()
@@ -407,7 +435,7 @@ SnapshotInfo:
 }
 
 // optimalSnapshotType returns the optimal snapshot type
-// based on the file size of the last full and delta snapshot file.
+// based on the file size of the last full and delta snapshot files.
 func (s *SnapshotManager) optimalSnapshotType() (Type, error) {
 	if s.deltaSnapshotSizeThresholdPercentage == 0.0 {
 		// special case => always create a delta snapshot to keep entire milestone diff history
@@ -427,22 +455,44 @@ func (s *SnapshotManager) optimalSnapshotType() (Type, error) {
 		return Full, err
 	}
 
-	deltaSnapshotFileInfo, err := os.Stat(s.snapshotDeltaPath)
-	deltaSnapshotFileExists := !os.IsNotExist(err)
+	deltaSnapshotPaths, err := DeltaSnapshotFilePaths(s.snapshotDeltaPath)
+	if err != nil {
+		// there was another unknown error
+		return Delta, err
+	}
 
-	if !deltaSnapshotFileExists {
+	if len(deltaSnapshotPaths) == 0 {
 		// delta snapshot doesn't exist => create a delta snapshot
 		return Delta, nil
 	}
 
-	if err != nil {
-		// there was another unknown error
-		return Delta, err
+	// the delta snapshot chain consists of all delta snapshot files
+	var deltaSnapshotFilesSize int64
+	for _, deltaSnapshotPath := range deltaSnapshotPaths {
+		deltaSnapshotFileInfo, err := os.Stat(deltaSnapshotPath)
+		if err != nil {
+			return Delta, err
+		}
+		deltaSnapshotFilesSize += deltaSnapshotFileInfo.Size()
+	}
+
+	if s.deltaChainEnabled {
+		tipHeader, err := s.readDeltaChainTipHeader()
+		if err != nil {
+			// the chain can't be continued => start with a new full snapshot
+			s.LogWarnf("creating full snapshot, because the delta snapshot chain is invalid: %s", err)
+			return Full, nil
+		}
+
+		if snapshotInfo := s.storage.SnapshotInfo(); snapshotInfo != nil && snapshotInfo.PruningIndex > tipHeader.SEPMilestoneIndex {
+			// the milestone diffs to continue the chain were already pruned => start with a new full snapshot
+			return Full, nil
+		}
 	}
 
-	// if the file size of the last delta snapshot is bigger than a certain percentage
+	// if the file size of the last delta snapshot files is bigger than a certain percentage
 	// of the full snapshot file, it's more efficient to create a new full snapshot.
-	if int64(float64(fullSnapshotFileInfo.Size())*s.deltaSnapshotSizeThresholdPercentage/100.0) < deltaSnapshotFileInfo.Size() {
+	if int64(float64(fullSnapshotFileInfo.Size())*s.deltaSnapshotSizeThresholdPercentage/100.0) < deltaSnapshotFilesSize {
 		return Full, nil
 	}
 
@@ -451,17 +501,31 @@ func (s *SnapshotManager) optimalSnapshotType() (Type, error) {
 
 // snapshotTypeFilePath returns the default file path
 // for the given snapshot type.
-func (s *SnapshotManager) snapshotTypeFilePath(snapshotType Type) string {
+// if the delta snapshot chain is enabled, the path of the next file in the chain is returned for delta snapshots.
+func (s *SnapshotManager) snapshotTypeFilePath(snapshotType Type) (string, error) {
 	switch snapshotType {
 	case Full:
-		return s.snapshotFullPath
+		return s.snapshotFullPath, nil
 	case Delta:
-		return s.snapshotDeltaPath
+		if !s.deltaChainEnabled {
+			return s.snapshotDeltaPath, nil
+		}
+
+		deltaChainPaths, err := DeltaChainFilePaths(s.snapshotDeltaPath)
+		if err != nil {
+			return "", err
+		}
+		return DeltaChainFilePath(s.snapshotDeltaPath, len(deltaChainPaths)+1), nil
 	default:
 		panic("unknown snapshot type")
 	}
 }
 
+// isDeltaChainFilePath checks whether the given file path is part of the delta snapshot chain.
+func (s *SnapshotManager) isDeltaChainFilePath(filePath string) bool {
+	return filepath.Dir(filepath.Clean(filePath)) == filepath.Clean(DeltaChainDirectory(s.snapshotDeltaPath))
+}
+
 // HandleNewConfirmedMilestoneEvent handles new confirmed milestone events which may trigger a delta snapshot creation and pruning.
 func (s *SnapshotManager) HandleNewConfirmedMilestoneEvent(ctx context.Context, confirmedMilestoneIndex milestone.Index) {
 	if !s.syncManager.IsNodeSynced() {
@@ -479,7 +543,13 @@ func (s *SnapshotManager) HandleNewConfirmedMilestoneEvent(ctx context.Context,
 			return
 		}
 
-		if err := s.createSnapshotWithoutLocking(ctx, snapshotType, confirmedMilestoneIndex-s.snapshotDepth, s.snapshotTypeFilePath(snapshotType), true); err != nil {
+		filePath, err := s.snapshotTypeFilePath(snapshotType)
+		if err != nil {
+			s.LogWarnf("%s: %s", ErrSnapshotCreationFailed, err)
+			return
+		}
+
+		if err := s.createSnapshotWithoutLocking(ctx, snapshotType, confirmedMilestoneIndex-s.snapshotDepth, filePath, true); err != nil {
 			if errors.Is(err, ErrCritical) {
 				s.LogPanicf("%s: %s", ErrSnapshotCreationFailed, err)
 			}
@@ -492,17 +562,27 @@ func (s *SnapshotManager) HandleNewConfirmedMilestoneEvent(ctx context.Context,
 		}
 	}
 
+	// the policies are combined, the most restrictive one (with the highest target index) wins.
 	var targetIndex milestone.Index = 0
+	var policy PruningPolicy
 	if s.pruningMilestonesEnabled && confirmedMilestoneIndex > s.pruningMilestonesMaxMilestonesToKeep {
 		targetIndex = confirmedMilestoneIndex - s.pruningMilestonesMaxMilestonesToKeep
+		policy = PruningPolicyMilestones
 	}
 
-	pruningBySize := false
 	if s.pruningSizeEnabled && (s.lastPruningBySizeTime.IsZero() || time.Since(s.lastPruningBySizeTime) > s.pruningSizeCooldownTime) {
 		targetIndexSize, err := s.calcTargetIndexBySize()
 		if err == nil && ((targetIndex == 0) || (targetIndex < targetIndexSize)) {
 			targetIndex = targetIndexSize
-			pruningBySize = true
+			policy = PruningPolicySize
+		}
+	}
+
+	if s.pruningAgeEnabled {
+		targetIndexAge, err := s.calcTargetIndexByAge()
+		if err == nil && ((targetIndex == 0) || (targetIndex < targetIndexAge)) {
+			targetIndex = targetIndexAge
+			policy = PruningPolicyAge
 		}
 	}
 
@@ -511,11 +591,11 @@ func (s *SnapshotManager) HandleNewConfirmedMilestoneEvent(ctx context.Context,
 		return
 	}
 
-	if _, err := s.pruneDatabase(ctx, targetIndex); err != nil {
+	if _, err := s.pruneDatabase(ctx, targetIndex, policy); err != nil {
 		s.LogDebugf("pruning aborted: %v", err)
 	}
 
-	if pruningBySize {
+	if policy == PruningPolicySize {
 		s.lastPruningBySizeTime = time.Now()
 	}
 }
@@ -532,20 +612,17 @@ func (s *SnapshotManager) SnapshotsFilesLedgerIndex() (milestone.Index, error) {
 		return 0, errors.New("no snapshot files available")
 	}
 
-	fullHeader, err := ReadSnapshotHeaderFromFile(s.snapshotFullPath)
+	deltaPaths, err := DeltaSnapshotFilePaths(s.snapshotDeltaPath)
 	if err != nil {
 		return 0, err
 	}
 
-	var deltaHeader *ReadFileHeader
-	if snapAvail == snapshotAvailBoth {
-		deltaHeader, err = ReadSnapshotHeaderFromFile(s.snapshotDeltaPath)
-		if err != nil {
-			return 0, err
-		}
+	fullHeader, deltaHeaders, err := ReadSnapshotFilesHeaders(s.snapshotFullPath, deltaPaths...)
+	if err != nil {
+		return 0, err
 	}
 
-	return getSnapshotFilesLedgerIndex(fullHeader, deltaHeader), nil
+	return lastSnapshotHeader(fullHeader, deltaHeaders).SEPMilestoneIndex, nil
 }
 
 // ImportSnapshots imports snapshot data from the configured file paths.
@@ -571,24 +648,33 @@ func (s *SnapshotManager) ImportSnapshots(ctx context.Context) error {
 		return errors.New("no snapshot files available after snapshot download")
 	}
 
-	if err = s.LoadSnapshotFromFile(ctx, Full, s.snapshotFullPath); err != nil {
-		_ = s.storage.MarkDatabasesCorrupted()
+	deltaPaths, err := DeltaSnapshotFilePaths(s.snapshotDeltaPath)
+	if err != nil {
 		return err
 	}
 
-	if snapAvail == snapshotAvailOnlyFull {
-		return nil
+	// check that every delta snapshot file is based on the preceding snapshot file before importing anything
+	if _, _, err := ReadSnapshotFilesHeaders(s.snapshotFullPath, deltaPaths...); err != nil {
+		return err
 	}
 
-	if err = s.LoadSnapshotFromFile(ctx, Delta, s.snapshotDeltaPath); err != nil {
+	if err = s.LoadSnapshotFromFile(ctx, Full, s.snapshotFullPath); err != nil {
 		_ = s.storage.MarkDatabasesCorrupted()
 		return err
 	}
 
+	for _, deltaPath := range deltaPaths {
+		if err = s.LoadSnapshotFromFile(ctx, Delta, deltaPath); err != nil {
+			_ = s.storage.MarkDatabasesCorrupted()
+			return err
+		}
+	}
+
 	return nil
 }
 
 // checks that either both snapshot files are available, only the full snapshot or none.
+// the delta snapshot files include the files of the delta snapshot chain.
 func (s *SnapshotManager) checkSnapshotFilesAvailability(fullPath string, deltaPath string) (snapshotAvailability, error) {
 	switch {
 	case len(fullPath) == 0:
@@ -598,18 +684,22 @@ func (s *SnapshotManager) checkSnapshotFilesAvailability(fullPath string, deltaP
 	}
 
 	_, fullSnapshotStatErr := os.Stat(fullPath)
-	_, deltaSnapshotStatErr := os.Stat(deltaPath)
+	deltaPaths, err := DeltaSnapshotFilePaths(deltaPath)
+	if err != nil {
+		return 0, err
+	}
+	deltaSnapshotExists := len(deltaPaths) > 0
 
 	switch {
-	case os.IsNotExist(fullSnapshotStatErr) && deltaSnapshotStatErr == nil:
+	case os.IsNotExist(fullSnapshotStatErr) && deltaSnapshotExists:
 		// only having the delta snapshot file does not make sense,
 		// as it relies on a full snapshot file to be available.
 		// downloading the full snapshot would not help, as it will probably
 		// be incompatible with the delta snapshot index.
-		return 0, fmt.Errorf("%w: there exists a delta snapshot but not a full snapshot file, delete the delta snapshot file and restart", ErrInvalidSnapshotAvailabilityState)
-	case os.IsNotExist(fullSnapshotStatErr) && os.IsNotExist(deltaSnapshotStatErr):
+		return 0, fmt.Errorf("%w: there exists a delta snapshot but not a full snapshot file, delete the delta snapshot files and restart", ErrInvalidSnapshotAvailabilityState)
+	case os.IsNotExist(fullSnapshotStatErr) && !deltaSnapshotExists:
 		return snapshotAvailNone, nil
-	case fullSnapshotStatErr == nil && os.IsNotExist(deltaSnapshotStatErr):
+	case fullSnapshotStatErr == nil && !deltaSnapshotExists:
 		return snapshotAvailOnlyFull, nil
 	default:
 		return snapshotAvailBoth, nil
@@ -0,0 +1,173 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// the suffix of the directory that contains the chained delta snapshot files, which is placed next to the delta snapshot file.
+	deltaChainDirectorySuffix = "_chain"
+)
+
+var (
+	// ErrDeltaChainInvalid is returned if a file of a delta snapshot chain is missing.
+	ErrDeltaChainInvalid = errors.New("invalid delta snapshot chain")
+)
+
+// DeltaChainDirectory returns the directory of the chained delta snapshot files that belong to the given delta snapshot file path.
+// e.g. "snapshots/mainnet/delta_snapshot.bin" => "snapshots/mainnet/delta_snapshot_chain"
+func DeltaChainDirectory(deltaPath string) string {
+	return strings.TrimSuffix(deltaPath, filepath.Ext(deltaPath)) + deltaChainDirectorySuffix
+}
+
+// DeltaChainFilePath returns the path of the chained delta snapshot file with the given number.
+// The numbers of the files in a chain start at 1.
+// e.g. "snapshots/mainnet/delta_snapshot.bin", 3 => "snapshots/mainnet/delta_snapshot_chain/000003.bin"
+func DeltaChainFilePath(deltaPath string, number int) string {
+	return filepath.Join(DeltaChainDirectory(deltaPath), fmt.Sprintf("%06d%s", number, filepath.Ext(deltaPath)))
+}
+
+// DeltaChainFilePaths returns the paths of the existing chained delta snapshot files
+// that belong to the given delta snapshot file path, in the order they have to be applied.
+func DeltaChainFilePaths(deltaPath string) ([]string, error) {
+
+	entries, err := os.ReadDir(DeltaChainDirectory(deltaPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read delta snapshot chain directory: %w", err)
+	}
+
+	ext := filepath.Ext(deltaPath)
+
+	var numbers []int
+	for _, entry := range entries {
+		// temporary files and partial downloads have a different suffix
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
+			continue
+		}
+
+		number, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ext))
+		if err != nil || number < 1 {
+			continue
+		}
+
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	paths := make([]string, 0, len(numbers))
+	for i, number := range numbers {
+		if number != i+1 {
+			return nil, errors.Wrapf(ErrDeltaChainInvalid, "chained delta snapshot file %s is missing", DeltaChainFilePath(deltaPath, i+1))
+		}
+		paths = append(paths, DeltaChainFilePath(deltaPath, number))
+	}
+
+	return paths, nil
+}
+
+// DeltaSnapshotFilePaths returns the paths of all existing delta snapshot files that belong to the given delta snapshot file path,
+// in the order they have to be applied. These are the delta snapshot file itself, followed by the chained delta snapshot files.
+func DeltaSnapshotFilePaths(deltaPath string) ([]string, error) {
+
+	var paths []string
+
+	_, err := os.Stat(deltaPath)
+	switch {
+	case err == nil:
+		paths = append(paths, deltaPath)
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("unable to check delta snapshot file: %w", err)
+	}
+
+	chainPaths, err := DeltaChainFilePaths(deltaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(paths, chainPaths...), nil
+}
+
+// RemoveDeltaChainFiles removes all chained delta snapshot files that belong to the given delta snapshot file path.
+func RemoveDeltaChainFiles(deltaPath string) error {
+	if err := os.RemoveAll(DeltaChainDirectory(deltaPath)); err != nil {
+		return fmt.Errorf("deleting delta snapshot chain failed: %w", err)
+	}
+	return nil
+}
+
+// VerifyDeltaSnapshotLink checks that the given delta snapshot is based on the given preceding snapshot file.
+func VerifyDeltaSnapshotLink(previousHeader *ReadFileHeader, deltaHeader *ReadFileHeader) error {
+
+	if deltaHeader.Type != Delta {
+		return errors.Wrapf(ErrSnapshotsNotMergeable, "snapshot file is of type %s but expected was %s", snapshotNames[deltaHeader.Type], snapshotNames[Delta])
+	}
+
+	if deltaHeader.NetworkID != previousHeader.NetworkID {
+		return errors.Wrapf(ErrSnapshotsNotMergeable, "delta snapshot's network ID %d does not correspond to the preceding snapshot's network ID %d", deltaHeader.NetworkID, previousHeader.NetworkID)
+	}
+
+	if deltaHeader.LedgerMilestoneIndex != previousHeader.SEPMilestoneIndex {
+		return errors.Wrapf(ErrSnapshotsNotMergeable, "delta snapshot's ledger index %d does not correspond to the preceding snapshot's SEPs index %d", deltaHeader.LedgerMilestoneIndex, previousHeader.SEPMilestoneIndex)
+	}
+
+	// only the first file of a delta snapshot chain may be based on a snapshot file without a checksum,
+	// the files that follow a delta snapshot file with a checksum have to reference it.
+	if deltaHeader.PreviousChecksum == nil && previousHeader.Type == Delta && previousHeader.Checksum != nil {
+		return errors.Wrap(ErrSnapshotsNotMergeable, "delta snapshot does not reference the checksum of the preceding delta snapshot")
+	}
+
+	if deltaHeader.PreviousChecksum != nil && !bytes.Equal(deltaHeader.PreviousChecksum, previousHeader.Checksum) {
+		return errors.Wrapf(ErrSnapshotsNotMergeable, "delta snapshot is based on the snapshot with checksum %s, but the preceding snapshot's checksum is %s", iotago.EncodeHex(deltaHeader.PreviousChecksum), iotago.EncodeHex(previousHeader.Checksum))
+	}
+
+	return nil
+}
+
+// ReadSnapshotFilesHeaders reads the headers of the given full snapshot file and the delta snapshot files,
+// and checks that every delta snapshot file is based on the preceding snapshot file.
+func ReadSnapshotFilesHeaders(fullPath string, deltaPaths ...string) (*ReadFileHeader, []*ReadFileHeader, error) {
+
+	fullHeader, err := ReadSnapshotHeaderFromFile(fullPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previousHeader := fullHeader
+	deltaHeaders := make([]*ReadFileHeader, 0, len(deltaPaths))
+	for _, deltaPath := range deltaPaths {
+		deltaHeader, err := ReadSnapshotHeaderFromFile(deltaPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := VerifyDeltaSnapshotLink(previousHeader, deltaHeader); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", deltaPath, err)
+		}
+
+		deltaHeaders = append(deltaHeaders, deltaHeader)
+		previousHeader = deltaHeader
+	}
+
+	return fullHeader, deltaHeaders, nil
+}
+
+// lastSnapshotHeader returns the header of the last snapshot file that is applied.
+func lastSnapshotHeader(fullHeader *ReadFileHeader, deltaHeaders []*ReadFileHeader) *ReadFileHeader {
+	if len(deltaHeaders) == 0 {
+		return fullHeader
+	}
+	return deltaHeaders[len(deltaHeaders)-1]
+}
@@ -0,0 +1,202 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+)
+
+// writes an empty snapshot file with the given header to the given path and returns the written header.
+func writeTestChainSnapshotFile(t *testing.T, filePath string, header *FileHeader) *ReadFileHeader {
+	sepProducer := func() (hornet.MessageID, error) {
+		return nil, nil
+	}
+
+	outputProducer := func() (*utxo.Output, error) {
+		return nil, nil
+	}
+
+	msDiffProducer := func() (*MilestoneDiff, error) {
+		return nil, nil
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0700))
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	require.NoError(t, err)
+	_, err = StreamSnapshotDataTo(file, uint64(time.Now().Unix()), header, sepProducer, outputProducer, msDiffProducer)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	readHeader, err := ReadSnapshotHeaderFromFile(filePath)
+	require.NoError(t, err)
+
+	return readHeader
+}
+
+// writes a chained delta snapshot file which is based on the given preceding snapshot file.
+func writeTestChainedDeltaSnapshotFile(t *testing.T, filePath string, previousHeader *ReadFileHeader, sepIndex milestone.Index) *ReadFileHeader {
+	return writeTestChainSnapshotFile(t, filePath, &FileHeader{
+		Version:              SupportedFormatVersion,
+		Type:                 Delta,
+		NetworkID:            previousHeader.NetworkID,
+		SEPMilestoneIndex:    sepIndex,
+		LedgerMilestoneIndex: previousHeader.SEPMilestoneIndex,
+		Compression:          CompressionZstd,
+		PreviousChecksum:     previousHeader.Checksum,
+	})
+}
+
+func TestDeltaChainFilePaths(t *testing.T) {
+	dir := t.TempDir()
+	deltaPath := filepath.Join(dir, "delta_snapshot.bin")
+
+	require.Equal(t, filepath.Join(dir, "delta_snapshot_chain"), DeltaChainDirectory(deltaPath))
+	require.Equal(t, filepath.Join(dir, "delta_snapshot_chain", "000003.bin"), DeltaChainFilePath(deltaPath, 3))
+
+	// no chain exists
+	paths, err := DeltaSnapshotFilePaths(deltaPath)
+	require.NoError(t, err)
+	require.Empty(t, paths)
+
+	require.NoError(t, os.MkdirAll(DeltaChainDirectory(deltaPath), 0700))
+	for _, name := range []string{"000002.bin", "000001.bin", "000003.bin", "000004.bin_tmp", "000004.bin.partial", "other.bin"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(DeltaChainDirectory(deltaPath), name), nil, 0666))
+	}
+
+	// temporary files and partial downloads are not part of the chain
+	paths, err = DeltaChainFilePaths(deltaPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{DeltaChainFilePath(deltaPath, 1), DeltaChainFilePath(deltaPath, 2), DeltaChainFilePath(deltaPath, 3)}, paths)
+
+	// the delta snapshot file is applied before the chain
+	require.NoError(t, ioutil.WriteFile(deltaPath, nil, 0666))
+	paths, err = DeltaSnapshotFilePaths(deltaPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{deltaPath, DeltaChainFilePath(deltaPath, 1), DeltaChainFilePath(deltaPath, 2), DeltaChainFilePath(deltaPath, 3)}, paths)
+
+	// a gap in the chain is invalid
+	require.NoError(t, os.Remove(DeltaChainFilePath(deltaPath, 2)))
+	_, err = DeltaChainFilePaths(deltaPath)
+	require.ErrorIs(t, err, ErrDeltaChainInvalid)
+
+	require.NoError(t, RemoveDeltaChainFiles(deltaPath))
+	paths, err = DeltaSnapshotFilePaths(deltaPath)
+	require.NoError(t, err)
+	require.Equal(t, []string{deltaPath}, paths)
+}
+
+func TestReadSnapshotFilesHeaders(t *testing.T) {
+	dir := t.TempDir()
+	fullPath := filepath.Join(dir, "full_snapshot.bin")
+	deltaPath := filepath.Join(dir, "delta_snapshot.bin")
+
+	fullHeader := writeTestChainSnapshotFile(t, fullPath, &FileHeader{
+		Version:              SupportedFormatVersion,
+		Type:                 Full,
+		NetworkID:            1337133713371337,
+		SEPMilestoneIndex:    1000,
+		LedgerMilestoneIndex: 1010,
+		TreasuryOutput:       &utxo.TreasuryOutput{Amount: 1337},
+		Compression:          CompressionZstd,
+	})
+
+	deltaHeader := writeTestChainedDeltaSnapshotFile(t, deltaPath, fullHeader, 1100)
+	chainHeader1 := writeTestChainedDeltaSnapshotFile(t, DeltaChainFilePath(deltaPath, 1), deltaHeader, 1200)
+	chainHeader2 := writeTestChainedDeltaSnapshotFile(t, DeltaChainFilePath(deltaPath, 2), chainHeader1, 1300)
+
+	// the delta snapshot references the full snapshot
+	require.Equal(t, fullHeader.Checksum, deltaHeader.PreviousChecksum)
+
+	deltaPaths, err := DeltaSnapshotFilePaths(deltaPath)
+	require.NoError(t, err)
+
+	readFullHeader, readDeltaHeaders, err := ReadSnapshotFilesHeaders(fullPath, deltaPaths...)
+	require.NoError(t, err)
+	require.True(t, snapshotHeadersEqual(fullHeader, readFullHeader))
+	require.Len(t, readDeltaHeaders, 3)
+	require.True(t, snapshotHeadersEqual(chainHeader2, lastSnapshotHeader(readFullHeader, readDeltaHeaders)))
+	require.Equal(t, milestone.Index(1300), lastSnapshotHeader(readFullHeader, readDeltaHeaders).SEPMilestoneIndex)
+
+	// the chain doesn't fit if a file is skipped
+	_, _, err = ReadSnapshotFilesHeaders(fullPath, DeltaChainFilePath(deltaPath, 1))
+	require.ErrorIs(t, err, ErrSnapshotsNotMergeable)
+
+	// a file with the same milestone range that was based on another predecessor doesn't fit
+	otherDeltaHeader := writeTestChainSnapshotFile(t, filepath.Join(dir, "other.bin"), &FileHeader{
+		Version:              SupportedFormatVersion,
+		Type:                 Delta,
+		NetworkID:            fullHeader.NetworkID,
+		SEPMilestoneIndex:    1100,
+		LedgerMilestoneIndex: 1000,
+		Compression:          CompressionZstd,
+	})
+	writeTestChainedDeltaSnapshotFile(t, DeltaChainFilePath(deltaPath, 1), otherDeltaHeader, 1200)
+
+	_, _, err = ReadSnapshotFilesHeaders(fullPath, deltaPaths...)
+	require.ErrorIs(t, err, ErrSnapshotsNotMergeable)
+
+	// delta snapshots without a reference to their predecessor are only checked by their milestone range
+	_, _, err = ReadSnapshotFilesHeaders(fullPath, filepath.Join(dir, "other.bin"))
+	require.NoError(t, err)
+
+	// but the files that follow a delta snapshot with a checksum have to reference it
+	writeTestChainSnapshotFile(t, DeltaChainFilePath(deltaPath, 1), &FileHeader{
+		Version:              SupportedFormatVersion,
+		Type:                 Delta,
+		NetworkID:            fullHeader.NetworkID,
+		SEPMilestoneIndex:    1200,
+		LedgerMilestoneIndex: 1100,
+		Compression:          CompressionZstd,
+	})
+
+	_, _, err = ReadSnapshotFilesHeaders(fullPath, deltaPaths...)
+	require.ErrorIs(t, err, ErrSnapshotsNotMergeable)
+}
+
+func TestSnapshotManifestDeltaChainSignature(t *testing.T) {
+	newManifestFile := func(hash string, sepIndex milestone.Index, ledgerIndex milestone.Index) *SnapshotManifestFile {
+		return &SnapshotManifestFile{
+			SHA256:               strings.Repeat(hash, 32),
+			Size:                 42,
+			SEPMilestoneIndex:    sepIndex,
+			LedgerMilestoneIndex: ledgerIndex,
+		}
+	}
+
+	withDelta := &SnapshotManifest{
+		Full:       newManifestFile("ab", 100, 100),
+		Delta:      newManifestFile("cd", 200, 100),
+		DeltaChain: []*SnapshotManifestFile{newManifestFile("ef", 300, 200)},
+	}
+
+	withoutDelta := &SnapshotManifest{
+		Full:       newManifestFile("ab", 100, 100),
+		DeltaChain: []*SnapshotManifestFile{newManifestFile("cd", 200, 100), newManifestFile("ef", 300, 200)},
+	}
+
+	msgWithDelta, err := withDelta.SigningMessage()
+	require.NoError(t, err)
+
+	msgWithoutDelta, err := withoutDelta.SigningMessage()
+	require.NoError(t, err)
+
+	// the chain is part of the signed message
+	require.NotEqual(t, msgWithDelta, msgWithoutDelta)
+
+	// the manifest describes at least the downloaded part of the chain
+	require.True(t, withoutDelta.matchesDeltaChainHeaders([]*ReadFileHeader{
+		{FileHeader: FileHeader{SEPMilestoneIndex: 200, LedgerMilestoneIndex: 100}},
+	}))
+	require.False(t, withDelta.matchesDeltaChainHeaders([]*ReadFileHeader{
+		{FileHeader: FileHeader{SEPMilestoneIndex: 200, LedgerMilestoneIndex: 100}},
+	}))
+}
@@ -0,0 +1,217 @@
+package snapshot
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// the size of the buffer of the decompressed data of a snapshot file.
+	// the buffer is needed to seek back the bytes that were not consumed while reading outputs.
+	sectionReaderBufferSize = 1024 * 1024
+)
+
+var (
+	// ErrUnknownCompression is returned if the compression of a snapshot file is unknown.
+	ErrUnknownCompression = errors.New("unknown snapshot compression")
+	// ErrSnapshotChecksumMismatch is returned if a hash of a snapshot file does not match its content.
+	ErrSnapshotChecksumMismatch = errors.New("snapshot checksum mismatch")
+)
+
+// Compression defines the compression of the data of a snapshot file.
+type Compression byte
+
+const (
+	// CompressionNone stores the snapshot data uncompressed.
+	CompressionNone Compression = iota
+	// CompressionGzip compresses the snapshot data with gzip.
+	CompressionGzip
+	// CompressionZstd compresses the snapshot data with zstd.
+	CompressionZstd
+)
+
+// maps the compression to its name.
+var compressionNames = map[Compression]string{
+	CompressionNone: "none",
+	CompressionGzip: "gzip",
+	CompressionZstd: "zstd",
+}
+
+func (c Compression) String() string {
+	if name, exists := compressionNames[c]; exists {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", c)
+}
+
+// CompressionFromString parses the name of a compression.
+func CompressionFromString(name string) (Compression, error) {
+	for compression, compressionName := range compressionNames {
+		if strings.EqualFold(name, compressionName) {
+			return compression, nil
+		}
+	}
+	return CompressionNone, errors.WithMessagef(ErrUnknownCompression, "compression: %s", name)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// newCompressionWriter returns a writer that compresses the written data with the given compression.
+// The writer has to be closed to flush the compressed data.
+func newCompressionWriter(writer io.Writer, compression Compression) (io.WriteCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return nopWriteCloser{Writer: writer}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(writer), nil
+	case CompressionZstd:
+		return zstd.NewWriter(writer)
+	default:
+		return nil, errors.WithMessagef(ErrUnknownCompression, "compression: %d", compression)
+	}
+}
+
+// newDecompressionReader returns a reader that decompresses the data of the given reader with the given compression.
+func newDecompressionReader(reader io.Reader, compression Compression) (io.ReadCloser, error) {
+	switch compression {
+	case CompressionNone:
+		return io.NopCloser(reader), nil
+	case CompressionGzip:
+		return gzip.NewReader(reader)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, errors.WithMessagef(ErrUnknownCompression, "compression: %d", compression)
+	}
+}
+
+// sectionHashWriter hashes the data that is written to the underlying writer.
+// The hash is reset at the end of every section of the snapshot file.
+type sectionHashWriter struct {
+	writer io.Writer
+	hash   hash.Hash
+}
+
+func newSectionHashWriter(writer io.Writer) *sectionHashWriter {
+	return &sectionHashWriter{
+		writer: writer,
+		hash:   sha256.New(),
+	}
+}
+
+func (w *sectionHashWriter) Write(p []byte) (int, error) {
+	_, _ = w.hash.Write(p)
+	return w.writer.Write(p)
+}
+
+// sectionHash returns the hash of the data written since the last call and resets the hash.
+func (w *sectionHashWriter) sectionHash() []byte {
+	sum := w.hash.Sum(nil)
+	w.hash.Reset()
+	return sum
+}
+
+// sectionHashReader is an io.ReadSeeker on top of a stream that can't seek, like the decompressed data of a snapshot file.
+// It hashes the consumed data, the hash is reset at the end of every section of the snapshot file.
+// Only seeking back within the data returned by the last read is supported,
+// which is needed to give back the bytes that were not consumed while deserializing outputs.
+type sectionHashReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	// buf[:pos] was consumed, buf[pos:] was not read yet.
+	buf []byte
+	pos int
+	// buf[:hashed] was already hashed.
+	hashed int
+	// the amount of bytes consumed from the stream.
+	offset int64
+	err    error
+}
+
+func newSectionHashReader(reader io.Reader) *sectionHashReader {
+	return &sectionHashReader{
+		reader: reader,
+		hash:   sha256.New(),
+		buf:    make([]byte, 0, sectionReaderBufferSize),
+	}
+}
+
+// fill makes sure that the buffer contains at least the given amount of unread bytes, unless the stream ended.
+// All previously consumed bytes are hashed and dropped from the buffer.
+func (r *sectionHashReader) fill(size int) {
+	if len(r.buf)-r.pos >= size || r.err != nil {
+		return
+	}
+
+	_, _ = r.hash.Write(r.buf[r.hashed:r.pos])
+	unread := copy(r.buf[:cap(r.buf)], r.buf[r.pos:])
+	if cap(r.buf) < size {
+		buf := make([]byte, unread, size)
+		copy(buf, r.buf[:unread])
+		r.buf = buf
+	}
+	r.buf = r.buf[:unread]
+	r.pos = 0
+	r.hashed = 0
+
+	for len(r.buf) < size && r.err == nil {
+		n, err := r.reader.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+n]
+		r.err = err
+	}
+}
+
+func (r *sectionHashReader) Read(p []byte) (int, error) {
+	r.fill(len(p))
+
+	n := copy(p, r.buf[r.pos:])
+	r.pos += n
+	r.offset += int64(n)
+
+	if n == 0 && len(p) > 0 {
+		return 0, r.err
+	}
+	return n, nil
+}
+
+func (r *sectionHashReader) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekCurrent {
+		return r.offset, fmt.Errorf("seeking from %d is not supported in compressed snapshot data", whence)
+	}
+
+	pos := int64(r.pos) + offset
+	if pos < int64(r.hashed) || pos > int64(len(r.buf)) {
+		return r.offset, fmt.Errorf("seeking %d bytes is out of the buffered range of the compressed snapshot data", offset)
+	}
+
+	r.pos = int(pos)
+	r.offset += offset
+	return r.offset, nil
+}
+
+// sectionHash returns the hash of the data consumed since the last call and resets the hash.
+func (r *sectionHashReader) sectionHash() []byte {
+	_, _ = r.hash.Write(r.buf[r.hashed:r.pos])
+	r.hashed = r.pos
+
+	sum := r.hash.Sum(nil)
+	r.hash.Reset()
+	return sum
+}
@@ -1,7 +1,9 @@
 package snapshot
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -18,15 +20,31 @@ import (
 )
 
 const (
-	// The supported snapshot file version.
-	SupportedFormatVersion byte = 2
+	// FormatVersionRaw is the snapshot file version which stores the data as a raw binary stream.
+	FormatVersionRaw byte = 2
+	// FormatVersionCompressed is the snapshot file version which supports the compression of the data
+	// and contains a hash of every section plus an overall checksum.
+	// The header of delta snapshots additionally contains the checksum of the preceding snapshot file,
+	// so that delta snapshots can be chained.
+	FormatVersionCompressed byte = 3
+	// The latest snapshot file version.
+	SupportedFormatVersion = FormatVersionCompressed
+	// The compression that is used for snapshot files if nothing else is configured.
+	DefaultCompression = CompressionNone
 	// The length of a solid entry point hash.
 	SolidEntryPointHashLength = iotago.MessageIDLength
 
-	// The offset of counters within a snapshot file:
-	// version + type + timestamp + network-id + sep-ms-index + ledger-ms-index
-	countersOffset = serializer.OneByte + serializer.OneByte + serializer.UInt64ByteSize + serializer.UInt64ByteSize +
-		serializer.UInt32ByteSize + serializer.UInt32ByteSize
+	// The size of the section hashes and the checksum after the header of a snapshot file:
+	// seps-hash + outputs-hash + ms-diffs-hash + checksum
+	sectionHashesSize = 4 * sha256.Size
+)
+
+var (
+	// the snapshot file versions that can be read.
+	supportedFormatVersions = map[byte]struct{}{
+		FormatVersionRaw:        {},
+		FormatVersionCompressed: {},
+	}
 )
 
 var (
@@ -205,6 +223,11 @@ type FileHeader struct {
 	// The treasury output existing for the given ledger milestone index.
 	// This field must be populated if a Full snapshot is created/read.
 	TreasuryOutput *utxo.TreasuryOutput
+	// The compression of the data after the header (since FormatVersionCompressed).
+	Compression Compression
+	// The checksum of the snapshot file this delta snapshot is based on (since FormatVersionCompressed).
+	// This field is empty if the delta snapshot is not chained to a preceding snapshot file with a checksum.
+	PreviousChecksum []byte
 }
 
 // ReadFileHeader is a FileHeader but with additional content read from the snapshot.
@@ -218,20 +241,12 @@ type ReadFileHeader struct {
 	OutputCount uint64
 	// The count of milestone diffs.
 	MilestoneDiffCount uint64
-}
-
-// getSnapshotFilesLedgerIndex returns the final ledger index if the given snapshot files would be applied.
-func getSnapshotFilesLedgerIndex(fullHeader *ReadFileHeader, deltaHeader *ReadFileHeader) milestone.Index {
-
-	if fullHeader == nil {
-		return 0
-	}
-
-	if deltaHeader == nil {
-		return fullHeader.SEPMilestoneIndex
-	}
-
-	return deltaHeader.SEPMilestoneIndex
+	// The SHA-256 hashes of the uncompressed solid entry points, outputs and milestone diffs sections (since FormatVersionCompressed).
+	SEPsHash           []byte
+	OutputsHash        []byte
+	MilestoneDiffsHash []byte
+	// The SHA-256 checksum over the header and the hashes of the sections (since FormatVersionCompressed).
+	Checksum []byte
 }
 
 // StreamSnapshotDataTo streams a snapshot data into the given io.WriteSeeker.
@@ -250,45 +265,37 @@ func StreamSnapshotDataTo(writeSeeker io.WriteSeeker, timestamp uint64, header *
 	}
 
 	var sepsCount, outputCount, msDiffCount uint64
+	var sepsHash, outputsHash, msDiffsHash []byte
 
 	timeStart := time.Now()
 
-	// write LS file version and type
-	if _, err := writeSeeker.Write([]byte{header.Version, byte(header.Type)}); err != nil {
-		return nil, fmt.Errorf("unable to write LS version and type: %w", err)
-	}
-
-	if err := binary.Write(writeSeeker, binary.LittleEndian, timestamp); err != nil {
-		return nil, fmt.Errorf("unable to write LS timestamp: %w", err)
-	}
-
-	if err := binary.Write(writeSeeker, binary.LittleEndian, header.NetworkID); err != nil {
-		return nil, fmt.Errorf("unable to write LS network ID: %w", err)
+	// the counters are written again with the correct values after all data was written
+	if err := writeFileHeader(writeSeeker, timestamp, header, sepsCount, outputCount, msDiffCount); err != nil {
+		return nil, err
 	}
 
-	if err := binary.Write(writeSeeker, binary.LittleEndian, header.SEPMilestoneIndex); err != nil {
-		return nil, fmt.Errorf("unable to write LS SEPs milestone index: %w", err)
-	}
-
-	if err := binary.Write(writeSeeker, binary.LittleEndian, header.LedgerMilestoneIndex); err != nil {
-		return nil, fmt.Errorf("unable to write LS ledger milestone index: %w", err)
-	}
-
-	// write count placeholders
-	placeholderSpace := serializer.UInt64ByteSize * 3
-	if header.Type == Delta {
-		placeholderSpace -= serializer.UInt64ByteSize
-	}
-	if _, err := writeSeeker.Write(make([]byte, placeholderSpace)); err != nil {
-		return nil, fmt.Errorf("unable to write LS counter placeholders: %w", err)
-	}
+	var dataWriter io.Writer = writeSeeker
+	var sectionWriter *sectionHashWriter
+	var closeDataWriter func() error
+	if header.Version >= FormatVersionCompressed {
+		// write placeholders for the section hashes and the checksum
+		if _, err := writeSeeker.Write(make([]byte, sectionHashesSize)); err != nil {
+			return nil, fmt.Errorf("unable to write LS checksum placeholders: %w", err)
+		}
 
-	if header.Type == Full {
-		if _, err := writeSeeker.Write(header.TreasuryOutput.MilestoneID[:]); err != nil {
-			return nil, fmt.Errorf("unable to write LS treasury output milestone hash: %w", err)
+		bufferedWriter := bufio.NewWriter(writeSeeker)
+		compressionWriter, err := newCompressionWriter(bufferedWriter, header.Compression)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create LS compression writer: %w", err)
 		}
-		if err := binary.Write(writeSeeker, binary.LittleEndian, header.TreasuryOutput.Amount); err != nil {
-			return nil, fmt.Errorf("unable to write LS treasury output amount: %w", err)
+
+		sectionWriter = newSectionHashWriter(compressionWriter)
+		dataWriter = sectionWriter
+		closeDataWriter = func() error {
+			if err := compressionWriter.Close(); err != nil {
+				return err
+			}
+			return bufferedWriter.Flush()
 		}
 	}
 
@@ -305,11 +312,15 @@ func StreamSnapshotDataTo(writeSeeker io.WriteSeeker, timestamp uint64, header *
 		}
 
 		sepsCount++
-		if _, err := writeSeeker.Write(sep[:]); err != nil {
+		if _, err := dataWriter.Write(sep[:]); err != nil {
 			return nil, fmt.Errorf("unable to write LS SEP #%d: %w", sepsCount, err)
 		}
 	}
 
+	if sectionWriter != nil {
+		sepsHash = sectionWriter.sectionHash()
+	}
+
 	timeSolidEntryPoints := time.Now()
 
 	if header.Type == Full {
@@ -325,12 +336,16 @@ func StreamSnapshotDataTo(writeSeeker io.WriteSeeker, timestamp uint64, header *
 
 			outputCount++
 			outputBytes := output.SnapshotBytes()
-			if _, err := writeSeeker.Write(outputBytes); err != nil {
+			if _, err := dataWriter.Write(outputBytes); err != nil {
 				return nil, fmt.Errorf("unable to write LS output #%d: %w", outputCount, err)
 			}
 		}
 	}
 
+	if sectionWriter != nil {
+		outputsHash = sectionWriter.sectionHash()
+	}
+
 	timeOutputs := time.Now()
 
 	for {
@@ -348,29 +363,49 @@ func StreamSnapshotDataTo(writeSeeker io.WriteSeeker, timestamp uint64, header *
 		if err != nil {
 			return nil, fmt.Errorf("unable to serialize LS milestone diff #%d: %w", msDiffCount, err)
 		}
-		if _, err := writeSeeker.Write(msDiffBytes); err != nil {
+		if _, err := dataWriter.Write(msDiffBytes); err != nil {
 			return nil, fmt.Errorf("unable to write LS milestone diff #%d: %w", msDiffCount, err)
 		}
 	}
 
+	if sectionWriter != nil {
+		msDiffsHash = sectionWriter.sectionHash()
+
+		if err := closeDataWriter(); err != nil {
+			return nil, fmt.Errorf("unable to finalize LS compressed data: %w", err)
+		}
+	}
+
 	timeMilestoneDiffs := time.Now()
 
-	if _, err := writeSeeker.Seek(countersOffset, io.SeekStart); err != nil {
-		return nil, fmt.Errorf("unable to seek to LS counter placeholders: %w", err)
+	if _, err := writeSeeker.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to seek to LS header: %w", err)
 	}
 
-	if err := binary.Write(writeSeeker, binary.LittleEndian, sepsCount); err != nil {
-		return nil, fmt.Errorf("unable to write to LS SEPs count: %w", err)
+	if err := writeFileHeader(writeSeeker, timestamp, header, sepsCount, outputCount, msDiffCount); err != nil {
+		return nil, err
 	}
 
-	if header.Type == Full {
-		if err := binary.Write(writeSeeker, binary.LittleEndian, outputCount); err != nil {
-			return nil, fmt.Errorf("unable to write to LS outputs count: %w", err)
+	if header.Version >= FormatVersionCompressed {
+		checksum, err := computeChecksum(&ReadFileHeader{
+			FileHeader:         *header,
+			Timestamp:          timestamp,
+			SEPCount:           sepsCount,
+			OutputCount:        outputCount,
+			MilestoneDiffCount: msDiffCount,
+			SEPsHash:           sepsHash,
+			OutputsHash:        outputsHash,
+			MilestoneDiffsHash: msDiffsHash,
+		})
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	if err := binary.Write(writeSeeker, binary.LittleEndian, msDiffCount); err != nil {
-		return nil, fmt.Errorf("unable to write to LS ms-diffs count: %w", err)
+		for _, sum := range [][]byte{sepsHash, outputsHash, msDiffsHash, checksum} {
+			if _, err := writeSeeker.Write(sum); err != nil {
+				return nil, fmt.Errorf("unable to write LS checksums: %w", err)
+			}
+		}
 	}
 
 	return &SnapshotMetrics{
@@ -381,6 +416,93 @@ func StreamSnapshotDataTo(writeSeeker io.WriteSeeker, timestamp uint64, header *
 	}, nil
 }
 
+// writeFileHeader writes the header of a snapshot file with the given counters.
+func writeFileHeader(writer io.Writer, timestamp uint64, header *FileHeader, sepsCount uint64, outputCount uint64, msDiffCount uint64) error {
+
+	// write LS file version and type
+	if _, err := writer.Write([]byte{header.Version, byte(header.Type)}); err != nil {
+		return fmt.Errorf("unable to write LS version and type: %w", err)
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, timestamp); err != nil {
+		return fmt.Errorf("unable to write LS timestamp: %w", err)
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, header.NetworkID); err != nil {
+		return fmt.Errorf("unable to write LS network ID: %w", err)
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, header.SEPMilestoneIndex); err != nil {
+		return fmt.Errorf("unable to write LS SEPs milestone index: %w", err)
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, header.LedgerMilestoneIndex); err != nil {
+		return fmt.Errorf("unable to write LS ledger milestone index: %w", err)
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, sepsCount); err != nil {
+		return fmt.Errorf("unable to write LS SEPs count: %w", err)
+	}
+
+	if header.Type == Full {
+		if err := binary.Write(writer, binary.LittleEndian, outputCount); err != nil {
+			return fmt.Errorf("unable to write LS outputs count: %w", err)
+		}
+	}
+
+	if err := binary.Write(writer, binary.LittleEndian, msDiffCount); err != nil {
+		return fmt.Errorf("unable to write LS ms-diffs count: %w", err)
+	}
+
+	if header.Type == Full {
+		if _, err := writer.Write(header.TreasuryOutput.MilestoneID[:]); err != nil {
+			return fmt.Errorf("unable to write LS treasury output milestone hash: %w", err)
+		}
+		if err := binary.Write(writer, binary.LittleEndian, header.TreasuryOutput.Amount); err != nil {
+			return fmt.Errorf("unable to write LS treasury output amount: %w", err)
+		}
+	}
+
+	if header.Version >= FormatVersionCompressed {
+		if _, err := writer.Write([]byte{byte(header.Compression)}); err != nil {
+			return fmt.Errorf("unable to write LS compression: %w", err)
+		}
+	}
+
+	if header.Version >= FormatVersionCompressed && header.Type == Delta {
+		previousChecksum := make([]byte, sha256.Size)
+		if len(header.PreviousChecksum) > 0 {
+			if len(header.PreviousChecksum) != sha256.Size {
+				return fmt.Errorf("invalid LS previous checksum length: %d", len(header.PreviousChecksum))
+			}
+			copy(previousChecksum, header.PreviousChecksum)
+		}
+
+		if _, err := writer.Write(previousChecksum); err != nil {
+			return fmt.Errorf("unable to write LS previous checksum: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// computeChecksum computes the checksum over the header and the hashes of the sections of a snapshot file.
+func computeChecksum(header *ReadFileHeader) ([]byte, error) {
+	checksum := sha256.New()
+
+	if err := writeFileHeader(checksum, header.Timestamp, &header.FileHeader, header.SEPCount, header.OutputCount, header.MilestoneDiffCount); err != nil {
+		return nil, err
+	}
+
+	for _, sum := range [][]byte{header.SEPsHash, header.OutputsHash, header.MilestoneDiffsHash} {
+		if _, err := checksum.Write(sum); err != nil {
+			return nil, err
+		}
+	}
+
+	return checksum.Sum(nil), nil
+}
+
 // ReadSnapshotHeader reads the snapshot header from the given reader.
 func ReadSnapshotHeader(reader io.Reader) (*ReadFileHeader, error) {
 	readHeader := &ReadFileHeader{}
@@ -435,11 +557,50 @@ func ReadSnapshotHeader(reader io.Reader) (*ReadFileHeader, error) {
 		readHeader.TreasuryOutput = to
 	}
 
+	if readHeader.Version >= FormatVersionCompressed {
+		if err := binary.Read(reader, binary.LittleEndian, &readHeader.Compression); err != nil {
+			return nil, fmt.Errorf("unable to read LS compression: %w", err)
+		}
+
+		if readHeader.Type == Delta {
+			previousChecksum := make([]byte, sha256.Size)
+			if _, err := io.ReadFull(reader, previousChecksum); err != nil {
+				return nil, fmt.Errorf("unable to read LS previous checksum: %w", err)
+			}
+
+			// an empty checksum means that the delta snapshot is not chained
+			if !bytes.Equal(previousChecksum, make([]byte, sha256.Size)) {
+				readHeader.PreviousChecksum = previousChecksum
+			}
+		}
+
+		readHeader.SEPsHash = make([]byte, sha256.Size)
+		readHeader.OutputsHash = make([]byte, sha256.Size)
+		readHeader.MilestoneDiffsHash = make([]byte, sha256.Size)
+		readHeader.Checksum = make([]byte, sha256.Size)
+		for _, sum := range [][]byte{readHeader.SEPsHash, readHeader.OutputsHash, readHeader.MilestoneDiffsHash, readHeader.Checksum} {
+			if _, err := io.ReadFull(reader, sum); err != nil {
+				return nil, fmt.Errorf("unable to read LS checksums: %w", err)
+			}
+		}
+
+		checksum, err := computeChecksum(readHeader)
+		if err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(checksum, readHeader.Checksum) {
+			return nil, errors.Wrapf(ErrSnapshotChecksumMismatch, "header checksum is %s but computed %s", iotago.EncodeHex(readHeader.Checksum), iotago.EncodeHex(checksum))
+		}
+	}
+
 	return readHeader, nil
 }
 
 // StreamSnapshotDataFrom consumes a snapshot from the given reader.
 // OutputConsumerFunc must not be nil if the snapshot is not a delta snapshot.
+// The section hashes of snapshot files since FormatVersionCompressed are verified while the data is consumed,
+// so the consumed data has to be discarded if an ErrSnapshotChecksumMismatch is returned.
 func StreamSnapshotDataFrom(reader io.ReadSeeker,
 	deSeriParas *iotago.DeSerializationParameters,
 	headerConsumer HeaderConsumerFunc,
@@ -460,7 +621,9 @@ func StreamSnapshotDataFrom(reader io.ReadSeeker,
 		case unspentTreasuryOutputConsumer == nil:
 			return ErrTreasuryOutputConsumerNotProvided
 		}
+	}
 
+	if readHeader.Type == Full {
 		if err := unspentTreasuryOutputConsumer(readHeader.TreasuryOutput); err != nil {
 			return err
 		}
@@ -470,9 +633,40 @@ func StreamSnapshotDataFrom(reader io.ReadSeeker,
 		return err
 	}
 
+	return streamSnapshotData(reader, readHeader, deSeriParas, sepConsumer, outputConsumer, msDiffConsumer)
+}
+
+// streamSnapshotData consumes the data after the header of a snapshot file from the given reader.
+// The hash of every section is checked after the section was consumed (since FormatVersionCompressed).
+func streamSnapshotData(reader io.ReadSeeker,
+	readHeader *ReadFileHeader,
+	deSeriParas *iotago.DeSerializationParameters,
+	sepConsumer SEPConsumerFunc,
+	outputConsumer OutputConsumerFunc,
+	msDiffConsumer MilestoneDiffConsumerFunc) error {
+
+	var dataReader io.ReadSeeker = reader
+	verifySectionHash := func(section string, expectedHash []byte) error { return nil }
+	if readHeader.Version >= FormatVersionCompressed {
+		decompressionReader, err := newDecompressionReader(reader, readHeader.Compression)
+		if err != nil {
+			return fmt.Errorf("unable to create LS decompression reader: %w", err)
+		}
+		defer func() { _ = decompressionReader.Close() }()
+
+		sectionReader := newSectionHashReader(decompressionReader)
+		dataReader = sectionReader
+		verifySectionHash = func(section string, expectedHash []byte) error {
+			if sectionHash := sectionReader.sectionHash(); !bytes.Equal(sectionHash, expectedHash) {
+				return errors.Wrapf(ErrSnapshotChecksumMismatch, "%s hash is %s but computed %s", section, iotago.EncodeHex(expectedHash), iotago.EncodeHex(sectionHash))
+			}
+			return nil
+		}
+	}
+
 	for i := uint64(0); i < readHeader.SEPCount; i++ {
 		solidEntryPointMessageID := make(hornet.MessageID, iotago.MessageIDLength)
-		if _, err := io.ReadFull(reader, solidEntryPointMessageID); err != nil {
+		if _, err := io.ReadFull(dataReader, solidEntryPointMessageID); err != nil {
 			return fmt.Errorf("unable to read LS SEP at pos %d: %w", i, err)
 		}
 		if err := sepConsumer(solidEntryPointMessageID); err != nil {
@@ -480,9 +674,13 @@ func StreamSnapshotDataFrom(reader io.ReadSeeker,
 		}
 	}
 
+	if err := verifySectionHash("SEPs", readHeader.SEPsHash); err != nil {
+		return err
+	}
+
 	if readHeader.Type == Full {
 		for i := uint64(0); i < readHeader.OutputCount; i++ {
-			output, err := readOutput(reader, deSeriParas)
+			output, err := readOutput(dataReader, deSeriParas)
 			if err != nil {
 				return fmt.Errorf("at pos %d: %w", i, err)
 			}
@@ -493,8 +691,12 @@ func StreamSnapshotDataFrom(reader io.ReadSeeker,
 		}
 	}
 
+	if err := verifySectionHash("outputs", readHeader.OutputsHash); err != nil {
+		return err
+	}
+
 	for i := uint64(0); i < readHeader.MilestoneDiffCount; i++ {
-		msDiff, err := readMilestoneDiff(reader, deSeriParas)
+		msDiff, err := readMilestoneDiff(dataReader, deSeriParas)
 		if err != nil {
 			return fmt.Errorf("at pos %d: %w", i, err)
 		}
@@ -503,6 +705,10 @@ func StreamSnapshotDataFrom(reader io.ReadSeeker,
 		}
 	}
 
+	if err := verifySectionHash("ms-diffs", readHeader.MilestoneDiffsHash); err != nil {
+		return err
+	}
+
 	return nil
 }
 
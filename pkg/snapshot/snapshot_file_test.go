@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"crypto/ed25519"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/blang/vfs"
 	"github.com/blang/vfs/memfs"
 	"github.com/dustin/go-humanize"
 	"github.com/stretchr/testify/require"
@@ -130,6 +132,9 @@ func TestStreamLocalSnapshotDataToAndFrom(t *testing.T) {
 			}
 			return t
 		}(),
+		newFullTest(t, "full zstd: 150 seps, 100k outputs, 50 ms diffs", snapshot.FormatVersionCompressed, snapshot.CompressionZstd, 100000),
+		newFullTest(t, "full gzip: 150 seps, 100k outputs, 50 ms diffs", snapshot.FormatVersionCompressed, snapshot.CompressionGzip, 100000),
+		newFullTest(t, "full raw: 150 seps, 100k outputs, 50 ms diffs", snapshot.FormatVersionRaw, snapshot.CompressionNone, 100000),
 	}
 
 	for _, tt := range testCases {
@@ -174,6 +179,144 @@ func TestStreamLocalSnapshotDataToAndFrom(t *testing.T) {
 
 }
 
+func newFullTest(t *testing.T, name string, version byte, compression snapshot.Compression, outputCount int) test {
+	originHeader := &snapshot.FileHeader{
+		Type:                 snapshot.Full,
+		Version:              version,
+		NetworkID:            1337133713371337,
+		SEPMilestoneIndex:    milestone.Index(rand.Intn(10000)),
+		LedgerMilestoneIndex: milestone.Index(rand.Intn(10000)),
+		TreasuryOutput:       &utxo.TreasuryOutput{MilestoneID: iotago.MilestoneID{}, Amount: 13337},
+		Compression:          compression,
+	}
+
+	// create generators and consumers
+	sepIterFunc, sepGenRetriever := newSEPGenerator(150)
+	sepConsumerFunc, sepsCollRetriever := newSEPCollector()
+
+	outputIterFunc, outputGenRetriever := newOutputsGenerator(outputCount)
+	outputConsumerFunc, outputCollRetriever := newOutputCollector()
+
+	msDiffIterFunc, msDiffGenRetriever := newMsDiffGenerator(50)
+	msDiffConsumerFunc, msDiffCollRetriever := newMsDiffCollector()
+
+	return test{
+		name:                          name,
+		snapshotFileName:              "full_snapshot.bin",
+		originHeader:                  originHeader,
+		originTimestamp:               uint64(time.Now().Unix()),
+		sepGenerator:                  sepIterFunc,
+		sepGenRetriever:               sepGenRetriever,
+		outputGenerator:               outputIterFunc,
+		outputGenRetriever:            outputGenRetriever,
+		msDiffGenerator:               msDiffIterFunc,
+		msDiffGenRetriever:            msDiffGenRetriever,
+		headerConsumer:                headerEqualFunc(t, originHeader),
+		sepConsumer:                   sepConsumerFunc,
+		sepConRetriever:               sepsCollRetriever,
+		outputConsumer:                outputConsumerFunc,
+		outputConRetriever:            outputCollRetriever,
+		unspentTreasuryOutputConsumer: unspentTreasuryOutputEqualFunc(t, originHeader.TreasuryOutput),
+		msDiffConsumer:                msDiffConsumerFunc,
+		msDiffConRetriever:            msDiffCollRetriever,
+	}
+}
+
+func TestSnapshotChecksumMismatch(t *testing.T) {
+
+	writeSnapshot := func(fs vfs.Filesystem, filePath string, compression snapshot.Compression) {
+		originHeader := &snapshot.FileHeader{
+			Type:                 snapshot.Delta,
+			Version:              snapshot.FormatVersionCompressed,
+			NetworkID:            666666666,
+			SEPMilestoneIndex:    milestone.Index(rand.Intn(10000)),
+			LedgerMilestoneIndex: milestone.Index(rand.Intn(10000)),
+			Compression:          compression,
+		}
+
+		sepIterFunc, _ := newSEPGenerator(150)
+		msDiffIterFunc, _ := newMsDiffGenerator(5)
+
+		snapshotFileWrite, err := fs.OpenFile(filePath, os.O_CREATE|os.O_RDWR, 0666)
+		require.NoError(t, err)
+		_, err = snapshot.StreamSnapshotDataTo(snapshotFileWrite, uint64(time.Now().Unix()), originHeader, sepIterFunc, nil, msDiffIterFunc)
+		require.NoError(t, err)
+		require.NoError(t, snapshotFileWrite.Close())
+	}
+
+	// consumedCount is the number of solid entry points and milestone diffs passed to the consumers by the last read.
+	var consumedCount int
+	readSnapshot := func(fs vfs.Filesystem, filePath string) error {
+		snapshotFileRead, err := fs.OpenFile(filePath, os.O_RDONLY, 0666)
+		require.NoError(t, err)
+		defer func() { _ = snapshotFileRead.Close() }()
+
+		consumedCount = 0
+		return snapshot.StreamSnapshotDataFrom(snapshotFileRead, testsuite.DeSerializationParameters,
+			func(header *snapshot.ReadFileHeader) error { return nil },
+			func(id hornet.MessageID) error {
+				consumedCount++
+				return nil
+			},
+			nil, nil,
+			func(milestoneDiff *snapshot.MilestoneDiff) error {
+				consumedCount++
+				return nil
+			},
+		)
+	}
+
+	// flips a byte at the given offset of the file
+	corruptSnapshot := func(fs vfs.Filesystem, filePath string, offset int64) {
+		snapshotFile, err := fs.OpenFile(filePath, os.O_RDWR, 0666)
+		require.NoError(t, err)
+		defer func() { _ = snapshotFile.Close() }()
+
+		b := make([]byte, 1)
+		_, err = snapshotFile.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		_, err = io.ReadFull(snapshotFile, b)
+		require.NoError(t, err)
+		b[0] ^= 0xFF
+		_, err = snapshotFile.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		_, err = snapshotFile.Write(b)
+		require.NoError(t, err)
+	}
+
+	// delta header: version + type + timestamp + network-id + sep-ms-index + ledger-ms-index + seps-count + ms-diffs-count + compression + previous-checksum
+	headerSize := int64(1 + 1 + 8 + 8 + 4 + 4 + 8 + 8 + 1 + 32)
+	hashesSize := int64(4 * 32)
+
+	fs := memfs.Create()
+
+	// unmodified snapshot
+	writeSnapshot(fs, "valid.bin", snapshot.CompressionNone)
+	require.NoError(t, readSnapshot(fs, "valid.bin"))
+	require.Equal(t, 150+5, consumedCount)
+
+	// corrupted header
+	writeSnapshot(fs, "header.bin", snapshot.CompressionNone)
+	corruptSnapshot(fs, "header.bin", 10)
+	require.ErrorIs(t, readSnapshot(fs, "header.bin"), snapshot.ErrSnapshotChecksumMismatch)
+
+	// corrupted solid entry point
+	writeSnapshot(fs, "sep.bin", snapshot.CompressionNone)
+	corruptSnapshot(fs, "sep.bin", headerSize+hashesSize+5)
+	require.ErrorIs(t, readSnapshot(fs, "sep.bin"), snapshot.ErrSnapshotChecksumMismatch)
+
+	// the hash of a section is checked after the section was consumed, the following sections are not consumed anymore
+	writeSnapshot(fs, "last-sep.bin", snapshot.CompressionNone)
+	corruptSnapshot(fs, "last-sep.bin", headerSize+hashesSize+150*32-1)
+	require.ErrorIs(t, readSnapshot(fs, "last-sep.bin"), snapshot.ErrSnapshotChecksumMismatch)
+	require.Equal(t, 150, consumedCount)
+
+	// corrupted section hash
+	writeSnapshot(fs, "hash.bin", snapshot.CompressionZstd)
+	corruptSnapshot(fs, "hash.bin", headerSize)
+	require.ErrorIs(t, readSnapshot(fs, "hash.bin"), snapshot.ErrSnapshotChecksumMismatch)
+}
+
 type sepRetrieverFunc func() hornet.MessageIDs
 
 func newSEPGenerator(count int) (snapshot.SEPProducerFunc, sepRetrieverFunc) {
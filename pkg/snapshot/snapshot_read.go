@@ -19,8 +19,8 @@ import (
 // the given targetHeader is populated with the value of the read file header.
 func newFileHeaderConsumer(targetHeader *ReadFileHeader, utxoManager *utxo.Manager, wantedType Type, wantedNetworkID ...uint64) HeaderConsumerFunc {
 	return func(header *ReadFileHeader) error {
-		if header.Version != SupportedFormatVersion {
-			return errors.Wrapf(ErrUnsupportedSnapshot, "snapshot file version is %d but this HORNET version only supports %v", header.Version, SupportedFormatVersion)
+		if _, supported := supportedFormatVersions[header.Version]; !supported {
+			return errors.Wrapf(ErrUnsupportedSnapshot, "snapshot file version is %d but this HORNET version only supports %d to %d", header.Version, FormatVersionRaw, SupportedFormatVersion)
 		}
 
 		if header.Type != wantedType {
@@ -170,39 +170,36 @@ func loadSnapshotFileToStorage(
 }
 
 // LoadSnapshotFilesToStorage loads the snapshot files from the given file paths into the storage.
-func LoadSnapshotFilesToStorage(ctx context.Context, dbStorage *storage.Storage, deSeriParas *iotago.DeSerializationParameters, fullPath string, deltaPath ...string) (*ReadFileHeader, *ReadFileHeader, error) {
+// The delta snapshot files are applied in the given order, every delta snapshot file has to be based on the preceding snapshot file.
+func LoadSnapshotFilesToStorage(ctx context.Context, dbStorage *storage.Storage, deSeriParas *iotago.DeSerializationParameters, fullPath string, deltaPaths ...string) (*ReadFileHeader, []*ReadFileHeader, error) {
 
-	if len(deltaPath) > 0 && deltaPath[0] != "" {
-
-		// check that the delta snapshot file's ledger index equals the snapshot index of the full one
-		fullHeader, err := ReadSnapshotHeaderFromFile(fullPath)
-		if err != nil {
-			return nil, nil, err
+	var existingDeltaPaths []string
+	for _, deltaPath := range deltaPaths {
+		if deltaPath != "" {
+			existingDeltaPaths = append(existingDeltaPaths, deltaPath)
 		}
+	}
 
-		deltaHeader, err := ReadSnapshotHeaderFromFile(deltaPath[0])
-		if err != nil {
+	if len(existingDeltaPaths) > 0 {
+		// check that every delta snapshot file's ledger index equals the snapshot index of the preceding one
+		if _, _, err := ReadSnapshotFilesHeaders(fullPath, existingDeltaPaths...); err != nil {
 			return nil, nil, err
 		}
-
-		if deltaHeader.LedgerMilestoneIndex != fullHeader.SEPMilestoneIndex {
-			return nil, nil, fmt.Errorf("%w: delta snapshot's ledger index %d does not correspond to full snapshot's SEPs index %d",
-				ErrSnapshotsNotMergeable, deltaHeader.LedgerMilestoneIndex, fullHeader.SEPMilestoneIndex)
-		}
 	}
 
-	var fullSnapshotHeader, deltaSnapshotHeader *ReadFileHeader
 	fullSnapshotHeader, err := loadSnapshotFileToStorage(ctx, dbStorage, Full, fullPath, deSeriParas)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	if len(deltaPath) > 0 && deltaPath[0] != "" {
-		deltaSnapshotHeader, err = loadSnapshotFileToStorage(ctx, dbStorage, Delta, deltaPath[0], deSeriParas)
+	deltaSnapshotHeaders := make([]*ReadFileHeader, 0, len(existingDeltaPaths))
+	for _, deltaPath := range existingDeltaPaths {
+		deltaSnapshotHeader, err := loadSnapshotFileToStorage(ctx, dbStorage, Delta, deltaPath, deSeriParas)
 		if err != nil {
 			return nil, nil, err
 		}
+		deltaSnapshotHeaders = append(deltaSnapshotHeaders, deltaSnapshotHeader)
 	}
 
-	return fullSnapshotHeader, deltaSnapshotHeader, nil
+	return fullSnapshotHeader, deltaSnapshotHeaders, nil
 }
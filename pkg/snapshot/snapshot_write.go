@@ -35,12 +35,12 @@ const (
 // MilestoneRetrieverFunc is a function which returns the milestone for the given index.
 type MilestoneRetrieverFunc func(index milestone.Index) (*iotago.Milestone, error)
 
-// MergeInfo holds information about a merge of a full and delta snapshot.
+// MergeInfo holds information about a merge of a full and delta snapshots.
 type MergeInfo struct {
 	// The header of the full snapshot.
 	FullSnapshotHeader *ReadFileHeader
-	// The header of the delta snapshot.
-	DeltaSnapshotHeader *ReadFileHeader
+	// The headers of the delta snapshots, in the order they were applied.
+	DeltaSnapshotHeaders []*ReadFileHeader
 	// The header of the merged snapshot.
 	MergedSnapshotHeader *ReadFileHeader
 }
@@ -166,10 +166,10 @@ func newMsIndexIterator(direction MsDiffDirection, ledgerIndex milestone.Index,
 	}
 }
 
-// returns a milestone diff producer which first reads out milestone diffs from an existing delta
-// snapshot file and then the remaining diffs from the database up to the target index.
-func newMsDiffsProducerDeltaFileAndDatabase(snapshotDeltaPath string, dbStorage *storage.Storage, utxoManager *utxo.Manager, ledgerIndex milestone.Index, targetIndex milestone.Index, deSeriParas *iotago.DeSerializationParameters) (MilestoneDiffProducerFunc, error) {
-	prevDeltaFileMsDiffsProducer, err := newMsDiffsFromPreviousDeltaSnapshot(snapshotDeltaPath, ledgerIndex, deSeriParas)
+// returns a milestone diff producer which first reads out milestone diffs from the existing delta
+// snapshot files and then the remaining diffs from the database up to the target index.
+func newMsDiffsProducerDeltaFileAndDatabase(snapshotDeltaPaths []string, dbStorage *storage.Storage, utxoManager *utxo.Manager, ledgerIndex milestone.Index, targetIndex milestone.Index, deSeriParas *iotago.DeSerializationParameters) (MilestoneDiffProducerFunc, error) {
+	prevDeltaFileMsDiffsProducer, err := newMsDiffsFromPreviousDeltaSnapshots(snapshotDeltaPaths, ledgerIndex, deSeriParas)
 	if err != nil {
 		return nil, err
 	}
@@ -202,39 +202,62 @@ func newMsDiffsProducerDeltaFileAndDatabase(snapshotDeltaPath string, dbStorage
 	}, nil
 }
 
-// returns a milestone diff producer which reads out the milestone diffs from an existing delta snapshot file.
-// the existing delta snapshot file is closed as soon as its milestone diffs are read.
-func newMsDiffsFromPreviousDeltaSnapshot(snapshotDeltaPath string, originLedgerIndex milestone.Index, deSeriParas *iotago.DeSerializationParameters) (MilestoneDiffProducerFunc, error) {
-	existingDeltaFile, err := os.OpenFile(snapshotDeltaPath, os.O_RDONLY, 0666)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read previous delta snapshot file for milestone diffs: %w", err)
+// returns a milestone diff producer which reads out the milestone diffs from the existing delta snapshot files.
+// the files are read in the given order, every file has to continue at the snapshot index of its predecessor.
+// every existing delta snapshot file is closed as soon as its milestone diffs are read.
+func newMsDiffsFromPreviousDeltaSnapshots(snapshotDeltaPaths []string, originLedgerIndex milestone.Index, deSeriParas *iotago.DeSerializationParameters) (MilestoneDiffProducerFunc, error) {
+	if len(snapshotDeltaPaths) == 0 {
+		return nil, errors.New("unable to read previous delta snapshot file for milestone diffs: no delta snapshot file found")
+	}
+
+	existingDeltaFiles := make([]*os.File, 0, len(snapshotDeltaPaths))
+	for _, snapshotDeltaPath := range snapshotDeltaPaths {
+		existingDeltaFile, err := os.OpenFile(snapshotDeltaPath, os.O_RDONLY, 0666)
+		if err != nil {
+			for _, file := range existingDeltaFiles {
+				_ = file.Close()
+			}
+			return nil, fmt.Errorf("unable to read previous delta snapshot file for milestone diffs: %w", err)
+		}
+		existingDeltaFiles = append(existingDeltaFiles, existingDeltaFile)
 	}
 
 	prodChan := make(chan interface{})
 	errChan := make(chan error)
 
 	go func() {
-		defer func() { _ = existingDeltaFile.Close() }()
-
-		if err := StreamSnapshotDataFrom(existingDeltaFile,
-			deSeriParas,
-			func(header *ReadFileHeader) error {
-				// check that the ledger index matches
-				if header.LedgerMilestoneIndex != originLedgerIndex {
-					return fmt.Errorf("%w: wanted %d but got %d", ErrExistingDeltaSnapshotWrongLedgerIndex, originLedgerIndex, header.LedgerMilestoneIndex)
-				}
-				return nil
-			},
-			func(id hornet.MessageID) error {
-				// we don't care about solid entry points
-				return nil
-			}, nil, nil,
-			func(milestoneDiff *MilestoneDiff) error {
-				prodChan <- milestoneDiff
-				return nil
-			},
-		); err != nil {
-			errChan <- err
+		defer func() {
+			for _, file := range existingDeltaFiles {
+				_ = file.Close()
+			}
+		}()
+
+		ledgerIndex := originLedgerIndex
+		for _, existingDeltaFile := range existingDeltaFiles {
+			if err := StreamSnapshotDataFrom(existingDeltaFile,
+				deSeriParas,
+				func(header *ReadFileHeader) error {
+					// check that the ledger index matches
+					if header.LedgerMilestoneIndex != ledgerIndex {
+						return fmt.Errorf("%w: wanted %d but got %d", ErrExistingDeltaSnapshotWrongLedgerIndex, ledgerIndex, header.LedgerMilestoneIndex)
+					}
+					// the next delta snapshot file continues at the snapshot index of this file
+					ledgerIndex = header.SEPMilestoneIndex
+					return nil
+				},
+				func(id hornet.MessageID) error {
+					// we don't care about solid entry points
+					return nil
+				}, nil, nil,
+				func(milestoneDiff *MilestoneDiff) error {
+					prodChan <- milestoneDiff
+					return nil
+				},
+			); err != nil {
+				errChan <- err
+				break
+			}
+			_ = existingDeltaFile.Close()
 		}
 
 		close(prodChan)
@@ -335,8 +358,8 @@ func (s *SnapshotManager) readLedgerIndex() (milestone.Index, error) {
 	return ledgerMilestoneIndex, nil
 }
 
-// reads out the snapshot milestone index from the full snapshot file.
-func (s *SnapshotManager) readSnapshotIndexFromFullSnapshotFile(snapshotFullPath ...string) (milestone.Index, error) {
+// reads out the header of the full snapshot file.
+func (s *SnapshotManager) readFullSnapshotHeader(snapshotFullPath ...string) (*ReadFileHeader, error) {
 	filePath := s.snapshotFullPath
 	if len(snapshotFullPath) > 0 && snapshotFullPath[0] != "" {
 		filePath = snapshotFullPath[0]
@@ -344,13 +367,26 @@ func (s *SnapshotManager) readSnapshotIndexFromFullSnapshotFile(snapshotFullPath
 
 	fullSnapshotHeader, err := ReadSnapshotHeaderFromFile(filePath)
 	if err != nil {
-		return 0, fmt.Errorf("unable to read full snapshot header for origin snapshot milestone index: %w", err)
+		return nil, fmt.Errorf("unable to read full snapshot header for origin snapshot milestone index: %w", err)
 	}
 
-	// note that a full snapshot contains the ledger to the CMI of the node which generated it,
-	// however, the state is rolled backed to the snapshot index, therefore, the snapshot index
-	// is the actual point from which on the delta snapshot should contain milestone diffs
-	return fullSnapshotHeader.SEPMilestoneIndex, nil
+	return fullSnapshotHeader, nil
+}
+
+// reads out the header of the last snapshot file in the delta snapshot chain,
+// which is the full snapshot file if no delta snapshot file exists.
+func (s *SnapshotManager) readDeltaChainTipHeader() (*ReadFileHeader, error) {
+	deltaPaths, err := DeltaSnapshotFilePaths(s.snapshotDeltaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fullHeader, deltaHeaders, err := ReadSnapshotFilesHeaders(s.snapshotFullPath, deltaPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read snapshot headers of the delta snapshot chain: %w", err)
+	}
+
+	return lastSnapshotHeader(fullHeader, deltaHeaders), nil
 }
 
 // returns the timestamp of the target milestone.
@@ -413,6 +449,7 @@ func (s *SnapshotManager) createSnapshotWithoutLocking(
 		Type:              snapshotType,
 		NetworkID:         snapshotInfo.NetworkID,
 		SEPMilestoneIndex: targetIndex,
+		Compression:       s.compression,
 	}
 
 	targetMsTimestamp, err := readTargetMilestoneTimestamp(s.storage, targetIndex)
@@ -423,8 +460,8 @@ func (s *SnapshotManager) createSnapshotWithoutLocking(
 	// generate producers
 	var utxoProducer OutputProducerFunc
 	var milestoneDiffProducer MilestoneDiffProducerFunc
-	switch snapshotType {
-	case Full:
+	switch {
+	case snapshotType == Full:
 		// ledger index corresponds to the CMI
 		header.LedgerMilestoneIndex, err = s.readLedgerIndex()
 		if err != nil {
@@ -442,17 +479,55 @@ func (s *SnapshotManager) createSnapshotWithoutLocking(
 		utxoProducer = newCMIUTXOProducer(s.utxoManager)
 		milestoneDiffProducer = newMsDiffsProducer(MilestoneRetrieverFromStorage(s.storage), s.utxoManager, MsDiffDirectionBackwards, header.LedgerMilestoneIndex, targetIndex)
 
-	case Delta:
+	case snapshotType == Delta && s.isDeltaChainFilePath(filePath):
+		// a chained delta snapshot is based on the last snapshot file in the chain.
+		// this will return an error if the full snapshot file is not available
+		previousHeader, err := s.readDeltaChainTipHeader()
+		if err != nil {
+			return err
+		}
+
+		header.LedgerMilestoneIndex = previousHeader.SEPMilestoneIndex
+		header.PreviousChecksum = previousHeader.Checksum
+
+		if targetIndex <= header.LedgerMilestoneIndex {
+			return errors.Wrapf(ErrTargetIndexTooOld, "minimum: %d, actual: %d", header.LedgerMilestoneIndex+1, targetIndex)
+		}
+
+		// a chained delta snapshot only contains the milestone diffs since the last snapshot file in the chain,
+		// which need to be available in the database.
+		if snapshotInfo.PruningIndex > header.LedgerMilestoneIndex {
+			return errors.Wrapf(ErrNotEnoughHistory, "milestone diffs since the last snapshot file in the chain (%d) were already pruned (%d)", header.LedgerMilestoneIndex, snapshotInfo.PruningIndex)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			return fmt.Errorf("could not create delta snapshot chain dir '%s': %w", filepath.Dir(filePath), err)
+		}
+
+		milestoneDiffProducer = newMsDiffsProducer(MilestoneRetrieverFromStorage(s.storage), s.utxoManager, MsDiffDirectionOnwards, header.LedgerMilestoneIndex, targetIndex)
+
+	case snapshotType == Delta:
 		// ledger index corresponds to the origin snapshot snapshot ledger.
 		// this will return an error if the full snapshot file is not available
-		header.LedgerMilestoneIndex, err = s.readSnapshotIndexFromFullSnapshotFile(snapshotFullPath...)
+		fullHeader, err := s.readFullSnapshotHeader(snapshotFullPath...)
 		if err != nil {
 			return err
 		}
 
-		// a delta snapshot contains the milestone diffs from a full snapshot's snapshot index onwards
-		_, err := os.Stat(s.snapshotDeltaPath)
-		deltaSnapshotFileExists := !os.IsNotExist(err)
+		// note that a full snapshot contains the ledger to the CMI of the node which generated it,
+		// however, the state is rolled backed to the snapshot index, therefore, the snapshot index
+		// is the actual point from which on the delta snapshot should contain milestone diffs
+		header.LedgerMilestoneIndex = fullHeader.SEPMilestoneIndex
+		header.PreviousChecksum = fullHeader.Checksum
+
+		// a delta snapshot contains the milestone diffs from a full snapshot's snapshot index onwards.
+		// the existing delta snapshot files (including a delta snapshot chain) contain the milestone diffs
+		// from the snapshot index of the internal full snapshot file onwards.
+		deltaPaths, err := DeltaSnapshotFilePaths(s.snapshotDeltaPath)
+		if err != nil {
+			return err
+		}
+		deltaSnapshotFileExists := len(deltaPaths) > 0
 
 		// if a delta snapshot is created via API, either the internal full snapshot file of the node or a newly created full snapshot file is used ("snapshotFullPath").
 		// if the internal full snapshot file is used, the existing delta snapshot file contains the needed data.
@@ -469,7 +544,7 @@ func (s *SnapshotManager) createSnapshotWithoutLocking(
 		default:
 			// as the needed milestone diffs are pruned from the database, we need to use
 			// the previous delta snapshot file to extract those in conjunction with what the database has available
-			milestoneDiffProducer, err = newMsDiffsProducerDeltaFileAndDatabase(s.snapshotDeltaPath, s.storage, s.utxoManager, header.LedgerMilestoneIndex, targetIndex, s.deSeriParas)
+			milestoneDiffProducer, err = newMsDiffsProducerDeltaFileAndDatabase(deltaPaths, s.storage, s.utxoManager, header.LedgerMilestoneIndex, targetIndex, s.deSeriParas)
 			if err != nil {
 				return err
 			}
@@ -499,11 +574,22 @@ func (s *SnapshotManager) createSnapshotWithoutLocking(
 
 	if (snapshotType == Full) && (filePath == s.snapshotFullPath) {
 		// if the old full snapshot file is overwritten
-		// we need to remove the old delta snapshot file since it
-		// isn't compatible to the full snapshot file anymore.
+		// we need to remove the old delta snapshot files since they
+		// aren't compatible to the full snapshot file anymore.
 		if err = os.Remove(s.snapshotDeltaPath); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("deleting delta snapshot file failed: %s", err)
 		}
+		if err = RemoveDeltaChainFiles(s.snapshotDeltaPath); err != nil {
+			return err
+		}
+	}
+
+	if (snapshotType == Delta) && (filePath == s.snapshotDeltaPath) {
+		// the new delta snapshot file contains all milestone diffs of
+		// an existing delta snapshot chain, so the chain is not needed anymore.
+		if err = RemoveDeltaChainFiles(s.snapshotDeltaPath); err != nil {
+			return err
+		}
 	}
 
 	timeSetSnapshotInfo := timeStreamSnapshotData
@@ -569,6 +655,7 @@ func createSnapshotFromCurrentStorageState(dbStorage *storage.Storage, filePath
 		SEPMilestoneIndex:    ledgerIndex,
 		LedgerMilestoneIndex: ledgerIndex,
 		TreasuryOutput:       unspentTreasuryOutput,
+		Compression:          DefaultCompression,
 	}
 
 	// returns a producer which returns all solid entry points in the database.
@@ -730,6 +817,7 @@ func CreateSnapshotFromStorage(
 		SEPMilestoneIndex:    targetIndex,
 		LedgerMilestoneIndex: targetIndex,
 		TreasuryOutput:       unspentTreasuryOutput,
+		Compression:          DefaultCompression,
 	}
 
 	targetMsTimestamp, err := readTargetMilestoneTimestamp(dbStorage, targetIndex)
@@ -796,12 +884,13 @@ func CreateSnapshotFromStorage(
 }
 
 // MergeSnapshotsFiles merges the given full and delta snapshots to create an updated full snapshot.
+// The delta snapshots are applied in the given order, e.g. a delta snapshot followed by a delta snapshot chain.
 // The result is a full snapshot file containing the ledger outputs corresponding to the
-// snapshot index of the specified delta snapshot. The target file does not include any milestone diffs
+// snapshot index of the last specified delta snapshot. The target file does not include any milestone diffs
 // and the ledger and snapshot index are equal.
 // This function consumes disk space over memory by importing the full snapshot into a temporary database,
 // applying the delta diffs onto it and then writing out the merged state.
-func MergeSnapshotsFiles(fullPath string, deltaPath string, targetFileName string, deSeriParas *iotago.DeSerializationParameters) (*MergeInfo, error) {
+func MergeSnapshotsFiles(fullPath string, deltaPaths []string, targetFileName string, deSeriParas *iotago.DeSerializationParameters) (*MergeInfo, error) {
 
 	targetEngine, err := database.DatabaseEngineAllowed(database.EnginePebble)
 	if err != nil {
@@ -836,7 +925,7 @@ func MergeSnapshotsFiles(fullPath string, deltaPath string, targetFileName strin
 		return nil, err
 	}
 
-	fullSnapshotHeader, deltaSnapshotHeader, err := LoadSnapshotFilesToStorage(context.Background(), dbStorage, deSeriParas, fullPath, deltaPath)
+	fullSnapshotHeader, deltaSnapshotHeaders, err := LoadSnapshotFilesToStorage(context.Background(), dbStorage, deSeriParas, fullPath, deltaPaths...)
 	if err != nil {
 		return nil, err
 	}
@@ -848,7 +937,7 @@ func MergeSnapshotsFiles(fullPath string, deltaPath string, targetFileName strin
 
 	return &MergeInfo{
 		FullSnapshotHeader:   fullSnapshotHeader,
-		DeltaSnapshotHeader:  deltaSnapshotHeader,
+		DeltaSnapshotHeaders: deltaSnapshotHeaders,
 		MergedSnapshotHeader: mergedSnapshotHeader,
 	}, nil
 }
@@ -0,0 +1,98 @@
+package toolset
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/iotaledger/iota.go/v3/nodeclient"
+)
+
+const (
+	// the route of the REST API of a running node to create a database checkpoint.
+	nodeRouteDatabaseCheckpoint = "/api/v2/control/database/checkpoint"
+)
+
+// databaseCheckpointResponse defines the response of a create database checkpoint REST API call.
+type databaseCheckpointResponse struct {
+	// The path of the checkpoint directory on the node.
+	Path string `json:"path"`
+	// The ledger index of the UTXO database in the checkpoint.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+	// The index of the last snapshot.
+	SnapshotIndex milestone.Index `json:"snapshotIndex"`
+	// The index of the solid entry points.
+	EntryPointIndex milestone.Index `json:"entryPointIndex"`
+	// The index the database was pruned to.
+	PruningIndex milestone.Index `json:"pruningIndex"`
+}
+
+// databaseCheckpoint creates a checkpoint of the databases of a running node via its REST API.
+// The databases can't be opened by the tool while the node is running, so the node creates the checkpoint itself.
+func databaseCheckpoint(args []string) error {
+
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	nodeURLFlag := fs.String(FlagToolDatabaseCheckpointNodeURL, DefaultValueNodeURL, "URL of the REST API of the running node")
+	jwtFlag := fs.String(FlagToolDatabaseCheckpointJWT, "", "the JWT token to access the protected routes of the REST API (optional)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabaseCheckpoint)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s",
+			ToolDatabaseCheckpoint,
+			FlagToolDatabaseCheckpointNodeURL,
+			DefaultValueNodeURL,
+		))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*nodeURLFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabaseCheckpointNodeURL)
+	}
+
+	var requestHeaderHook nodeclient.RequestHeaderHook
+	if len(*jwtFlag) > 0 {
+		requestHeaderHook = func(header http.Header) {
+			header.Set("Authorization", "Bearer "+*jwtFlag)
+		}
+	}
+
+	if !*outputJSONFlag {
+		fmt.Println("creating database checkpoint...")
+	}
+
+	ts := time.Now()
+
+	checkpoint := &databaseCheckpointResponse{}
+	if _, err := nodeclient.New(*nodeURLFlag).DoWithRequestHeaderHook(context.Background(), http.MethodPost, nodeRouteDatabaseCheckpoint, requestHeaderHook, nil, checkpoint); err != nil {
+		return fmt.Errorf("creating database checkpoint failed: %w", err)
+	}
+
+	if *outputJSONFlag {
+		return printJSON(checkpoint)
+	}
+
+	fmt.Printf(`    >
+        - Ledger index:          %d
+        - Snapshot index:        %d
+        - Entry point index:     %d
+        - Pruning index:         %d`+"\n\n",
+		checkpoint.LedgerIndex,
+		checkpoint.SnapshotIndex,
+		checkpoint.EntryPointIndex,
+		checkpoint.PruningIndex,
+	)
+
+	fmt.Printf("successfully created database checkpoint '%s' on the node, took %v\n", checkpoint.Path, time.Since(ts).Truncate(time.Millisecond))
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/gohornet/hornet/pkg/database"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func databaseLedgerAt(args []string) error {
+
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueMainnetDatabasePath, "the path to the database")
+	targetIndexFlag := fs.Uint32(FlagToolDatabaseTargetIndex, 0, "the milestone index of the ledger state")
+	addressFlag := fs.String(FlagToolAddress, "", "the bech32 address to compute the balance for (optional)")
+	outputIDFlag := fs.String(FlagToolOutputID, "", "the output ID to get the state for (optional)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabaseLedgerAt)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolDatabaseLedgerAt,
+			FlagToolDatabasePath,
+			DefaultValueMainnetDatabasePath,
+			FlagToolDatabaseTargetIndex,
+			"100",
+		))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*databasePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabasePath)
+	}
+	if *targetIndexFlag == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabaseTargetIndex)
+	}
+	if len(*addressFlag) > 0 && len(*outputIDFlag) > 0 {
+		return fmt.Errorf("only one of '%s' and '%s' can be specified", FlagToolAddress, FlagToolOutputID)
+	}
+
+	var address iotago.Address
+	if len(*addressFlag) > 0 {
+		_, addr, err := iotago.ParseBech32(strings.ToLower(*addressFlag))
+		if err != nil {
+			return fmt.Errorf("invalid '%s': %w", FlagToolAddress, err)
+		}
+		address = addr
+	}
+
+	var outputID *iotago.OutputID
+	if len(*outputIDFlag) > 0 {
+		id, err := iotago.OutputIDFromHex(strings.ToLower(*outputIDFlag))
+		if err != nil {
+			return fmt.Errorf("invalid '%s': %w", FlagToolOutputID, err)
+		}
+		outputID = &id
+	}
+
+	tangleStore, err := getTangleStorage(*databasePathFlag, "database", string(database.EngineAuto), true, true, true, false, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tangleStore.ShutdownStorages()
+		tangleStore.FlushAndCloseStores()
+	}()
+
+	targetIndex := milestone.Index(*targetIndexFlag)
+
+	ts := time.Now()
+
+	switch {
+	case outputID != nil:
+		return printOutputStateAtMilestone(tangleStore, outputID, targetIndex, *outputJSONFlag)
+
+	case address != nil:
+		return printAddressBalanceAtMilestone(tangleStore, *addressFlag, address, targetIndex, *outputJSONFlag)
+
+	default:
+		if !*outputJSONFlag {
+			fmt.Printf("computing the ledger state at milestone %d...\n", targetIndex)
+		}
+
+		if err := printLedgerStateAtMilestone(tangleStore, targetIndex, *outputJSONFlag); err != nil {
+			return err
+		}
+
+		if !*outputJSONFlag {
+			fmt.Printf("successfully computed the ledger state, took %v\n", time.Since(ts).Truncate(time.Millisecond))
+		}
+		return nil
+	}
+}
+
+func printLedgerStateAtMilestone(dbStorage *storage.Storage, targetIndex milestone.Index, outputJSON bool) error {
+
+	var outputsCount int
+	var totalBalance uint64
+	if err := dbStorage.UTXOManager().ForEachUnspentOutputAtMilestone(targetIndex, func(output *utxo.Output) bool {
+		outputsCount++
+		totalBalance += output.Deposit()
+		return true
+	}); err != nil {
+		return err
+	}
+
+	if outputJSON {
+		result := struct {
+			LedgerIndex  milestone.Index `json:"ledgerIndex"`
+			UTXOsCount   int             `json:"UTXOsCount"`
+			TotalBalance uint64          `json:"totalBalance"`
+		}{
+			LedgerIndex:  targetIndex,
+			UTXOsCount:   outputsCount,
+			TotalBalance: totalBalance,
+		}
+
+		return printJSON(result)
+	}
+
+	fmt.Printf(`    >
+        - Ledger index:  %d
+        - UTXOs count:   %d
+        - Total balance: %d`+"\n\n",
+		targetIndex,
+		outputsCount,
+		totalBalance,
+	)
+
+	return nil
+}
+
+func printAddressBalanceAtMilestone(dbStorage *storage.Storage, bech32Address string, address iotago.Address, targetIndex milestone.Index, outputJSON bool) error {
+
+	balance, count, err := dbStorage.UTXOManager().AddressBalanceAtMilestone(address, targetIndex)
+	if err != nil {
+		return err
+	}
+
+	if outputJSON {
+		result := struct {
+			Address     string          `json:"address"`
+			LedgerIndex milestone.Index `json:"ledgerIndex"`
+			Balance     uint64          `json:"balance"`
+			OutputCount int             `json:"outputCount"`
+		}{
+			Address:     bech32Address,
+			LedgerIndex: targetIndex,
+			Balance:     balance,
+			OutputCount: count,
+		}
+
+		return printJSON(result)
+	}
+
+	fmt.Printf(`    >
+        - Address:      %s
+        - Ledger index: %d
+        - Balance:      %d
+        - Output count: %d`+"\n\n",
+		bech32Address,
+		targetIndex,
+		balance,
+		count,
+	)
+
+	return nil
+}
+
+func printOutputStateAtMilestone(dbStorage *storage.Storage, outputID *iotago.OutputID, targetIndex milestone.Index, outputJSON bool) error {
+
+	dbStorage.UTXOManager().ReadLockLedger()
+	defer dbStorage.UTXOManager().ReadUnlockLedger()
+
+	output, spent, err := dbStorage.UTXOManager().ReadOutputStateAtMilestoneWithoutLocking(outputID, targetIndex)
+	if err != nil {
+		return err
+	}
+
+	var milestoneIndexSpent milestone.Index
+	if spent != nil {
+		milestoneIndexSpent = spent.MilestoneIndex()
+	}
+
+	if outputJSON {
+		result := struct {
+			OutputID             string          `json:"outputID"`
+			LedgerIndex          milestone.Index `json:"ledgerIndex"`
+			Amount               uint64          `json:"amount"`
+			MilestoneIndexBooked milestone.Index `json:"milestoneIndexBooked"`
+			Spent                bool            `json:"spent"`
+			MilestoneIndexSpent  milestone.Index `json:"milestoneIndexSpent,omitempty"`
+		}{
+			OutputID:             outputID.ToHex(),
+			LedgerIndex:          targetIndex,
+			Amount:               output.Deposit(),
+			MilestoneIndexBooked: output.MilestoneIndex(),
+			Spent:                spent != nil,
+			MilestoneIndexSpent:  milestoneIndexSpent,
+		}
+
+		return printJSON(result)
+	}
+
+	fmt.Printf(`    >
+        - Output ID:              %s
+        - Ledger index:           %d
+        - Amount:                 %d
+        - Milestone index booked: %d
+        - Spent:                  %s`+"\n",
+		outputID.ToHex(),
+		targetIndex,
+		output.Deposit(),
+		output.MilestoneIndex(),
+		yesOrNo(spent != nil),
+	)
+	if spent != nil {
+		fmt.Printf("        - Milestone index spent:  %d\n", milestoneIndexSpent)
+	}
+	fmt.Println()
+
+	return nil
+}
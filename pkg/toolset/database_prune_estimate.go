@@ -0,0 +1,154 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/labstack/gommon/bytes"
+	flag "github.com/spf13/pflag"
+
+	snapCore "github.com/gohornet/hornet/core/snapshot"
+	"github.com/gohornet/hornet/pkg/database"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/gohornet/hornet/pkg/utils"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func databasePruneEstimate(args []string) error {
+
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	databasePathFlag := fs.String(FlagToolDatabasePath, DefaultValueMainnetDatabasePath, "the path to the database")
+	targetIndexFlag := fs.Uint32(FlagToolDatabaseTargetIndex, 0, "the pruning target index")
+	targetDatabaseSizeFlag := fs.String(FlagToolDatabaseTargetSize, "", "the target size of the database (e.g. 30GB)")
+	thresholdPercentageFlag := fs.Float64(FlagToolDatabaseThresholdPercentage, 10.0, "the percentage the database size gets reduced if the target size is reached")
+	pinnedTagPrefixesFlag := fs.StringSlice(FlagToolDatabasePinnedTagPrefixes, nil, "the hex encoded tag prefixes of the messages that are kept by the pruning (e.g. the pruning.pinnedTagPrefixes of the node)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolDatabasePruneEstimate)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s",
+			ToolDatabasePruneEstimate,
+			FlagToolDatabasePath,
+			DefaultValueMainnetDatabasePath,
+			FlagToolDatabaseTargetIndex,
+			"100",
+		))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*databasePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolDatabasePath)
+	}
+	if (*targetIndexFlag == 0) == (len(*targetDatabaseSizeFlag) == 0) {
+		return fmt.Errorf("either '%s' or '%s' has to be specified", FlagToolDatabaseTargetIndex, FlagToolDatabaseTargetSize)
+	}
+
+	var pinnedTagPrefixes [][]byte
+	for _, tagPrefixHex := range *pinnedTagPrefixesFlag {
+		tagPrefix, err := iotago.DecodeHex(tagPrefixHex)
+		if err != nil || len(tagPrefix) == 0 || len(tagPrefix) > iotago.MaxTagLength {
+			return fmt.Errorf("invalid '%s': %s", FlagToolDatabasePinnedTagPrefixes, tagPrefixHex)
+		}
+		pinnedTagPrefixes = append(pinnedTagPrefixes, tagPrefix)
+	}
+
+	tangleStore, err := getTangleStorage(*databasePathFlag, "database", string(database.EngineAuto), true, false, true, false, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tangleStore.ShutdownStorages()
+		tangleStore.FlushAndCloseStores()
+	}()
+
+	targetIndex := milestone.Index(*targetIndexFlag)
+	if len(*targetDatabaseSizeFlag) > 0 {
+		targetDatabaseSizeBytes, err := bytes.Parse(*targetDatabaseSizeFlag)
+		if err != nil {
+			return fmt.Errorf("invalid '%s': %w", FlagToolDatabaseTargetSize, err)
+		}
+
+		currentDatabaseSizeBytes, err := utils.FolderSize(*databasePathFlag)
+		if err != nil {
+			return err
+		}
+
+		// the ledger index is the confirmed milestone index of a stopped node
+		ledgerIndex, err := tangleStore.UTXOManager().ReadLedgerIndex()
+		if err != nil {
+			return err
+		}
+
+		targetIndex, err = snapshot.TargetIndexBySize(currentDatabaseSizeBytes, targetDatabaseSizeBytes, *thresholdPercentageFlag, tangleStore.SnapshotInfo().PruningIndex, ledgerIndex)
+		if err != nil {
+			return err
+		}
+	}
+
+	estimate, err := snapshot.EstimatePruningFromStorage(
+		getGracefulStopContext(),
+		tangleStore,
+		tangleStore.UTXOManager(),
+		targetIndex,
+		milestone.Index(belowMaxDepth+snapCore.SolidEntryPointCheckAdditionalThresholdPast),
+		milestone.Index(belowMaxDepth+snapCore.AdditionalPruningThreshold),
+		pinnedTagPrefixes,
+	)
+	if err != nil {
+		return err
+	}
+
+	if *outputJSONFlag {
+
+		result := struct {
+			StartIndex                milestone.Index `json:"startIndex"`
+			TargetIndex               milestone.Index `json:"targetIndex"`
+			MilestonesCount           int             `json:"milestonesCount"`
+			MessagesCount             int             `json:"messagesCount"`
+			UnreferencedMessagesCount int             `json:"unreferencedMessagesCount"`
+			ChildrenCount             int             `json:"childrenCount"`
+			TangleBytes               int64           `json:"tangleBytes"`
+			LedgerDiffsBytes          int64           `json:"ledgerDiffsBytes"`
+			EstimatedFreedBytes       int64           `json:"estimatedFreedBytes"`
+		}{
+			StartIndex:                estimate.StartIndex,
+			TargetIndex:               estimate.TargetIndex,
+			MilestonesCount:           estimate.MilestonesCount,
+			MessagesCount:             estimate.MessagesCount,
+			UnreferencedMessagesCount: estimate.UnreferencedMessagesCount,
+			ChildrenCount:             estimate.ChildrenCount,
+			TangleBytes:               estimate.TangleBytes,
+			LedgerDiffsBytes:          estimate.LedgerDiffsBytes,
+			EstimatedFreedBytes:       estimate.EstimatedFreedBytes,
+		}
+
+		return printJSON(result)
+	}
+
+	fmt.Printf(`    >
+        - Milestone range:       %d-%d
+        - Milestones:            %d
+        - Messages:              %d
+        - Unreferenced messages: %d
+        - Children:              %d
+        - Tangle data:           %s
+        - Ledger diffs:          %s
+        - Estimated freed:       %s`+"\n\n",
+		estimate.StartIndex,
+		estimate.TargetIndex,
+		estimate.MilestonesCount,
+		estimate.MessagesCount,
+		estimate.UnreferencedMessagesCount,
+		estimate.ChildrenCount,
+		bytes.Format(estimate.TangleBytes),
+		bytes.Format(estimate.LedgerDiffsBytes),
+		bytes.Format(estimate.EstimatedFreedBytes),
+	)
+
+	return nil
+}
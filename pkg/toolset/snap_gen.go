@@ -98,6 +98,7 @@ func snapshotGen(args []string) error {
 			MilestoneID: iotago.MilestoneID{},
 			Amount:      treasury,
 		},
+		Compression: snapshot.DefaultCompression,
 	}
 
 	// solid entry points
@@ -19,7 +19,7 @@ func snapshotHash(args []string) error {
 
 	fs := flag.NewFlagSet("", flag.ContinueOnError)
 	fullSnapshotPathFlag := fs.String(FlagToolSnapshotPathFull, "snapshots/mainnet/full_snapshot.bin", "the path to the full snapshot file")
-	deltaSnapshotPathFlag := fs.String(FlagToolSnapshotPathDelta, "snapshots/mainnet/delta_snapshot.bin", "the path to the delta snapshot file (optional)")
+	deltaSnapshotPathFlag := fs.String(FlagToolSnapshotPathDelta, "snapshots/mainnet/delta_snapshot.bin", "the path to the delta snapshot file, the files of its delta snapshot chain are applied as well (optional)")
 	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
 
 	fs.Usage = func() {
@@ -42,7 +42,15 @@ func snapshotHash(args []string) error {
 	}
 
 	fullPath := *fullSnapshotPathFlag
-	deltaPath := *deltaSnapshotPathFlag
+
+	var deltaPaths []string
+	if len(*deltaSnapshotPathFlag) > 0 {
+		var err error
+		deltaPaths, err = snapshot.DeltaSnapshotFilePaths(*deltaSnapshotPathFlag)
+		if err != nil {
+			return err
+		}
+	}
 
 	targetEngine, err := database.DatabaseEngineAllowed(database.EnginePebble)
 	if err != nil {
@@ -77,7 +85,7 @@ func snapshotHash(args []string) error {
 		return err
 	}
 
-	_, _, err = snapshot.LoadSnapshotFilesToStorage(context.Background(), dbStorage, nil, fullPath, deltaPath)
+	_, _, err = snapshot.LoadSnapshotFilesToStorage(context.Background(), dbStorage, nil, fullPath, deltaPaths...)
 	if err != nil {
 		return err
 	}
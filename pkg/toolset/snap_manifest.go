@@ -0,0 +1,115 @@
+package toolset
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+func snapshotManifest(args []string) error {
+
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fullSnapshotPathFlag := fs.String(FlagToolSnapshotPathFull, "snapshots/mainnet/full_snapshot.bin", "the path to the full snapshot file")
+	deltaSnapshotPathFlag := fs.String(FlagToolSnapshotPathDelta, "", "the path to the delta snapshot file, the files of its delta snapshot chain are added as well (optional)")
+	outputFilePathFlag := fs.String(FlagToolOutputPath, "", "the file path to the generated manifest file")
+	privateKeyFlag := fs.String(FlagToolPrivateKey, "", "the ed25519 private key to sign the manifest with (optional)")
+	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", ToolSnapManifest)
+		fs.PrintDefaults()
+		println(fmt.Sprintf("\nexample: %s --%s %s --%s %s --%s %s --%s %s",
+			ToolSnapManifest,
+			FlagToolSnapshotPathFull,
+			"snapshots/mainnet/full_snapshot.bin",
+			FlagToolSnapshotPathDelta,
+			"snapshots/mainnet/delta_snapshot.bin",
+			FlagToolOutputPath,
+			"snapshots/mainnet/manifest.json",
+			FlagToolPrivateKey,
+			"[PRIVATE_KEY]"))
+	}
+
+	if err := parseFlagSet(fs, args); err != nil {
+		return err
+	}
+
+	if len(*fullSnapshotPathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolSnapshotPathFull)
+	}
+	if len(*outputFilePathFlag) == 0 {
+		return fmt.Errorf("'%s' not specified", FlagToolOutputPath)
+	}
+
+	manifest := &snapshot.SnapshotManifest{}
+
+	var err error
+	manifest.Full, err = snapshot.NewSnapshotManifestFile(*fullSnapshotPathFlag)
+	if err != nil {
+		return fmt.Errorf("unable to create manifest of the full snapshot file: %w", err)
+	}
+
+	if len(*deltaSnapshotPathFlag) > 0 {
+		deltaPath := *deltaSnapshotPathFlag
+
+		// the delta snapshot file itself doesn't exist if only a delta snapshot chain is used
+		if _, err := os.Stat(deltaPath); err == nil {
+			manifest.Delta, err = snapshot.NewSnapshotManifestFile(deltaPath)
+			if err != nil {
+				return fmt.Errorf("unable to create manifest of the delta snapshot file: %w", err)
+			}
+		}
+
+		deltaChainPaths, err := snapshot.DeltaChainFilePaths(deltaPath)
+		if err != nil {
+			return err
+		}
+
+		for _, deltaChainPath := range deltaChainPaths {
+			manifestFile, err := snapshot.NewSnapshotManifestFile(deltaChainPath)
+			if err != nil {
+				return fmt.Errorf("unable to create manifest of the chained delta snapshot file %s: %w", deltaChainPath, err)
+			}
+			manifest.DeltaChain = append(manifest.DeltaChain, manifestFile)
+		}
+
+		if manifest.Delta == nil && len(manifest.DeltaChain) == 0 {
+			return fmt.Errorf("no delta snapshot files found for '%s'", deltaPath)
+		}
+	}
+
+	if len(*privateKeyFlag) > 0 {
+		privateKey, err := utils.ParseEd25519PrivateKeyFromString(*privateKeyFlag)
+		if err != nil {
+			return fmt.Errorf("invalid private key given: %w", err)
+		}
+
+		if err := manifest.Sign(privateKey); err != nil {
+			return fmt.Errorf("unable to sign manifest: %w", err)
+		}
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(*outputFilePathFlag, manifestJSON, 0666); err != nil {
+		return fmt.Errorf("unable to write manifest file: %w", err)
+	}
+
+	if *outputJSONFlag {
+		return printJSON(manifest)
+	}
+
+	fmt.Printf("successfully created snapshot manifest '%s'\n", *outputFilePathFlag)
+	fmt.Println(string(manifestJSON))
+
+	return nil
+}
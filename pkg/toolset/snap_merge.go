@@ -16,7 +16,7 @@ func snapshotMerge(args []string) error {
 
 	fs := flag.NewFlagSet("", flag.ContinueOnError)
 	snapshotPathFullFlag := fs.String(FlagToolSnapshotPathFull, "", "the path to the full snapshot file")
-	snapshotPathDeltaFlag := fs.String(FlagToolSnapshotPathDelta, "", "the path to the delta snapshot file")
+	snapshotPathDeltaFlag := fs.StringSlice(FlagToolSnapshotPathDelta, nil, "the paths to the delta snapshot files, applied in the given order (e.g. a delta snapshot file followed by the files of a delta snapshot chain)")
 	snapshotPathTargetFlag := fs.String(FlagToolSnapshotPathTarget, "", "the path to the target/merged snapshot file")
 	outputJSONFlag := fs.Bool(FlagToolOutputJSON, false, FlagToolDescriptionOutputJSON)
 
@@ -28,7 +28,7 @@ func snapshotMerge(args []string) error {
 			FlagToolSnapshotPathFull,
 			"snapshots/mainnet/full_snapshot.bin",
 			FlagToolSnapshotPathDelta,
-			"snapshots/mainnet/delta_snapshot.bin",
+			"snapshots/mainnet/delta_snapshot_chain/000001.bin,snapshots/mainnet/delta_snapshot_chain/000002.bin",
 			FlagToolSnapshotPathTarget,
 			"merged_snapshot.bin"))
 	}
@@ -47,7 +47,7 @@ func snapshotMerge(args []string) error {
 		return fmt.Errorf("'%s' not specified", FlagToolSnapshotPathTarget)
 	}
 
-	var fullPath, deltaPath, targetPath = *snapshotPathFullFlag, *snapshotPathDeltaFlag, *snapshotPathTargetFlag
+	var fullPath, deltaPaths, targetPath = *snapshotPathFullFlag, *snapshotPathDeltaFlag, *snapshotPathTargetFlag
 
 	if !*outputJSONFlag {
 		fmt.Println("merging snapshot files...")
@@ -55,7 +55,7 @@ func snapshotMerge(args []string) error {
 
 	ts := time.Now()
 
-	mergeInfo, err := snapshot.MergeSnapshotsFiles(fullPath, deltaPath, targetPath, nil)
+	mergeInfo, err := snapshot.MergeSnapshotsFiles(fullPath, deltaPaths, targetPath, nil)
 	if err != nil {
 		return err
 	}
@@ -65,7 +65,13 @@ func snapshotMerge(args []string) error {
 	}
 
 	_ = printSnapshotHeaderInfo("full", fullPath, mergeInfo.FullSnapshotHeader, *outputJSONFlag)
-	_ = printSnapshotHeaderInfo("delta", deltaPath, mergeInfo.DeltaSnapshotHeader, *outputJSONFlag)
+	for i, deltaPath := range deltaPaths {
+		name := "delta"
+		if len(deltaPaths) > 1 {
+			name = fmt.Sprintf("delta %d", i+1)
+		}
+		_ = printSnapshotHeaderInfo(name, deltaPath, mergeInfo.DeltaSnapshotHeaders[i], *outputJSONFlag)
+	}
 	_ = printSnapshotHeaderInfo("merged", targetPath, mergeInfo.MergedSnapshotHeader, *outputJSONFlag)
 
 	if !*outputJSONFlag {
@@ -78,6 +84,12 @@ func snapshotMerge(args []string) error {
 // prints information about the given snapshot file header.
 func printSnapshotHeaderInfo(name string, path string, header *snapshot.ReadFileHeader, outputJSON bool) error {
 
+	// the checksum only exists since the compressed snapshot format version
+	checksum := ""
+	if header.Checksum != nil {
+		checksum = iotago.EncodeHex(header.Checksum)
+	}
+
 	if outputJSON {
 
 		type treasuryStruct struct {
@@ -96,6 +108,9 @@ func printSnapshotHeaderInfo(name string, path string, header *snapshot.ReadFile
 		result := struct {
 			SnapshotName        string          `json:"snapshotName,omitempty"`
 			FilePath            string          `json:"filePath"`
+			Version             byte            `json:"version"`
+			Compression         string          `json:"compression"`
+			Checksum            string          `json:"checksum,omitempty"`
 			SnapshotTime        time.Time       `json:"snapshotTime"`
 			NetworkID           uint64          `json:"networkID"`
 			Treasury            *treasuryStruct `json:"treasury"`
@@ -107,6 +122,9 @@ func printSnapshotHeaderInfo(name string, path string, header *snapshot.ReadFile
 		}{
 			SnapshotName:        name,
 			FilePath:            path,
+			Version:             header.Version,
+			Compression:         header.Compression.String(),
+			Checksum:            checksum,
 			SnapshotTime:        time.Unix(int64(header.Timestamp), 0),
 			NetworkID:           header.NetworkID,
 			Treasury:            treasury,
@@ -128,6 +146,9 @@ func printSnapshotHeaderInfo(name string, path string, header *snapshot.ReadFile
 
 	fmt.Printf(`    >%s
         - File path:      %s
+        - Version:        %d
+        - Compression:    %s
+        - Checksum:       %s
         - Snapshot time:  %v
         - Network ID:     %d
         - Treasury:       %s
@@ -138,6 +159,14 @@ func printSnapshotHeaderInfo(name string, path string, header *snapshot.ReadFile
         - Milestone diffs count: %d`+"\n",
 		snapshotNameString,
 		path,
+		header.Version,
+		header.Compression,
+		func() string {
+			if checksum == "" {
+				return "no checksum in header"
+			}
+			return checksum
+		}(),
 		time.Unix(int64(header.Timestamp), 0),
 		header.NetworkID,
 		func() string {
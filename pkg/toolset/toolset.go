@@ -40,6 +40,8 @@ const (
 	FlagToolPublicKey  = "publicKey"
 
 	FlagToolHRP       = "hrp"
+	FlagToolAddress   = "address"
+	FlagToolOutputID  = "outputID"
 	FlagToolBIP32Path = "bip32Path"
 	FlagToolNetworkID = "networkID"
 	FlagToolPassword  = "password"
@@ -59,31 +61,40 @@ const (
 	FlagToolSnapGenTreasuryAllocation = "treasuryAllocation"
 
 	FlagToolDatabaseTargetIndex            = "targetIndex"
+	FlagToolDatabaseTargetSize             = "targetDatabaseSize"
+	FlagToolDatabaseThresholdPercentage    = "thresholdPercentage"
+	FlagToolDatabasePinnedTagPrefixes      = "pinnedTagPrefixes"
 	FlagToolDatabaseMergeNodeURL           = "nodeURL"
 	FlagToolDatabaseMergeChronicle         = "chronicleMode"
 	FlagToolDatabaseMergeChronicleKeyspace = "chronicleKeySpace"
+	FlagToolDatabaseCheckpointNodeURL      = "nodeURL"
+	FlagToolDatabaseCheckpointJWT          = "jwt"
 )
 
 const (
-	ToolPwdHash            = "pwd-hash"
-	ToolP2PIdentityGen     = "p2pidentity-gen"
-	ToolP2PExtractIdentity = "p2pidentity-extract"
-	ToolEd25519Key         = "ed25519-key"
-	ToolEd25519Addr        = "ed25519-addr"
-	ToolJWTApi             = "jwt-api"
-	ToolSnapGen            = "snap-gen"
-	ToolSnapMerge          = "snap-merge"
-	ToolSnapInfo           = "snap-info"
-	ToolSnapHash           = "snap-hash"
-	ToolBenchmarkIO        = "bench-io"
-	ToolBenchmarkCPU       = "bench-cpu"
-	ToolDatabaseLedgerHash = "db-hash"
-	ToolDatabaseHealth     = "db-health"
-	ToolDatabaseMerge      = "db-merge"
-	ToolDatabaseMigration  = "db-migration"
-	ToolDatabaseSnapshot   = "db-snapshot"
-	ToolDatabaseSplit      = "db-split"
-	ToolDatabaseVerify     = "db-verify"
+	ToolPwdHash               = "pwd-hash"
+	ToolP2PIdentityGen        = "p2pidentity-gen"
+	ToolP2PExtractIdentity    = "p2pidentity-extract"
+	ToolEd25519Key            = "ed25519-key"
+	ToolEd25519Addr           = "ed25519-addr"
+	ToolJWTApi                = "jwt-api"
+	ToolSnapGen               = "snap-gen"
+	ToolSnapMerge             = "snap-merge"
+	ToolSnapInfo              = "snap-info"
+	ToolSnapHash              = "snap-hash"
+	ToolSnapManifest          = "snap-manifest"
+	ToolBenchmarkIO           = "bench-io"
+	ToolBenchmarkCPU          = "bench-cpu"
+	ToolDatabaseCheckpoint    = "db-checkpoint"
+	ToolDatabaseLedgerHash    = "db-hash"
+	ToolDatabaseLedgerAt      = "db-ledger-at"
+	ToolDatabaseHealth        = "db-health"
+	ToolDatabaseMerge         = "db-merge"
+	ToolDatabaseMigration     = "db-migration"
+	ToolDatabasePruneEstimate = "db-prune-estimate"
+	ToolDatabaseSnapshot      = "db-snapshot"
+	ToolDatabaseSplit         = "db-split"
+	ToolDatabaseVerify        = "db-verify"
 )
 
 const (
@@ -91,6 +102,7 @@ const (
 	DefaultValueMainnetDatabasePath      = "mainnetdb"
 	DefaultValueP2PDatabasePath          = "p2pstore"
 	DefaultValueCoordinatorStateFilePath = "coordinator.state"
+	DefaultValueNodeURL                  = "http://localhost:14265"
 	DefaultValueDatabaseEngine           = database.EngineRocksDB
 )
 
@@ -123,25 +135,29 @@ func HandleTools() {
 	}
 
 	tools := map[string]func([]string) error{
-		ToolPwdHash:            hashPasswordAndSalt,
-		ToolP2PIdentityGen:     generateP2PIdentity,
-		ToolP2PExtractIdentity: extractP2PIdentity,
-		ToolEd25519Key:         generateEd25519Key,
-		ToolEd25519Addr:        generateEd25519Address,
-		ToolJWTApi:             generateJWTApiToken,
-		ToolSnapGen:            snapshotGen,
-		ToolSnapMerge:          snapshotMerge,
-		ToolSnapInfo:           snapshotInfo,
-		ToolSnapHash:           snapshotHash,
-		ToolBenchmarkIO:        benchmarkIO,
-		ToolBenchmarkCPU:       benchmarkCPU,
-		ToolDatabaseLedgerHash: databaseLedgerHash,
-		ToolDatabaseHealth:     databaseHealth,
-		ToolDatabaseMerge:      databaseMerge,
-		ToolDatabaseMigration:  databaseMigration,
-		ToolDatabaseSnapshot:   databaseSnapshot,
-		ToolDatabaseSplit:      databaseSplit,
-		ToolDatabaseVerify:     databaseVerify,
+		ToolPwdHash:               hashPasswordAndSalt,
+		ToolP2PIdentityGen:        generateP2PIdentity,
+		ToolP2PExtractIdentity:    extractP2PIdentity,
+		ToolEd25519Key:            generateEd25519Key,
+		ToolEd25519Addr:           generateEd25519Address,
+		ToolJWTApi:                generateJWTApiToken,
+		ToolSnapGen:               snapshotGen,
+		ToolSnapMerge:             snapshotMerge,
+		ToolSnapInfo:              snapshotInfo,
+		ToolSnapHash:              snapshotHash,
+		ToolSnapManifest:          snapshotManifest,
+		ToolBenchmarkIO:           benchmarkIO,
+		ToolBenchmarkCPU:          benchmarkCPU,
+		ToolDatabaseCheckpoint:    databaseCheckpoint,
+		ToolDatabaseLedgerHash:    databaseLedgerHash,
+		ToolDatabaseLedgerAt:      databaseLedgerAt,
+		ToolDatabaseHealth:        databaseHealth,
+		ToolDatabaseMerge:         databaseMerge,
+		ToolDatabaseMigration:     databaseMigration,
+		ToolDatabasePruneEstimate: databasePruneEstimate,
+		ToolDatabaseSnapshot:      databaseSnapshot,
+		ToolDatabaseSplit:         databaseSplit,
+		ToolDatabaseVerify:        databaseVerify,
 	}
 
 	tool, exists := tools[strings.ToLower(args[1])]
@@ -175,12 +191,16 @@ func listTools() {
 	fmt.Printf("%-20s merges a full and delta snapshot into an updated full snapshot\n", fmt.Sprintf("%s:", ToolSnapMerge))
 	fmt.Printf("%-20s outputs information about a snapshot file\n", fmt.Sprintf("%s:", ToolSnapInfo))
 	fmt.Printf("%-20s calculates the sha256 hash of the ledger state inside a snapshot file\n", fmt.Sprintf("%s:", ToolSnapHash))
+	fmt.Printf("%-20s creates the (signed) manifest to publish snapshot files for download\n", fmt.Sprintf("%s:", ToolSnapManifest))
 	fmt.Printf("%-20s benchmarks the IO throughput\n", fmt.Sprintf("%s:", ToolBenchmarkIO))
 	fmt.Printf("%-20s benchmarks the CPU performance\n", fmt.Sprintf("%s:", ToolBenchmarkCPU))
+	fmt.Printf("%-20s creates a checkpoint of the tangle and utxo databases of a running node\n", fmt.Sprintf("%s:", ToolDatabaseCheckpoint))
 	fmt.Printf("%-20s calculates the sha256 hash of the ledger state of a database\n", fmt.Sprintf("%s:", ToolDatabaseLedgerHash))
+	fmt.Printf("%-20s queries the ledger state, an output or an address balance at a past milestone index\n", fmt.Sprintf("%s:", ToolDatabaseLedgerAt))
 	fmt.Printf("%-20s checks the health status of the database\n", fmt.Sprintf("%s:", ToolDatabaseHealth))
 	fmt.Printf("%-20s merges missing tangle data from a database to another one\n", fmt.Sprintf("%s:", ToolDatabaseMerge))
 	fmt.Printf("%-20s migrates the database to another engine\n", fmt.Sprintf("%s:", ToolDatabaseMigration))
+	fmt.Printf("%-20s estimates the effects of pruning the database without modifying it\n", fmt.Sprintf("%s:", ToolDatabasePruneEstimate))
 	fmt.Printf("%-20s creates a full snapshot from a database\n", fmt.Sprintf("%s:", ToolDatabaseSnapshot))
 	fmt.Printf("%-20s split a legacy database into `tangle` and `utxo`\n", fmt.Sprintf("%s:", ToolDatabaseSplit))
 	fmt.Printf("%-20s verifies a valid ledger state and the existence of all messages`\n", fmt.Sprintf("%s:", ToolDatabaseVerify))
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ParseDuration parses a duration string like time.ParseDuration,
+// but additionally supports a leading number of days (e.g. "30d" or "1d12h").
+func ParseDuration(s string) (time.Duration, error) {
+
+	dayIndex := strings.IndexByte(s, 'd')
+	if dayIndex == -1 {
+		return time.ParseDuration(s)
+	}
+
+	days, err := strconv.ParseUint(s[:dayIndex], 10, 32)
+	if err != nil {
+		return 0, errors.Errorf("invalid duration %q", s)
+	}
+
+	duration := time.Duration(days) * 24 * time.Hour
+	if rest := s[dayIndex+1:]; rest != "" {
+		restDuration, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+		if restDuration < 0 {
+			return 0, errors.Errorf("invalid duration %q", s)
+		}
+		duration += restDuration
+	}
+
+	return duration, nil
+}
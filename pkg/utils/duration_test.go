@@ -0,0 +1,29 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gohornet/hornet/pkg/utils"
+)
+
+func TestParseDuration(t *testing.T) {
+
+	for s, expected := range map[string]time.Duration{
+		"30d":   30 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"0d":    0,
+		"90m":   90 * time.Minute,
+	} {
+		duration, err := utils.ParseDuration(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, duration, s)
+	}
+
+	for _, s := range []string{"", "d", "-1d", "1.5d", "1d-2h", "1dx", "abc"} {
+		_, err := utils.ParseDuration(s)
+		assert.Error(t, err, s)
+	}
+}
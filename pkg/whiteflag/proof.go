@@ -0,0 +1,88 @@
+package whiteflag
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/dag"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+var (
+	// ErrMessageNotReferencedByMilestone is returned when the message of a proof was not referenced by the milestone.
+	ErrMessageNotReferencedByMilestone = errors.New("message not referenced by milestone")
+)
+
+// ReferencedMessagesOfMilestone returns the IDs of the messages that were referenced by an already confirmed milestone,
+// in the order in which they were applied by ComputeWhiteFlagMutations.
+// The past cone of the milestone must not be pruned.
+func ReferencedMessagesOfMilestone(ctx context.Context, parentsTraverserStorage dag.ParentsTraverserStorage, msIndex milestone.Index, parents hornet.MessageIDs) (hornet.MessageIDs, error) {
+
+	messagesReferenced := make(hornet.MessageIDs, 0)
+
+	// the same messages are traversed in the same order as during the confirmation,
+	// because exactly the messages that were not referenced at that time were referenced by this milestone.
+	condition := func(cachedMsgMeta *storage.CachedMetadata) (bool, error) { // meta +1
+		defer cachedMsgMeta.Release(true) // meta -1
+
+		referenced, at := cachedMsgMeta.Metadata().ReferencedWithIndex()
+		return referenced && at == msIndex, nil
+	}
+
+	consumer := func(cachedMsgMeta *storage.CachedMetadata) error { // meta +1
+		defer cachedMsgMeta.Release(true) // meta -1
+
+		messagesReferenced = append(messagesReferenced, cachedMsgMeta.Metadata().MessageID())
+		return nil
+	}
+
+	onMissingParent := func(parentMessageID hornet.MessageID) error {
+		return fmt.Errorf("%w: message %s", common.ErrMessageNotFound, parentMessageID.ToHex())
+	}
+
+	if err := dag.TraverseParents(ctx, parentsTraverserStorage, parents, condition, consumer, onMissingParent, nil, false); err != nil {
+		return nil, err
+	}
+
+	return messagesReferenced, nil
+}
+
+// ConfirmedMerkleAuditPath computes the audit path of the given message in the merkle tree of all messages referenced by the milestone.
+// The root of the tree is the ConfirmedMerkleRoot of the milestone.
+func ConfirmedMerkleAuditPath(messagesReferenced hornet.MessageIDs, messageID hornet.MessageID) ([]*MerkleAuditPathNode, error) {
+
+	index := -1
+	marshalers := make([]encoding.BinaryMarshaler, len(messagesReferenced))
+	for i := range messagesReferenced {
+		marshalers[i] = messagesReferenced[i]
+		if index == -1 && bytes.Equal(messagesReferenced[i], messageID) {
+			index = i
+		}
+	}
+
+	if index == -1 {
+		return nil, ErrMessageNotReferencedByMilestone
+	}
+
+	return NewHasher(crypto.BLAKE2b_256).AuditPath(marshalers, index)
+}
+
+// VerifyConfirmedMerkleAuditPath checks whether the given audit path proves that the message was referenced by the milestone.
+func VerifyConfirmedMerkleAuditPath(ms *iotago.Milestone, messageID hornet.MessageID, path []*MerkleAuditPathNode) (bool, error) {
+
+	root, err := NewHasher(crypto.BLAKE2b_256).RootFromAuditPath(messageID, path)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(ms.ConfirmedMerkleRoot[:], root), nil
+}
@@ -0,0 +1,54 @@
+package whiteflag
+
+import (
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// TransactionSimulation is the result of the white-flag checks of a transaction against the ledger state.
+type TransactionSimulation struct {
+	// The conflict the transaction would be marked with if it was referenced by the next milestone.
+	Conflict storage.Conflict
+	// The error that caused the conflict.
+	ConflictErr error
+}
+
+// SimulateTransaction applies the white-flag checks to the given transaction against the current ledger state,
+// as if it was referenced by a milestone with the given index and timestamp, without applying any mutations.
+// Only UTXO inputs are considered, other inputs are reported by the semantic validation.
+// The ledger state must be read locked while this function is getting called in order to ensure consistency.
+func SimulateTransaction(utxoManager *utxo.Manager,
+	networkId uint64,
+	msIndex milestone.Index,
+	msTimestamp uint32,
+	transaction *iotago.Transaction) (*TransactionSimulation, error) {
+
+	semValCtx := &iotago.SemanticValidationContext{
+		ExtParas: &iotago.ExternalUnlockParameters{
+			ConfMsIndex: uint32(msIndex),
+			ConfUnix:    msTimestamp,
+		},
+	}
+
+	var inputs []*iotago.OutputID
+	if essence := transaction.Essence; essence != nil {
+		for _, input := range essence.Inputs {
+			if utxoInput, ok := input.(*iotago.UTXOInput); ok {
+				id := utxoInput.ID()
+				inputs = append(inputs, &id)
+			}
+		}
+	}
+
+	_, conflict, conflictErr, err := checkTransaction(utxoManager, map[string]*utxo.Spent{}, map[string]*utxo.Output{}, networkId, semValCtx, transaction, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransactionSimulation{
+		Conflict:    conflict,
+		ConflictErr: conflictErr,
+	}, nil
+}
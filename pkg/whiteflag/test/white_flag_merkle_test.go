@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/utxo/utils"
 	"github.com/gohornet/hornet/pkg/whiteflag"
 	iotago "github.com/iotaledger/iota.go/v3"
 
@@ -45,3 +46,36 @@ func TestWhiteFlagMerkleTreeHash(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, bytes.Equal(hash, expectedHash))
 }
+
+func TestWhiteFlagMerkleAuditPath(t *testing.T) {
+
+	hasher := whiteflag.NewHasher(crypto.BLAKE2b_256)
+
+	var includedMessages []encoding.BinaryMarshaler
+	for i := 0; i < 13; i++ {
+		includedMessages = append(includedMessages, utils.RandMessageID())
+
+		root, err := hasher.Hash(includedMessages)
+		require.NoError(t, err)
+
+		for index, msg := range includedMessages {
+			path, err := hasher.AuditPath(includedMessages, index)
+			require.NoError(t, err)
+
+			proofRoot, err := hasher.RootFromAuditPath(msg, path)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(root, proofRoot))
+		}
+
+		// a message that is not part of the tree must not be verifiable with an existing path
+		path, err := hasher.AuditPath(includedMessages, 0)
+		require.NoError(t, err)
+
+		proofRoot, err := hasher.RootFromAuditPath(utils.RandMessageID(), path)
+		require.NoError(t, err)
+		require.False(t, bytes.Equal(root, proofRoot))
+	}
+
+	_, err := hasher.AuditPath(includedMessages, len(includedMessages))
+	require.Error(t, err)
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func TestWhiteFlagConfirmedMerkleAuditPath(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+	seed2Wallet := utils.NewHDWallet("Seed2", seed2, 0)
+
+	genesisAddress := seed1Wallet.Address()
+
+	te := testsuite.SetupTestEnvironment(t, genesisAddress, 2, BelowMaxDepth, MinPoWScore, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	//Add token supply to our local HDWallet
+	seed1Wallet.BookOutput(te.GenesisOutput)
+	te.AssertWalletBalance(seed1Wallet, iotago.TokenSupply)
+
+	messageA := te.NewMessageBuilder("A").
+		Parents(hornet.MessageIDs{te.Milestones[0].Milestone().MessageID, te.Milestones[1].Milestone().MessageID}).
+		FromWallet(seed1Wallet).
+		ToWallet(seed2Wallet).
+		Amount(1_000_000).
+		Build().
+		Store().
+		BookOnWallets()
+
+	messageB := te.NewMessageBuilder("B").
+		Parents(hornet.MessageIDs{te.Milestones[1].Milestone().MessageID}).
+		BuildTaggedData().
+		Store()
+
+	messageC := te.NewMessageBuilder("C").
+		Parents(hornet.MessageIDs{messageA.StoredMessageID(), messageB.StoredMessageID()}).
+		BuildTaggedData().
+		Store()
+
+	conf, confStats := te.IssueAndConfirmMilestoneOnTips(hornet.MessageIDs{messageC.StoredMessageID()}, false)
+	require.Equal(t, 3+1, confStats.MessagesReferenced) // 3 + previous milestone
+
+	cachedMilestoneMsg := te.Storage().MilestoneCachedMessageOrNil(conf.MilestoneIndex) // message +1
+	require.NotNil(t, cachedMilestoneMsg)
+	defer cachedMilestoneMsg.Release(true) // message -1
+
+	ms := cachedMilestoneMsg.Message().Milestone()
+	require.NotNil(t, ms)
+
+	// the referenced messages are collected in the same order as during the confirmation
+	messagesReferenced, err := whiteflag.ReferencedMessagesOfMilestone(context.Background(), te.Storage(), conf.MilestoneIndex, cachedMilestoneMsg.Message().Parents())
+	require.NoError(t, err)
+	require.Equal(t, conf.Mutations.MessagesReferenced, messagesReferenced)
+
+	// every referenced message can be proven against the confirmed merkle root of the milestone
+	for _, messageID := range messagesReferenced {
+		path, err := whiteflag.ConfirmedMerkleAuditPath(messagesReferenced, messageID)
+		require.NoError(t, err)
+
+		valid, err := whiteflag.VerifyConfirmedMerkleAuditPath(ms, messageID, path)
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+
+	// the audit path of a message does not prove the inclusion of another message
+	path, err := whiteflag.ConfirmedMerkleAuditPath(messagesReferenced, messageA.StoredMessageID())
+	require.NoError(t, err)
+
+	valid, err := whiteflag.VerifyConfirmedMerkleAuditPath(ms, messageB.StoredMessageID(), path)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// messages that were not referenced by the milestone have no audit path
+	_, err = whiteflag.ConfirmedMerkleAuditPath(messagesReferenced, te.Milestones[0].Milestone().MessageID)
+	require.ErrorIs(t, err, whiteflag.ErrMessageNotReferencedByMilestone)
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/testsuite"
+	"github.com/gohornet/hornet/pkg/testsuite/utils"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func TestWhiteFlagSimulateTransaction(t *testing.T) {
+
+	seed1Wallet := utils.NewHDWallet("Seed1", seed1, 0)
+	seed2Wallet := utils.NewHDWallet("Seed2", seed2, 0)
+
+	genesisAddress := seed1Wallet.Address()
+
+	te := testsuite.SetupTestEnvironment(t, genesisAddress, 2, BelowMaxDepth, MinPoWScore, showConfirmationGraphs)
+	defer te.CleanupTestEnvironment(!showConfirmationGraphs)
+
+	//Add token supply to our local HDWallet
+	seed1Wallet.BookOutput(te.GenesisOutput)
+	te.AssertWalletBalance(seed1Wallet, iotago.TokenSupply)
+
+	simulate := func(msg *testsuite.Message) *whiteflag.TransactionSimulation {
+		te.UTXOManager().ReadLockLedger()
+		defer te.UTXOManager().ReadUnlockLedger()
+
+		transaction, ok := msg.IotaMessage().Payload.(*iotago.Transaction)
+		require.True(t, ok)
+
+		simulation, err := whiteflag.SimulateTransaction(te.UTXOManager(), te.NetworkID(), te.LastMilestoneIndex()+1, 0, transaction)
+		require.NoError(t, err)
+		return simulation
+	}
+
+	messageA := te.NewMessageBuilder("A").
+		Parents(hornet.MessageIDs{te.Milestones[0].Milestone().MessageID, te.Milestones[1].Milestone().MessageID}).
+		FromWallet(seed1Wallet).
+		ToWallet(seed2Wallet).
+		Amount(iotago.TokenSupply).
+		Build()
+
+	// the transaction is valid against the current ledger state
+	simulation := simulate(messageA)
+	require.EqualValues(t, storage.ConflictNone, simulation.Conflict)
+	require.NoError(t, simulation.ConflictErr)
+
+	// the simulation must not mutate the ledger
+	te.AssertLedgerBalance(seed1Wallet, iotago.TokenSupply)
+
+	// a transaction with unknown inputs conflicts
+	messageB := te.NewMessageBuilder("B").
+		Parents(hornet.MessageIDs{te.Milestones[0].Milestone().MessageID, te.Milestones[1].Milestone().MessageID}).
+		FromWallet(seed1Wallet).
+		ToWallet(seed2Wallet).
+		Amount(iotago.TokenSupply).
+		FakeInputs().
+		Build()
+
+	simulation = simulate(messageB)
+	require.EqualValues(t, storage.ConflictInputUTXONotFound, simulation.Conflict)
+	require.Error(t, simulation.ConflictErr)
+
+	// confirm the transaction
+	messageA.Store().BookOnWallets()
+	_, confStats := te.IssueAndConfirmMilestoneOnTips(hornet.MessageIDs{messageA.StoredMessageID()}, true)
+	require.Equal(t, 1, confStats.MessagesIncludedWithTransactions)
+
+	// the inputs are spent now, so the same transaction conflicts
+	simulation = simulate(messageA)
+	require.EqualValues(t, storage.ConflictInputUTXOAlreadySpent, simulation.Conflict)
+	require.Error(t, simulation.ConflictErr)
+}
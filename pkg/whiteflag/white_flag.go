@@ -166,80 +166,15 @@ func ComputeWhiteFlagMutations(ctx context.Context,
 			return nil
 		}
 
-		var conflict = storage.ConflictNone
-
 		transaction := message.Transaction()
 		transactionID, err := transaction.ID()
 		if err != nil {
 			return err
 		}
 
-		if transaction.Essence.NetworkID != networkId {
-			conflict = storage.ConflictInvalidNetworkID
-		}
-
-		// go through all the inputs and validate that they are still unspent, in the ledger or were created during confirmation
-		inputOutputs := utxo.Outputs{}
-		if conflict == storage.ConflictNone {
-			inputs := message.TransactionEssenceUTXOInputs()
-			for _, input := range inputs {
-
-				// check if this input was already spent during the confirmation
-				_, hasSpent := wfConf.NewSpents[string(input[:])]
-				if hasSpent {
-					// UTXO already spent, so mark as conflict
-					conflict = storage.ConflictInputUTXOAlreadySpentInThisMilestone
-					break
-				}
-
-				// check if this input was newly created during the confirmation
-				output, hasOutput := wfConf.NewOutputs[string(input[:])]
-				if hasOutput {
-					// UTXO is in the current ledger mutation, so use it
-					inputOutputs = append(inputOutputs, output)
-					continue
-				}
-
-				// check current ledger for this input
-				output, err = utxoManager.ReadOutputByOutputIDWithoutLocking(input)
-				if err != nil {
-					if errors.Is(err, kvstore.ErrKeyNotFound) {
-						// input not found, so mark as invalid tx
-						conflict = storage.ConflictInputUTXONotFound
-						break
-					}
-					return err
-				}
-
-				// check if this output is unspent
-				unspent, err := utxoManager.IsOutputUnspentWithoutLocking(output)
-				if err != nil {
-					return err
-				}
-
-				if !unspent {
-					// output is already spent, so mark as conflict
-					conflict = storage.ConflictInputUTXOAlreadySpent
-					break
-				}
-
-				inputOutputs = append(inputOutputs, output)
-			}
-
-			if conflict == storage.ConflictNone {
-				// Verify that all outputs consume all inputs and have valid signatures. Also verify that the amounts match.
-				if err := transaction.SemanticallyValidate(semValCtx, inputOutputs.ToOutputSet()); err != nil {
-					if errors.Is(err, iotago.ErrMissingUTXO) {
-						conflict = storage.ConflictInputUTXONotFound
-					} else if errors.Is(err, iotago.ErrInputOutputSumMismatch) {
-						conflict = storage.ConflictInputOutputSumMismatch
-					} else if errors.Is(err, iotago.ErrEd25519SignatureInvalid) || errors.Is(err, iotago.ErrEd25519PubKeyAndAddrMismatch) {
-						conflict = storage.ConflictInvalidSignature
-					} else {
-						conflict = storage.ConflictSemanticValidationFailed
-					}
-				}
-			}
+		inputOutputs, conflict, _, err := checkTransaction(utxoManager, wfConf.NewSpents, wfConf.NewOutputs, networkId, semValCtx, transaction, message.TransactionEssenceUTXOInputs())
+		if err != nil {
+			return err
 		}
 
 		// go through all deposits and generate unspent outputs
@@ -341,3 +276,78 @@ func ComputeWhiteFlagMutations(ctx context.Context,
 
 	return wfConf, nil
 }
+
+// checkTransaction validates the given transaction against the ledger state and the mutations of the current confirmation.
+// It checks that the inputs are still unspent, in the ledger or were created during the confirmation,
+// and semantically validates the transaction against the consumed outputs.
+// It returns the consumed outputs, the conflict and the error that caused the conflict.
+// The returned err is only set if the validation could not be performed.
+func checkTransaction(utxoManager *utxo.Manager,
+	newSpents map[string]*utxo.Spent,
+	newOutputs map[string]*utxo.Output,
+	networkId uint64,
+	semValCtx *iotago.SemanticValidationContext,
+	transaction *iotago.Transaction,
+	inputs []*iotago.OutputID) (inputOutputs utxo.Outputs, conflict storage.Conflict, conflictErr error, err error) {
+
+	if transaction.Essence.NetworkID != networkId {
+		return nil, storage.ConflictInvalidNetworkID, fmt.Errorf("invalid network ID: %d", transaction.Essence.NetworkID), nil
+	}
+
+	// go through all the inputs and validate that they are still unspent, in the ledger or were created during confirmation
+	inputOutputs = utxo.Outputs{}
+	for _, input := range inputs {
+
+		// check if this input was already spent during the confirmation
+		_, hasSpent := newSpents[string(input[:])]
+		if hasSpent {
+			// UTXO already spent, so mark as conflict
+			return nil, storage.ConflictInputUTXOAlreadySpentInThisMilestone, fmt.Errorf("input already spent in this milestone: %s", input.ToHex()), nil
+		}
+
+		// check if this input was newly created during the confirmation
+		output, hasOutput := newOutputs[string(input[:])]
+		if hasOutput {
+			// UTXO is in the current ledger mutation, so use it
+			inputOutputs = append(inputOutputs, output)
+			continue
+		}
+
+		// check current ledger for this input
+		output, err = utxoManager.ReadOutputByOutputIDWithoutLocking(input)
+		if err != nil {
+			if errors.Is(err, kvstore.ErrKeyNotFound) {
+				// input not found, so mark as invalid tx
+				return nil, storage.ConflictInputUTXONotFound, fmt.Errorf("input not found: %s", input.ToHex()), nil
+			}
+			return nil, storage.ConflictNone, nil, err
+		}
+
+		// check if this output is unspent
+		unspent, err := utxoManager.IsOutputUnspentWithoutLocking(output)
+		if err != nil {
+			return nil, storage.ConflictNone, nil, err
+		}
+
+		if !unspent {
+			// output is already spent, so mark as conflict
+			return nil, storage.ConflictInputUTXOAlreadySpent, fmt.Errorf("input already spent: %s", input.ToHex()), nil
+		}
+
+		inputOutputs = append(inputOutputs, output)
+	}
+
+	// Verify that all outputs consume all inputs and have valid signatures. Also verify that the amounts match.
+	if err := transaction.SemanticallyValidate(semValCtx, inputOutputs.ToOutputSet()); err != nil {
+		if errors.Is(err, iotago.ErrMissingUTXO) {
+			return nil, storage.ConflictInputUTXONotFound, err, nil
+		} else if errors.Is(err, iotago.ErrInputOutputSumMismatch) {
+			return nil, storage.ConflictInputOutputSumMismatch, err, nil
+		} else if errors.Is(err, iotago.ErrEd25519SignatureInvalid) || errors.Is(err, iotago.ErrEd25519PubKeyAndAddrMismatch) {
+			return nil, storage.ConflictInvalidSignature, err, nil
+		}
+		return nil, storage.ConflictSemanticValidationFailed, err, nil
+	}
+
+	return inputOutputs, storage.ConflictNone, nil, nil
+}
@@ -3,6 +3,7 @@ package whiteflag
 import (
 	"crypto"
 	"encoding"
+	"fmt"
 	"math/bits"
 )
 
@@ -82,3 +83,66 @@ func largestPowerOfTwo(x int) int {
 	}
 	return 1 << (bits.Len(uint(x-1)) - 1)
 }
+
+// MerkleAuditPathNode is the hash of a sibling node on the path from a leaf to the root of a Merkle tree.
+type MerkleAuditPathNode struct {
+	// Hash of the sibling node.
+	Hash []byte
+	// Left is true if the sibling node is the left child of their common parent.
+	Left bool
+}
+
+// AuditPath computes the Merkle audit path of the leaf at the given index.
+// The nodes of the path are ordered from the leaf to the root.
+func (t *Hasher) AuditPath(data []encoding.BinaryMarshaler, index int) ([]*MerkleAuditPathNode, error) {
+	if index < 0 || index >= len(data) {
+		return nil, fmt.Errorf("index %d out of range, tree size: %d", index, len(data))
+	}
+	if len(data) == 1 {
+		return []*MerkleAuditPathNode{}, nil
+	}
+
+	k := largestPowerOfTwo(len(data))
+	if index < k {
+		path, err := t.AuditPath(data[:k], index)
+		if err != nil {
+			return nil, err
+		}
+		r, err := t.Hash(data[k:])
+		if err != nil {
+			return nil, err
+		}
+		return append(path, &MerkleAuditPathNode{Hash: r, Left: false}), nil
+	}
+
+	path, err := t.AuditPath(data[k:], index-k)
+	if err != nil {
+		return nil, err
+	}
+	l, err := t.Hash(data[:k])
+	if err != nil {
+		return nil, err
+	}
+	return append(path, &MerkleAuditPathNode{Hash: l, Left: true}), nil
+}
+
+// RootFromAuditPath computes the Merkle tree root hash of the given leaf and its audit path.
+// The leaf is part of the tree if the result is equal to the known root hash.
+func (t *Hasher) RootFromAuditPath(leaf encoding.BinaryMarshaler, path []*MerkleAuditPathNode) ([]byte, error) {
+	hash, err := t.hashLeaf(leaf)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range path {
+		if len(node.Hash) != t.Size() {
+			return nil, fmt.Errorf("invalid audit path node hash length: %d", len(node.Hash))
+		}
+		if node.Left {
+			hash = t.hashNode(node.Hash, hash)
+			continue
+		}
+		hash = t.hashNode(hash, node.Hash)
+	}
+	return hash, nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/gohornet/hornet/pkg/protocol/gossip"
 	restapipkg "github.com/gohornet/hornet/pkg/restapi"
 	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/snapshot"
 	"github.com/gohornet/hornet/pkg/tangle"
 	"github.com/gohornet/hornet/pkg/tipselect"
 	"github.com/gohornet/hornet/plugins/restapi"
@@ -82,6 +83,7 @@ type dependencies struct {
 	UTXODatabase             *database.Database `name:"utxoDatabase"`
 	Storage                  *storage.Storage
 	SyncManager              *syncmanager.SyncManager
+	SnapshotManager          *snapshot.SnapshotManager
 	Tangle                   *tangle.Tangle
 	ServerMetrics            *metrics.ServerMetrics
 	RequestQueue             gossip.RequestQueue
@@ -238,6 +240,8 @@ func run() {
 
 	// run the database size collector
 	runDatabaseSizeCollector()
+	// run the pruning event feed
+	runPruningEventFeed()
 	// run the spammer feed
 	runSpammerMetricWorker()
 }
@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/iotaledger/hive.go/events"
+)
+
+var (
+	lastPruningEvent = &PruningEvent{}
+)
+
+// PruningEvent represents a started database pruning run.
+type PruningEvent struct {
+	Policy      string
+	TargetIndex milestone.Index
+	Time        time.Time
+}
+
+func (p *PruningEvent) MarshalJSON() ([]byte, error) {
+	event := struct {
+		Policy      string          `json:"policy"`
+		TargetIndex milestone.Index `json:"target_index"`
+		Time        int64           `json:"ts"`
+	}{
+		Policy:      p.Policy,
+		TargetIndex: p.TargetIndex,
+	}
+
+	if !p.Time.IsZero() {
+		event.Time = p.Time.Unix()
+	}
+
+	return json.Marshal(event)
+}
+
+func runPruningEventFeed() {
+
+	onPruningTriggered := events.NewClosure(func(trigger *snapshot.PruningTrigger) {
+		lastPruningEvent = &PruningEvent{
+			Policy:      trigger.Policy.String(),
+			TargetIndex: trigger.TargetIndex,
+			Time:        time.Now(),
+		}
+		hub.BroadcastMsg(&Msg{Type: MsgTypePruningEvent, Data: lastPruningEvent})
+	})
+
+	if err := Plugin.Daemon().BackgroundWorker("Dashboard[Pruning]", func(ctx context.Context) {
+		deps.SnapshotManager.Events.PruningTriggered.Attach(onPruningTriggered)
+		<-ctx.Done()
+		deps.SnapshotManager.Events.PruningTriggered.Detach(onPruningTriggered)
+	}, shutdown.PriorityDashboard); err != nil {
+		Plugin.LogPanicf("failed to start worker: %s", err)
+	}
+}
@@ -43,6 +43,8 @@ const (
 	MsgTypeSpamMetrics = 15
 	// MsgTypeAvgSpamMetrics is the type of the AvgSpamMetric message.
 	MsgTypeAvgSpamMetrics = 16
+	// MsgTypePruningEvent is the type of the database pruning message for the metrics.
+	MsgTypePruningEvent = 17
 )
 
 func websocketRoute(ctx echo.Context) error {
@@ -100,6 +102,9 @@ func websocketRoute(ctx echo.Context) error {
 		case MsgTypeDatabaseCleanupEvent:
 			client.Send(&Msg{Type: MsgTypeDatabaseCleanupEvent, Data: lastDBCleanup})
 
+		case MsgTypePruningEvent:
+			client.Send(&Msg{Type: MsgTypePruningEvent, Data: lastPruningEvent})
+
 		case MsgTypeMs:
 			start := deps.SyncManager.LatestMilestoneIndex()
 			for i := start - 10; i <= start; i++ {
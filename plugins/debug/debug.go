@@ -22,8 +22,16 @@ func outputsIDs(c echo.Context) (*outputIDsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	outputIDs := []string{}
 	appendConsumerFunc := func(output *utxo.Output) bool {
+		if !paginator.Add(output.OutputID()[:]) {
+			return false
+		}
 		outputIDs = append(outputIDs, output.OutputID().ToHex())
 		return true
 	}
@@ -39,13 +47,14 @@ func outputsIDs(c echo.Context) (*outputIDsResponse, error) {
 		}
 	}
 
-	err = deps.UTXOManager.ForEachOutput(outputConsumerFunc, utxo.ReadLockLedger(false))
+	err = deps.UTXOManager.ForEachOutput(outputConsumerFunc, utxo.ReadLockLedger(false), utxo.IterateFromCursor(paginator.Cursor))
 	if err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading unspent outputs failed, error: %s", err)
 	}
 
 	return &outputIDsResponse{
-		OutputIDs: outputIDs,
+		PaginationResponse: paginator.Response(),
+		OutputIDs:          outputIDs,
 	}, nil
 }
 
@@ -55,8 +64,16 @@ func unspentOutputsIDs(c echo.Context) (*outputIDsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	outputIDs := []string{}
 	appendConsumerFunc := func(output *utxo.Output) bool {
+		if !paginator.Add(output.OutputID()[:]) {
+			return false
+		}
 		outputIDs = append(outputIDs, output.OutputID().ToHex())
 		return true
 	}
@@ -72,13 +89,14 @@ func unspentOutputsIDs(c echo.Context) (*outputIDsResponse, error) {
 		}
 	}
 
-	err = deps.UTXOManager.ForEachUnspentOutput(outputConsumerFunc, utxo.ReadLockLedger(false))
+	err = deps.UTXOManager.ForEachUnspentOutput(outputConsumerFunc, utxo.ReadLockLedger(false), utxo.IterateFromCursor(paginator.Cursor))
 	if err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading unspent outputs failed, error: %s", err)
 	}
 
 	return &outputIDsResponse{
-		OutputIDs: outputIDs,
+		PaginationResponse: paginator.Response(),
+		OutputIDs:          outputIDs,
 	}, nil
 }
 
@@ -88,8 +106,16 @@ func spentOutputsIDs(c echo.Context) (*outputIDsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	outputIDs := []string{}
 	appendConsumerFunc := func(spent *utxo.Spent) bool {
+		if !paginator.Add(spent.OutputID()[:]) {
+			return false
+		}
 		outputIDs = append(outputIDs, spent.OutputID().ToHex())
 		return true
 	}
@@ -105,13 +131,14 @@ func spentOutputsIDs(c echo.Context) (*outputIDsResponse, error) {
 		}
 	}
 
-	err = deps.UTXOManager.ForEachSpentOutput(spentConsumerFunc, utxo.ReadLockLedger(false))
+	err = deps.UTXOManager.ForEachSpentOutput(spentConsumerFunc, utxo.ReadLockLedger(false), utxo.IterateFromCursor(paginator.Cursor))
 	if err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading spent outputs failed, error: %s", err)
 	}
 
 	return &outputIDsResponse{
-		OutputIDs: outputIDs,
+		PaginationResponse: paginator.Response(),
+		OutputIDs:          outputIDs,
 	}, nil
 }
 
@@ -23,15 +23,15 @@ const (
 	RouteDebugSolidifier = "/solidifier"
 
 	// RouteDebugOutputs is the debug route for getting all output IDs.
-	// GET returns the outputIDs for all outputs.
+	// GET returns the outputIDs for all outputs (paginated).
 	RouteDebugOutputs = "/outputs"
 
 	// RouteDebugOutputsUnspent is the debug route for getting all unspent output IDs.
-	// GET returns the outputIDs for all unspent outputs.
+	// GET returns the outputIDs for all unspent outputs (paginated).
 	RouteDebugOutputsUnspent = "/outputs/unspent"
 
 	// RouteDebugOutputsSpent is the debug route for getting all spent output IDs.
-	// GET returns the outputIDs for all spent outputs.
+	// GET returns the outputIDs for all spent outputs (paginated).
 	RouteDebugOutputsSpent = "/outputs/spent"
 
 	// RouteDebugMilestoneDiffs is the debug route for getting a milestone diff by it's milestoneIndex.
@@ -66,14 +66,15 @@ var (
 
 type dependencies struct {
 	dig.In
-	Storage           *storage.Storage
-	SyncManager       *syncmanager.SyncManager
-	Tangle            *tangle.Tangle
-	RequestQueue      gossip.RequestQueue
-	UTXOManager       *utxo.Manager
-	NodeConfig        *configuration.Configuration `name:"nodeConfig"`
-	NetworkID         uint64                       `name:"networkId"`
-	RestPluginManager *restapi.RestPluginManager   `optional:"true"`
+	Storage                 *storage.Storage
+	SyncManager             *syncmanager.SyncManager
+	Tangle                  *tangle.Tangle
+	RequestQueue            gossip.RequestQueue
+	UTXOManager             *utxo.Manager
+	NodeConfig              *configuration.Configuration `name:"nodeConfig"`
+	NetworkID               uint64                       `name:"networkId"`
+	RestPluginManager       *restapi.RestPluginManager   `optional:"true"`
+	RestAPILimitsMaxResults int                          `name:"restAPILimitsMaxResults" optional:"true"`
 }
 
 func configure() {
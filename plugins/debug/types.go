@@ -2,11 +2,13 @@ package debug
 
 import (
 	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/restapi"
 	restapiv2 "github.com/gohornet/hornet/plugins/restapi/v2"
 )
 
 // outputIDsResponse defines the response of a GET debug outputs REST API call.
 type outputIDsResponse struct {
+	restapi.PaginationResponse
 	// The output IDs (transaction hash + output index) of the outputs.
 	OutputIDs []string `json:"outputIds"`
 }
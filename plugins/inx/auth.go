@@ -0,0 +1,204 @@
+package inx
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+	inx "github.com/iotaledger/inx/go"
+)
+
+const (
+	// ScopeRead allows an extension to read the node status, the tangle and the ledger, and to perform GET requests on the REST API.
+	ScopeRead = "read"
+	// ScopeSubmit allows an extension to submit messages and to perform non GET requests on the REST API.
+	ScopeSubmit = "submit"
+	// ScopeRegisterRoutes allows an extension to register and unregister routes on the REST API of the node.
+	ScopeRegisterRoutes = "register-routes"
+
+	// the metadata key that contains the token of the extension.
+	authorizationMetadataKey = "authorization"
+	// the prefix of the token in the authorization metadata.
+	authorizationBearerPrefix = "Bearer "
+)
+
+var (
+	// ErrUnknownScope is returned if an unknown scope is configured for an extension token.
+	ErrUnknownScope = errors.New("unknown scope")
+
+	// methodScopes contains the scope that is needed to call a method of the INX service.
+	// methods that are not contained in the map can't be called if the authentication is enabled.
+	methodScopes = map[string]string{
+		"ReadNodeStatus":             ScopeRead,
+		"ReadProtocolParameters":     ScopeRead,
+		"ReadMilestone":              ScopeRead,
+		"ListenToLatestMilestone":    ScopeRead,
+		"ListenToConfirmedMilestone": ScopeRead,
+		"ComputeWhiteFlag":           ScopeRead,
+		"ListenToMessages":           ScopeRead,
+		"ListenToSolidMessages":      ScopeRead,
+		"ListenToReferencedMessages": ScopeRead,
+		"SubmitMessage":              ScopeSubmit,
+		"ReadMessage":                ScopeRead,
+		"ReadMessageMetadata":        ScopeRead,
+		"ReadUnspentOutputs":         ScopeRead,
+		"ListenToLedgerUpdates":      ScopeRead,
+		"ListenToTreasuryUpdates":    ScopeRead,
+		"ReadOutput":                 ScopeRead,
+		"ListenToMigrationReceipts":  ScopeRead,
+		"RegisterAPIRoute":           ScopeRegisterRoutes,
+		"UnregisterAPIRoute":         ScopeRegisterRoutes,
+		// the scope of PerformAPIRequest depends on the HTTP method of the request.
+		"PerformAPIRequest": ScopeRead,
+	}
+)
+
+// ExtensionToken is the token of an extension and the scopes it is allowed to use.
+type ExtensionToken struct {
+	// Name of the extension, used in logs and metrics.
+	Name string `json:"name"`
+	// Token the extension needs to send in the "authorization" metadata as "Bearer <token>".
+	Token string `json:"token"`
+	// Scopes of the extension.
+	Scopes []string `json:"scopes"`
+}
+
+// extensionAuth authenticates and authorizes the requests of INX extensions.
+type extensionAuth struct {
+	tokens  []*ExtensionToken
+	metrics *metrics.INXMetrics
+}
+
+func newExtensionAuth(tokens []*ExtensionToken, inxMetrics *metrics.INXMetrics) (*extensionAuth, error) {
+	for _, token := range tokens {
+		if token.Name == "" {
+			return nil, errors.New("extension token without name")
+		}
+		if token.Token == "" {
+			return nil, fmt.Errorf("extension token of \"%s\" is empty", token.Name)
+		}
+		for _, scope := range token.Scopes {
+			switch scope {
+			case ScopeRead, ScopeSubmit, ScopeRegisterRoutes:
+			default:
+				return nil, errors.WithMessagef(ErrUnknownScope, "extension: %s, scope: %s", token.Name, scope)
+			}
+		}
+	}
+
+	return &extensionAuth{
+		tokens:  tokens,
+		metrics: inxMetrics,
+	}, nil
+}
+
+// extensionToken returns the configured token that was sent in the metadata of the request.
+func (a *extensionAuth) extensionToken(ctx context.Context) *ExtensionToken {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	for _, value := range md.Get(authorizationMetadataKey) {
+		if !strings.HasPrefix(value, authorizationBearerPrefix) {
+			continue
+		}
+		sentToken := []byte(strings.TrimPrefix(value, authorizationBearerPrefix))
+
+		for _, token := range a.tokens {
+			if subtle.ConstantTimeCompare(sentToken, []byte(token.Token)) == 1 {
+				return token
+			}
+		}
+	}
+	return nil
+}
+
+// authorize checks whether the request is allowed to call the given method.
+// the request is nil for streaming calls.
+func (a *extensionAuth) authorize(ctx context.Context, fullMethod string, req interface{}) error {
+	token := a.extensionToken(ctx)
+	if token == nil {
+		a.metrics.UnauthenticatedRequestCounter.Inc()
+		return status.Error(codes.Unauthenticated, "missing or invalid extension token")
+	}
+
+	method := strings.TrimPrefix(fullMethod, "/"+inx.INX_ServiceDesc.ServiceName+"/")
+	requiredScope, exists := methodScopes[method]
+	if !exists {
+		a.metrics.PermissionDeniedRequestCounter.Inc()
+		return status.Errorf(codes.PermissionDenied, "method %s is not allowed for extensions", method)
+	}
+
+	if apiRequest, ok := req.(*inx.APIRequest); ok && apiRequest.GetMethod() != http.MethodGet {
+		requiredScope = ScopeSubmit
+	}
+
+	for _, scope := range token.Scopes {
+		if scope == requiredScope {
+			a.metrics.IncExtensionRequests(token.Name)
+			return nil
+		}
+	}
+
+	a.metrics.PermissionDeniedRequestCounter.Inc()
+	Plugin.LogDebugf("extension \"%s\" is missing the scope \"%s\" to call %s", token.Name, requiredScope, method)
+	return status.Errorf(codes.PermissionDenied, "scope \"%s\" is needed to call %s", requiredScope, method)
+}
+
+func (a *extensionAuth) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *extensionAuth) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(ss.Context(), info.FullMethod, nil); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// loadTLSCredentials loads the server certificate and, if a client CA is given, enforces the verification of client certificates.
+func loadTLSCredentials(certPath string, keyPath string, clientCAPath string) (credentials.TransportCredentials, error) {
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAPath != "" {
+		clientCA, err := os.ReadFile(clientCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client CA: %w", err)
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(clientCA) {
+			return nil, fmt.Errorf("no valid certificates found in client CA file %s", clientCAPath)
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
@@ -0,0 +1,97 @@
+package inx
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gohornet/hornet/pkg/metrics"
+	"github.com/iotaledger/hive.go/configuration"
+	"github.com/iotaledger/hive.go/logger"
+	inx "github.com/iotaledger/inx/go"
+)
+
+func newTestExtensionAuth(t *testing.T) (*extensionAuth, *metrics.INXMetrics) {
+	cfg := configuration.New()
+	require.NoError(t, cfg.Set("logger.disableStacktrace", true))
+
+	// no need to check the error, since the global logger could already be initialized
+	_ = logger.InitGlobalLogger(cfg)
+
+	inxMetrics := &metrics.INXMetrics{}
+	auth, err := newExtensionAuth([]*ExtensionToken{
+		{Name: "indexer", Token: "indexer-token", Scopes: []string{ScopeRead}},
+		{Name: "faucet", Token: "faucet-token", Scopes: []string{ScopeRead, ScopeSubmit}},
+	}, inxMetrics)
+	require.NoError(t, err)
+
+	return auth, inxMetrics
+}
+
+func contextWithToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationMetadataKey, authorizationBearerPrefix+token))
+}
+
+func fullMethod(method string) string {
+	return "/" + inx.INX_ServiceDesc.ServiceName + "/" + method
+}
+
+func TestNewExtensionAuth(t *testing.T) {
+
+	for _, tokens := range [][]*ExtensionToken{
+		{{Name: "", Token: "token", Scopes: []string{ScopeRead}}},
+		{{Name: "indexer", Token: "", Scopes: []string{ScopeRead}}},
+	} {
+		_, err := newExtensionAuth(tokens, &metrics.INXMetrics{})
+		require.Error(t, err)
+	}
+
+	_, err := newExtensionAuth([]*ExtensionToken{{Name: "indexer", Token: "token", Scopes: []string{"admin"}}}, &metrics.INXMetrics{})
+	require.ErrorIs(t, err, ErrUnknownScope)
+}
+
+func TestExtensionAuthAuthorize(t *testing.T) {
+
+	auth, inxMetrics := newTestExtensionAuth(t)
+
+	// missing and invalid tokens
+	err := auth.authorize(context.Background(), fullMethod("ReadNodeStatus"), nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = auth.authorize(contextWithToken("unknown-token"), fullMethod("ReadNodeStatus"), nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	err = auth.authorize(metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationMetadataKey, "indexer-token")), fullMethod("ReadNodeStatus"), nil)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Equal(t, uint32(3), inxMetrics.UnauthenticatedRequestCounter.Load())
+
+	// scopes
+	require.NoError(t, auth.authorize(contextWithToken("indexer-token"), fullMethod("ListenToLedgerUpdates"), nil))
+	require.NoError(t, auth.authorize(contextWithToken("faucet-token"), fullMethod("SubmitMessage"), &inx.RawMessage{}))
+
+	err = auth.authorize(contextWithToken("indexer-token"), fullMethod("SubmitMessage"), &inx.RawMessage{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = auth.authorize(contextWithToken("faucet-token"), fullMethod("RegisterAPIRoute"), &inx.APIRouteRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// unknown methods are never allowed
+	err = auth.authorize(contextWithToken("faucet-token"), fullMethod("UnknownMethod"), nil)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.Equal(t, uint32(3), inxMetrics.PermissionDeniedRequestCounter.Load())
+
+	// API requests need the submit scope for all methods except GET
+	require.NoError(t, auth.authorize(contextWithToken("indexer-token"), fullMethod("PerformAPIRequest"), &inx.APIRequest{Method: http.MethodGet}))
+
+	err = auth.authorize(contextWithToken("indexer-token"), fullMethod("PerformAPIRequest"), &inx.APIRequest{Method: http.MethodPost})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	require.NoError(t, auth.authorize(contextWithToken("faucet-token"), fullMethod("PerformAPIRequest"), &inx.APIRequest{Method: http.MethodPost}))
+
+	require.Equal(t, map[string]uint64{"indexer": 2, "faucet": 2}, inxMetrics.ExtensionRequests())
+}
@@ -0,0 +1,184 @@
+package inx
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	inx "github.com/iotaledger/inx/go"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+// The inx.MessageFilter of the current INX protocol version does not contain any fields yet,
+// so the filter criteria are passed as gRPC metadata of the stream request.
+// All given criteria need to match, if a criterion is given multiple times, one of the values needs to match.
+const (
+	// MessageFilterMetadataPayloadType filters messages by the type of their payload (decimal).
+	MessageFilterMetadataPayloadType = "inx-filter-payload-type"
+	// MessageFilterMetadataTagPrefix filters messages by the hex encoded prefix of the tag of their tagged data.
+	// The tagged data can either be the payload of the message or the payload of a transaction essence.
+	MessageFilterMetadataTagPrefix = "inx-filter-tag-prefix"
+	// MessageFilterMetadataMilestonesOnly filters for messages that contain a milestone payload ("true" or "false").
+	MessageFilterMetadataMilestonesOnly = "inx-filter-milestones-only"
+	// MessageFilterMetadataAddress filters for transactions that create outputs which reference the given bech32 address in their unlock conditions.
+	MessageFilterMetadataAddress = "inx-filter-address"
+)
+
+// messageFilter is the server side filter of the messages that are sent to an INX message stream.
+type messageFilter struct {
+	payloadTypes   map[iotago.PayloadType]struct{}
+	tagPrefixes    [][]byte
+	milestonesOnly bool
+	addresses      map[string]struct{}
+}
+
+// newMessageFilter creates a messageFilter from the given inx.MessageFilter and the metadata of the stream.
+// It returns nil if no filter criteria were given.
+func newMessageFilter(ctx context.Context, _ *inx.MessageFilter) (*messageFilter, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	filter := &messageFilter{
+		payloadTypes: make(map[iotago.PayloadType]struct{}),
+		addresses:    make(map[string]struct{}),
+	}
+	var hasCriteria bool
+
+	for _, value := range md.Get(MessageFilterMetadataPayloadType) {
+		payloadType, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid payload type filter: %s", value)
+		}
+		filter.payloadTypes[iotago.PayloadType(payloadType)] = struct{}{}
+		hasCriteria = true
+	}
+
+	for _, value := range md.Get(MessageFilterMetadataTagPrefix) {
+		tagPrefix, err := iotago.DecodeHex(strings.ToLower(value))
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid tag prefix filter: %s, error: %s", value, err)
+		}
+		filter.tagPrefixes = append(filter.tagPrefixes, tagPrefix)
+		hasCriteria = true
+	}
+
+	for _, value := range md.Get(MessageFilterMetadataMilestonesOnly) {
+		milestonesOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid milestones only filter: %s", value)
+		}
+		if milestonesOnly {
+			filter.milestonesOnly = true
+			hasCriteria = true
+		}
+	}
+
+	for _, value := range md.Get(MessageFilterMetadataAddress) {
+		hrp, address, err := iotago.ParseBech32(value)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid address filter: %s, error: %s", value, err)
+		}
+		if hrp != deps.Bech32HRP {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid address filter: %s, invalid bech32 human readable prefix: %s", value, hrp)
+		}
+		filter.addresses[address.Key()] = struct{}{}
+		hasCriteria = true
+	}
+
+	if !hasCriteria {
+		return nil, nil
+	}
+
+	return filter, nil
+}
+
+// Matches returns whether the given message passes the filter.
+// A nil filter matches all messages.
+func (f *messageFilter) Matches(msg *storage.Message) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.milestonesOnly && !msg.IsMilestone() {
+		return false
+	}
+
+	if len(f.payloadTypes) > 0 {
+		payload := msg.Message().Payload
+		if payload == nil {
+			return false
+		}
+		if _, ok := f.payloadTypes[payload.PayloadType()]; !ok {
+			return false
+		}
+	}
+
+	if len(f.tagPrefixes) > 0 && !f.matchesTagPrefix(msg) {
+		return false
+	}
+
+	if len(f.addresses) > 0 && !f.matchesAddress(msg) {
+		return false
+	}
+
+	return true
+}
+
+// MatchesMessageID loads the message with the given ID and returns whether it passes the filter.
+// A nil filter matches all messages without loading them.
+func (f *messageFilter) MatchesMessageID(messageID hornet.MessageID) bool {
+	if f == nil {
+		return true
+	}
+
+	cachedMsg := deps.Storage.CachedMessageOrNil(messageID) // message +1
+	if cachedMsg == nil {
+		return false
+	}
+	defer cachedMsg.Release(true) // message -1
+
+	return f.Matches(cachedMsg.Message())
+}
+
+func (f *messageFilter) matchesTagPrefix(msg *storage.Message) bool {
+	taggedData := msg.TaggedData()
+	if taggedData == nil {
+		taggedData = msg.TransactionEssenceTaggedData()
+	}
+	if taggedData == nil {
+		return false
+	}
+
+	for _, tagPrefix := range f.tagPrefixes {
+		if bytes.HasPrefix(taggedData.Tag, tagPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *messageFilter) matchesAddress(msg *storage.Message) bool {
+	essence := msg.TransactionEssence()
+	if essence == nil {
+		return false
+	}
+
+	for _, output := range essence.Outputs {
+		for _, address := range utxo.UnlockConditionAddresses(output) {
+			if _, ok := f.addresses[address.Key()]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package inx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/iotaledger/hive.go/serializer/v2"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func newTestMessage(t *testing.T, payload iotago.Payload) *storage.Message {
+	msg, err := storage.NewMessage(&iotago.Message{
+		ProtocolVersion: iotago.ProtocolVersion,
+		Parents:         iotago.MessageIDs{iotago.MessageID{}},
+		Payload:         payload,
+	}, serializer.DeSeriModeNoValidation, iotago.ZeroRentParas)
+	require.NoError(t, err)
+	return msg
+}
+
+func newTestTransaction(address iotago.Address, taggedData iotago.Payload) *iotago.Transaction {
+	return &iotago.Transaction{
+		Essence: &iotago.TransactionEssence{
+			NetworkID: 1,
+			Inputs:    iotago.Inputs{&iotago.UTXOInput{}},
+			Outputs: iotago.Outputs{
+				&iotago.BasicOutput{
+					Amount: 1_000_000,
+					Conditions: iotago.UnlockConditions{
+						&iotago.AddressUnlockCondition{Address: address},
+					},
+				},
+			},
+			Payload: taggedData,
+		},
+		UnlockBlocks: iotago.UnlockBlocks{},
+	}
+}
+
+func newTestMessageFilter(t *testing.T, keyValues ...string) *messageFilter {
+	filter, err := newMessageFilter(metadata.NewIncomingContext(context.Background(), metadata.Pairs(keyValues...)), nil)
+	require.NoError(t, err)
+	return filter
+}
+
+func TestMessageFilterWithoutCriteria(t *testing.T) {
+
+	filter, err := newMessageFilter(context.Background(), nil)
+	require.NoError(t, err)
+	require.Nil(t, filter)
+
+	filter = newTestMessageFilter(t, MessageFilterMetadataMilestonesOnly, "false")
+	require.Nil(t, filter)
+
+	// a nil filter matches all messages
+	require.True(t, filter.Matches(newTestMessage(t, nil)))
+}
+
+func TestMessageFilterInvalidCriteria(t *testing.T) {
+
+	deps.Bech32HRP = iotago.PrefixTestnet
+
+	for _, keyValues := range [][]string{
+		{MessageFilterMetadataPayloadType, "tagged"},
+		{MessageFilterMetadataTagPrefix, "0xzz"},
+		{MessageFilterMetadataMilestonesOnly, "maybe"},
+		{MessageFilterMetadataAddress, "invalid"},
+		{MessageFilterMetadataAddress, (&iotago.Ed25519Address{}).Bech32(iotago.PrefixMainnet)},
+	} {
+		_, err := newMessageFilter(metadata.NewIncomingContext(context.Background(), metadata.Pairs(keyValues...)), nil)
+		require.Error(t, err)
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestMessageFilterMatches(t *testing.T) {
+
+	deps.Bech32HRP = iotago.PrefixTestnet
+
+	address := &iotago.Ed25519Address{1}
+	otherAddress := &iotago.Ed25519Address{2}
+
+	taggedDataMsg := newTestMessage(t, &iotago.TaggedData{Tag: []byte("hello"), Data: []byte("world")})
+	transactionMsg := newTestMessage(t, newTestTransaction(address, &iotago.TaggedData{Tag: []byte("help")}))
+	otherTransactionMsg := newTestMessage(t, newTestTransaction(otherAddress, nil))
+	milestoneMsg := newTestMessage(t, &iotago.Milestone{Index: 1})
+	emptyMsg := newTestMessage(t, nil)
+
+	// payload types
+	filter := newTestMessageFilter(t,
+		MessageFilterMetadataPayloadType, "5",
+		MessageFilterMetadataPayloadType, "6",
+	)
+	require.True(t, filter.Matches(taggedDataMsg))
+	require.True(t, filter.Matches(transactionMsg))
+	require.False(t, filter.Matches(milestoneMsg))
+	require.False(t, filter.Matches(emptyMsg))
+
+	// tag prefixes match the tagged data payload and the tagged data of transaction essences
+	filter = newTestMessageFilter(t, MessageFilterMetadataTagPrefix, iotago.EncodeHex([]byte("hel")))
+	require.True(t, filter.Matches(taggedDataMsg))
+	require.True(t, filter.Matches(transactionMsg))
+	require.False(t, filter.Matches(otherTransactionMsg))
+	require.False(t, filter.Matches(emptyMsg))
+
+	filter = newTestMessageFilter(t, MessageFilterMetadataTagPrefix, iotago.EncodeHex([]byte("hell")))
+	require.True(t, filter.Matches(taggedDataMsg))
+	require.False(t, filter.Matches(transactionMsg))
+
+	// milestones only
+	filter = newTestMessageFilter(t, MessageFilterMetadataMilestonesOnly, "true")
+	require.True(t, filter.Matches(milestoneMsg))
+	require.False(t, filter.Matches(taggedDataMsg))
+
+	// addresses in the unlock conditions of the created outputs
+	filter = newTestMessageFilter(t, MessageFilterMetadataAddress, address.Bech32(iotago.PrefixTestnet))
+	require.True(t, filter.Matches(transactionMsg))
+	require.False(t, filter.Matches(otherTransactionMsg))
+	require.False(t, filter.Matches(taggedDataMsg))
+
+	// all criteria need to match
+	filter = newTestMessageFilter(t,
+		MessageFilterMetadataAddress, address.Bech32(iotago.PrefixTestnet),
+		MessageFilterMetadataAddress, otherAddress.Bech32(iotago.PrefixTestnet),
+		MessageFilterMetadataTagPrefix, iotago.EncodeHex([]byte("help")),
+	)
+	require.True(t, filter.Matches(transactionMsg))
+	require.False(t, filter.Matches(otherTransactionMsg))
+}
@@ -9,6 +9,20 @@ import (
 const (
 	// CfgINXBindAddress the bind address on which the INX can be accessed from
 	CfgINXBindAddress = "inx.bindAddress"
+
+	// CfgINXTLSEnabled defines whether the INX server uses TLS
+	CfgINXTLSEnabled = "inx.tls.enabled"
+	// CfgINXTLSCertPath the path to the certificate file of the INX server
+	CfgINXTLSCertPath = "inx.tls.certPath"
+	// CfgINXTLSKeyPath the path to the private key file of the INX server
+	CfgINXTLSKeyPath = "inx.tls.keyPath"
+	// CfgINXTLSClientCAPath the path to the CA file used to verify the client certificates (optional)
+	CfgINXTLSClientCAPath = "inx.tls.clientCAPath"
+
+	// CfgINXAuthEnabled defines whether extensions need to authenticate with a token
+	CfgINXAuthEnabled = "inx.auth.enabled"
+	// CfgINXAuthTokens the tokens of the extensions and their scopes
+	CfgINXAuthTokens = "inx.auth.tokens"
 )
 
 var params = &node.PluginParams{
@@ -16,7 +30,13 @@ var params = &node.PluginParams{
 		"nodeConfig": func() *flag.FlagSet {
 			fs := flag.NewFlagSet("", flag.ContinueOnError)
 			fs.String(CfgINXBindAddress, "localhost:9029", "the bind address on which the INX can be accessed from")
+			fs.Bool(CfgINXTLSEnabled, false, "whether the INX server uses TLS")
+			fs.String(CfgINXTLSCertPath, "", "the path to the certificate file of the INX server")
+			fs.String(CfgINXTLSKeyPath, "", "the path to the private key file of the INX server")
+			fs.String(CfgINXTLSClientCAPath, "", "the path to the CA file used to verify the client certificates (optional)")
+			fs.Bool(CfgINXAuthEnabled, false, "whether extensions need to authenticate with a token")
 			return fs
 		}(),
 	},
+	Masked: []string{CfgINXAuthTokens},
 }
@@ -6,8 +6,10 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/dig"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/gohornet/hornet/pkg/keymanager"
+	"github.com/gohornet/hornet/pkg/metrics"
 	"github.com/gohornet/hornet/pkg/model/storage"
 	"github.com/gohornet/hornet/pkg/model/syncmanager"
 	"github.com/gohornet/hornet/pkg/model/utxo"
@@ -69,8 +71,55 @@ type dependencies struct {
 }
 
 func provide(c *dig.Container) {
-	if err := c.Provide(func() *INXServer {
-		return newINXServer()
+
+	type serverDeps struct {
+		dig.In
+		NodeConfig *configuration.Configuration `name:"nodeConfig"`
+		INXMetrics *metrics.INXMetrics
+	}
+
+	if err := c.Provide(func() *metrics.INXMetrics {
+		return &metrics.INXMetrics{}
+	}); err != nil {
+		Plugin.LogPanic(err)
+	}
+
+	if err := c.Provide(func(deps serverDeps) *INXServer {
+
+		var transportCredentials credentials.TransportCredentials
+		if deps.NodeConfig.Bool(CfgINXTLSEnabled) {
+			var err error
+			transportCredentials, err = loadTLSCredentials(
+				deps.NodeConfig.String(CfgINXTLSCertPath),
+				deps.NodeConfig.String(CfgINXTLSKeyPath),
+				deps.NodeConfig.String(CfgINXTLSClientCAPath),
+			)
+			if err != nil {
+				Plugin.LogPanicf("loading TLS credentials for INX failed: %s", err)
+			}
+		}
+
+		var auth *extensionAuth
+		if deps.NodeConfig.Bool(CfgINXAuthEnabled) {
+			var tokens []*ExtensionToken
+			if err := deps.NodeConfig.Unmarshal(CfgINXAuthTokens, &tokens); err != nil {
+				Plugin.LogPanicf("parsing '%s' failed: %s", CfgINXAuthTokens, err)
+			}
+			if len(tokens) == 0 {
+				Plugin.LogWarnf("'%s' is enabled but no extension tokens are configured in '%s'", CfgINXAuthEnabled, CfgINXAuthTokens)
+			}
+			if transportCredentials == nil {
+				Plugin.LogWarnf("'%s' is enabled without '%s', the extension tokens are sent in plaintext", CfgINXAuthEnabled, CfgINXTLSEnabled)
+			}
+
+			var err error
+			auth, err = newExtensionAuth(tokens, deps.INXMetrics)
+			if err != nil {
+				Plugin.LogPanicf("parsing '%s' failed: %s", CfgINXAuthTokens, err)
+			}
+		}
+
+		return newINXServer(transportCredentials, auth)
 	}); err != nil {
 		Plugin.LogPanic(err)
 	}
@@ -6,6 +6,7 @@ import (
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	inx "github.com/iotaledger/inx/go"
 	iotago "github.com/iotaledger/iota.go/v3"
@@ -16,11 +17,23 @@ const (
 	workerQueueSize = 10000
 )
 
-func newINXServer() *INXServer {
-	grpcServer := grpc.NewServer(
-		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
-	)
+func newINXServer(transportCredentials credentials.TransportCredentials, auth *extensionAuth) *INXServer {
+	streamInterceptors := []grpc.StreamServerInterceptor{grpc_prometheus.StreamServerInterceptor}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{grpc_prometheus.UnaryServerInterceptor}
+	if auth != nil {
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor)
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor)
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+	}
+	if transportCredentials != nil {
+		serverOptions = append(serverOptions, grpc.Creds(transportCredentials))
+	}
+
+	grpcServer := grpc.NewServer(serverOptions...)
 	s := &INXServer{grpcServer: grpcServer}
 	inx.RegisterINXServer(grpcServer, s)
 	return s
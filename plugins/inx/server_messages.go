@@ -114,6 +114,11 @@ func (s *INXServer) ReadMessageMetadata(_ context.Context, messageID *inx.Messag
 }
 
 func (s *INXServer) ListenToMessages(filter *inx.MessageFilter, srv inx.INX_ListenToMessagesServer) error {
+	msgFilter, err := newMessageFilter(srv.Context(), filter)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	wp := workerpool.New(func(task workerpool.Task) {
 		cachedMsg := task.Param(0).(*storage.CachedMessage)
@@ -127,7 +132,10 @@ func (s *INXServer) ListenToMessages(filter *inx.MessageFilter, srv inx.INX_List
 		task.Return(nil)
 	})
 	closure := events.NewClosure(func(cachedMsg *storage.CachedMessage, latestMilestoneIndex milestone.Index, confirmedMilestoneIndex milestone.Index) {
-		//TODO: apply filter?
+		if !msgFilter.Matches(cachedMsg.Message()) {
+			cachedMsg.Release(true) // message -1
+			return
+		}
 		wp.Submit(cachedMsg)
 	})
 	wp.Start()
@@ -139,6 +147,11 @@ func (s *INXServer) ListenToMessages(filter *inx.MessageFilter, srv inx.INX_List
 }
 
 func (s *INXServer) ListenToSolidMessages(filter *inx.MessageFilter, srv inx.INX_ListenToSolidMessagesServer) error {
+	msgFilter, err := newMessageFilter(srv.Context(), filter)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	wp := workerpool.New(func(task workerpool.Task) {
 		msgMeta := task.Param(0).(*storage.CachedMetadata)
@@ -157,7 +170,10 @@ func (s *INXServer) ListenToSolidMessages(filter *inx.MessageFilter, srv inx.INX
 		task.Return(nil)
 	}, workerpool.WorkerCount(workerCount), workerpool.QueueSize(workerQueueSize), workerpool.FlushTasksAtShutdown(true))
 	closure := events.NewClosure(func(msgMeta *storage.CachedMetadata) {
-		//TODO: apply filter?
+		if !msgFilter.MatchesMessageID(msgMeta.Metadata().MessageID()) {
+			msgMeta.Release(true) // meta -1
+			return
+		}
 		wp.Submit(msgMeta)
 	})
 	wp.Start()
@@ -169,6 +185,11 @@ func (s *INXServer) ListenToSolidMessages(filter *inx.MessageFilter, srv inx.INX
 }
 
 func (s *INXServer) ListenToReferencedMessages(filter *inx.MessageFilter, srv inx.INX_ListenToReferencedMessagesServer) error {
+	msgFilter, err := newMessageFilter(srv.Context(), filter)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	wp := workerpool.New(func(task workerpool.Task) {
 		msgMeta := task.Param(0).(*storage.CachedMetadata)
@@ -186,8 +207,11 @@ func (s *INXServer) ListenToReferencedMessages(filter *inx.MessageFilter, srv in
 		}
 		task.Return(nil)
 	}, workerpool.WorkerCount(workerCount), workerpool.QueueSize(workerQueueSize), workerpool.FlushTasksAtShutdown(true))
-	closure := events.NewClosure(func(msgMeta *storage.CachedMetadata, index milestone.Index, confTime uint64) {
-		//TODO: apply filter?
+	closure := events.NewClosure(func(msgMeta *storage.CachedMetadata, index milestone.Index, confTime uint32) {
+		if !msgFilter.MatchesMessageID(msgMeta.Metadata().MessageID()) {
+			msgMeta.Release(true) // meta -1
+			return
+		}
 		wp.Submit(msgMeta)
 	})
 	wp.Start()
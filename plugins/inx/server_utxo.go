@@ -169,65 +169,151 @@ func (s *INXServer) ReadUnspentOutputs(_ *inx.NoParams, srv inx.INX_ReadUnspentO
 	return err
 }
 
-func (s *INXServer) ListenToLedgerUpdates(req *inx.LedgerRequest, srv inx.INX_ListenToLedgerUpdatesServer) error {
+// ledgerUpdateReplay keeps track of the ledger updates that were sent to a client.
+// It replays the stored milestone diffs, so that the client receives every ledger update
+// exactly once and in order, regardless of whether the update was stored or received live.
+type ledgerUpdateReplay struct {
+	utxoManager *utxo.Manager
+	send        func(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) error
 
-	sendPreviousMilestoneDiffs := func(startIndex milestone.Index) error {
-		if startIndex > 0 {
-			deps.UTXOManager.ReadLockLedger()
-			defer deps.UTXOManager.ReadUnlockLedger()
+	// the index of the last ledger update that was sent to the client.
+	// it is only accessed by the stream goroutine until the workerpool is started, and by the single worker afterwards.
+	lastSentIndex milestone.Index
+}
 
-			// Stream all available milestone diffs first
-			pruningIndex := deps.Storage.SnapshotInfo().PruningIndex
-			if startIndex <= pruningIndex {
-				return status.Errorf(codes.InvalidArgument, "given startMilestoneIndex %d is older than the current pruningIndex %d", startIndex, pruningIndex)
-			}
+func newLedgerUpdateReplay(utxoManager *utxo.Manager, lastSentIndex milestone.Index, send func(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) error) *ledgerUpdateReplay {
+	return &ledgerUpdateReplay{
+		utxoManager:   utxoManager,
+		send:          send,
+		lastSentIndex: lastSentIndex,
+	}
+}
 
-			ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
-			if err != nil {
-				return status.Error(codes.Unavailable, "error accessing the UTXO ledger")
-			}
-			for currentIndex := startIndex; currentIndex <= ledgerIndex; currentIndex++ {
-				msDiff, err := deps.UTXOManager.MilestoneDiffWithoutLocking(currentIndex)
-				if err != nil {
-					return status.Errorf(codes.NotFound, "ledger update for milestoneIndex %d not found", currentIndex)
-				}
-				payload, err := NewLedgerUpdate(msDiff.Index, msDiff.Outputs, msDiff.Spents)
-				if err != nil {
-					return err
-				}
-				if err := srv.Send(payload); err != nil {
-					return fmt.Errorf("send error: %w", err)
-				}
-			}
+func (r *ledgerUpdateReplay) sendLedgerUpdate(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) error {
+	if err := r.send(index, newOutputs, newSpents); err != nil {
+		return err
+	}
+	r.lastSentIndex = index
+	return nil
+}
+
+// sendStoredMilestoneDiffs sends all stored milestone diffs after the last sent index up to the given index.
+// the stored milestone diffs are never modified, so they can be read without holding the ledger lock.
+// this is important because the LedgerUpdated event is triggered while the ledger is write locked.
+func (r *ledgerUpdateReplay) sendStoredMilestoneDiffs(targetIndex milestone.Index) error {
+	for r.lastSentIndex < targetIndex {
+		msDiff, err := r.utxoManager.MilestoneDiffWithoutLocking(r.lastSentIndex + 1)
+		if err != nil {
+			return status.Errorf(codes.NotFound, "ledger update for milestoneIndex %d not found", r.lastSentIndex+1)
+		}
+		if err := r.sendLedgerUpdate(msDiff.Index, msDiff.Outputs, msDiff.Spents); err != nil {
+			return err
 		}
+	}
+	return nil
+}
+
+// catchUp replays the stored milestone diffs until the client caught up with the ledger.
+func (r *ledgerUpdateReplay) catchUp() error {
+	for {
+		ledgerIndex, err := r.utxoManager.ReadLedgerIndex()
+		if err != nil {
+			return status.Error(codes.Unavailable, "error accessing the UTXO ledger")
+		}
+		if r.lastSentIndex >= ledgerIndex {
+			return nil
+		}
+		if err := r.sendStoredMilestoneDiffs(ledgerIndex); err != nil {
+			return err
+		}
+	}
+}
+
+// liveUpdate sends a ledger update that was received live.
+// updates that were already sent by the replay are skipped and gaps are filled with the stored milestone diffs.
+func (r *ledgerUpdateReplay) liveUpdate(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) error {
+	if index <= r.lastSentIndex {
+		// already sent by the replay
 		return nil
 	}
 
-	if err := sendPreviousMilestoneDiffs(milestone.Index(req.GetStartMilestoneIndex())); err != nil {
+	// fill the gap between the replay and the live updates
+	if err := r.sendStoredMilestoneDiffs(index - 1); err != nil {
 		return err
 	}
 
+	return r.sendLedgerUpdate(index, newOutputs, newSpents)
+}
+
+func (s *INXServer) ListenToLedgerUpdates(req *inx.LedgerRequest, srv inx.INX_ListenToLedgerUpdatesServer) error {
+
+	replay := newLedgerUpdateReplay(deps.UTXOManager, 0, func(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) error {
+		payload, err := NewLedgerUpdate(index, newOutputs, newSpents)
+		if err != nil {
+			return err
+		}
+		if err := srv.Send(payload); err != nil {
+			return fmt.Errorf("send error: %w", err)
+		}
+		return nil
+	})
+
+	startIndex := milestone.Index(req.GetStartMilestoneIndex())
+	if startIndex > 0 {
+		pruningIndex := deps.Storage.SnapshotInfo().PruningIndex
+		if startIndex <= pruningIndex {
+			return status.Errorf(codes.InvalidArgument, "given startMilestoneIndex %d is older than the current pruningIndex %d", startIndex, pruningIndex)
+		}
+		replay.lastSentIndex = startIndex - 1
+
+		// replay the stored milestone diffs before listening to the live updates,
+		// otherwise the live updates would queue up during a long replay and block the confirmation of new milestones.
+		if err := replay.catchUp(); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	wp := workerpool.New(func(task workerpool.Task) {
+		defer task.Return(nil)
+
 		index := task.Param(0).(milestone.Index)
 		newOutputs := task.Param(1).(utxo.Outputs)
 		newSpents := task.Param(2).(utxo.Spents)
-		payload, err := NewLedgerUpdate(index, newOutputs, newSpents)
-		if err != nil {
-			Plugin.LogInfof("send error: %v", err)
-			cancel()
-		}
-		if err := srv.Send(payload); err != nil {
+
+		if err := replay.liveUpdate(index, newOutputs, newSpents); err != nil {
 			Plugin.LogInfof("send error: %v", err)
 			cancel()
 		}
-		task.Return(nil)
-	})
+	}, workerpool.WorkerCount(workerCount), workerpool.QueueSize(workerQueueSize), workerpool.FlushTasksAtShutdown(true))
 	closure := events.NewClosure(func(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) {
 		wp.Submit(index, newOutputs, newSpents)
 	})
-	wp.Start()
+
+	// attach to the live updates while the ledger is locked, so that no ledger update gets lost
+	// between reading the ledger index and attaching to the event.
+	deps.UTXOManager.ReadLockLedger()
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		deps.UTXOManager.ReadUnlockLedger()
+		cancel()
+		return status.Error(codes.Unavailable, "error accessing the UTXO ledger")
+	}
+	if startIndex == 0 {
+		// only live updates were requested
+		replay.lastSentIndex = ledgerIndex
+	}
 	deps.Tangle.Events.LedgerUpdated.Attach(closure)
+	deps.UTXOManager.ReadUnlockLedger()
+
+	// send the milestone diffs that were applied between the replay and attaching to the event
+	if err := replay.sendStoredMilestoneDiffs(ledgerIndex); err != nil {
+		deps.Tangle.Events.LedgerUpdated.Detach(closure)
+		cancel()
+		return err
+	}
+
+	wp.Start()
 	<-ctx.Done()
 	deps.Tangle.Events.LedgerUpdated.Detach(closure)
 	wp.Stop()
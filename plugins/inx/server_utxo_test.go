@@ -0,0 +1,68 @@
+package inx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+)
+
+// newTestLedger creates a ledger with empty milestone diffs up to the given ledger index.
+func newTestLedger(t *testing.T, ledgerIndex milestone.Index) *utxo.Manager {
+	utxoManager := utxo.New(mapdb.NewMapDB())
+	for msIndex := milestone.Index(1); msIndex <= ledgerIndex; msIndex++ {
+		require.NoError(t, utxoManager.ApplyConfirmation(msIndex, utxo.Outputs{}, utxo.Spents{}, nil, nil))
+	}
+	return utxoManager
+}
+
+func newTestLedgerUpdateReplay(utxoManager *utxo.Manager, lastSentIndex milestone.Index, sentIndexes *[]milestone.Index) *ledgerUpdateReplay {
+	return newLedgerUpdateReplay(utxoManager, lastSentIndex, func(index milestone.Index, _ utxo.Outputs, _ utxo.Spents) error {
+		*sentIndexes = append(*sentIndexes, index)
+		return nil
+	})
+}
+
+func TestLedgerUpdateReplay(t *testing.T) {
+
+	utxoManager := newTestLedger(t, 5)
+
+	var sentIndexes []milestone.Index
+	replay := newTestLedgerUpdateReplay(utxoManager, 2, &sentIndexes)
+
+	// the stored milestone diffs after the start index are replayed up to the ledger index
+	require.NoError(t, replay.catchUp())
+	require.Equal(t, []milestone.Index{3, 4, 5}, sentIndexes)
+
+	// live updates that were already replayed are skipped
+	require.NoError(t, replay.liveUpdate(5, utxo.Outputs{}, utxo.Spents{}))
+	require.Equal(t, []milestone.Index{3, 4, 5}, sentIndexes)
+
+	// gaps between the replay and the live updates are filled with the stored milestone diffs
+	require.NoError(t, utxoManager.ApplyConfirmation(6, utxo.Outputs{}, utxo.Spents{}, nil, nil))
+	require.NoError(t, utxoManager.ApplyConfirmation(7, utxo.Outputs{}, utxo.Spents{}, nil, nil))
+	require.NoError(t, replay.liveUpdate(7, utxo.Outputs{}, utxo.Spents{}))
+	require.Equal(t, []milestone.Index{3, 4, 5, 6, 7}, sentIndexes)
+
+	require.NoError(t, replay.liveUpdate(8, utxo.Outputs{}, utxo.Spents{}))
+	require.Equal(t, []milestone.Index{3, 4, 5, 6, 7, 8}, sentIndexes)
+}
+
+func TestLedgerUpdateReplayMissingMilestoneDiff(t *testing.T) {
+
+	utxoManager := newTestLedger(t, 3)
+
+	var sentIndexes []milestone.Index
+	replay := newTestLedgerUpdateReplay(utxoManager, 3, &sentIndexes)
+
+	// the gap can't be filled if the milestone diff was not stored
+	err := replay.liveUpdate(6, utxo.Outputs{}, utxo.Spents{})
+	require.Error(t, err)
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Empty(t, sentIndexes)
+}
@@ -1,6 +1,7 @@
 package participation
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
@@ -81,15 +82,31 @@ func getEvents(c echo.Context) (*EventsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	eventIDs := deps.ParticipationManager.EventIDs(eventTypes...)
+	sort.Slice(eventIDs, func(i, j int) bool {
+		return bytes.Compare(eventIDs[i][:], eventIDs[j][:]) < 0
+	})
 
 	hexEventIDs := []string{}
 	for _, id := range eventIDs {
+		if paginator.Skip(id[:]) {
+			continue
+		}
+		if !paginator.Add(id[:]) {
+			break
+		}
 		hexEventIDs = append(hexEventIDs, id.ToHex())
 	}
-	sort.Strings(hexEventIDs)
 
-	return &EventsResponse{EventIDs: hexEventIDs}, nil
+	return &EventsResponse{
+		PaginationResponse: paginator.Response(),
+		EventIDs:           hexEventIDs,
+	}, nil
 }
 
 func createEvent(c echo.Context) (*CreateEventResponse, error) {
@@ -455,6 +472,11 @@ func getActiveParticipations(c echo.Context) (*ParticipationsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	// We need to lock the ledger here so that we don't get partial results while the next milestone is being confirmed
 	deps.UTXOManager.ReadLockLedger()
 	defer deps.UTXOManager.ReadUnlockLedger()
@@ -462,7 +484,15 @@ func getActiveParticipations(c echo.Context) (*ParticipationsResponse, error) {
 	response := &ParticipationsResponse{
 		Participations: make(map[string]*TrackedParticipation),
 	}
+	// the participations are ordered by output ID, which is used as the cursor
 	if err := deps.ParticipationManager.ForEachActiveParticipation(eventID, func(trackedParticipation *participation.TrackedParticipation) bool {
+		if paginator.Skip(trackedParticipation.OutputID[:]) {
+			return true
+		}
+		if !paginator.Add(trackedParticipation.OutputID[:]) {
+			return false
+		}
+
 		t := &TrackedParticipation{
 			MessageID:           trackedParticipation.MessageID.ToHex(),
 			Amount:              trackedParticipation.Amount,
@@ -474,6 +504,7 @@ func getActiveParticipations(c echo.Context) (*ParticipationsResponse, error) {
 	}); err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "error fetching active participations: %s", err)
 	}
+	response.PaginationResponse = paginator.Response()
 	return response, nil
 }
 
@@ -483,6 +514,11 @@ func getPastParticipations(c echo.Context) (*ParticipationsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	// We need to lock the ledger here so that we don't get partial results while the next milestone is being confirmed
 	deps.UTXOManager.ReadLockLedger()
 	defer deps.UTXOManager.ReadUnlockLedger()
@@ -490,7 +526,15 @@ func getPastParticipations(c echo.Context) (*ParticipationsResponse, error) {
 	response := &ParticipationsResponse{
 		Participations: make(map[string]*TrackedParticipation),
 	}
+	// the participations are ordered by output ID, which is used as the cursor
 	if err := deps.ParticipationManager.ForEachPastParticipation(eventID, func(trackedParticipation *participation.TrackedParticipation) bool {
+		if paginator.Skip(trackedParticipation.OutputID[:]) {
+			return true
+		}
+		if !paginator.Add(trackedParticipation.OutputID[:]) {
+			return false
+		}
+
 		t := &TrackedParticipation{
 			MessageID:           trackedParticipation.MessageID.ToHex(),
 			Amount:              trackedParticipation.Amount,
@@ -502,5 +546,6 @@ func getPastParticipations(c echo.Context) (*ParticipationsResponse, error) {
 	}); err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "error fetching past participations: %s", err)
 	}
+	response.PaginationResponse = paginator.Response()
 	return response, nil
 }
@@ -33,7 +33,7 @@ const (
 const (
 
 	// RouteParticipationEvents is the route to list all events, returning their ID, the event name and status.
-	// GET returns a list of all events known to the node. Optional query parameter returns filters events by type (query parameters: "type").
+	// GET returns a list of all events known to the node. Optional query parameter returns filters events by type (query parameters: "type") (paginated).
 	RouteParticipationEvents = "/events"
 
 	// RouteParticipationEvent is the route to access a single participation by its ID.
@@ -69,11 +69,11 @@ const (
 	RouteAdminDeleteEvent = "/admin/events/:" + ParameterParticipationEventID
 
 	// RouteAdminActiveParticipations is the route the node operator can use to get all the active participations for a certain event.
-	// GET returns a list of all active participations
+	// GET returns a list of all active participations (paginated).
 	RouteAdminActiveParticipations = "/admin/events/:" + ParameterParticipationEventID + "/active"
 
 	// RouteAdminPastParticipations is the route the node operator can use to get all the past participations for a certain event.
-	// GET returns a list of all past participations
+	// GET returns a list of all past participations (paginated).
 	RouteAdminPastParticipations = "/admin/events/:" + ParameterParticipationEventID + "/past"
 
 	// RouteAdminRewards is the route the node operator can use to get the rewards for a staking event.
@@ -103,14 +103,15 @@ var (
 
 type dependencies struct {
 	dig.In
-	NodeConfig           *configuration.Configuration `name:"nodeConfig"`
-	ParticipationManager *participation.ParticipationManager
-	UTXOManager          *utxo.Manager
-	SyncManager          *syncmanager.SyncManager
-	Tangle               *tangle.Tangle
-	Bech32HRP            iotago.NetworkPrefix `name:"bech32HRP"`
-	ShutdownHandler      *shutdown.ShutdownHandler
-	RestPluginManager    *restapi.RestPluginManager `optional:"true"`
+	NodeConfig              *configuration.Configuration `name:"nodeConfig"`
+	ParticipationManager    *participation.ParticipationManager
+	UTXOManager             *utxo.Manager
+	SyncManager             *syncmanager.SyncManager
+	Tangle                  *tangle.Tangle
+	Bech32HRP               iotago.NetworkPrefix `name:"bech32HRP"`
+	ShutdownHandler         *shutdown.ShutdownHandler
+	RestPluginManager       *restapi.RestPluginManager `optional:"true"`
+	RestAPILimitsMaxResults int                        `name:"restAPILimitsMaxResults" optional:"true"`
 }
 
 func provide(c *dig.Container) {
@@ -1,9 +1,13 @@
 package participation
 
-import "github.com/gohornet/hornet/pkg/model/milestone"
+import (
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/restapi"
+)
 
 // EventsResponse defines the response of a GET RouteParticipationEvents REST API call.
 type EventsResponse struct {
+	restapi.PaginationResponse
 	// The hex encoded IDs of the found events.
 	EventIDs []string `json:"eventIds"`
 }
@@ -72,6 +76,7 @@ type RewardsResponse struct {
 
 // ParticipationsResponse defines the response of a GET RouteAdminActiveParticipations or RouteAdminPastParticipations REST API call.
 type ParticipationsResponse struct {
+	restapi.PaginationResponse
 	// Participations holds the participations that are/were tracked.
 	Participations map[string]*TrackedParticipation `json:"participations"`
 }
@@ -136,6 +136,7 @@ func collectDebug() {
 	if lastDatabasePruningMetrics != nil {
 		databasePruningDurations.WithLabelValues("prune_unreferenced_messages").Set(lastDatabasePruningMetrics.DurationPruneUnreferencedMessages.Seconds())
 		databasePruningDurations.WithLabelValues("traverse_milestone_cone").Set(lastDatabasePruningMetrics.DurationTraverseMilestoneCone.Seconds())
+		databasePruningDurations.WithLabelValues("archive_milestone").Set(lastDatabasePruningMetrics.DurationArchiveMilestone.Seconds())
 		databasePruningDurations.WithLabelValues("prune_milestone").Set(lastDatabasePruningMetrics.DurationPruneMilestone.Seconds())
 		databasePruningDurations.WithLabelValues("prune_messages").Set(lastDatabasePruningMetrics.DurationPruneMessages.Seconds())
 		databasePruningDurations.WithLabelValues("set_snapshot_info").Set(lastDatabasePruningMetrics.DurationSetSnapshotInfo.Seconds())
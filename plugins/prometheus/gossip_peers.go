@@ -9,6 +9,7 @@ var (
 	gossipPeersRequests       *prometheus.GaugeVec
 	gossipPeersHeartbeats     *prometheus.GaugeVec
 	gossipPeersDroppedPackets *prometheus.GaugeVec
+	gossipPeersBytes          *prometheus.GaugeVec
 	gossipPeersConnected      *prometheus.GaugeVec
 )
 
@@ -54,6 +55,16 @@ func configureGossipPeers() {
 		[]string{"address", "alias", "id", "type"},
 	)
 
+	gossipPeersBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "gossip_peers",
+			Name:      "bytes",
+			Help:      "Number of received and sent bytes by peer, direction and message type.",
+		},
+		[]string{"address", "alias", "id", "direction", "type"},
+	)
+
 	gossipPeersConnected = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "iota",
@@ -68,6 +79,7 @@ func configureGossipPeers() {
 	registry.MustRegister(gossipPeersRequests)
 	registry.MustRegister(gossipPeersHeartbeats)
 	registry.MustRegister(gossipPeersDroppedPackets)
+	registry.MustRegister(gossipPeersBytes)
 	registry.MustRegister(gossipPeersConnected)
 
 	addCollect(collectGossipPeers)
@@ -78,6 +90,7 @@ func collectGossipPeers() {
 	gossipPeersRequests.Reset()
 	gossipPeersHeartbeats.Reset()
 	gossipPeersDroppedPackets.Reset()
+	gossipPeersBytes.Reset()
 	gossipPeersConnected.Reset()
 
 	for _, peer := range deps.PeeringManager.PeerInfoSnapshots() {
@@ -97,6 +110,16 @@ func collectGossipPeers() {
 			}
 		}
 
+		getBytesLabels := func(direction string, messageType string) prometheus.Labels {
+			return prometheus.Labels{
+				"id":        peer.ID,
+				"address":   peer.Addresses[0].String(),
+				"alias":     peer.Alias,
+				"direction": direction,
+				"type":      messageType,
+			}
+		}
+
 		gossipProto := deps.GossipService.Protocol(peer.Peer.ID)
 		if gossipProto == nil {
 			continue
@@ -117,6 +140,13 @@ func collectGossipPeers() {
 
 		gossipPeersDroppedPackets.With(getLabels("sent")).Set(float64(peer.DroppedSentPackets))
 
+		for messageType, bytes := range gossipProto.Metrics.ReceivedBytes.Snapshot() {
+			gossipPeersBytes.With(getBytesLabels("received", messageType)).Set(float64(bytes))
+		}
+		for messageType, bytes := range gossipProto.Metrics.SentBytes.Snapshot() {
+			gossipPeersBytes.With(getBytesLabels("sent", messageType)).Set(float64(bytes))
+		}
+
 		gossipPeersConnected.With(peerLabels).Set(0)
 		if peer.Connected {
 			gossipPeersConnected.With(peerLabels).Set(1)
@@ -0,0 +1,57 @@
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	inxUnauthenticatedRequests  prometheus.Gauge
+	inxPermissionDeniedRequests prometheus.Gauge
+	inxExtensionRequests        *prometheus.GaugeVec
+)
+
+func configureINX() {
+	inxUnauthenticatedRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "inx",
+			Name:      "unauthenticated_request_count",
+			Help:      "The amount of INX requests with a missing or invalid extension token.",
+		},
+	)
+
+	inxPermissionDeniedRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "inx",
+			Name:      "permission_denied_request_count",
+			Help:      "The amount of INX requests of extensions without the required scope.",
+		},
+	)
+
+	inxExtensionRequests = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "iota",
+			Subsystem: "inx",
+			Name:      "extension_request_count",
+			Help:      "The amount of authorized INX requests by extension.",
+		},
+		[]string{"extension"},
+	)
+
+	registry.MustRegister(inxUnauthenticatedRequests)
+	registry.MustRegister(inxPermissionDeniedRequests)
+	registry.MustRegister(inxExtensionRequests)
+
+	addCollect(collectINX)
+}
+
+func collectINX() {
+	inxUnauthenticatedRequests.Set(float64(deps.INXMetrics.UnauthenticatedRequestCounter.Load()))
+	inxPermissionDeniedRequests.Set(float64(deps.INXMetrics.PermissionDeniedRequestCounter.Load()))
+
+	inxExtensionRequests.Reset()
+	for extension, count := range deps.INXMetrics.ExtensionRequests() {
+		inxExtensionRequests.WithLabelValues(extension).Set(float64(count))
+	}
+}
@@ -86,6 +86,7 @@ type dependencies struct {
 	PrometheusEcho       *echo.Echo            `name:"prometheusEcho"`
 	ExternalMetricsProxy *restapi.DynamicProxy `name:"externalMetricsProxy"`
 	INXServer            *inx.INXServer        `optional:"true"`
+	INXMetrics           *metrics.INXMetrics   `optional:"true"`
 }
 
 func provide(c *dig.Container) {
@@ -136,6 +137,9 @@ func configure() {
 	if deps.NodeConfig.Bool(CfgPrometheusINX) && deps.INXServer != nil {
 		deps.INXServer.ConfigurePrometheus()
 		registry.MustRegister(grpc_prometheus.DefaultServerMetrics)
+		if deps.INXMetrics != nil {
+			configureINX()
+		}
 	}
 	if deps.NodeConfig.Bool(CfgPrometheusMigration) {
 		if deps.ReceiptService != nil {
@@ -23,6 +23,14 @@ const (
 	CfgRestAPILimitsMaxBodyLength = "restAPI.limits.bodyLength"
 	// the maximum number of results that may be returned by an endpoint
 	CfgRestAPILimitsMaxResults = "restAPI.limits.maxResults"
+	// the maximum number of milestones a historic ledger query on an address may go back from the ledger index (0 = unlimited)
+	CfgRestAPILimitsMaxHistoryDepth = "restAPI.limits.maxHistoryDepth"
+	// the maximum number of events that are buffered for a single event stream client before events get dropped
+	CfgRestAPIEventsClientBufferSize = "restAPI.events.clientBufferSize"
+	// the maximum number of clients that can subscribe to the event stream at the same time
+	CfgRestAPIEventsMaxClients = "restAPI.events.maxClients"
+	// the origins of browser clients that are allowed to connect to the event stream via websocket, besides the node's own origin. "*" allows all origins
+	CfgRestAPIEventsAllowedOrigins = "restAPI.events.allowedOrigins"
 )
 
 var params = &node.PluginParams{
@@ -42,6 +50,8 @@ var params = &node.PluginParams{
 					"/api/v2/addresses*",
 					"/api/v2/treasury",
 					"/api/v2/receipts*",
+					"/api/v2/events",
+					"/api/v2/proofs/validate",
 					"/api/plugins/participation/v1/events*",
 					"/api/plugins/participation/v1/outputs*",
 					"/api/plugins/participation/v1/addresses*",
@@ -56,6 +66,10 @@ var params = &node.PluginParams{
 			fs.Int(CfgRestAPIPoWWorkerCount, 1, "the amount of workers used for calculating PoW when issuing messages via API")
 			fs.String(CfgRestAPILimitsMaxBodyLength, "1M", "the maximum number of characters that the body of an API call may contain")
 			fs.Int(CfgRestAPILimitsMaxResults, 1000, "the maximum number of results that may be returned by an endpoint")
+			fs.Int(CfgRestAPILimitsMaxHistoryDepth, 8640, "the maximum number of milestones a historic ledger query on an address may go back from the ledger index (0 = unlimited)")
+			fs.Int(CfgRestAPIEventsClientBufferSize, 1000, "the maximum number of events that are buffered for a single event stream client before events get dropped")
+			fs.Int(CfgRestAPIEventsMaxClients, 100, "the maximum number of clients that can subscribe to the event stream at the same time")
+			fs.StringSlice(CfgRestAPIEventsAllowedOrigins, []string{}, "the origins of browser clients that are allowed to connect to the event stream via websocket, besides the node's own origin. \"*\" allows all origins")
 			return fs
 		}(),
 	},
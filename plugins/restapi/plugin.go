@@ -65,14 +65,16 @@ func initConfigPars(c *dig.Container) {
 
 	type cfgResult struct {
 		dig.Out
-		RestAPIBindAddress      string `name:"restAPIBindAddress"`
-		RestAPILimitsMaxResults int    `name:"restAPILimitsMaxResults"`
+		RestAPIBindAddress           string `name:"restAPIBindAddress"`
+		RestAPILimitsMaxResults      int    `name:"restAPILimitsMaxResults"`
+		RestAPILimitsMaxHistoryDepth int    `name:"restAPILimitsMaxHistoryDepth"`
 	}
 
 	if err := c.Provide(func(deps cfgDeps) cfgResult {
 		return cfgResult{
-			RestAPIBindAddress:      deps.NodeConfig.String(CfgRestAPIBindAddress),
-			RestAPILimitsMaxResults: deps.NodeConfig.Int(CfgRestAPILimitsMaxResults),
+			RestAPIBindAddress:           deps.NodeConfig.String(CfgRestAPIBindAddress),
+			RestAPILimitsMaxResults:      deps.NodeConfig.Int(CfgRestAPILimitsMaxResults),
+			RestAPILimitsMaxHistoryDepth: deps.NodeConfig.Int(CfgRestAPILimitsMaxHistoryDepth),
 		}
 	}); err != nil {
 		Plugin.LogPanic(err)
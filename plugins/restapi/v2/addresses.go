@@ -0,0 +1,190 @@
+package v2
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/gohornet/hornet/pkg/restapi"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func outputsByAddress(c echo.Context) (*addressOutputsResponse, error) {
+	address, err := restapi.ParseBech32AddressParam(c, deps.Bech32HRP)
+	if err != nil {
+		return nil, err
+	}
+
+	outputType, err := restapi.ParseOutputTypeQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := restapi.ParseAddressRoleQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	atMilestone, err := restapi.ParseAtMilestoneQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// we need to lock the ledger here to have the correct ledger index for the results.
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	if atMilestone != nil {
+		if role != nil {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "the %s query parameter is not supported for queries at a milestone index", restapi.QueryParameterAddressRole)
+		}
+		return outputsByAddressAtMilestoneWithoutLocking(address, outputType, *atMilestone, paginator)
+	}
+
+	if !deps.UTXOManager.AddressIndexEnabled() {
+		return nil, errors.WithMessagef(restapi.ErrServiceNotImplemented, "the address index is disabled, the outputs can only be queried with the %s query parameter", restapi.QueryParameterAtMilestone)
+	}
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading ledger index failed, error: %s", err)
+	}
+
+	outputIDs := make([]string, 0)
+	if err := deps.UTXOManager.ForEachUnspentOutputOnAddress(address, &utxo.AddressIndexFilter{Role: role, OutputType: outputType}, func(cursor []byte, output *utxo.Output) bool {
+		if !paginator.Add(cursor) {
+			return false
+		}
+
+		outputIDs = append(outputIDs, output.OutputID().ToHex())
+		return true
+	}, utxo.ReadLockLedger(false), utxo.IterateFromCursor(paginator.Cursor)); err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading outputs for address failed: %s, error: %s", address.Bech32(deps.Bech32HRP), err)
+	}
+
+	return &addressOutputsResponse{
+		PaginationResponse: paginator.Response(),
+		Address:            address.Bech32(deps.Bech32HRP),
+		LedgerIndex:        ledgerIndex,
+		Items:              outputIDs,
+	}, nil
+}
+
+// checkHistoryDepthWithoutLocking checks that a historic query on an address at the given milestone index
+// doesn't need to roll back more milestone diffs than allowed by the config.
+func checkHistoryDepthWithoutLocking(msIndex milestone.Index) error {
+
+	maxHistoryDepth := deps.RestAPILimitsMaxHistoryDepth
+	if maxHistoryDepth <= 0 {
+		return nil
+	}
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return errors.WithMessagef(echo.ErrInternalServerError, "reading ledger index failed, error: %s", err)
+	}
+
+	if msIndex < ledgerIndex && ledgerIndex-msIndex > milestone.Index(maxHistoryDepth) {
+		return errors.WithMessagef(restapi.ErrInvalidParameter, "milestone index %d is more than %d milestones older than the ledger index %d", msIndex, maxHistoryDepth, ledgerIndex)
+	}
+
+	return nil
+}
+
+// outputsByAddressAtMilestoneWithoutLocking returns the outputs that were unspent on the given address at the given milestone index.
+// The historic outputs are not contained in the address index, so they are collected and sorted by their output ID,
+// which is used as the cursor of the pagination.
+func outputsByAddressAtMilestoneWithoutLocking(address iotago.Address, outputType *iotago.OutputType, msIndex milestone.Index, paginator *restapi.Paginator) (*addressOutputsResponse, error) {
+
+	if err := checkHistoryDepthWithoutLocking(msIndex); err != nil {
+		return nil, err
+	}
+
+	outputIDs := make([]*iotago.OutputID, 0)
+	if err := deps.UTXOManager.ForEachUnspentOutputOnAddressAtMilestone(address, msIndex, func(output *utxo.Output) bool {
+		if outputType != nil && output.OutputType() != *outputType {
+			return true
+		}
+		outputIDs = append(outputIDs, output.OutputID())
+		return true
+	}, utxo.ReadLockLedger(false)); err != nil {
+		if errors.Is(err, utxo.ErrLedgerStateNotAvailable) {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "reading outputs for address failed: %s, error: %s", address.Bech32(deps.Bech32HRP), err)
+		}
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading outputs for address failed: %s, error: %s", address.Bech32(deps.Bech32HRP), err)
+	}
+
+	sort.Slice(outputIDs, func(i, j int) bool {
+		return bytes.Compare(outputIDs[i][:], outputIDs[j][:]) < 0
+	})
+
+	items := make([]string, 0)
+	for _, outputID := range outputIDs {
+		if paginator.Skip(outputID[:]) {
+			continue
+		}
+		if !paginator.Add(outputID[:]) {
+			break
+		}
+		items = append(items, outputID.ToHex())
+	}
+
+	return &addressOutputsResponse{
+		PaginationResponse: paginator.Response(),
+		Address:            address.Bech32(deps.Bech32HRP),
+		LedgerIndex:        msIndex,
+		Items:              items,
+	}, nil
+}
+
+func balanceByAddress(c echo.Context) (*addressBalanceResponse, error) {
+	address, err := restapi.ParseBech32AddressParam(c, deps.Bech32HRP)
+	if err != nil {
+		return nil, err
+	}
+
+	atMilestone, err := restapi.ParseAtMilestoneQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// we need to lock the ledger here to have the correct ledger index for the result.
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading ledger index failed, error: %s", err)
+	}
+
+	if atMilestone != nil {
+		if err := checkHistoryDepthWithoutLocking(*atMilestone); err != nil {
+			return nil, err
+		}
+		ledgerIndex = *atMilestone
+	}
+
+	balance, count, err := deps.UTXOManager.AddressBalanceAtMilestone(address, ledgerIndex, utxo.ReadLockLedger(false))
+	if err != nil {
+		if errors.Is(err, utxo.ErrLedgerStateNotAvailable) {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "reading balance for address failed: %s, error: %s", address.Bech32(deps.Bech32HRP), err)
+		}
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading balance for address failed: %s, error: %s", address.Bech32(deps.Bech32HRP), err)
+	}
+
+	return &addressBalanceResponse{
+		Address:     address.Bech32(deps.Bech32HRP),
+		Balance:     iotago.EncodeUint64(balance),
+		OutputCount: count,
+		LedgerIndex: ledgerIndex,
+	}, nil
+}
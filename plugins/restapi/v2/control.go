@@ -3,13 +3,16 @@ package v2
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/bytes"
 	"github.com/pkg/errors"
 
+	"github.com/gohornet/hornet/pkg/database"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/snapshot"
 )
 
 func pruneDatabase(c echo.Context) (*pruneDatabaseResponse, error) {
@@ -64,6 +67,97 @@ func pruneDatabase(c echo.Context) (*pruneDatabaseResponse, error) {
 	}, nil
 }
 
+func estimatePruneDatabase(c echo.Context) (*pruneDatabaseEstimateResponse, error) {
+
+	index, err := restapi.ParseMilestoneIndexQueryParam(c, restapi.QueryParameterIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	depth, err := restapi.ParseMilestoneIndexQueryParam(c, restapi.QueryParameterDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	targetDatabaseSize := c.QueryParam(restapi.QueryParameterTargetDatabaseSize)
+
+	paramsCount := 0
+	if index != nil {
+		paramsCount++
+	}
+	if depth != nil {
+		paramsCount++
+	}
+	if targetDatabaseSize != "" {
+		paramsCount++
+	}
+	if paramsCount != 1 {
+		return nil, errors.WithMessage(restapi.ErrInvalidParameter, "either index, depth or size has to be specified")
+	}
+
+	var estimate *snapshot.PruningEstimate
+
+	switch {
+	case index != nil:
+		estimate, err = deps.SnapshotManager.EstimatePruningByTargetIndex(c.Request().Context(), *index)
+
+	case depth != nil:
+		estimate, err = deps.SnapshotManager.EstimatePruningByDepth(c.Request().Context(), *depth)
+
+	default:
+		targetDatabaseSizeBytes, errParse := bytes.Parse(targetDatabaseSize)
+		if errParse != nil {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid targetDatabaseSize: %s, error: %s", targetDatabaseSize, errParse)
+		}
+
+		estimate, err = deps.SnapshotManager.EstimatePruningBySize(c.Request().Context(), targetDatabaseSizeBytes)
+	}
+	if err != nil {
+		if errors.Is(err, snapshot.ErrNoPruningNeeded) || errors.Is(err, snapshot.ErrNotEnoughHistory) {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "estimating pruning failed: %s", err)
+		}
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "estimating pruning failed: %s", err)
+	}
+
+	return &pruneDatabaseEstimateResponse{
+		StartIndex:                estimate.StartIndex,
+		TargetIndex:               estimate.TargetIndex,
+		MilestonesCount:           estimate.MilestonesCount,
+		MessagesCount:             estimate.MessagesCount,
+		UnreferencedMessagesCount: estimate.UnreferencedMessagesCount,
+		ChildrenCount:             estimate.ChildrenCount,
+		TangleBytes:               estimate.TangleBytes,
+		LedgerDiffsBytes:          estimate.LedgerDiffsBytes,
+		EstimatedFreedBytes:       estimate.EstimatedFreedBytes,
+	}, nil
+}
+
+func createDatabaseCheckpoint(_ echo.Context) (*createDatabaseCheckpointResponse, error) {
+
+	if deps.SnapshotManager.IsSnapshottingOrPruning() {
+		return nil, errors.WithMessage(echo.ErrServiceUnavailable, "node is already creating a snapshot or pruning is running")
+	}
+
+	// the checkpoints are stored next to the database folder
+	checkpointPath := filepath.Join(filepath.Dir(filepath.Clean(deps.DatabasePath)), "checkpoints", fmt.Sprintf("checkpoint_%d", time.Now().Unix()))
+
+	checkpointInfo, err := deps.SnapshotManager.CreateDatabaseCheckpoint(checkpointPath)
+	if err != nil {
+		if errors.Is(err, database.ErrCheckpointNotSupported) || errors.Is(err, snapshot.ErrCheckpointTargetExists) {
+			return nil, errors.WithMessagef(echo.ErrServiceUnavailable, "creating database checkpoint failed: %s", err)
+		}
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "creating database checkpoint failed: %s", err)
+	}
+
+	return &createDatabaseCheckpointResponse{
+		Path:            checkpointPath,
+		LedgerIndex:     checkpointInfo.LedgerIndex,
+		SnapshotIndex:   checkpointInfo.SnapshotIndex,
+		EntryPointIndex: checkpointInfo.EntryPointIndex,
+		PruningIndex:    checkpointInfo.PruningIndex,
+	}, nil
+}
+
 func createSnapshots(c echo.Context) (*createSnapshotsResponse, error) {
 
 	if deps.SnapshotManager.IsSnapshottingOrPruning() {
@@ -0,0 +1,473 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/storage"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/restapi/eventstream"
+	"github.com/iotaledger/hive.go/events"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// the interval in which keep alive messages are sent to event stream clients.
+	eventStreamKeepAliveInterval = 30 * time.Second
+	// the timeout for writing a message to an event stream websocket client.
+	eventStreamWriteTimeout = 5 * time.Second
+	// the maximum size of messages received from event stream websocket clients.
+	eventStreamMaxReadSize = 512
+)
+
+var (
+	eventBroker *eventstream.Broker
+
+	// the maximum number of events that are buffered for a single event stream client.
+	eventStreamClientBufferSize int
+
+	// the origins of browser clients that are allowed to connect via websocket, besides the node's own origin.
+	eventStreamAllowedOrigins map[string]struct{}
+
+	eventStreamUpgrader = &websocket.Upgrader{
+		HandshakeTimeout: eventStreamWriteTimeout,
+		CheckOrigin:      checkEventStreamOrigin,
+	}
+
+	// closures
+	onConfirmedMilestoneChanged *events.Closure
+	onMessageReferenced         *events.Closure
+	onLedgerUpdated             *events.Closure
+	onTreasuryMutated           *events.Closure
+	onNewReceipt                *events.Closure
+)
+
+func configureEventStream(clientBufferSize int, maxClients int, allowedOrigins []string) {
+
+	eventBroker = eventstream.NewBroker(maxClients)
+	eventStreamClientBufferSize = clientBufferSize
+
+	eventStreamAllowedOrigins = make(map[string]struct{})
+	for _, origin := range allowedOrigins {
+		eventStreamAllowedOrigins[strings.ToLower(origin)] = struct{}{}
+	}
+
+	onConfirmedMilestoneChanged = events.NewClosure(func(cachedMilestone *storage.CachedMilestone) {
+		defer cachedMilestone.Release(true) // milestone -1
+
+		if !eventBroker.HasSubscribers(eventstream.TopicMilestoneConfirmed) {
+			return
+		}
+
+		ms := cachedMilestone.Milestone()
+		eventBroker.Publish(&eventstream.Event{
+			Topic: eventstream.TopicMilestoneConfirmed,
+			Attributes: map[eventstream.Attribute][]string{
+				eventstream.AttributeMessageID: {ms.MessageID.ToHex()},
+			},
+			Data: &milestoneResponse{
+				Index:     uint32(ms.Index),
+				MessageID: ms.MessageID.ToHex(),
+				Time:      uint32(ms.Timestamp.Unix()),
+			},
+		})
+	})
+
+	onMessageReferenced = events.NewClosure(func(cachedMsgMeta *storage.CachedMetadata, _ milestone.Index, _ uint32) {
+		defer cachedMsgMeta.Release(true) // meta -1
+
+		if !eventBroker.HasSubscribers(eventstream.TopicMessageReferenced) {
+			return
+		}
+
+		metadata := cachedMsgMeta.Metadata()
+		response, err := newMessageMetadataResponse(metadata)
+		if err != nil {
+			Plugin.LogWarnf("creating message metadata for event stream failed: %s", err)
+			return
+		}
+
+		tags := []string{}
+		cachedMsg := deps.Storage.CachedMessageOrNil(metadata.MessageID()) // message +1
+		if cachedMsg != nil {
+			defer cachedMsg.Release(true) // message -1
+
+			taggedData := cachedMsg.Message().TaggedData()
+			if taggedData == nil {
+				taggedData = cachedMsg.Message().TransactionEssenceTaggedData()
+			}
+			if taggedData != nil {
+				tags = append(tags, iotago.EncodeHex(taggedData.Tag))
+			}
+		}
+
+		eventBroker.Publish(&eventstream.Event{
+			Topic: eventstream.TopicMessageReferenced,
+			Attributes: map[eventstream.Attribute][]string{
+				eventstream.AttributeMessageID: {response.MessageID},
+				eventstream.AttributeTag:       tags,
+			},
+			Data: response,
+		})
+	})
+
+	onLedgerUpdated = events.NewClosure(func(index milestone.Index, newOutputs utxo.Outputs, newSpents utxo.Spents) {
+		if !eventBroker.HasSubscribers(eventstream.TopicOutputs) {
+			return
+		}
+
+		for _, output := range newOutputs {
+			response, err := NewOutputResponse(output, index, false)
+			if err != nil {
+				Plugin.LogWarnf("creating output response for event stream failed: %s", err)
+				continue
+			}
+			eventBroker.Publish(&eventstream.Event{
+				Topic:      eventstream.TopicOutputs,
+				Attributes: outputEventAttributes(output),
+				Data:       response,
+			})
+		}
+
+		for _, spent := range newSpents {
+			response, err := NewSpentResponse(spent, index, false)
+			if err != nil {
+				Plugin.LogWarnf("creating output response for event stream failed: %s", err)
+				continue
+			}
+			eventBroker.Publish(&eventstream.Event{
+				Topic:      eventstream.TopicOutputs,
+				Attributes: outputEventAttributes(spent.Output()),
+				Data:       response,
+			})
+		}
+	})
+
+	onTreasuryMutated = events.NewClosure(func(_ milestone.Index, tuple *utxo.TreasuryMutationTuple) {
+		if !eventBroker.HasSubscribers(eventstream.TopicTreasury) {
+			return
+		}
+
+		eventBroker.Publish(&eventstream.Event{
+			Topic: eventstream.TopicTreasury,
+			Data: &treasuryResponse{
+				MilestoneID: iotago.EncodeHex(tuple.NewOutput.MilestoneID[:]),
+				Amount:      iotago.EncodeUint64(tuple.NewOutput.Amount),
+			},
+		})
+	})
+
+	onNewReceipt = events.NewClosure(func(receipt *iotago.ReceiptMilestoneOpt) {
+		if !eventBroker.HasSubscribers(eventstream.TopicReceipts) {
+			return
+		}
+
+		eventBroker.Publish(&eventstream.Event{
+			Topic: eventstream.TopicReceipts,
+			Data:  receipt,
+		})
+	})
+}
+
+func attachEventStreamEvents() {
+	deps.Tangle.Events.ConfirmedMilestoneChanged.Attach(onConfirmedMilestoneChanged)
+	deps.Tangle.Events.MessageReferenced.Attach(onMessageReferenced)
+	deps.Tangle.Events.LedgerUpdated.Attach(onLedgerUpdated)
+	deps.Tangle.Events.TreasuryMutated.Attach(onTreasuryMutated)
+	deps.Tangle.Events.NewReceipt.Attach(onNewReceipt)
+}
+
+func detachEventStreamEvents() {
+	deps.Tangle.Events.ConfirmedMilestoneChanged.Detach(onConfirmedMilestoneChanged)
+	deps.Tangle.Events.MessageReferenced.Detach(onMessageReferenced)
+	deps.Tangle.Events.LedgerUpdated.Detach(onLedgerUpdated)
+	deps.Tangle.Events.TreasuryMutated.Detach(onTreasuryMutated)
+	deps.Tangle.Events.NewReceipt.Detach(onNewReceipt)
+}
+
+// checkEventStreamOrigin checks the origin of websocket upgrade requests.
+// Requests without an origin are not sent by browsers and are always allowed,
+// browser clients need to have the same origin as the node or one of the configured allowed origins.
+func checkEventStreamOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+
+	if _, allowAll := eventStreamAllowedOrigins["*"]; allowAll {
+		return true
+	}
+
+	if _, allowed := eventStreamAllowedOrigins[strings.ToLower(origin)]; allowed {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(originURL.Host, r.Host)
+}
+
+// outputEventAttributes returns the attributes of an output event that can be used to filter the event stream.
+func outputEventAttributes(output *utxo.Output) map[eventstream.Attribute][]string {
+	addresses := []string{}
+	for _, address := range output.UnlockConditionAddresses() {
+		addresses = append(addresses, address.Bech32(deps.Bech32HRP))
+	}
+
+	return map[eventstream.Attribute][]string{
+		eventstream.AttributeMessageID: {output.MessageID().ToHex()},
+		eventstream.AttributeOutputID:  {output.OutputID().ToHex()},
+		eventstream.AttributeAddress:   addresses,
+	}
+}
+
+// queryParamValues returns all values of the given query parameter.
+// The parameter can be given multiple times and values can be separated by comma.
+func queryParamValues(c echo.Context, name string) []string {
+	var values []string
+	for _, param := range c.QueryParams()[name] {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); len(value) > 0 {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func parseEventStreamFilter(c echo.Context) (*eventstream.Filter, error) {
+
+	topics := queryParamValues(c, restapi.QueryParameterTopics)
+	if len(topics) == 0 {
+		topics = eventstream.Topics
+	}
+
+	for _, topic := range topics {
+		var known bool
+		for _, knownTopic := range eventstream.Topics {
+			if topic == knownTopic {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "unknown topic: %s", topic)
+		}
+	}
+
+	filter := eventstream.NewFilter(topics...)
+
+	for _, messageIDHex := range queryParamValues(c, restapi.QueryParameterMessageID) {
+		messageID, err := iotago.DecodeHex(messageIDHex)
+		if err != nil || len(messageID) != iotago.MessageIDLength {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid message ID: %s", messageIDHex)
+		}
+		filter.AddAttributeValue(eventstream.AttributeMessageID, iotago.EncodeHex(messageID))
+	}
+
+	for _, outputIDHex := range queryParamValues(c, restapi.QueryParameterOutputID) {
+		outputID, err := iotago.DecodeHex(outputIDHex)
+		if err != nil || len(outputID) != iotago.OutputIDLength {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid output ID: %s", outputIDHex)
+		}
+		filter.AddAttributeValue(eventstream.AttributeOutputID, iotago.EncodeHex(outputID))
+	}
+
+	for _, tagHex := range queryParamValues(c, restapi.QueryParameterTag) {
+		tag, err := iotago.DecodeHex(tagHex)
+		if err != nil || len(tag) > iotago.MaxTagLength {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid tag: %s", tagHex)
+		}
+		filter.AddAttributeValue(eventstream.AttributeTag, iotago.EncodeHex(tag))
+	}
+
+	for _, bech32Address := range queryParamValues(c, restapi.QueryParameterAddress) {
+		hrp, address, err := iotago.ParseBech32(bech32Address)
+		if err != nil || hrp != deps.Bech32HRP {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid address: %s", bech32Address)
+		}
+		filter.AddAttributeValue(eventstream.AttributeAddress, address.Bech32(deps.Bech32HRP))
+	}
+
+	return filter, nil
+}
+
+// eventStream subscribes to the event stream of the node.
+// Clients which request a websocket upgrade receive the events as websocket messages,
+// all other clients receive the events as server-sent events.
+func eventStream(c echo.Context) error {
+	filter, err := parseEventStreamFilter(c)
+	if err != nil {
+		return err
+	}
+
+	if websocket.IsWebSocketUpgrade(c.Request()) {
+		return serveEventStreamWebsocket(c, filter)
+	}
+	return serveEventStreamSSE(c, filter)
+}
+
+// nextEventStreamMessage returns the next message for the client of the given subscription.
+// If events were dropped since the last message, a notification about the dropped events is returned first.
+func nextEventStreamMessage(subscription *eventstream.Subscription, event *eventstream.Event) []*eventStreamMessage {
+	var messages []*eventStreamMessage
+	if dropped := subscription.TakeDropped(); dropped > 0 {
+		messages = append(messages, &eventStreamMessage{
+			Topic: eventstream.TopicDropped,
+			Data:  &droppedEventsResponse{Count: dropped},
+		})
+	}
+
+	return append(messages, &eventStreamMessage{
+		Topic: event.Topic,
+		Data:  event.Data,
+	})
+}
+
+// subscribeEventStream subscribes a new client to the event broker.
+func subscribeEventStream(filter *eventstream.Filter) (*eventstream.Subscription, error) {
+	subscription, err := eventBroker.Subscribe(filter, eventStreamClientBufferSize)
+	if err != nil {
+		if errors.Is(err, eventstream.ErrSubscriptionLimitReached) {
+			return nil, errors.WithMessage(echo.ErrServiceUnavailable, err.Error())
+		}
+		return nil, errors.WithMessage(echo.ErrInternalServerError, err.Error())
+	}
+	return subscription, nil
+}
+
+func serveEventStreamSSE(c echo.Context, filter *eventstream.Filter) error {
+
+	subscription, err := subscribeEventStream(filter)
+	if err != nil {
+		return err
+	}
+	defer eventBroker.Unsubscribe(subscription)
+
+	response := c.Response()
+	response.Header().Set(echo.HeaderContentType, "text/event-stream")
+	response.Header().Set(echo.HeaderCacheControl, "no-cache")
+	response.Header().Set(echo.HeaderConnection, "keep-alive")
+	response.WriteHeader(http.StatusOK)
+	response.Flush()
+
+	keepAliveTicker := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			// client was disconnected
+			return nil
+
+		case <-Plugin.Daemon().ContextStopped().Done():
+			// node is shutting down
+			return nil
+
+		case <-keepAliveTicker.C:
+			// comments are ignored by the clients, but keep the connection alive
+			if _, err := fmt.Fprint(response, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			response.Flush()
+
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+
+			for _, msg := range nextEventStreamMessage(subscription, event) {
+				data, err := json.Marshal(msg.Data)
+				if err != nil {
+					Plugin.LogWarnf("marshaling event stream message failed: %s", err)
+					continue
+				}
+
+				if _, err := fmt.Fprintf(response, "event: %s\ndata: %s\n\n", msg.Topic, data); err != nil {
+					// client was disconnected
+					return nil
+				}
+			}
+			response.Flush()
+		}
+	}
+}
+
+func serveEventStreamWebsocket(c echo.Context, filter *eventstream.Filter) error {
+
+	// subscribe before the upgrade, so that rejected clients receive a regular HTTP error
+	subscription, err := subscribeEventStream(filter)
+	if err != nil {
+		return err
+	}
+	defer eventBroker.Unsubscribe(subscription)
+
+	conn, err := eventStreamUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// the upgrader already replied with an error to the client
+		return nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	// the client is not expected to send messages, but we need to read to process control messages
+	// and to notice that the connection was closed.
+	connClosed := make(chan struct{})
+	conn.SetReadLimit(eventStreamMaxReadSize)
+	go func() {
+		defer close(connClosed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAliveTicker := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAliveTicker.Stop()
+
+	for {
+		select {
+		case <-connClosed:
+			// client was disconnected
+			return nil
+
+		case <-Plugin.Daemon().ContextStopped().Done():
+			// node is shutting down
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "node is shutting down"), time.Now().Add(eventStreamWriteTimeout))
+			return nil
+
+		case <-keepAliveTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventStreamWriteTimeout)); err != nil {
+				return nil
+			}
+
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+
+			for _, msg := range nextEventStreamMessage(subscription, event) {
+				if err := conn.SetWriteDeadline(time.Now().Add(eventStreamWriteTimeout)); err != nil {
+					return nil
+				}
+				if err := conn.WriteJSON(msg); err != nil {
+					// client was disconnected or is too slow
+					return nil
+				}
+			}
+		}
+	}
+}
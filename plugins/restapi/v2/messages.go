@@ -8,6 +8,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/model/hornet"
 	"github.com/gohornet/hornet/pkg/model/milestone"
 	"github.com/gohornet/hornet/pkg/model/storage"
 	"github.com/gohornet/hornet/pkg/restapi"
@@ -32,13 +33,16 @@ func messageMetadataByID(c echo.Context) (*messageMetadataResponse, error) {
 		return nil, err
 	}
 
-	cachedMsgMeta := deps.Storage.CachedMessageMetadataOrNil(messageID)
-	if cachedMsgMeta == nil {
+	// pruned messages are served from the cold storage on archive nodes
+	metadata := deps.Storage.MessageMetadataWithFallbackOrNil(messageID)
+	if metadata == nil {
 		return nil, errors.WithMessagef(echo.ErrNotFound, "message not found: %s", messageID.ToHex())
 	}
-	defer cachedMsgMeta.Release(true) // meta -1
 
-	metadata := cachedMsgMeta.Metadata()
+	return newMessageMetadataResponse(metadata)
+}
+
+func newMessageMetadataResponse(metadata *storage.MessageMetadata) (*messageMetadataResponse, error) {
 
 	var referencedByMilestone *milestone.Index = nil
 	referenced, referencedIndex := metadata.ReferencedWithIndex()
@@ -74,7 +78,7 @@ func messageMetadataByID(c echo.Context) (*messageMetadataResponse, error) {
 		// determine info about the quality of the tip if not referenced
 		cmi := deps.SyncManager.ConfirmedMilestoneIndex()
 
-		tipScore, err := deps.TipScoreCalculator.TipScore(Plugin.Daemon().ContextStopped(), cachedMsgMeta.Metadata().MessageID(), cmi)
+		tipScore, err := deps.TipScoreCalculator.TipScore(Plugin.Daemon().ContextStopped(), metadata.MessageID(), cmi)
 		if err != nil {
 			if errors.Is(err, common.ErrOperationAborted) {
 				return nil, errors.WithMessage(echo.ErrServiceUnavailable, err.Error())
@@ -112,13 +116,13 @@ func storageMessageByID(c echo.Context) (*storage.Message, error) {
 		return nil, err
 	}
 
-	cachedMsg := deps.Storage.CachedMessageOrNil(messageID) // message +1
-	if cachedMsg == nil {
+	// pruned messages are served from the cold storage on archive nodes
+	message := deps.Storage.MessageWithFallbackOrNil(messageID)
+	if message == nil {
 		return nil, errors.WithMessagef(echo.ErrNotFound, "message not found: %s", messageID.ToHex())
 	}
-	defer cachedMsg.Release(true) // message -1
 
-	return cachedMsg.Message(), nil
+	return message, nil
 }
 
 func messageByID(c echo.Context) (*iotago.Message, error) {
@@ -144,17 +148,28 @@ func childrenIDsByID(c echo.Context) (*childrenResponse, error) {
 		return nil, err
 	}
 
-	maxResults := deps.RestAPILimitsMaxResults
-	childrenMessageIDs, err := deps.Storage.ChildrenMessageIDs(messageID, storage.WithIteratorMaxIterations(maxResults))
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
 	if err != nil {
+		return nil, err
+	}
+
+	children := make([]string, 0)
+	if err := deps.Storage.ForEachChildMessageIDFromCursorWithFallback(messageID, paginator.Cursor, func(childMessageID hornet.MessageID) bool {
+		if !paginator.Add(childMessageID) {
+			return false
+		}
+		children = append(children, childMessageID.ToHex())
+		return true
+	}); err != nil {
 		return nil, errors.WithMessage(echo.ErrInternalServerError, err.Error())
 	}
 
 	return &childrenResponse{
-		MessageID:  messageID.ToHex(),
-		MaxResults: uint32(maxResults),
-		Count:      uint32(len(childrenMessageIDs)),
-		Children:   childrenMessageIDs.ToHex(),
+		PaginationResponse: paginator.Response(),
+		MessageID:          messageID.ToHex(),
+		MaxResults:         uint32(paginator.PageSize),
+		Count:              uint32(len(children)),
+		Children:           children,
 	}, nil
 }
 
@@ -164,6 +179,42 @@ func sendMessage(c echo.Context) (*messageCreatedResponse, error) {
 		return nil, errors.WithMessage(echo.ErrServiceUnavailable, "node is not synced")
 	}
 
+	msg, err := messageFromRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	switch payload := msg.Payload.(type) {
+	case *iotago.Transaction:
+		if payload.Essence.NetworkID != deps.NetworkID {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid payload, error: wrong networkID: %d", payload.Essence.NetworkID)
+		}
+	default:
+	}
+
+	mergedCtx, mergedCtxCancel := utils.MergeContexts(c.Request().Context(), Plugin.Daemon().ContextStopped())
+	defer mergedCtxCancel()
+
+	messageID, err := attacher.AttachMessage(mergedCtx, msg)
+	if err != nil {
+		if errors.Is(err, tangle.ErrMessageAttacherAttachingNotPossible) {
+			return nil, errors.WithMessage(echo.ErrServiceUnavailable, err.Error())
+		}
+		if errors.Is(err, tangle.ErrMessageAttacherInvalidMessage) {
+			return nil, errors.WithMessage(restapi.ErrInvalidParameter, err.Error())
+		}
+		return nil, err
+	}
+
+	return &messageCreatedResponse{
+		MessageID: messageID.ToHex(),
+	}, nil
+}
+
+// messageFromRequest parses the message in the body of the request based on the given "Content-Type" header.
+// The message is not validated, the parents might need to be set.
+func messageFromRequest(c echo.Context) (*iotago.Message, error) {
+
 	mimeType, err := restapi.GetRequestContentType(c, restapi.MIMEApplicationVendorIOTASerializerV1, echo.MIMEApplicationJSON)
 	if err != nil {
 		return nil, err
@@ -201,29 +252,5 @@ func sendMessage(c echo.Context) (*messageCreatedResponse, error) {
 		return nil, errors.WithMessage(restapi.ErrInvalidParameter, "invalid message, error: protocolVersion invalid")
 	}
 
-	switch payload := msg.Payload.(type) {
-	case *iotago.Transaction:
-		if payload.Essence.NetworkID != deps.NetworkID {
-			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid payload, error: wrong networkID: %d", payload.Essence.NetworkID)
-		}
-	default:
-	}
-
-	mergedCtx, mergedCtxCancel := utils.MergeContexts(c.Request().Context(), Plugin.Daemon().ContextStopped())
-	defer mergedCtxCancel()
-
-	messageID, err := attacher.AttachMessage(mergedCtx, msg)
-	if err != nil {
-		if errors.Is(err, tangle.ErrMessageAttacherAttachingNotPossible) {
-			return nil, errors.WithMessage(echo.ErrServiceUnavailable, err.Error())
-		}
-		if errors.Is(err, tangle.ErrMessageAttacherInvalidMessage) {
-			return nil, errors.WithMessage(restapi.ErrInvalidParameter, err.Error())
-		}
-		return nil, err
-	}
-
-	return &messageCreatedResponse{
-		MessageID: messageID.ToHex(),
-	}, nil
+	return msg, nil
 }
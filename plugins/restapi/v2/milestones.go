@@ -1,12 +1,23 @@
 package v2
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 
 	"github.com/gohornet/hornet/pkg/restapi"
 
 	"github.com/iotaledger/hive.go/kvstore"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+const (
+	// the cursor marker of the created outputs of a milestone.
+	utxoChangeCursorCreated byte = 0
+	// the cursor marker of the consumed outputs of a milestone.
+	utxoChangeCursorConsumed byte = 1
 )
 
 func milestoneByIndex(c echo.Context) (*milestoneResponse, error) {
@@ -16,16 +27,16 @@ func milestoneByIndex(c echo.Context) (*milestoneResponse, error) {
 		return nil, err
 	}
 
-	cachedMilestone := deps.Storage.CachedMilestoneOrNil(msIndex) // milestone +1
-	if cachedMilestone == nil {
+	// pruned milestones are served from the cold storage on archive nodes
+	ms := deps.Storage.MilestoneWithFallbackOrNil(msIndex)
+	if ms == nil {
 		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone not found: %d", msIndex)
 	}
-	defer cachedMilestone.Release(true) // milestone -1
 
 	return &milestoneResponse{
-		Index:     uint32(cachedMilestone.Milestone().Index),
-		MessageID: cachedMilestone.Milestone().MessageID.ToHex(),
-		Time:      uint32(cachedMilestone.Milestone().Timestamp.Unix()),
+		Index:     uint32(ms.Index),
+		MessageID: ms.MessageID.ToHex(),
+		Time:      uint32(ms.Timestamp.Unix()),
 	}, nil
 }
 
@@ -36,7 +47,7 @@ func milestoneUTXOChangesByIndex(c echo.Context) (*milestoneUTXOChangesResponse,
 		return nil, err
 	}
 
-	diff, err := deps.UTXOManager.MilestoneDiffWithoutLocking(msIndex)
+	diff, err := deps.Storage.MilestoneDiffWithFallback(msIndex)
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
 			return nil, errors.WithMessagef(echo.ErrNotFound, "can't load milestone diff for index: %d, error: %s", msIndex, err)
@@ -44,20 +55,52 @@ func milestoneUTXOChangesByIndex(c echo.Context) (*milestoneUTXOChangesResponse,
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "can't load milestone diff for index: %d, error: %s", msIndex, err)
 	}
 
-	createdOutputs := make([]string, len(diff.Outputs))
-	consumedOutputs := make([]string, len(diff.Spents))
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
 
+	createdOutputIDs := make(iotago.OutputIDs, len(diff.Outputs))
 	for i, output := range diff.Outputs {
-		createdOutputs[i] = output.OutputID().ToHex()
+		createdOutputIDs[i] = *output.OutputID()
+	}
+
+	consumedOutputIDs := make(iotago.OutputIDs, len(diff.Spents))
+	for i, spent := range diff.Spents {
+		consumedOutputIDs[i] = *spent.OutputID()
+	}
+
+	createdOutputs := make([]string, 0)
+	consumedOutputs := make([]string, 0)
+
+	collectOutputIDs := func(marker byte, outputIDs iotago.OutputIDs, result *[]string) bool {
+		sort.Slice(outputIDs, func(i, j int) bool {
+			return bytes.Compare(outputIDs[i][:], outputIDs[j][:]) < 0
+		})
+
+		for i := range outputIDs {
+			// the cursor consists of the list marker and the output ID,
+			// so the created outputs are listed before the consumed outputs.
+			cursor := append([]byte{marker}, outputIDs[i][:]...)
+			if paginator.Skip(cursor) {
+				continue
+			}
+			if !paginator.Add(cursor) {
+				return false
+			}
+			*result = append(*result, outputIDs[i].ToHex())
+		}
+		return true
 	}
 
-	for i, output := range diff.Spents {
-		consumedOutputs[i] = output.OutputID().ToHex()
+	if collectOutputIDs(utxoChangeCursorCreated, createdOutputIDs, &createdOutputs) {
+		collectOutputIDs(utxoChangeCursorConsumed, consumedOutputIDs, &consumedOutputs)
 	}
 
 	return &milestoneUTXOChangesResponse{
-		Index:           uint32(msIndex),
-		CreatedOutputs:  createdOutputs,
-		ConsumedOutputs: consumedOutputs,
+		PaginationResponse: paginator.Response(),
+		Index:              uint32(msIndex),
+		CreatedOutputs:     createdOutputs,
+		ConsumedOutputs:    consumedOutputs,
 	}, nil
 }
@@ -1,6 +1,8 @@
 package v2
 
 import (
+	"sort"
+
 	"github.com/labstack/echo/v4"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/multiformats/go-multiaddr"
@@ -36,6 +38,7 @@ func WrapInfoSnapshot(info *p2p.PeerInfoSnapshot) *PeerResponse {
 		Alias:          alias,
 		Relation:       info.Relation,
 		Connected:      info.Connected,
+		Score:          info.Score,
 		Gossip:         gossipInfo,
 	}
 }
@@ -66,14 +69,45 @@ func removePeer(c echo.Context) error {
 	return deps.PeeringManager.DisconnectPeer(peerID, errors.New("peer was removed via API"))
 }
 
-//nolint:unparam // even if the error is never used, the structure of all routes should be the same
-func listPeers(_ echo.Context) ([]*PeerResponse, error) {
+func listPeers(c echo.Context) (interface{}, error) {
 	peerInfos := deps.PeeringManager.PeerInfoSnapshots()
-	results := make([]*PeerResponse, len(peerInfos))
-	for i, info := range peerInfos {
-		results[i] = WrapInfoSnapshot(info)
+
+	if len(c.QueryParam(restapi.QueryParameterPageSize)) == 0 && len(c.QueryParam(restapi.QueryParameterCursor)) == 0 {
+		// the complete list is returned as a plain array if no pagination was requested,
+		// to keep the response compatible with existing clients.
+		results := make([]*PeerResponse, len(peerInfos))
+		for i, info := range peerInfos {
+			results[i] = WrapInfoSnapshot(info)
+		}
+		return results, nil
 	}
-	return results, nil
+
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// the peers are ordered by their identifier, which is used as the cursor of the pagination.
+	sort.Slice(peerInfos, func(i, j int) bool {
+		return peerInfos[i].Peer.ID < peerInfos[j].Peer.ID
+	})
+
+	results := make([]*PeerResponse, 0)
+	for _, info := range peerInfos {
+		cursor := []byte(info.Peer.ID)
+		if paginator.Skip(cursor) {
+			continue
+		}
+		if !paginator.Add(cursor) {
+			break
+		}
+		results = append(results, WrapInfoSnapshot(info))
+	}
+
+	return &peersResponse{
+		PaginationResponse: paginator.Response(),
+		Peers:              results,
+	}, nil
 }
 
 func addPeer(c echo.Context) (*PeerResponse, error) {
@@ -0,0 +1,82 @@
+package v2
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/snapshot"
+)
+
+func pinMessage(c echo.Context) error {
+
+	messageID, err := restapi.ParseMessageIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	if err := deps.SnapshotManager.PinMessage(messageID); err != nil {
+		if errors.Is(err, common.ErrMessageNotFound) {
+			return errors.WithMessagef(echo.ErrNotFound, "message not found: %s", messageID.ToHex())
+		}
+		return errors.WithMessagef(echo.ErrInternalServerError, "pinning message failed: %s", err)
+	}
+
+	return nil
+}
+
+func unpinMessage(c echo.Context) error {
+
+	messageID, err := restapi.ParseMessageIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	if err := deps.SnapshotManager.UnpinMessage(messageID); err != nil {
+		if errors.Is(err, snapshot.ErrMessageNotPinned) {
+			return errors.WithMessagef(echo.ErrNotFound, "message not pinned: %s", messageID.ToHex())
+		}
+		return errors.WithMessagef(echo.ErrInternalServerError, "unpinning message failed: %s", err)
+	}
+
+	return nil
+}
+
+func pinnedMessageIDs(c echo.Context) (*pinsResponse, error) {
+
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs, err := deps.SnapshotManager.PinnedMessageIDs()
+	if err != nil {
+		return nil, errors.WithMessage(echo.ErrInternalServerError, err.Error())
+	}
+
+	sort.Slice(messageIDs, func(i, j int) bool {
+		return bytes.Compare(messageIDs[i], messageIDs[j]) < 0
+	})
+
+	pins := make([]string, 0)
+	for _, messageID := range messageIDs {
+		if paginator.Skip(messageID) {
+			continue
+		}
+		if !paginator.Add(messageID) {
+			break
+		}
+		pins = append(pins, messageID.ToHex())
+	}
+
+	return &pinsResponse{
+		PaginationResponse: paginator.Response(),
+		MaxResults:         uint32(paginator.PageSize),
+		Count:              uint32(len(pins)),
+		MessageIDs:         pins,
+	}, nil
+}
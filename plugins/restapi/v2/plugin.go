@@ -1,6 +1,7 @@
 package v2
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"go.uber.org/dig"
 
 	"github.com/gohornet/hornet/pkg/app"
+	"github.com/gohornet/hornet/pkg/keymanager"
 	"github.com/gohornet/hornet/pkg/model/storage"
 	"github.com/gohornet/hornet/pkg/model/syncmanager"
 	"github.com/gohornet/hornet/pkg/model/utxo"
@@ -17,6 +19,7 @@ import (
 	"github.com/gohornet/hornet/pkg/pow"
 	"github.com/gohornet/hornet/pkg/protocol/gossip"
 	restapipkg "github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/shutdown"
 	"github.com/gohornet/hornet/pkg/snapshot"
 	"github.com/gohornet/hornet/pkg/tangle"
 	"github.com/gohornet/hornet/pkg/tipselect"
@@ -45,9 +48,23 @@ const (
 	RouteMessageMetadata = "/messages/:" + restapipkg.ParameterMessageID + "/metadata"
 
 	// RouteMessageChildren is the route for getting message IDs of the children of a message, identified by its messageID.
-	// GET returns the message IDs of all children.
+	// GET returns the message IDs of all children (paginated).
 	RouteMessageChildren = "/messages/:" + restapipkg.ParameterMessageID + "/children"
 
+	// RouteMessageProof is the route for getting the proof of inclusion of a message, identified by its messageID.
+	// GET returns the milestone that referenced the message, the message and the merkle audit path of the message in the confirmed merkle root of the milestone.
+	RouteMessageProof = "/messages/:" + restapipkg.ParameterMessageID + "/proof"
+
+	// RoutePin is the route for pinning messages, identified by their messageID.
+	// Pinned messages, their metadata and the milestones that referenced them are kept by the pruning.
+	// POST pins the message.
+	// DELETE unpins the message.
+	RoutePin = "/pins/:" + restapipkg.ParameterMessageID
+
+	// RoutePins is the route for getting all pinned messages.
+	// GET returns the message IDs of all pinned messages (paginated).
+	RoutePins = "/pins"
+
 	// RouteMessages is the route for creating new messages.
 	// POST creates a single new message and returns the new message ID.
 	// The message is parsed based on the given type in the request "Content-Type" header.
@@ -61,40 +78,66 @@ const (
 	// MIMEVendorIOTASerializer => bytes
 	RouteTransactionsIncludedMessage = "/transactions/:" + restapipkg.ParameterTransactionID + "/included-message"
 
+	// RouteTransactionsSimulate is the route to validate a transaction against the current ledger state without attaching it.
+	// POST simulates the white-flag validation of the transaction payload of the given message
+	// and returns the ledger inclusion state and the conflict reason.
+	// "Content-Type" header:
+	// MIMEApplicationJSON => json
+	// MIMEVendorIOTASerializer => bytes
+	RouteTransactionsSimulate = "/transactions/simulate"
+
 	// RouteMilestone is the route for getting a milestone by its milestoneIndex.
 	// GET returns the milestone.
 	RouteMilestone = "/milestones/:" + restapipkg.ParameterMilestoneIndex
 
 	// RouteMilestoneUTXOChanges is the route for getting all UTXO changes of a milestone by its milestoneIndex.
-	// GET returns the output IDs of all UTXO changes.
+	// GET returns the output IDs of all UTXO changes (paginated).
 	RouteMilestoneUTXOChanges = "/milestones/:" + restapipkg.ParameterMilestoneIndex + "/utxo-changes"
 
 	// RouteOutput is the route for getting an output by its outputID (transactionHash + outputIndex).
 	// GET returns the output based on the given type in the request "Accept" header.
+	// The optional "atMilestone" query parameter returns the state of the output at a past milestone index.
 	// MIMEApplicationJSON => json
 	// MIMEVendorIOTASerializer => bytes
 	RouteOutput = "/outputs/:" + restapipkg.ParameterOutputID
 
 	// RouteOutputMetadata is the route for getting output metadata by its outputID (transactionHash + outputIndex) without getting the data again.
 	// GET returns the output metadata.
+	// The optional "atMilestone" query parameter returns the state of the output at a past milestone index.
 	RouteOutputMetadata = "/outputs/:" + restapipkg.ParameterOutputID + "/metadata"
 
+	// RouteAddressOutputs is the route for getting the unspent outputs which reference the given bech32 address in their unlock conditions.
+	// GET returns the output IDs of the unspent outputs (paginated).
+	// The optional "atMilestone" query parameter returns the outputs owned by the address that were unspent at a past milestone index.
+	// Without the address index, the outputs can only be queried with the "atMilestone" query parameter.
+	RouteAddressOutputs = "/addresses/:" + restapipkg.ParameterAddress + "/outputs"
+
+	// RouteAddressBalance is the route for getting the balance of the given bech32 address.
+	// GET returns the sum of the deposits of the unspent outputs which are owned by the address (AddressUnlockCondition).
+	// The optional "atMilestone" query parameter returns the balance at a past milestone index.
+	// Without the address index, all unspent outputs are checked to calculate the balance.
+	RouteAddressBalance = "/addresses/:" + restapipkg.ParameterAddress + "/balance"
+
 	// RouteTreasury is the route for getting the current treasury output.
 	// GET returns the treasury.
 	RouteTreasury = "/treasury"
 
 	// RouteReceipts is the route for getting all persisted receipts on a node.
-	// GET returns the receipts.
+	// GET returns the receipts (paginated).
 	RouteReceipts = "/receipts"
 
 	// RouteReceiptsMigratedAtIndex is the route for getting all persisted receipts for a given migrated at index on a node.
-	// GET returns the receipts for the given migrated at index.
+	// GET returns the receipts for the given migrated at index (paginated).
 	RouteReceiptsMigratedAtIndex = "/receipts/:" + restapipkg.ParameterMilestoneIndex
 
 	// RouteComputeWhiteFlagMutations is the route to compute the white flag mutations for the cone of the given parents.
 	// POST computes the white flag mutations.
 	RouteComputeWhiteFlagMutations = "/whiteflag"
 
+	// RouteProofsValidate is the route to validate a proof of inclusion of a message.
+	// POST checks the milestone signatures and the merkle audit path of the proof.
+	RouteProofsValidate = "/proofs/validate"
+
 	// RoutePeer is the route for getting peers by their peerID.
 	// GET returns the peer
 	// DELETE deletes the peer.
@@ -102,13 +145,29 @@ const (
 
 	// RoutePeers is the route for getting all peers of the node.
 	// GET returns a list of all peers.
+	// The list is paginated if a page size or cursor is given (query parameters: "pageSize", "cursor").
 	// POST adds a new peer.
 	RoutePeers = "/peers"
 
+	// RouteEvents is the route to subscribe to the event stream of the node.
+	// GET streams the events as server-sent events, or as websocket messages if a websocket upgrade is requested.
+	// The events can be filtered by topic, message ID, output ID, tag prefix and address (query parameters: "topics", "messageId", "outputId", "tag", "address").
+	RouteEvents = "/events"
+
 	// RouteControlDatabasePrune is the control route to manually prune the database.
 	// POST prunes the database.
 	RouteControlDatabasePrune = "/control/database/prune"
 
+	// RouteControlDatabasePruneEstimate is the control route to estimate the effects of pruning the database.
+	// GET returns the milestone range, the amount of messages and the sizes that would be pruned.
+	// Either the target index, the depth or the target database size has to be given (query parameters: "index", "depth", "targetDatabaseSize").
+	// Returns a bad request error if no pruning is needed or there is not enough history.
+	RouteControlDatabasePruneEstimate = "/control/database/prune/estimate"
+
+	// RouteControlDatabaseCheckpoint is the control route to create a checkpoint of the databases while the node is running.
+	// POST creates a checkpoint of the tangle and UTXO databases.
+	RouteControlDatabaseCheckpoint = "/control/database/checkpoint"
+
 	// RouteControlSnapshotsCreate is the control route to manually create a snapshot files.
 	// POST creates a snapshot (full, delta or both).
 	RouteControlSnapshotsCreate = "/control/snapshots/create"
@@ -121,6 +180,7 @@ func init() {
 			Name:      "RestAPIV2",
 			DepsFunc:  func(cDeps dependencies) { deps = cDeps },
 			Configure: configure,
+			Run:       run,
 		},
 	}
 }
@@ -145,6 +205,7 @@ type dependencies struct {
 	PeeringManager                        *p2p.Manager
 	GossipService                         *gossip.Service
 	UTXOManager                           *utxo.Manager
+	KeyManager                            *keymanager.KeyManager
 	PoWHandler                            *pow.Handler
 	SnapshotManager                       *snapshot.SnapshotManager
 	AppInfo                               *app.AppInfo
@@ -160,8 +221,11 @@ type dependencies struct {
 	MinPoWScore                           float64                    `name:"minPoWScore"`
 	Bech32HRP                             iotago.NetworkPrefix       `name:"bech32HRP"`
 	RestAPILimitsMaxResults               int                        `name:"restAPILimitsMaxResults"`
+	RestAPILimitsMaxHistoryDepth          int                        `name:"restAPILimitsMaxHistoryDepth"`
+	MilestonePublicKeyCount               int                        `name:"milestonePublicKeyCount"`
 	SnapshotsFullPath                     string                     `name:"snapshotsFullPath"`
 	SnapshotsDeltaPath                    string                     `name:"snapshotsDeltaPath"`
+	DatabasePath                          string                     `name:"databasePath"`
 	TipSelector                           *tipselect.TipSelector     `optional:"true"`
 	Echo                                  *echo.Echo                 `optional:"true"`
 	RestPluginManager                     *restapi.RestPluginManager `optional:"true"`
@@ -252,6 +316,15 @@ func configure() {
 		return restapipkg.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.GET(RouteMessageProof, func(c echo.Context) error {
+		resp, err := messageProofByID(c)
+		if err != nil {
+			return err
+		}
+
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.POST(RouteMessages, func(c echo.Context) error {
 		resp, err := sendMessage(c)
 		if err != nil {
@@ -285,6 +358,14 @@ func configure() {
 		}
 	})
 
+	routeGroup.POST(RouteTransactionsSimulate, func(c echo.Context) error {
+		resp, err := simulateTransaction(c)
+		if err != nil {
+			return err
+		}
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.GET(RouteMilestone, func(c echo.Context) error {
 		resp, err := milestoneByIndex(c)
 		if err != nil {
@@ -333,6 +414,27 @@ func configure() {
 		return restapipkg.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	// the address api calls at a milestone index fall back to checking all unspent outputs if the address index is disabled
+	if deps.UTXOManager.AddressIndexEnabled() {
+		AddFeature("AddressIndex")
+	}
+
+	routeGroup.GET(RouteAddressOutputs, func(c echo.Context) error {
+		resp, err := outputsByAddress(c)
+		if err != nil {
+			return err
+		}
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.GET(RouteAddressBalance, func(c echo.Context) error {
+		resp, err := balanceByAddress(c)
+		if err != nil {
+			return err
+		}
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.GET(RouteTreasury, func(c echo.Context) error {
 		resp, err := treasury(c)
 		if err != nil {
@@ -357,6 +459,28 @@ func configure() {
 		return restapipkg.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.POST(RoutePin, func(c echo.Context) error {
+		if err := pinMessage(c); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	routeGroup.DELETE(RoutePin, func(c echo.Context) error {
+		if err := unpinMessage(c); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	routeGroup.GET(RoutePins, func(c echo.Context) error {
+		resp, err := pinnedMessageIDs(c)
+		if err != nil {
+			return err
+		}
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.GET(RoutePeer, func(c echo.Context) error {
 		resp, err := getPeer(c)
 		if err != nil {
@@ -389,6 +513,15 @@ func configure() {
 		return restapipkg.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.POST(RouteProofsValidate, func(c echo.Context) error {
+		resp, err := validateProof(c)
+		if err != nil {
+			return err
+		}
+
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.POST(RoutePeers, func(c echo.Context) error {
 		resp, err := addPeer(c)
 		if err != nil {
@@ -397,6 +530,14 @@ func configure() {
 		return restapipkg.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	configureEventStream(
+		deps.NodeConfig.Int(restapi.CfgRestAPIEventsClientBufferSize),
+		deps.NodeConfig.Int(restapi.CfgRestAPIEventsMaxClients),
+		deps.NodeConfig.Strings(restapi.CfgRestAPIEventsAllowedOrigins),
+	)
+
+	routeGroup.GET(RouteEvents, eventStream)
+
 	routeGroup.POST(RouteControlDatabasePrune, func(c echo.Context) error {
 		resp, err := pruneDatabase(c)
 		if err != nil {
@@ -405,6 +546,22 @@ func configure() {
 		return restapipkg.JSONResponse(c, http.StatusOK, resp)
 	})
 
+	routeGroup.GET(RouteControlDatabasePruneEstimate, func(c echo.Context) error {
+		resp, err := estimatePruneDatabase(c)
+		if err != nil {
+			return err
+		}
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
+	routeGroup.POST(RouteControlDatabaseCheckpoint, func(c echo.Context) error {
+		resp, err := createDatabaseCheckpoint(c)
+		if err != nil {
+			return err
+		}
+		return restapipkg.JSONResponse(c, http.StatusOK, resp)
+	})
+
 	routeGroup.POST(RouteControlSnapshotsCreate, func(c echo.Context) error {
 		resp, err := createSnapshots(c)
 		if err != nil {
@@ -414,6 +571,16 @@ func configure() {
 	})
 }
 
+func run() {
+	if err := Plugin.Daemon().BackgroundWorker("RestAPIV2[EventStream]", func(ctx context.Context) {
+		attachEventStreamEvents()
+		<-ctx.Done()
+		detachEventStreamEvents()
+	}, shutdown.PriorityRestAPI); err != nil {
+		Plugin.LogPanicf("failed to start worker: %s", err)
+	}
+}
+
 // AddFeature adds a feature to the RouteInfo endpoint.
 func AddFeature(feature string) {
 	features = append(features, feature)
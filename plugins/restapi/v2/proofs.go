@@ -0,0 +1,124 @@
+package v2
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/common"
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/whiteflag"
+	iotago "github.com/iotaledger/iota.go/v3"
+)
+
+func messageProofByID(c echo.Context) (*MessageProof, error) {
+
+	messageID, err := restapi.ParseMessageIDParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedMsg := deps.Storage.CachedMessageOrNil(messageID) // message +1
+	if cachedMsg == nil {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "message not found: %s", messageID.ToHex())
+	}
+	defer cachedMsg.Release(true) // message -1
+
+	referenced, msIndex := cachedMsg.Metadata().ReferencedWithIndex()
+	if !referenced {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "message not referenced by a milestone yet: %s", messageID.ToHex())
+	}
+
+	cachedMilestoneMsg := deps.Storage.MilestoneCachedMessageOrNil(msIndex) // message +1
+	if cachedMilestoneMsg == nil {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "milestone not found: %d", msIndex)
+	}
+	defer cachedMilestoneMsg.Release(true) // message -1
+
+	messagesReferenced, err := whiteflag.ReferencedMessagesOfMilestone(Plugin.Daemon().ContextStopped(), deps.Storage, msIndex, cachedMilestoneMsg.Message().Parents())
+	if err != nil {
+		if errors.Is(err, common.ErrOperationAborted) {
+			return nil, errors.WithMessagef(echo.ErrServiceUnavailable, "failed to collect the messages referenced by milestone %d: %s", msIndex, err)
+		}
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "failed to collect the messages referenced by milestone %d: %s", msIndex, err)
+	}
+
+	auditPath, err := whiteflag.ConfirmedMerkleAuditPath(messagesReferenced, messageID)
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "failed to compute the merkle audit path of message %s: %s", messageID.ToHex(), err)
+	}
+
+	auditPathResponse := make([]*MerkleAuditPathNode, len(auditPath))
+	for i, node := range auditPath {
+		auditPathResponse[i] = &MerkleAuditPathNode{
+			Hash: iotago.EncodeHex(node.Hash),
+			Left: node.Left,
+		}
+	}
+
+	return &MessageProof{
+		Milestone: cachedMilestoneMsg.Message().Message(),
+		Message:   cachedMsg.Message().Message(),
+		AuditPath: auditPathResponse,
+	}, nil
+}
+
+func validateProof(c echo.Context) (*ValidateProofResponse, error) {
+
+	request := &MessageProof{}
+	if err := c.Bind(request); err != nil {
+		return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid request, error: %s", err)
+	}
+
+	if request.Milestone == nil || request.Message == nil {
+		return nil, errors.WithMessage(restapi.ErrInvalidParameter, "invalid request, error: milestone and message are required")
+	}
+
+	ms, ok := request.Milestone.Payload.(*iotago.Milestone)
+	if !ok {
+		return nil, errors.WithMessage(restapi.ErrInvalidParameter, "invalid request, error: milestone message does not contain a milestone payload")
+	}
+
+	auditPath := make([]*whiteflag.MerkleAuditPathNode, len(request.AuditPath))
+	for i, node := range request.AuditPath {
+		if node == nil {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid audit path, error: node %d missing", i)
+		}
+		hash, err := iotago.DecodeHex(node.Hash)
+		if err != nil {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid audit path, error: %s", err)
+		}
+		auditPath[i] = &whiteflag.MerkleAuditPathNode{
+			Hash: hash,
+			Left: node.Left,
+		}
+	}
+
+	// the parents of the milestone message have to match the parents in the milestone payload
+	if len(request.Milestone.Parents) != len(ms.Parents) {
+		return &ValidateProofResponse{Valid: false}, nil
+	}
+	for i, parent := range request.Milestone.Parents {
+		if parent != ms.Parents[i] {
+			return &ValidateProofResponse{Valid: false}, nil
+		}
+	}
+
+	// the milestone needs to be signed by the keys that were valid at the index of the milestone
+	if err := ms.VerifySignatures(deps.MilestonePublicKeyCount, deps.KeyManager.PublicKeysSetForMilestoneIndex(milestone.Index(ms.Index))); err != nil {
+		return &ValidateProofResponse{Valid: false}, nil
+	}
+
+	messageID, err := request.Message.ID()
+	if err != nil {
+		return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid message, error: %s", err)
+	}
+
+	valid, err := whiteflag.VerifyConfirmedMerkleAuditPath(ms, hornet.MessageIDFromArray(*messageID), auditPath)
+	if err != nil {
+		return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid audit path, error: %s", err)
+	}
+
+	return &ValidateProofResponse{Valid: valid}, nil
+}
@@ -8,16 +8,28 @@ import (
 	"github.com/gohornet/hornet/pkg/restapi"
 )
 
-func receipts(_ echo.Context) (*receiptsResponse, error) {
+func receipts(c echo.Context) (*receiptsResponse, error) {
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	receipts := make([]*utxo.ReceiptTuple, 0)
 	if err := deps.UTXOManager.ForEachReceiptTuple(func(rt *utxo.ReceiptTuple) bool {
+		if !paginator.Add(rt.Cursor()) {
+			return false
+		}
+
 		receipts = append(receipts, rt)
 		return true
-	}, utxo.ReadLockLedger(false)); err != nil {
+	}, utxo.ReadLockLedger(false), utxo.IterateFromCursor(paginator.Cursor)); err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "unable to retrieve receipts: %s", err)
 	}
 
-	return &receiptsResponse{Receipts: receipts}, nil
+	return &receiptsResponse{
+		PaginationResponse: paginator.Response(),
+		Receipts:           receipts,
+	}, nil
 }
 
 func receiptsByMigratedAtIndex(c echo.Context) (*receiptsResponse, error) {
@@ -26,13 +38,25 @@ func receiptsByMigratedAtIndex(c echo.Context) (*receiptsResponse, error) {
 		return nil, err
 	}
 
+	paginator, err := restapi.ParsePaginationQueryParams(c, deps.RestAPILimitsMaxResults)
+	if err != nil {
+		return nil, err
+	}
+
 	receipts := make([]*utxo.ReceiptTuple, 0)
 	if err := deps.UTXOManager.ForEachReceiptTupleMigratedAt(migratedAt, func(rt *utxo.ReceiptTuple) bool {
+		if !paginator.Add(rt.Cursor()) {
+			return false
+		}
+
 		receipts = append(receipts, rt)
 		return true
-	}, utxo.ReadLockLedger(false)); err != nil {
+	}, utxo.ReadLockLedger(false), utxo.IterateFromCursor(paginator.Cursor)); err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "unable to retrieve receipts for migrated at index %d: %s", migratedAt, err)
 	}
 
-	return &receiptsResponse{Receipts: receipts}, nil
+	return &receiptsResponse{
+		PaginationResponse: paginator.Response(),
+		Receipts:           receipts,
+	}, nil
 }
@@ -1,12 +1,16 @@
 package v2
 
 import (
+	"time"
+
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 
 	"github.com/gohornet/hornet/pkg/model/storage"
 	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/whiteflag"
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/serializer/v2"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -53,3 +57,62 @@ func messageBytesByTransactionID(c echo.Context) ([]byte, error) {
 	}
 	return message.Data(), nil
 }
+
+func simulateTransaction(c echo.Context) (*transactionSimulationResponse, error) {
+
+	if !deps.SyncManager.IsNodeAlmostSynced() {
+		return nil, errors.WithMessage(echo.ErrServiceUnavailable, "node is not synced")
+	}
+
+	msg, err := messageFromRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, ok := msg.Payload.(*iotago.Transaction)
+	if !ok {
+		return nil, errors.WithMessage(restapi.ErrInvalidParameter, "invalid message, error: message does not contain a transaction payload")
+	}
+
+	// the syntactic validation of the transaction also checks the storage deposit of the outputs.
+	// the message itself is not validated, because the parents are not needed for the simulation.
+	if _, err := transaction.Serialize(serializer.DeSeriModePerformValidation, deps.DeserializationParameters); err != nil {
+		return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid transaction, error: %s", err)
+	}
+
+	transactionID, err := transaction.ID()
+	if err != nil {
+		return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid transaction, error: %s", err)
+	}
+
+	// we need to lock the ledger here to validate the transaction against a consistent ledger state.
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading ledger index failed, error: %s", err)
+	}
+
+	// the transaction is validated as if it was referenced by the next milestone.
+	simulation, err := whiteflag.SimulateTransaction(deps.UTXOManager, deps.NetworkID, ledgerIndex+1, uint32(time.Now().Unix()), transaction)
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "simulating transaction failed: %s, error: %s", transactionID.ToHex(), err)
+	}
+
+	response := &transactionSimulationResponse{
+		TransactionID:        transactionID.ToHex(),
+		LedgerIndex:          ledgerIndex,
+		LedgerInclusionState: "included",
+	}
+
+	if simulation.Conflict != storage.ConflictNone {
+		response.LedgerInclusionState = "conflicting"
+		response.ConflictReason = &simulation.Conflict
+		if simulation.ConflictErr != nil {
+			response.ConflictDetails = simulation.ConflictErr.Error()
+		}
+	}
+
+	return response, nil
+}
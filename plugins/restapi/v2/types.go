@@ -7,6 +7,7 @@ import (
 	"github.com/gohornet/hornet/pkg/model/storage"
 	"github.com/gohornet/hornet/pkg/model/utxo"
 	"github.com/gohornet/hornet/pkg/protocol/gossip"
+	"github.com/gohornet/hornet/pkg/restapi"
 	iotago "github.com/iotaledger/iota.go/v3"
 )
 
@@ -71,6 +72,7 @@ type tipsResponse struct {
 
 // receiptsResponse defines the response of a receipts REST API call.
 type receiptsResponse struct {
+	restapi.PaginationResponse
 	Receipts []*utxo.ReceiptTuple `json:"receipts"`
 }
 
@@ -102,8 +104,23 @@ type messageCreatedResponse struct {
 	MessageID string `json:"messageId"`
 }
 
+// transactionSimulationResponse defines the response of a POST transactions simulate REST API call.
+type transactionSimulationResponse struct {
+	// The hex encoded transaction ID of the transaction.
+	TransactionID string `json:"transactionId"`
+	// The ledger index the transaction was validated against.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+	// The ledger inclusion state the transaction would have if it was referenced by the next milestone.
+	LedgerInclusionState string `json:"ledgerInclusionState"`
+	// The reason why the transaction would be marked as conflicting.
+	ConflictReason *storage.Conflict `json:"conflictReason,omitempty"`
+	// The error of the validation that caused the conflict.
+	ConflictDetails string `json:"conflictDetails,omitempty"`
+}
+
 // childrenResponse defines the response of a GET children REST API call.
 type childrenResponse struct {
+	restapi.PaginationResponse
 	// The hex encoded message ID of the message.
 	MessageID string `json:"messageId"`
 	// The maximum count of results that are returned by the node.
@@ -114,6 +131,17 @@ type childrenResponse struct {
 	Children []string `json:"childrenMessageIds"`
 }
 
+// pinsResponse defines the response of a GET pins REST API call.
+type pinsResponse struct {
+	restapi.PaginationResponse
+	// The maximum count of results that are returned by the node.
+	MaxResults uint32 `json:"maxResults"`
+	// The actual count of results that are returned.
+	Count uint32 `json:"count"`
+	// The hex encoded message IDs of the pinned messages.
+	MessageIDs []string `json:"messageIds"`
+}
+
 // milestoneResponse defines the response of a GET milestones REST API call.
 type milestoneResponse struct {
 	// The index of the milestone.
@@ -126,6 +154,7 @@ type milestoneResponse struct {
 
 // milestoneUTXOChangesResponse defines the response of a GET milestone UTXO changes REST API call.
 type milestoneUTXOChangesResponse struct {
+	restapi.PaginationResponse
 	// The index of the milestone.
 	Index uint32 `json:"index"`
 	// The output IDs (transaction hash + output index) of the newly created outputs.
@@ -160,6 +189,29 @@ type OutputResponse struct {
 	RawOutput *json.RawMessage `json:"output,omitempty"`
 }
 
+// addressOutputsResponse defines the response of a GET address outputs REST API call.
+type addressOutputsResponse struct {
+	restapi.PaginationResponse
+	// The bech32 encoded address.
+	Address string `json:"address"`
+	// The ledger index at which the outputs were collected.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+	// The output IDs (transaction hash + output index) of the unspent outputs.
+	Items []string `json:"items"`
+}
+
+// addressBalanceResponse defines the response of a GET address balance REST API call.
+type addressBalanceResponse struct {
+	// The bech32 encoded address.
+	Address string `json:"address"`
+	// The sum of the deposits of the unspent outputs that reference the address in their unlock conditions.
+	Balance string `json:"balance"`
+	// The amount of unspent outputs that reference the address in their unlock conditions.
+	OutputCount int `json:"outputCount"`
+	// The ledger index at which the balance was computed.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+}
+
 // treasuryResponse defines the response of a GET treasury REST API call.
 type treasuryResponse struct {
 	MilestoneID string `json:"milestoneId"`
@@ -186,10 +238,33 @@ type PeerResponse struct {
 	Relation string `json:"relation"`
 	// Whether the peer is connected.
 	Connected bool `json:"connected"`
+	// The score of the peer.
+	Score int64 `json:"score"`
 	// The gossip protocol information of the peer.
 	Gossip *gossip.Info `json:"gossip,omitempty"`
 }
 
+// peersResponse defines the response of a paginated GET peers REST API call.
+type peersResponse struct {
+	restapi.PaginationResponse
+	// The peers of the node.
+	Peers []*PeerResponse `json:"peers"`
+}
+
+// eventStreamMessage defines a message of the event stream.
+type eventStreamMessage struct {
+	// The topic of the event.
+	Topic string `json:"topic"`
+	// The payload of the event.
+	Data interface{} `json:"data"`
+}
+
+// droppedEventsResponse defines the payload of an event stream message about dropped events.
+type droppedEventsResponse struct {
+	// The amount of events that were dropped because the client did not keep up with the event rate.
+	Count uint64 `json:"count"`
+}
+
 // pruneDatabaseRequest defines the request of a prune database REST API call.
 type pruneDatabaseRequest struct {
 	// The pruning target index.
@@ -206,6 +281,42 @@ type pruneDatabaseResponse struct {
 	Index milestone.Index `json:"index"`
 }
 
+// pruneDatabaseEstimateResponse defines the response of a prune database estimate REST API call.
+type pruneDatabaseEstimateResponse struct {
+	// The first milestone index that would be pruned.
+	StartIndex milestone.Index `json:"startIndex"`
+	// The last milestone index that would be pruned.
+	TargetIndex milestone.Index `json:"targetIndex"`
+	// The amount of milestones that would be pruned.
+	MilestonesCount int `json:"milestonesCount"`
+	// The amount of referenced messages that would be pruned.
+	MessagesCount int `json:"messagesCount"`
+	// The amount of unreferenced messages that would be pruned.
+	UnreferencedMessagesCount int `json:"unreferencedMessagesCount"`
+	// The amount of parent/child references that would be pruned.
+	ChildrenCount int `json:"childrenCount"`
+	// The size of the tangle data that would be pruned in bytes.
+	TangleBytes int64 `json:"tangleBytes"`
+	// The size of the ledger diffs that would be pruned in bytes.
+	LedgerDiffsBytes int64 `json:"ledgerDiffsBytes"`
+	// The estimated amount of freed bytes.
+	EstimatedFreedBytes int64 `json:"estimatedFreedBytes"`
+}
+
+// createDatabaseCheckpointResponse defines the response of a create database checkpoint REST API call.
+type createDatabaseCheckpointResponse struct {
+	// The path of the checkpoint directory.
+	Path string `json:"path"`
+	// The ledger index of the UTXO database in the checkpoint.
+	LedgerIndex milestone.Index `json:"ledgerIndex"`
+	// The index of the last snapshot.
+	SnapshotIndex milestone.Index `json:"snapshotIndex"`
+	// The index of the solid entry points.
+	EntryPointIndex milestone.Index `json:"entryPointIndex"`
+	// The index the database was pruned to.
+	PruningIndex milestone.Index `json:"pruningIndex"`
+}
+
 // createSnapshotsRequest defines the request of a create snapshots REST API call.
 type createSnapshotsRequest struct {
 	// The index of the full snapshot.
@@ -245,3 +356,27 @@ type ComputeWhiteFlagMutationsResponse struct {
 	// The hex encoded applied merkle tree root as a result of the white flag computation.
 	AppliedMerkleRoot string `json:"appliedMerkleRoot"`
 }
+
+// MerkleAuditPathNode defines a node of the merkle audit path of a message proof.
+type MerkleAuditPathNode struct {
+	// The hex encoded hash of the sibling node.
+	Hash string `json:"hash"`
+	// Whether the sibling node is the left child of their common parent.
+	Left bool `json:"left"`
+}
+
+// MessageProof defines the response of a GET message proof REST API call and the request of a POST validate proof REST API call.
+type MessageProof struct {
+	// The milestone message that referenced the message.
+	Milestone *iotago.Message `json:"milestone"`
+	// The message.
+	Message *iotago.Message `json:"message"`
+	// The merkle audit path of the message ID, from the leaf to the confirmed merkle root of the milestone.
+	AuditPath []*MerkleAuditPathNode `json:"auditPath"`
+}
+
+// ValidateProofResponse defines the response of a POST validate proof REST API call.
+type ValidateProofResponse struct {
+	// Whether the proof is valid.
+	Valid bool `json:"valid"`
+}
@@ -57,10 +57,19 @@ func outputByID(c echo.Context, metadataOnly bool) (*OutputResponse, error) {
 		return nil, err
 	}
 
+	atMilestone, err := restapi.ParseAtMilestoneQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
 	// we need to lock the ledger here to have the correct index for unspent info of the output.
 	deps.UTXOManager.ReadLockLedger()
 	defer deps.UTXOManager.ReadUnlockLedger()
 
+	if atMilestone != nil {
+		return outputByIDAtMilestoneWithoutLocking(outputID, *atMilestone, metadataOnly)
+	}
+
 	ledgerIndex, err := deps.UTXOManager.ReadLedgerIndexWithoutLocking()
 	if err != nil {
 		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading output failed: %s, error: %s", outputID.ToHex(), err)
@@ -92,12 +101,50 @@ func outputByID(c echo.Context, metadataOnly bool) (*OutputResponse, error) {
 	return NewSpentResponse(spent, ledgerIndex, metadataOnly)
 }
 
+// outputByIDAtMilestoneWithoutLocking returns the output with the state it had at the given milestone index.
+func outputByIDAtMilestoneWithoutLocking(outputID *iotago.OutputID, msIndex milestone.Index, metadataOnly bool) (*OutputResponse, error) {
+	output, spent, err := deps.UTXOManager.ReadOutputStateAtMilestoneWithoutLocking(outputID, msIndex)
+	if err != nil {
+		if errors.Is(err, utxo.ErrLedgerStateNotAvailable) {
+			return nil, errors.WithMessagef(restapi.ErrInvalidParameter, "reading output failed: %s, error: %s", outputID.ToHex(), err)
+		}
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, errors.WithMessagef(echo.ErrNotFound, "output not found at milestone %d: %s", msIndex, outputID.ToHex())
+		}
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading output failed: %s, error: %s", outputID.ToHex(), err)
+	}
+
+	if spent != nil {
+		return NewSpentResponse(spent, msIndex, metadataOnly)
+	}
+	return NewOutputResponse(output, msIndex, metadataOnly)
+}
+
+func outputByIDAtMilestone(outputID *iotago.OutputID, msIndex milestone.Index) (*OutputResponse, error) {
+	deps.UTXOManager.ReadLockLedger()
+	defer deps.UTXOManager.ReadUnlockLedger()
+
+	return outputByIDAtMilestoneWithoutLocking(outputID, msIndex, true)
+}
+
 func rawOutputByID(c echo.Context) ([]byte, error) {
 	outputID, err := restapi.ParseOutputIDParam(c)
 	if err != nil {
 		return nil, err
 	}
 
+	atMilestone, err := restapi.ParseAtMilestoneQueryParam(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if atMilestone != nil {
+		// the raw output doesn't change, but it has to exist at the given milestone index.
+		if _, err := outputByIDAtMilestone(outputID, *atMilestone); err != nil {
+			return nil, err
+		}
+	}
+
 	bytes, err := deps.UTXOManager.ReadRawOutputBytesByOutputIDWithoutLocking(outputID)
 	if err != nil {
 		if errors.Is(err, kvstore.ErrKeyNotFound) {
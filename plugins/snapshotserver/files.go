@@ -0,0 +1,260 @@
+package snapshotserver
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/snapshot"
+)
+
+const (
+	// the HTTP headers that contain the information from the snapshot file header.
+	headerSnapshotVersion              = "X-Snapshot-Version"
+	headerSnapshotType                 = "X-Snapshot-Type"
+	headerSnapshotNetworkID            = "X-Snapshot-Network-Id"
+	headerSnapshotSEPMilestoneIndex    = "X-Snapshot-Sep-Milestone-Index"
+	headerSnapshotLedgerMilestoneIndex = "X-Snapshot-Ledger-Milestone-Index"
+	headerSnapshotTimestamp            = "X-Snapshot-Timestamp"
+	headerSnapshotCompression          = "X-Snapshot-Compression"
+	headerSnapshotSHA256               = "X-Snapshot-Sha256"
+)
+
+// snapshotFileInfo holds the information about a served snapshot file.
+type snapshotFileInfo struct {
+	size         int64
+	modTime      time.Time
+	header       *snapshot.ReadFileHeader
+	manifestFile *snapshot.SnapshotManifestFile
+}
+
+// snapshotFileCache caches the information about the served snapshot files,
+// so the files only need to be hashed again if they were replaced.
+type snapshotFileCache struct {
+	sync.Mutex
+	entries map[string]*snapshotFileCacheEntry
+}
+
+// snapshotFileCacheEntry holds the cached information about a single snapshot file.
+// the entry is locked while the file is hashed, so that requests for other files are not blocked,
+// and concurrent requests for the same file wait for the result instead of hashing the file again.
+type snapshotFileCacheEntry struct {
+	sync.Mutex
+	info *snapshotFileInfo
+}
+
+func newSnapshotFileCache() *snapshotFileCache {
+	return &snapshotFileCache{
+		entries: make(map[string]*snapshotFileCacheEntry),
+	}
+}
+
+// entry returns the cache entry for the given snapshot file path.
+func (c *snapshotFileCache) entry(filePath string) *snapshotFileCacheEntry {
+	c.Lock()
+	defer c.Unlock()
+
+	entry, exists := c.entries[filePath]
+	if !exists {
+		entry = &snapshotFileCacheEntry{}
+		c.entries[filePath] = entry
+	}
+
+	return entry
+}
+
+// open opens the snapshot file at the given path and returns the information about the opened file.
+// the caller has to close the file.
+func (c *snapshotFileCache) open(filePath string) (*os.File, *snapshotFileInfo, error) {
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, errors.WithMessagef(echo.ErrNotFound, "snapshot file not found: %s", filepath.Base(filePath))
+		}
+		return nil, nil, errors.WithMessagef(echo.ErrInternalServerError, "opening snapshot file failed: %s", err)
+	}
+
+	info, err := c.info(filePath, file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, errors.WithMessagef(echo.ErrInternalServerError, "reading snapshot file failed: %s", err)
+	}
+
+	return file, info, nil
+}
+
+// info returns the cached information about the opened snapshot file, or computes it if the file changed.
+func (c *snapshotFileCache) info(filePath string, file *os.File) (*snapshotFileInfo, error) {
+	entry := c.entry(filePath)
+	entry.Lock()
+	defer entry.Unlock()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if info := entry.info; info != nil && info.size == stat.Size() && info.modTime.Equal(stat.ModTime()) {
+		return info, nil
+	}
+
+	header, err := snapshot.ReadSnapshotHeader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestFile, err := snapshot.NewSnapshotManifestFileFromReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.info = &snapshotFileInfo{
+		size:         stat.Size(),
+		modTime:      stat.ModTime(),
+		header:       header,
+		manifestFile: manifestFile,
+	}
+
+	return entry.info, nil
+}
+
+// snapshotFileInfoOrNil returns the information about the snapshot file at the given path, or nil if the file doesn't exist.
+func snapshotFileInfoOrNil(filePath string) (*snapshotFileInfo, error) {
+	file, info, err := fileCache.open(filePath)
+	if err != nil {
+		if errors.Is(err, echo.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	_ = file.Close()
+
+	return info, nil
+}
+
+func parseDeltaChainNumberParam(c echo.Context) (int, error) {
+	numberParam := strings.TrimSuffix(c.Param(ParameterDeltaChainNumber), ".bin")
+
+	number, err := strconv.Atoi(numberParam)
+	if err != nil || number < 1 {
+		return 0, errors.WithMessagef(restapi.ErrInvalidParameter, "invalid delta snapshot chain number: %s", c.Param(ParameterDeltaChainNumber))
+	}
+
+	return number, nil
+}
+
+func serveSnapshotFile(c echo.Context, filePath string) error {
+
+	file, info, err := fileCache.open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, echo.MIMEOctetStream)
+	header.Set("ETag", strconv.Quote(info.manifestFile.SHA256))
+	header.Set(headerSnapshotVersion, strconv.FormatUint(uint64(info.header.Version), 10))
+	header.Set(headerSnapshotType, strconv.FormatUint(uint64(info.header.Type), 10))
+	header.Set(headerSnapshotNetworkID, strconv.FormatUint(info.header.NetworkID, 10))
+	header.Set(headerSnapshotSEPMilestoneIndex, strconv.FormatUint(uint64(info.header.SEPMilestoneIndex), 10))
+	header.Set(headerSnapshotLedgerMilestoneIndex, strconv.FormatUint(uint64(info.header.LedgerMilestoneIndex), 10))
+	header.Set(headerSnapshotTimestamp, strconv.FormatUint(info.header.Timestamp, 10))
+	header.Set(headerSnapshotCompression, info.header.Compression.String())
+	header.Set(headerSnapshotSHA256, info.manifestFile.SHA256)
+
+	// ServeContent handles range and conditional requests and sets the content length.
+	http.ServeContent(c.Response(), c.Request(), filepath.Base(filePath), info.modTime, file)
+
+	return nil
+}
+
+// manifest returns the manifest of the current snapshot files.
+// the delta snapshot file is only included if it fits the full snapshot file,
+// the delta snapshot chain is included up to the first file that doesn't fit its predecessor.
+func manifest() (*snapshot.SnapshotManifest, error) {
+
+	fullInfo, err := snapshotFileInfoOrNil(deps.SnapshotsFullPath)
+	if err != nil {
+		return nil, err
+	}
+	if fullInfo == nil {
+		return nil, errors.WithMessage(echo.ErrNotFound, "full snapshot file not found")
+	}
+
+	result := &snapshot.SnapshotManifest{
+		Full: fullInfo.manifestFile,
+	}
+
+	deltaInfo, err := snapshotFileInfoOrNil(deps.SnapshotsDeltaPath)
+	if err != nil {
+		return nil, err
+	}
+	previousHeader := fullInfo.header
+	if deltaInfo != nil && snapshot.VerifyDeltaSnapshotLink(fullInfo.header, deltaInfo.header) == nil {
+		result.Delta = deltaInfo.manifestFile
+		previousHeader = deltaInfo.header
+	}
+
+	deltaChainPaths, err := snapshot.DeltaChainFilePaths(deps.SnapshotsDeltaPath)
+	if err != nil {
+		return nil, errors.WithMessagef(echo.ErrInternalServerError, "reading delta snapshot chain failed: %s", err)
+	}
+
+	for _, deltaChainPath := range deltaChainPaths {
+		deltaChainInfo, err := snapshotFileInfoOrNil(deltaChainPath)
+		if err != nil {
+			return nil, err
+		}
+		if deltaChainInfo == nil || snapshot.VerifyDeltaSnapshotLink(previousHeader, deltaChainInfo.header) != nil {
+			break
+		}
+
+		result.DeltaChain = append(result.DeltaChain, deltaChainInfo.manifestFile)
+		previousHeader = deltaChainInfo.header
+	}
+
+	if manifestPrivateKey != nil {
+		if err := result.Sign(manifestPrivateKey); err != nil {
+			return nil, errors.WithMessagef(echo.ErrInternalServerError, "signing snapshot manifest failed: %s", err)
+		}
+	}
+
+	return result, nil
+}
+
+// downloadTarget returns the download target of the current snapshot files, with URLs based on the configured public URL.
+func downloadTarget() (*snapshot.DownloadTarget, error) {
+
+	baseURL := strings.TrimSuffix(deps.NodeConfig.String(CfgSnapshotServerPublicURL), "/")
+	if baseURL == "" {
+		return nil, errors.WithMessagef(echo.ErrNotFound, "download target not available, '%s' is not configured", CfgSnapshotServerPublicURL)
+	}
+
+	m, err := manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	target := &snapshot.DownloadTarget{
+		Full:     baseURL + RouteFullSnapshot,
+		Manifest: baseURL + RouteManifest,
+	}
+	if m.Delta != nil {
+		target.Delta = baseURL + RouteDeltaSnapshot
+	}
+	for i := range m.DeltaChain {
+		target.DeltaChain = append(target.DeltaChain, baseURL+strings.Replace(RouteDeltaChainSnapshot, ":"+ParameterDeltaChainNumber, strconv.Itoa(i+1), 1))
+	}
+
+	return target, nil
+}
@@ -0,0 +1,233 @@
+package snapshotserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gohornet/hornet/pkg/model/hornet"
+	"github.com/gohornet/hornet/pkg/model/milestone"
+	"github.com/gohornet/hornet/pkg/model/utxo"
+	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/iotaledger/hive.go/configuration"
+)
+
+const (
+	testNetworkID = 1337
+)
+
+// writeTestSnapshotFile writes a snapshot file without solid entry points, outputs and milestone diffs.
+// Delta snapshot files reference the given checksum of their preceding snapshot file.
+func writeTestSnapshotFile(t *testing.T, filePath string, snapshotType snapshot.Type, sepIndex milestone.Index, ledgerIndex milestone.Index, previousChecksum []byte) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0700))
+
+	header := &snapshot.FileHeader{
+		Version:              snapshot.FormatVersionCompressed,
+		Type:                 snapshotType,
+		NetworkID:            testNetworkID,
+		SEPMilestoneIndex:    sepIndex,
+		LedgerMilestoneIndex: ledgerIndex,
+		Compression:          snapshot.CompressionNone,
+		PreviousChecksum:     previousChecksum,
+	}
+
+	var outputProd snapshot.OutputProducerFunc
+	if snapshotType == snapshot.Full {
+		header.TreasuryOutput = &utxo.TreasuryOutput{}
+		outputProd = func() (*utxo.Output, error) { return nil, nil }
+	}
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, file.Close()) }()
+
+	_, err = snapshot.StreamSnapshotDataTo(file, 1650000000, header,
+		func() (hornet.MessageID, error) { return nil, nil },
+		outputProd,
+		func() (*snapshot.MilestoneDiff, error) { return nil, nil },
+	)
+	require.NoError(t, err)
+}
+
+// snapshotChecksum returns the checksum of the given snapshot file.
+func snapshotChecksum(t *testing.T, filePath string) []byte {
+	header, err := snapshot.ReadSnapshotHeaderFromFile(filePath)
+	require.NoError(t, err)
+	return header.Checksum
+}
+
+// setupTestSnapshotServer writes a full and a delta snapshot file and returns the echo instance that serves them.
+func setupTestSnapshotServer(t *testing.T, publicURL string) *echo.Echo {
+	tempDir := t.TempDir()
+
+	nodeConfig := configuration.New()
+	require.NoError(t, nodeConfig.Set(CfgSnapshotServerPublicURL, publicURL))
+
+	deps = dependencies{
+		NodeConfig:         nodeConfig,
+		SnapshotsFullPath:  filepath.Join(tempDir, "full_snapshot.bin"),
+		SnapshotsDeltaPath: filepath.Join(tempDir, "delta_snapshot.bin"),
+	}
+	manifestPrivateKey = nil
+	fileCache = newSnapshotFileCache()
+
+	writeTestSnapshotFile(t, deps.SnapshotsFullPath, snapshot.Full, 10, 10, nil)
+	writeTestSnapshotFile(t, deps.SnapshotsDeltaPath, snapshot.Delta, 20, 10, snapshotChecksum(t, deps.SnapshotsFullPath))
+
+	e := echo.New()
+	e.HTTPErrorHandler = restapi.ErrorHandler()
+	setupRoutes(e)
+
+	return e
+}
+
+func request(e *echo.Echo, method string, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestServeSnapshotFile(t *testing.T) {
+
+	e := setupTestSnapshotServer(t, "")
+
+	fileBytes, err := os.ReadFile(deps.SnapshotsFullPath)
+	require.NoError(t, err)
+
+	rec := request(e, http.MethodGet, RouteFullSnapshot, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, fileBytes, rec.Body.Bytes())
+	require.Equal(t, strconv.Itoa(int(snapshot.FormatVersionCompressed)), rec.Header().Get(headerSnapshotVersion))
+	require.Equal(t, strconv.Itoa(int(snapshot.Full)), rec.Header().Get(headerSnapshotType))
+	require.Equal(t, strconv.Itoa(testNetworkID), rec.Header().Get(headerSnapshotNetworkID))
+	require.Equal(t, "10", rec.Header().Get(headerSnapshotSEPMilestoneIndex))
+	require.Equal(t, "10", rec.Header().Get(headerSnapshotLedgerMilestoneIndex))
+	require.Equal(t, "1650000000", rec.Header().Get(headerSnapshotTimestamp))
+	require.Equal(t, snapshot.CompressionNone.String(), rec.Header().Get(headerSnapshotCompression))
+
+	sha256 := rec.Header().Get(headerSnapshotSHA256)
+	require.NotEmpty(t, sha256)
+	require.Equal(t, strconv.Quote(sha256), rec.Header().Get("ETag"))
+
+	// range requests are supported
+	rec = request(e, http.MethodGet, RouteFullSnapshot, http.Header{"Range": []string{"bytes=5-9"}})
+	require.Equal(t, http.StatusPartialContent, rec.Code)
+	require.Equal(t, fileBytes[5:10], rec.Body.Bytes())
+
+	// HEAD requests only return the headers
+	rec = request(e, http.MethodHead, RouteDeltaSnapshot, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Body.Bytes())
+	require.Equal(t, strconv.Itoa(int(snapshot.Delta)), rec.Header().Get(headerSnapshotType))
+
+	// the chained delta snapshot files don't exist
+	rec = request(e, http.MethodGet, "/delta_snapshot_chain/1.bin", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	for _, number := range []string{"0", "-1", "abc"} {
+		rec = request(e, http.MethodGet, "/delta_snapshot_chain/"+number, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestSnapshotFileCacheRefresh(t *testing.T) {
+
+	e := setupTestSnapshotServer(t, "")
+
+	rec := request(e, http.MethodHead, RouteDeltaSnapshot, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "20", rec.Header().Get(headerSnapshotSEPMilestoneIndex))
+	sha256 := rec.Header().Get(headerSnapshotSHA256)
+
+	// the cached information is recomputed after the file was replaced
+	writeTestSnapshotFile(t, deps.SnapshotsDeltaPath, snapshot.Delta, 30, 10, snapshotChecksum(t, deps.SnapshotsFullPath))
+
+	rec = request(e, http.MethodHead, RouteDeltaSnapshot, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "30", rec.Header().Get(headerSnapshotSEPMilestoneIndex))
+	require.NotEqual(t, sha256, rec.Header().Get(headerSnapshotSHA256))
+}
+
+func TestManifest(t *testing.T) {
+
+	e := setupTestSnapshotServer(t, "")
+
+	// the chain continues from the delta snapshot file, the third file doesn't fit its predecessor
+	writeTestSnapshotFile(t, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, 1), snapshot.Delta, 30, 20, snapshotChecksum(t, deps.SnapshotsDeltaPath))
+	writeTestSnapshotFile(t, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, 2), snapshot.Delta, 40, 30, snapshotChecksum(t, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, 1)))
+	writeTestSnapshotFile(t, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, 3), snapshot.Delta, 60, 50, snapshotChecksum(t, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, 2)))
+
+	rec := request(e, http.MethodGet, RouteManifest, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	m := &snapshot.SnapshotManifest{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), m))
+	require.NotNil(t, m.Full)
+	require.Equal(t, milestone.Index(10), m.Full.SEPMilestoneIndex)
+	require.NotNil(t, m.Delta)
+	require.Equal(t, milestone.Index(20), m.Delta.SEPMilestoneIndex)
+	require.Len(t, m.DeltaChain, 2)
+	require.Equal(t, milestone.Index(30), m.DeltaChain[0].SEPMilestoneIndex)
+	require.Equal(t, milestone.Index(40), m.DeltaChain[1].SEPMilestoneIndex)
+
+	// the delta snapshot file is not included if it doesn't fit the full snapshot file
+	writeTestSnapshotFile(t, deps.SnapshotsDeltaPath, snapshot.Delta, 20, 15, snapshotChecksum(t, deps.SnapshotsFullPath))
+
+	rec = request(e, http.MethodGet, RouteManifest, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	m = &snapshot.SnapshotManifest{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), m))
+	require.NotNil(t, m.Full)
+	require.Nil(t, m.Delta)
+	require.Empty(t, m.DeltaChain)
+
+	// there is no manifest without a full snapshot file
+	require.NoError(t, os.Remove(deps.SnapshotsFullPath))
+
+	rec = request(e, http.MethodGet, RouteManifest, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestDownloadTarget(t *testing.T) {
+
+	e := setupTestSnapshotServer(t, "https://snapshots.example.com/")
+
+	writeTestSnapshotFile(t, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, 1), snapshot.Delta, 30, 20, snapshotChecksum(t, deps.SnapshotsDeltaPath))
+
+	// the URLs are based on the configured public URL and not on the requested host
+	req := httptest.NewRequest(http.MethodGet, RouteDownloadTarget, nil)
+	req.Host = "attacker.example.com"
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	target := &snapshot.DownloadTarget{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), target))
+	require.Equal(t, &snapshot.DownloadTarget{
+		Full:       "https://snapshots.example.com/full_snapshot.bin",
+		Delta:      "https://snapshots.example.com/delta_snapshot.bin",
+		DeltaChain: []string{"https://snapshots.example.com/delta_snapshot_chain/1"},
+		Manifest:   "https://snapshots.example.com/manifest.json",
+	}, target)
+
+	// the download target is not available without a configured public URL
+	e = setupTestSnapshotServer(t, "")
+
+	rec = request(e, http.MethodGet, RouteDownloadTarget, nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,29 @@
+package snapshotserver
+
+import (
+	flag "github.com/spf13/pflag"
+
+	"github.com/gohornet/hornet/pkg/node"
+)
+
+const (
+	// the bind address on which the snapshot server listens on.
+	CfgSnapshotServerBindAddress = "snapshotServer.bindAddress"
+	// the public base URL under which other nodes reach the snapshot server, used for the URLs of the download target (e.g. "https://snapshots.example.com")
+	CfgSnapshotServerPublicURL = "snapshotServer.publicURL"
+	// whether to sign the manifest of the snapshot files with the private key given in the "SNAPSHOT_SERVER_PRV_KEY" environment variable.
+	CfgSnapshotServerSignManifest = "snapshotServer.signManifest"
+)
+
+var params = &node.PluginParams{
+	Params: map[string]*flag.FlagSet{
+		"nodeConfig": func() *flag.FlagSet {
+			fs := flag.NewFlagSet("", flag.ContinueOnError)
+			fs.String(CfgSnapshotServerBindAddress, "localhost:8070", "the bind address on which the snapshot server listens on")
+			fs.String(CfgSnapshotServerPublicURL, "", "the public base URL under which other nodes reach the snapshot server, used for the URLs of the download target (e.g. \"https://snapshots.example.com\")")
+			fs.Bool(CfgSnapshotServerSignManifest, false, "whether to sign the manifest of the snapshot files with the private key given in the \"SNAPSHOT_SERVER_PRV_KEY\" environment variable")
+			return fs
+		}(),
+	},
+	Masked: nil,
+}
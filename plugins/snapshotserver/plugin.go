@@ -0,0 +1,184 @@
+package snapshotserver
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/pkg/errors"
+	"go.uber.org/dig"
+
+	"github.com/gohornet/hornet/pkg/node"
+	"github.com/gohornet/hornet/pkg/restapi"
+	"github.com/gohornet/hornet/pkg/shutdown"
+	"github.com/gohornet/hornet/pkg/snapshot"
+	"github.com/gohornet/hornet/pkg/utils"
+	"github.com/iotaledger/hive.go/configuration"
+)
+
+const (
+	// RouteFullSnapshot is the route for downloading the full snapshot file.
+	// GET returns the full snapshot file, range requests are supported.
+	RouteFullSnapshot = "/full_snapshot.bin"
+
+	// RouteDeltaSnapshot is the route for downloading the delta snapshot file.
+	// GET returns the delta snapshot file, range requests are supported.
+	RouteDeltaSnapshot = "/delta_snapshot.bin"
+
+	// RouteDeltaChainSnapshot is the route for downloading a file of the delta snapshot chain.
+	// GET returns the chained delta snapshot file with the given number, range requests are supported.
+	RouteDeltaChainSnapshot = "/delta_snapshot_chain/:" + ParameterDeltaChainNumber
+
+	// RouteManifest is the route for getting the manifest with the hashes of the snapshot files.
+	// GET returns the manifest as JSON.
+	RouteManifest = "/manifest.json"
+
+	// RouteDownloadTarget is the route for getting the download target of the snapshot files,
+	// which can be added to the "snapshots.downloadURLs" of other nodes.
+	// The URLs of the download target are based on the configured public URL of the snapshot server.
+	// GET returns the download target as JSON.
+	RouteDownloadTarget = "/target.json"
+)
+
+const (
+	// ParameterDeltaChainNumber is used to identify a file of the delta snapshot chain by its number.
+	ParameterDeltaChainNumber = "number"
+)
+
+const (
+	// the environment variable that contains the private key to sign the manifest with.
+	manifestPrivateKeyEnvKey = "SNAPSHOT_SERVER_PRV_KEY"
+)
+
+func init() {
+	Plugin = &node.Plugin{
+		Status: node.StatusDisabled,
+		Pluggable: node.Pluggable{
+			Name:      "SnapshotServer",
+			DepsFunc:  func(cDeps dependencies) { deps = cDeps },
+			Params:    params,
+			Configure: configure,
+			Run:       run,
+		},
+	}
+}
+
+var (
+	Plugin *node.Plugin
+	deps   dependencies
+
+	server             *echo.Echo
+	manifestPrivateKey ed25519.PrivateKey
+	fileCache          = newSnapshotFileCache()
+)
+
+type dependencies struct {
+	dig.In
+	NodeConfig         *configuration.Configuration `name:"nodeConfig"`
+	SnapshotsFullPath  string                       `name:"snapshotsFullPath"`
+	SnapshotsDeltaPath string                       `name:"snapshotsDeltaPath"`
+}
+
+func configure() {
+	if deps.NodeConfig.Bool(CfgSnapshotServerSignManifest) {
+		privateKeys, err := utils.LoadEd25519PrivateKeysFromEnvironment(manifestPrivateKeyEnvKey)
+		if err != nil {
+			Plugin.LogPanicf("loading the private key to sign the snapshot manifest failed, err: %s", err)
+		}
+
+		if len(privateKeys) == 0 {
+			Plugin.LogPanic("loading the private key to sign the snapshot manifest failed, err: no private keys given")
+		}
+
+		if len(privateKeys) > 1 {
+			Plugin.LogPanic("loading the private key to sign the snapshot manifest failed, err: too many private keys given")
+		}
+
+		manifestPrivateKey = privateKeys[0]
+	}
+
+	server = echo.New()
+	server.HideBanner = true
+	server.Use(middleware.Recover())
+
+	errorHandler := restapi.ErrorHandler()
+	server.HTTPErrorHandler = func(err error, c echo.Context) {
+		Plugin.LogDebugf("HTTP request failed: %s", err)
+		errorHandler(err, c)
+	}
+
+	setupRoutes(server)
+}
+
+func setupRoutes(e *echo.Echo) {
+
+	methods := []string{http.MethodGet, http.MethodHead}
+
+	e.Match(methods, RouteFullSnapshot, func(c echo.Context) error {
+		return serveSnapshotFile(c, deps.SnapshotsFullPath)
+	})
+
+	e.Match(methods, RouteDeltaSnapshot, func(c echo.Context) error {
+		return serveSnapshotFile(c, deps.SnapshotsDeltaPath)
+	})
+
+	e.Match(methods, RouteDeltaChainSnapshot, func(c echo.Context) error {
+		number, err := parseDeltaChainNumberParam(c)
+		if err != nil {
+			return err
+		}
+
+		return serveSnapshotFile(c, snapshot.DeltaChainFilePath(deps.SnapshotsDeltaPath, number))
+	})
+
+	e.GET(RouteManifest, func(c echo.Context) error {
+		resp, err := manifest()
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	})
+
+	e.GET(RouteDownloadTarget, func(c echo.Context) error {
+		resp, err := downloadTarget()
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	})
+}
+
+func run() {
+	Plugin.LogInfo("Starting snapshot server ...")
+
+	if err := Plugin.Daemon().BackgroundWorker("Snapshot server", func(ctx context.Context) {
+		Plugin.LogInfo("Starting snapshot server ... done")
+
+		bindAddr := deps.NodeConfig.String(CfgSnapshotServerBindAddress)
+
+		go func() {
+			Plugin.LogInfof("You can now access the snapshot files using: http://%s%s", bindAddr, RouteManifest)
+			if err := server.Start(bindAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				Plugin.LogWarnf("Stopped snapshot server due to an error (%s)", err)
+			}
+		}()
+
+		<-ctx.Done()
+		Plugin.LogInfo("Stopping snapshot server ...")
+
+		shutdownCtx, shutdownCtxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			Plugin.LogWarn(err)
+		}
+		shutdownCtxCancel()
+		Plugin.LogInfo("Stopping snapshot server ... done")
+	}, shutdown.PrioritySnapshotServer); err != nil {
+		Plugin.LogPanicf("failed to start worker: %s", err)
+	}
+}
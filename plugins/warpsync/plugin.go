@@ -78,7 +78,13 @@ func run() {
 func configureEvents() {
 
 	onHeartbeatUpdated := events.NewClosure(func(hb *gossip.Heartbeat) {
-		warpSync.UpdateCurrentConfirmedMilestone(deps.SyncManager.ConfirmedMilestoneIndex())
+		confirmedMilestoneIndex := deps.SyncManager.ConfirmedMilestoneIndex()
+		warpSync.UpdateCurrentConfirmedMilestone(confirmedMilestoneIndex)
+
+		// only peers which could provide the next milestone cone are able to help us sync up to their target
+		if !hb.CouldHaveDataForMilestone(confirmedMilestoneIndex + 1) {
+			return
+		}
 		warpSync.UpdateTargetMilestone(hb.SolidMilestoneIndex)
 	})
 
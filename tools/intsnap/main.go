@@ -53,6 +53,7 @@ var fullSnapshotHeader = &snapshot.FileHeader{
 		MilestoneID: iotago.MilestoneID{},
 		Amount:      originTreasurySupply,
 	},
+	Compression: snapshot.DefaultCompression,
 }
 
 var originTreasurySupply = iotago.TokenSupply - fullSnapshotOutputs[0].Deposit() - fullSnapshotOutputs[1].Deposit()
@@ -134,6 +135,7 @@ var deltaSnapshotHeader = &snapshot.FileHeader{
 	NetworkID:            iotago.NetworkIDFromString("alphanet1"),
 	SEPMilestoneIndex:    5,
 	LedgerMilestoneIndex: 1,
+	Compression:          snapshot.DefaultCompression,
 }
 
 var deltaSnapshotMsDiffs = []*snapshot.MilestoneDiff{